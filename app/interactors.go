@@ -1,37 +1,93 @@
 package app
 
 import (
-	"time"
-
 	"github.com/tadoku/api/infra"
 	"github.com/tadoku/api/usecases"
 )
 
 // Interactors is a collection of all repositories
 type Interactors struct {
-	Session usecases.SessionInteractor
-	Contest usecases.ContestInteractor
-	Ranking usecases.RankingInteractor
-	User    usecases.UserInteractor
+	Session             usecases.SessionInteractor
+	TwoFactor           usecases.TwoFactorInteractor
+	LoginThrottle       usecases.LoginThrottleInteractor
+	PersonalAccessToken usecases.PersonalAccessTokenInteractor
+	Contest             usecases.ContestInteractor
+	Ranking             usecases.RankingInteractor
+	User                usecases.UserInteractor
+	Moderation          usecases.ModerationInteractor
+	Health              usecases.HealthInteractor
 }
 
 // NewInteractors initializes all repositories
 func NewInteractors(
 	r *Repositories,
 	jwtGenerator usecases.JWTGenerator,
-	sessionLength time.Duration,
+	passwordHasher usecases.PasswordHasher,
+	mailer usecases.Mailer,
+	metrics usecases.MetricsRecorder,
+	totpAuthenticator usecases.TOTPAuthenticator,
+	secretBox usecases.SecretBox,
+	loginThrottleConfig usecases.LoginThrottleConfig,
+	sessionConfig usecases.SessionConfig,
 ) *Interactors {
-	passwordHasher := infra.NewPasswordHasher()
+	tokenGenerator := infra.NewTokenGenerator(32)
+	twoFactor := usecases.NewTwoFactorInteractor(
+		r.TwoFactor,
+		r.RecoveryCode,
+		r.User,
+		totpAuthenticator,
+		secretBox,
+		infra.NewTokenGenerator(9),
+		passwordHasher,
+	)
+	loginThrottle := usecases.NewLoginThrottleInteractor(r.LoginAttempt, loginThrottleConfig)
+	auditLogger := usecases.NewAuditLogger(r.AuditEvent)
+	ranking := usecases.NewRankingInteractor(r.Ranking, r.Contest, r.ContestLog, r.User, r.Transactions, metrics, infra.NewValidator())
 
 	return &Interactors{
 		Session: usecases.NewSessionInteractor(
 			r.User,
+			r.OneTimeToken,
+			r.Session,
+			r.RefreshToken,
+			r.TwoFactorChallenge,
+			r.Transactions,
+			twoFactor,
+			loginThrottle,
 			passwordHasher,
 			jwtGenerator,
-			sessionLength,
+			tokenGenerator,
+			mailer,
+			infra.NewValidator(),
+			sessionConfig,
+		),
+		TwoFactor:     twoFactor,
+		LoginThrottle: loginThrottle,
+		PersonalAccessToken: usecases.NewPersonalAccessTokenInteractor(
+			r.PersonalAccessToken,
+			r.User,
+			tokenGenerator,
+			infra.NewValidator(),
+		),
+		Contest: usecases.NewContestInteractor(r.Contest, r.Transactions, auditLogger, infra.NewValidator()),
+		Ranking: ranking,
+		User: usecases.NewUserInteractor(
+			r.User,
+			r.OneTimeToken,
+			r.Session,
+			r.PersonalAccessToken,
+			r.TwoFactor,
+			r.RecoveryCode,
+			r.LoginAttempt,
+			r.Ranking,
+			r.ContestLog,
+			r.Transactions,
+			passwordHasher,
+			tokenGenerator,
+			mailer,
+			infra.NewValidator(),
+			sessionConfig,
 		),
-		Contest: usecases.NewContestInteractor(r.Contest, infra.NewValidator()),
-		Ranking: usecases.NewRankingInteractor(r.Ranking, r.Contest, r.ContestLog, r.User, infra.NewValidator()),
-		User:    usecases.NewUserInteractor(r.User, passwordHasher),
+		Moderation: usecases.NewModerationInteractor(r.User, r.Session, r.AuditEvent, r.Transactions, auditLogger),
 	}
 }
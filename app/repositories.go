@@ -8,18 +8,38 @@ import (
 
 // Repositories is a collection of all repositories
 type Repositories struct {
-	User       usecases.UserRepository
-	Contest    usecases.ContestRepository
-	ContestLog usecases.ContestLogRepository
-	Ranking    usecases.RankingRepository
+	User                usecases.UserRepository
+	OneTimeToken        usecases.OneTimeTokenRepository
+	Session             usecases.SessionRepository
+	RefreshToken        usecases.RefreshTokenRepository
+	PersonalAccessToken usecases.PersonalAccessTokenRepository
+	TwoFactor           usecases.TwoFactorAuthenticationRepository
+	RecoveryCode        usecases.RecoveryCodeRepository
+	TwoFactorChallenge  usecases.TwoFactorChallengeRepository
+	LoginAttempt        usecases.LoginAttemptRepository
+	AuditEvent          usecases.AuditEventRepository
+	Contest             usecases.ContestRepository
+	ContestLog          usecases.ContestLogRepository
+	Ranking             usecases.RankingRepository
+	Transactions        usecases.TransactionManager
 }
 
 // NewRepositories initializes all repositories
 func NewRepositories(sh rdb.SQLHandler) *Repositories {
 	return &Repositories{
-		User:       r.NewUserRepository(sh),
-		Contest:    r.NewContestRepository(sh),
-		ContestLog: r.NewContestLogRepository(sh),
-		Ranking:    r.NewRankingRepository(sh),
+		User:                r.NewUserRepository(sh),
+		OneTimeToken:        r.NewOneTimeTokenRepository(sh),
+		Session:             r.NewSessionRepository(sh),
+		RefreshToken:        r.NewRefreshTokenRepository(sh),
+		PersonalAccessToken: r.NewPersonalAccessTokenRepository(sh),
+		TwoFactor:           r.NewTwoFactorAuthenticationRepository(sh),
+		RecoveryCode:        r.NewRecoveryCodeRepository(sh),
+		TwoFactorChallenge:  r.NewTwoFactorChallengeRepository(sh),
+		LoginAttempt:        r.NewLoginAttemptRepository(sh),
+		AuditEvent:          r.NewAuditEventRepository(sh),
+		Contest:             r.NewContestRepository(sh),
+		ContestLog:          r.NewContestLogRepository(sh),
+		Ranking:             r.NewRankingRepository(sh),
+		Transactions:        r.NewTransactionManager(sh),
 	}
 }
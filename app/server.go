@@ -1,9 +1,14 @@
 package app
 
 import (
+	"context"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/creasty/configo"
@@ -20,9 +25,18 @@ type ServerDependencies interface {
 	AutoConfigure() error
 
 	Init()
+	Shutdown() error
 	Router() services.Router
+	JWTKeys() *infra.JWTKeys
 	JWTGenerator() usecases.JWTGenerator
+	PasswordHasher() usecases.PasswordHasher
+	SecretBox() usecases.SecretBox
 	ErrorReporter() usecases.ErrorReporter
+	Mailer() usecases.Mailer
+	Metrics() *infra.Metrics
+	RankingReconciliation() *infra.PeriodicJob
+	RankingReconciliationEnabled() bool
+	LoginAttemptCleanup() *infra.PeriodicJob
 
 	RDB() *infra.RDB
 	SQLHandler() rdb.SQLHandler
@@ -30,6 +44,7 @@ type ServerDependencies interface {
 	Repositories() *Repositories
 	Interactors() *Interactors
 	Services() *Services
+	Routes() []services.Route
 }
 
 // NewServerDependencies instantiates all the dependencies for the api server
@@ -38,14 +53,50 @@ func NewServerDependencies() ServerDependencies {
 }
 
 type serverDependencies struct {
-	Port                 string        `envconfig:"app_port" valid:"required"`
-	JWTSecret            string        `envconfig:"jwt_secret" valid:"required"`
-	ErrorReporterDSN     string        `envconfig:"error_reporter_dsn"`
-	SessionLength        time.Duration `envconfig:"user_session_length" valid:"required"`
-	DatabaseURL          string        `envconfig:"database_url" valid:"required"`
-	DatabaseMaxIdleConns int           `envconfig:"database_max_idle_conns" valid:"required"`
-	DatabaseMaxOpenConns int           `envconfig:"database_max_open_conns" valid:"required"`
-	CORSAllowedOrigins   []string      `envconfig:"cors_allowed_origins" valid:"required"`
+	Port                       string        `envconfig:"app_port" valid:"required"`
+	FrontendURL                string        `envconfig:"frontend_url" valid:"required"`
+	JWTSecret                  string        `envconfig:"jwt_secret"`
+	JWTKeysDirectory           string        `envconfig:"jwt_keys_directory"`
+	JWTSigningKeyID            string        `envconfig:"jwt_signing_key_id"`
+	ErrorReporterDSN           string        `envconfig:"error_reporter_dsn"`
+	SessionLength              time.Duration `envconfig:"user_session_length" valid:"required"`
+	AccessTokenLifetime        time.Duration `envconfig:"access_token_lifetime" valid:"required"`
+	PasswordResetTokenLifetime time.Duration `envconfig:"password_reset_token_lifetime" valid:"required"`
+	EmailVerificationLifetime  time.Duration `envconfig:"email_verification_token_lifetime" valid:"required"`
+	RequireEmailVerification   bool          `envconfig:"require_email_verification"`
+	TwoFactorIssuer            string        `envconfig:"two_factor_issuer" valid:"required"`
+	TwoFactorChallengeLifetime time.Duration `envconfig:"two_factor_challenge_lifetime" valid:"required"`
+	TwoFactorSecretKey         string        `envconfig:"two_factor_secret_key" valid:"required"`
+	RequireTwoFactorForAdmins  bool          `envconfig:"require_two_factor_for_admins"`
+	LoginThrottleStore         string        `envconfig:"login_throttle_store"`
+	LoginThrottleAccountFree   int           `envconfig:"login_throttle_account_free_attempts" valid:"required"`
+	LoginThrottleIPFree        int           `envconfig:"login_throttle_ip_free_attempts" valid:"required"`
+	LoginThrottleBaseLockout   time.Duration `envconfig:"login_throttle_base_lockout" valid:"required"`
+	LoginThrottleMaxLockout    time.Duration `envconfig:"login_throttle_max_lockout" valid:"required"`
+	LoginThrottleResetAfter    time.Duration `envconfig:"login_throttle_reset_after" valid:"required"`
+	PasswordHashAlgorithm      string        `envconfig:"password_hash_algorithm"`
+	PasswordBcryptCost         int           `envconfig:"password_bcrypt_cost"`
+	PasswordArgon2Time         int           `envconfig:"password_argon2_time"`
+	PasswordArgon2Memory       int           `envconfig:"password_argon2_memory"`
+	PasswordArgon2Threads      int           `envconfig:"password_argon2_threads"`
+	DatabaseURL                string        `envconfig:"database_url" valid:"required"`
+	DatabaseMaxIdleConns       int           `envconfig:"database_max_idle_conns" valid:"required"`
+	DatabaseMaxOpenConns       int           `envconfig:"database_max_open_conns" valid:"required"`
+	DatabaseRequestTimeout     time.Duration `envconfig:"database_request_timeout"`
+	CORSAllowedOrigins         []string      `envconfig:"cors_allowed_origins" valid:"required"`
+	TrustedProxies             []string      `envconfig:"trusted_proxies"`
+	MailerFrom                 string        `envconfig:"mailer_from" valid:"required"`
+	MailerSMTPHost             string        `envconfig:"mailer_smtp_host"`
+	MailerSMTPPort             int           `envconfig:"mailer_smtp_port"`
+	MailerSMTPUsername         string        `envconfig:"mailer_smtp_username"`
+	MailerSMTPPassword         string        `envconfig:"mailer_smtp_password"`
+	MailerDirectory            string        `envconfig:"mailer_directory"`
+	MigrationsDirectory        string        `envconfig:"migrations_directory"`
+	HealthCheckTimeout         time.Duration `envconfig:"health_check_timeout"`
+	ShutdownTimeout            time.Duration `envconfig:"shutdown_timeout"`
+	RankingReconcileEnabled    bool          `envconfig:"ranking_reconcile_enabled"`
+	RankingReconcileInterval   time.Duration `envconfig:"ranking_reconcile_interval"`
+	MetricsToken               string        `envconfig:"metrics_token"`
 
 	router struct {
 		result services.Router
@@ -57,11 +108,46 @@ type serverDependencies struct {
 		once   sync.Once
 	}
 
+	jwtKeys struct {
+		result *infra.JWTKeys
+		once   sync.Once
+	}
+
 	jwtGenerator struct {
 		result usecases.JWTGenerator
 		once   sync.Once
 	}
 
+	passwordHasher struct {
+		result usecases.PasswordHasher
+		once   sync.Once
+	}
+
+	secretBox struct {
+		result usecases.SecretBox
+		once   sync.Once
+	}
+
+	mailer struct {
+		result usecases.Mailer
+		once   sync.Once
+	}
+
+	metrics struct {
+		result *infra.Metrics
+		once   sync.Once
+	}
+
+	rankingReconciliation struct {
+		result *infra.PeriodicJob
+		once   sync.Once
+	}
+
+	loginAttemptCleanup struct {
+		result *infra.PeriodicJob
+		once   sync.Once
+	}
+
 	rdb struct {
 		result *infra.RDB
 		once   sync.Once
@@ -99,7 +185,7 @@ func (d *serverDependencies) AutoConfigure() error {
 func (d *serverDependencies) Services() *Services {
 	holder := &d.services
 	holder.once.Do(func() {
-		holder.result = NewServices(d.Interactors())
+		holder.result = NewServices(d.Interactors(), d.JWTGenerator(), d.Routes)
 	})
 	return holder.result
 }
@@ -112,6 +198,15 @@ func (d *serverDependencies) Repositories() *Repositories {
 	holder := &d.repositories
 	holder.once.Do(func() {
 		holder.result = NewRepositories(d.SQLHandler())
+
+		switch d.LoginThrottleStore {
+		case "", "memory":
+			holder.result.LoginAttempt = infra.NewMemoryLoginAttemptRepository()
+		case "postgres":
+			// Already backed by the database, which is shared between instances
+		default:
+			log.Fatalf("unknown login throttle store: %s", d.LoginThrottleStore)
+		}
 	})
 	return holder.result
 }
@@ -123,11 +218,54 @@ func (d *serverDependencies) Repositories() *Repositories {
 func (d *serverDependencies) Interactors() *Interactors {
 	holder := &d.interactors
 	holder.once.Do(func() {
-		holder.result = NewInteractors(d.Repositories(), d.JWTGenerator(), d.SessionLength)
+		holder.result = NewInteractors(
+			d.Repositories(),
+			d.JWTGenerator(),
+			d.PasswordHasher(),
+			d.Mailer(),
+			d.Metrics(),
+			infra.NewTOTPAuthenticator(d.TwoFactorIssuer),
+			d.SecretBox(),
+			usecases.LoginThrottleConfig{
+				AccountFreeAttempts: d.LoginThrottleAccountFree,
+				IPFreeAttempts:      d.LoginThrottleIPFree,
+				BaseLockout:         d.LoginThrottleBaseLockout,
+				MaxLockout:          d.LoginThrottleMaxLockout,
+				ResetAfter:          d.LoginThrottleResetAfter,
+			},
+			usecases.SessionConfig{
+				SessionLength:              d.SessionLength,
+				AccessTokenLifetime:        d.AccessTokenLifetime,
+				PasswordResetLifetime:      d.PasswordResetTokenLifetime,
+				EmailVerificationLifetime:  d.EmailVerificationLifetime,
+				TwoFactorChallengeLifetime: d.TwoFactorChallengeLifetime,
+				RequireEmailVerification:   d.RequireEmailVerification,
+				RequireTwoFactorForAdmins:  d.RequireTwoFactorForAdmins,
+				FrontendURL:                d.FrontendURL,
+			},
+		)
+		holder.result.Health = d.healthInteractor()
 	})
 	return holder.result
 }
 
+func (d *serverDependencies) healthInteractor() usecases.HealthInteractor {
+	timeout := d.HealthCheckTimeout
+	if timeout == 0 {
+		timeout = 2 * time.Second
+	}
+	migrations := d.MigrationsDirectory
+	if migrations == "" {
+		migrations = "./migrations"
+	}
+
+	return usecases.NewHealthInteractor(
+		infra.NewDatabaseHealthCheck(d.RDB(), timeout),
+		infra.NewMigrationsHealthCheck(d.RDB(), migrations, timeout),
+		infra.NewErrorReporterHealthCheck(d.ErrorReporter()),
+	)
+}
+
 // ------------------------------
 // Router
 // ------------------------------
@@ -135,51 +273,230 @@ func (d *serverDependencies) Interactors() *Interactors {
 func (d *serverDependencies) Router() services.Router {
 	holder := &d.router
 	holder.once.Do(func() {
-		holder.result = infra.NewRouter(d.Port, d.JWTSecret, d.CORSAllowedOrigins, d.ErrorReporter(), d.routes()...)
+		holder.result = infra.NewRouter(
+			d.Port,
+			d.JWTKeys(),
+			d.CORSAllowedOrigins,
+			d.trustedProxies(),
+			d.ErrorReporter(),
+			d.Metrics(),
+			os.Stdout,
+			d.DatabaseRequestTimeout,
+			d.Interactors().Session,
+			d.Interactors().PersonalAccessToken,
+			d.Routes()...,
+		)
 	})
 	return holder.result
 }
 
-func (d *serverDependencies) routes() []services.Route {
+// trustedProxies are the networks that are allowed to tell us who the client is with `X-Forwarded-For`
+func (d *serverDependencies) trustedProxies() []*net.IPNet {
+	proxies := make([]*net.IPNet, 0, len(d.TrustedProxies))
+	for _, cidr := range d.TrustedProxies {
+		_, proxy, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Fatalf("invalid trusted proxy %s: %v\n", cidr, err)
+		}
+		proxies = append(proxies, proxy)
+	}
+
+	return proxies
+}
+
+// Routes lists every route of the api, together with the documentation that's used to generate the OpenAPI specification
+func (d *serverDependencies) Routes() []services.Route {
 	return []services.Route{
 		// Service infra
-		{Method: http.MethodGet, Path: "/ping", HandlerFunc: d.Services().Health.Ping},
+		{Method: http.MethodGet, Path: "/ping", HandlerFunc: d.Services().Health.Ping, Summary: "Check if the api is online", Response: "pong"},
+		{Method: http.MethodGet, Path: "/health/live", HandlerFunc: d.Services().Health.Live, Summary: "Check if the process is running", Response: map[string]usecases.HealthStatus{}},
+		{Method: http.MethodGet, Path: "/health/ready", HandlerFunc: d.Services().Health.Ready, Summary: "Check if the dependencies of the api are available, responds with 503 when they aren't", Response: usecases.HealthReport{}},
+		{Method: http.MethodGet, Path: "/.well-known/jwks.json", HandlerFunc: d.Services().Key.JWKS, Summary: "Public keys that access tokens can be verified with", Response: map[string][]usecases.JSONWebKey{}},
+		{Method: http.MethodGet, Path: "/openapi.json", HandlerFunc: d.Services().Documentation.OpenAPI, Summary: "OpenAPI specification of the api", Response: services.OpenAPIDocument{}},
 
 		// Session
-		{Method: http.MethodPost, Path: "/login", HandlerFunc: d.Services().Session.Login},
-		{Method: http.MethodPost, Path: "/register", HandlerFunc: d.Services().Session.Register},
-		{Method: http.MethodPost, Path: "/refresh", HandlerFunc: d.Services().Session.Refresh, MinRole: domain.RoleUser},
+		{Method: http.MethodPost, Path: "/login", HandlerFunc: d.Services().Session.Login, Summary: "Log in, or start a two factor challenge", Request: services.SessionLoginBody{}, Response: services.SessionLoginResponse{}},
+		{Method: http.MethodPost, Path: "/login/two_factor", HandlerFunc: d.Services().Session.CompleteTwoFactor, Summary: "Finish logging in with a two factor code", Request: services.SessionTwoFactorBody{}, Response: services.SessionResponse{}},
+		{Method: http.MethodPost, Path: "/register", HandlerFunc: d.Services().Session.Register, Summary: "Create an account", Request: domain.User{}, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/refresh", HandlerFunc: d.Services().Session.Refresh, Summary: "Exchange a refresh token for new tokens", Request: services.SessionRefreshBody{}, Response: services.SessionResponse{}},
+		{Method: http.MethodPost, Path: "/logout", HandlerFunc: d.Services().Session.Logout, Summary: "Revoke the session of a refresh token", Request: services.SessionRefreshBody{}},
+		{Method: http.MethodPost, Path: "/sessions/revoke_all", HandlerFunc: d.Services().Session.RevokeAll, MinRole: domain.RoleUser, Summary: "Log out everywhere"},
+		{Method: http.MethodPost, Path: "/password_reset/request", HandlerFunc: d.Services().Session.RequestPasswordReset, Summary: "Send a password reset link", Request: services.SessionRequestPasswordResetBody{}},
+		{Method: http.MethodPost, Path: "/password_reset/confirm", HandlerFunc: d.Services().Session.ConfirmPasswordReset, Summary: "Set a new password with a reset token", Request: services.SessionConfirmPasswordResetBody{}},
+		{Method: http.MethodPost, Path: "/verify_email", HandlerFunc: d.Services().Session.VerifyEmail, Summary: "Verify an email address", Request: services.SessionVerifyEmailBody{}},
+		{Method: http.MethodPost, Path: "/verify_email/resend", HandlerFunc: d.Services().Session.ResendEmailVerification, Summary: "Send a new verification link", Request: services.SessionResendEmailVerificationBody{}},
+		{Method: http.MethodPost, Path: "/email_change/confirm", HandlerFunc: d.Services().User.ConfirmEmailChange, Summary: "Confirm a new email address", Request: services.UserConfirmEmailChangeBody{}, Status: http.StatusOK},
 
 		// Users
-		{Method: http.MethodPost, Path: "/users/update_password", HandlerFunc: d.Services().User.UpdatePassword, MinRole: domain.RoleUser},
-		{Method: http.MethodPost, Path: "/users/profile", HandlerFunc: d.Services().User.UpdateProfile, MinRole: domain.RoleUser},
+		{Method: http.MethodPost, Path: "/users/update_password", HandlerFunc: d.Services().User.UpdatePassword, MinRole: domain.RoleUser, Summary: "Change the password", Request: services.UserUpdatePasswordBody{}, Status: http.StatusOK},
+		{Method: http.MethodPost, Path: "/users/profile", HandlerFunc: d.Services().User.UpdateProfile, MinRole: domain.RoleUser, Summary: "Change the display name", Request: services.UserUpdateProfileBody{}, Status: http.StatusOK},
+		{Method: http.MethodGet, Path: "/users/:id/profile", HandlerFunc: d.Services().User.Profile, Summary: "Public profile of a user", Response: domain.UserProfile{}},
+		{Method: http.MethodGet, Path: "/users/preferences", HandlerFunc: d.Services().User.Preferences, MinRole: domain.RoleUser, Summary: "Preferences of the current user", Response: domain.Preferences{}},
+		{Method: http.MethodPut, Path: "/users/preferences", HandlerFunc: d.Services().User.UpdatePreferences, MinRole: domain.RoleUser, Summary: "Update the preferences of the current user", Request: domain.Preferences{}, Response: domain.Preferences{}},
+		{Method: http.MethodPost, Path: "/users/email", HandlerFunc: d.Services().User.RequestEmailChange, MinRole: domain.RoleUser, Summary: "Send a confirmation link to a new email address", Request: services.UserRequestEmailChangeBody{}, Status: http.StatusOK},
+		{Method: http.MethodGet, Path: "/users/export", HandlerFunc: d.Services().User.Export, MinRole: domain.RoleUser, Summary: "Export all data of the current user, as a zip of csv files with format=csv", Response: usecases.UserExport{}, Query: []string{"format"}},
+		{Method: http.MethodDelete, Path: "/users/me", HandlerFunc: d.Services().User.Delete, MinRole: domain.RoleUser, Summary: "Delete the current user", Request: services.UserDeleteBody{}, Status: http.StatusOK},
+		{Method: http.MethodGet, Path: "/users/sessions", HandlerFunc: d.Services().Session.ActiveSessions, MinRole: domain.RoleUser, Summary: "Sessions of the current user", Response: []services.SessionListEntry{}},
+		{Method: http.MethodDelete, Path: "/users/sessions/:id", HandlerFunc: d.Services().Session.RevokeSession, MinRole: domain.RoleUser, Summary: "Revoke a session"},
+		{Method: http.MethodGet, Path: "/users/two_factor", HandlerFunc: d.Services().TwoFactor.Status, MinRole: domain.RoleUser, Summary: "Whether two factor authentication is enabled", Response: map[string]bool{}},
+		{Method: http.MethodPost, Path: "/users/two_factor", HandlerFunc: d.Services().TwoFactor.Enroll, MinRole: domain.RoleUser, Summary: "Start enabling two factor authentication", Request: services.TwoFactorEnrollBody{}, Response: usecases.TwoFactorEnrollment{}},
+		{Method: http.MethodPost, Path: "/users/two_factor/confirm", HandlerFunc: d.Services().TwoFactor.Confirm, MinRole: domain.RoleUser, Summary: "Enable two factor authentication, responds with recovery codes", Request: services.TwoFactorCodeBody{}, Response: map[string][]string{}},
+		{Method: http.MethodPost, Path: "/users/two_factor/disable", HandlerFunc: d.Services().TwoFactor.Disable, MinRole: domain.RoleUser, Summary: "Disable two factor authentication", Request: services.TwoFactorDisableBody{}},
+		{Method: http.MethodGet, Path: "/users/tokens", HandlerFunc: d.Services().PersonalAccessToken.List, MinRole: domain.RoleUser, Summary: "Personal access tokens of the current user", Response: domain.PersonalAccessTokens{}},
+		{Method: http.MethodPost, Path: "/users/tokens", HandlerFunc: d.Services().PersonalAccessToken.Create, MinRole: domain.RoleUser, Summary: "Create a personal access token, the token is only shown once", Request: services.PersonalAccessTokenCreateBody{}, Response: services.PersonalAccessTokenCreated{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Path: "/users/tokens/:id", HandlerFunc: d.Services().PersonalAccessToken.Revoke, MinRole: domain.RoleUser, Summary: "Revoke a personal access token"},
+
+		// Admin
+		{Method: http.MethodGet, Path: "/admin/users", HandlerFunc: d.Services().Moderation.Users, MinRole: domain.RoleAdmin, Summary: "Search users", Response: domain.Users{}, Query: []string{"query", "page"}},
+		{Method: http.MethodPut, Path: "/admin/users/:id/role", HandlerFunc: d.Services().Moderation.UpdateRole, MinRole: domain.RoleAdmin, Summary: "Change the role of a user", Request: services.ModerationRoleBody{}},
+		{Method: http.MethodPost, Path: "/admin/users/:id/disable", HandlerFunc: d.Services().Moderation.Disable, MinRole: domain.RoleAdmin, Summary: "Disable a user and log them out everywhere", Request: services.ModerationDisableBody{}},
+		{Method: http.MethodPost, Path: "/admin/users/:id/enable", HandlerFunc: d.Services().Moderation.Enable, MinRole: domain.RoleAdmin, Summary: "Enable a disabled user"},
+		{Method: http.MethodGet, Path: "/admin/audit", HandlerFunc: d.Services().Moderation.Audit, MinRole: domain.RoleAdmin, Summary: "Audit log of privileged actions", Response: domain.AuditEvents{}, Query: []string{"page", "action", "target_type", "actor_id", "target_id"}},
 
 		// Contests
-		{Method: http.MethodGet, Path: "/contests", HandlerFunc: d.Services().Contest.All},
-		{Method: http.MethodGet, Path: "/contests/:id", HandlerFunc: d.Services().Contest.Get},
-		{Method: http.MethodPost, Path: "/contests", HandlerFunc: d.Services().Contest.Create, MinRole: domain.RoleAdmin},
-		{Method: http.MethodPut, Path: "/contests/:id", HandlerFunc: d.Services().Contest.Update, MinRole: domain.RoleAdmin},
+		{Method: http.MethodGet, Path: "/contests", HandlerFunc: d.Services().Contest.All, Summary: "Most recent contests", Response: []domain.Contest{}, Query: []string{"limit"}},
+		{Method: http.MethodGet, Path: "/contests/:id", HandlerFunc: d.Services().Contest.Get, Summary: "A single contest", Response: domain.Contest{}},
+		{Method: http.MethodPost, Path: "/contests", HandlerFunc: d.Services().Contest.Create, MinRole: domain.RoleAdmin, Summary: "Create a contest", Request: domain.Contest{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/contests/:id", HandlerFunc: d.Services().Contest.Update, MinRole: domain.RoleAdmin, Summary: "Update a contest", Request: domain.Contest{}},
 
 		// Rankings
-		{Method: http.MethodGet, Path: "/rankings/current", HandlerFunc: d.Services().Ranking.CurrentRegistration, MinRole: domain.RoleUser},
-		{Method: http.MethodGet, Path: "/rankings/registration", HandlerFunc: d.Services().Ranking.RankingsForRegistration},
-		{Method: http.MethodPost, Path: "/rankings", HandlerFunc: d.Services().Ranking.Create, MinRole: domain.RoleUser},
+		{Method: http.MethodGet, Path: "/rankings/current", HandlerFunc: d.Services().Ranking.CurrentRegistration, MinRole: domain.RoleUser, Scope: domain.ScopeRankingsRead, Summary: "Languages the current user signed up with for the running contest", Response: domain.RankingRegistration{}},
+		{Method: http.MethodGet, Path: "/rankings/registration", HandlerFunc: d.Services().Ranking.RankingsForRegistration, Summary: "Rankings of a user in a contest", Response: []domain.RankingView{}, Query: []string{"contest_id", "user_id"}},
+		{Method: http.MethodPost, Path: "/rankings", HandlerFunc: d.Services().Ranking.Create, MinRole: domain.RoleUser, Summary: "Sign up for a contest", Request: services.CreateRankingPayload{}, Status: http.StatusCreated},
 		// TODO: Rename Get to All
-		{Method: http.MethodGet, Path: "/rankings", HandlerFunc: d.Services().Ranking.Get},
+		{Method: http.MethodGet, Path: "/rankings", HandlerFunc: d.Services().Ranking.Get, Summary: "Leaderboard of a contest", Response: []domain.RankingView{}, Query: []string{"contest_id", "language"}},
 
 		// Contest logs
-		{Method: http.MethodPost, Path: "/contest_logs", HandlerFunc: d.Services().ContestLog.Create, MinRole: domain.RoleUser},
+		{Method: http.MethodPost, Path: "/contest_logs", HandlerFunc: d.Services().ContestLog.Create, MinRole: domain.RoleUser, Scope: domain.ScopeLogsWrite, Summary: "Log reading for a contest", Request: domain.ContestLog{}, Status: http.StatusCreated},
 		// TODO: Rename Get to All
-		{Method: http.MethodGet, Path: "/contest_logs", HandlerFunc: d.Services().ContestLog.Get},
-		{Method: http.MethodPut, Path: "/contest_logs/:id", HandlerFunc: d.Services().ContestLog.Update, MinRole: domain.RoleUser},
-		{Method: http.MethodDelete, Path: "/contest_logs/:id", HandlerFunc: d.Services().ContestLog.Delete, MinRole: domain.RoleUser},
+		{Method: http.MethodGet, Path: "/contest_logs", HandlerFunc: d.Services().ContestLog.Get, Summary: "Logs of a user in a contest", Response: []domain.ContestLogView{}, Query: []string{"contest_id", "user_id"}},
+		{Method: http.MethodPut, Path: "/contest_logs/:id", HandlerFunc: d.Services().ContestLog.Update, MinRole: domain.RoleUser, Scope: domain.ScopeLogsWrite, Summary: "Update a log", Request: domain.ContestLog{}},
+		{Method: http.MethodDelete, Path: "/contest_logs/:id", HandlerFunc: d.Services().ContestLog.Delete, MinRole: domain.RoleUser, Scope: domain.ScopeLogsWrite, Summary: "Delete a log", Status: http.StatusOK},
 	}
 }
 
+func (d *serverDependencies) JWTKeys() *infra.JWTKeys {
+	holder := &d.jwtKeys
+	holder.once.Do(func() {
+		if d.JWTKeysDirectory == "" {
+			if d.JWTSecret == "" {
+				log.Fatalf("either a jwt secret or a directory with jwt keys is required\n")
+			}
+
+			holder.result = infra.NewSecretJWTKeys(d.JWTSecret)
+			return
+		}
+
+		var err error
+		holder.result, err = infra.LoadJWTKeys(d.JWTKeysDirectory, d.JWTSigningKeyID)
+
+		if err != nil {
+			log.Fatalf("failed to load jwt keys: %v\n", err)
+		}
+	})
+	return holder.result
+}
+
+func (d *serverDependencies) PasswordHasher() usecases.PasswordHasher {
+	holder := &d.passwordHasher
+	holder.once.Do(func() {
+		var err error
+		holder.result, err = infra.NewPasswordHasher(infra.PasswordHasherConfig{
+			Algorithm:     d.PasswordHashAlgorithm,
+			BcryptCost:    d.PasswordBcryptCost,
+			Argon2Time:    uint32(d.PasswordArgon2Time),
+			Argon2Memory:  uint32(d.PasswordArgon2Memory),
+			Argon2Threads: uint8(d.PasswordArgon2Threads),
+		})
+
+		if err != nil {
+			log.Fatalf("failed to set up password hashing: %v\n", err)
+		}
+	})
+	return holder.result
+}
+
+// SecretBox encrypts the secrets of authenticator apps, they're useless without TwoFactorSecretKey
+func (d *serverDependencies) SecretBox() usecases.SecretBox {
+	holder := &d.secretBox
+	holder.once.Do(func() {
+		var err error
+		holder.result, err = infra.NewSecretBox(d.TwoFactorSecretKey)
+
+		if err != nil {
+			log.Fatalf("failed to set up secret encryption: %v\n", err)
+		}
+	})
+	return holder.result
+}
+
 func (d *serverDependencies) JWTGenerator() usecases.JWTGenerator {
 	holder := &d.jwtGenerator
 	holder.once.Do(func() {
-		holder.result = infra.NewJWTGenerator(d.JWTSecret)
+		holder.result = infra.NewJWTGenerator(d.JWTKeys())
+	})
+	return holder.result
+}
+
+func (d *serverDependencies) Mailer() usecases.Mailer {
+	holder := &d.mailer
+	holder.once.Do(func() {
+		if d.MailerSMTPHost == "" {
+			holder.result = infra.NewFileMailer(d.MailerDirectory, d.MailerFrom)
+			return
+		}
+
+		holder.result = infra.NewSMTPMailer(
+			d.MailerSMTPHost,
+			d.MailerSMTPPort,
+			d.MailerSMTPUsername,
+			d.MailerSMTPPassword,
+			d.MailerFrom,
+		)
+	})
+	return holder.result
+}
+
+func (d *serverDependencies) Metrics() *infra.Metrics {
+	holder := &d.metrics
+	holder.once.Do(func() {
+		holder.result = infra.NewMetrics(d.RDB(), d.MetricsToken)
+	})
+	return holder.result
+}
+
+// RankingReconciliation repairs rankings that drifted from their logs, as logs only add their difference to rankings
+func (d *serverDependencies) RankingReconciliation() *infra.PeriodicJob {
+	holder := &d.rankingReconciliation
+	holder.once.Do(func() {
+		interval := d.RankingReconcileInterval
+		if interval == 0 {
+			interval = 15 * time.Minute
+		}
+
+		holder.result = infra.NewPeriodicJob("ranking reconciliation", interval, d.ErrorReporter(), func(ctx context.Context) error {
+			repaired, err := d.Interactors().Ranking.ReconcileRankings(ctx)
+			if repaired > 0 {
+				log.Printf("repaired the rankings of %d users that drifted from their logs\n", repaired)
+			}
+			return err
+		})
+	})
+	return holder.result
+}
+
+// RankingReconciliationEnabled tells whether this instance should repair rankings, only one instance should
+func (d *serverDependencies) RankingReconciliationEnabled() bool {
+	return d.RankingReconcileEnabled
+}
+
+// LoginAttemptCleanup removes failed logins that have been forgotten already, so they don't pile up in the database
+func (d *serverDependencies) LoginAttemptCleanup() *infra.PeriodicJob {
+	holder := &d.loginAttemptCleanup
+	holder.once.Do(func() {
+		holder.result = infra.NewPeriodicJob("login attempt cleanup", time.Hour, d.ErrorReporter(), func(ctx context.Context) error {
+			return d.Interactors().LoginThrottle.ForgetStaleFailures(ctx)
+		})
 	})
 	return holder.result
 }
@@ -202,6 +519,41 @@ func (d *serverDependencies) Init() {
 	_ = d.ErrorReporter()
 }
 
+// Shutdown drains the requests in flight before releasing everything they could still be using
+func (d *serverDependencies) Shutdown() error {
+	timeout := d.ShutdownTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := d.Router().Shutdown(ctx)
+	if err != nil {
+		err = domain.WrapError(err)
+	}
+
+	if stopErr := d.RankingReconciliation().Stop(ctx); stopErr != nil && err == nil {
+		err = stopErr
+	}
+	if stopErr := d.LoginAttemptCleanup().Stop(ctx); stopErr != nil && err == nil {
+		err = stopErr
+	}
+
+	if reporter := d.ErrorReporter(); reporter != nil {
+		deadline, _ := ctx.Deadline()
+		if !reporter.Flush(time.Until(deadline)) {
+			log.Println("not all errors were sent off before shutting down")
+		}
+	}
+
+	if closeErr := d.RDB().Close(); closeErr != nil && err == nil {
+		err = domain.WrapError(closeErr)
+	}
+
+	return err
+}
+
 // ------------------------------
 // Relational database
 // ------------------------------
@@ -228,10 +580,32 @@ func (d *serverDependencies) SQLHandler() rdb.SQLHandler {
 	return holder.result
 }
 
-// RunServer starts the actual API server
+// RunServer starts the actual API server, it keeps running until the process is told to stop
 func RunServer(d ServerDependencies) error {
 	d.Init()
 
+	if d.RankingReconciliationEnabled() {
+		d.RankingReconciliation().Start()
+	}
+	d.LoginAttemptCleanup().Start()
+
 	router := d.Router()
-	return router.StartListening()
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- router.StartListening()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+	defer signal.Stop(signals)
+
+	select {
+	case err := <-stopped:
+		// The server couldn't start, eg. because the port is already taken
+		return err
+	case sig := <-signals:
+		log.Printf("received %s, shutting down\n", sig)
+	}
+
+	return d.Shutdown()
 }
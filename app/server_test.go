@@ -0,0 +1,52 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/interfaces/services"
+)
+
+func TestServerDependencies_RoutesAreDocumented(t *testing.T) {
+	d := &serverDependencies{
+		JWTSecret:          "secret",
+		TwoFactorSecretKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+		DatabaseURL:        "postgres://localhost/tadoku",
+	}
+
+	routes := d.Routes()
+	doc := services.NewOpenAPIDocument(APIVersion, routes)
+
+	for _, route := range routes {
+		name := route.Method + " " + route.Path
+		assert.True(t, route.Documented(), "%s needs a summary, and typed request and response bodies", name)
+
+		path := route.Path
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasPrefix(segment, ":") {
+				path = strings.Replace(path, segment, "{"+strings.TrimPrefix(segment, ":")+"}", 1)
+			}
+		}
+		_, ok := doc.Paths[path][strings.ToLower(route.Method)]
+		assert.True(t, ok, "%s is missing from the specification", name)
+	}
+
+	_, err := json.Marshal(doc)
+	assert.NoError(t, err)
+}
+
+func TestServerDependencies_Shutdown(t *testing.T) {
+	d := &serverDependencies{
+		JWTSecret:          "secret",
+		TwoFactorSecretKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+		DatabaseURL:        "postgres://localhost/tadoku",
+		ShutdownTimeout:    time.Second,
+	}
+
+	assert.NoError(t, d.Shutdown())
+	assert.EqualError(t, d.RDB().Ping(), "sql: database is closed")
+}
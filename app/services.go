@@ -2,26 +2,40 @@ package app
 
 import (
 	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
 )
 
+// APIVersion is the version of the api that's published in the OpenAPI specification
+const APIVersion = "1.0.0"
+
 // Services is a collection of all services
 type Services struct {
-	Health     services.HealthService
-	Session    services.SessionService
-	Contest    services.ContestService
-	Ranking    services.RankingService
-	ContestLog services.ContestLogService
-	User       services.UserService
+	Health              services.HealthService
+	Key                 services.KeyService
+	Session             services.SessionService
+	TwoFactor           services.TwoFactorService
+	PersonalAccessToken services.PersonalAccessTokenService
+	Contest             services.ContestService
+	Ranking             services.RankingService
+	ContestLog          services.ContestLogService
+	User                services.UserService
+	Moderation          services.ModerationService
+	Documentation       services.DocumentationService
 }
 
 // NewServices initializes all interactors
-func NewServices(i *Interactors) *Services {
+func NewServices(i *Interactors, jwtGenerator usecases.JWTGenerator, routes func() []services.Route) *Services {
 	return &Services{
-		Health:     services.NewHealthService(),
-		Session:    services.NewSessionService(i.Session),
-		Contest:    services.NewContestService(i.Contest),
-		Ranking:    services.NewRankingService(i.Ranking),
-		ContestLog: services.NewContestLogService(i.Ranking),
-		User:       services.NewUserService(i.User),
+		Health:              services.NewHealthService(i.Health),
+		Key:                 services.NewKeyService(jwtGenerator),
+		Session:             services.NewSessionService(i.Session, i.LoginThrottle),
+		TwoFactor:           services.NewTwoFactorService(i.TwoFactor),
+		PersonalAccessToken: services.NewPersonalAccessTokenService(i.PersonalAccessToken),
+		Contest:             services.NewContestService(i.Contest),
+		Ranking:             services.NewRankingService(i.Ranking),
+		ContestLog:          services.NewContestLogService(i.Ranking),
+		User:                services.NewUserService(i.User),
+		Moderation:          services.NewModerationService(i.Moderation),
+		Documentation:       services.NewDocumentationService(APIVersion, routes),
 	}
 }
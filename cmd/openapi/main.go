@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/tadoku/api/app"
+	"github.com/tadoku/api/interfaces/services"
+)
+
+func main() {
+	output := flag.String("output", "openapi.json", "where the specification is written to")
+	flag.Parse()
+
+	deps := app.NewServerDependencies()
+	err := deps.AutoConfigure()
+	if err != nil {
+		panic(fmt.Sprintf("Specification cannot be generated: %v\n", err))
+	}
+
+	doc := services.NewOpenAPIDocument(app.APIVersion, deps.Routes())
+	body, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("Could not encode specification: %v\n", err)
+	}
+
+	err = ioutil.WriteFile(*output, body, 0644)
+	if err != nil {
+		log.Fatalf("Could not write specification: %v\n", err)
+	}
+}
@@ -13,5 +13,8 @@ func main() {
 		panic(fmt.Sprintf("Server cannot be started: %v\n", err))
 	}
 
-	app.RunServer(deps)
+	err = app.RunServer(deps)
+	if err != nil {
+		panic(fmt.Sprintf("Server stopped unexpectedly: %v\n", err))
+	}
 }
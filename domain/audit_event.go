@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/srvc/fail"
+)
+
+// AuditAction describes what an admin did, it's prefixed with the type of target it was done to
+type AuditAction string
+
+// These are all the actions that end up in the audit log
+const (
+	AuditActionContestCreate AuditAction = "contest.create"
+	AuditActionContestUpdate AuditAction = "contest.update"
+	AuditActionUserRole      AuditAction = "user.role"
+	AuditActionUserDisable   AuditAction = "user.disable"
+	AuditActionUserEnable    AuditAction = "user.enable"
+)
+
+// TargetType is the kind of entity the action was done to, e.g. contest for contest.create
+func (a AuditAction) TargetType() string {
+	return strings.SplitN(string(a), ".", 2)[0]
+}
+
+// AuditEvent is a record of a privileged action, who did it, to what and from where
+type AuditEvent struct {
+	ID         uint64       `json:"id" db:"id"`
+	ActorID    uint64       `json:"actor_id" db:"actor_id"`
+	Action     AuditAction  `json:"action" db:"action"`
+	TargetType string       `json:"target_type" db:"target_type"`
+	TargetID   uint64       `json:"target_id" db:"target_id"`
+	Changes    AuditChanges `json:"changes" db:"changes"`
+	IPAddress  string       `json:"ip_address" db:"ip_address"`
+	UserAgent  string       `json:"user_agent" db:"user_agent"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+}
+
+// AuditEvents is a collection of audit events
+type AuditEvents []AuditEvent
+
+// AuditEventFilter narrows down the audit log, zero values match everything
+type AuditEventFilter struct {
+	ActorID    uint64
+	Action     AuditAction
+	TargetType string
+	TargetID   uint64
+}
+
+// AuditChange holds the JSON value of a single field before and after an action
+type AuditChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditChanges holds every field that was changed by an action
+type AuditChanges map[string]AuditChange
+
+// NewAuditChanges diffs the JSON representation of an entity, either side can be nil for when it was created or removed
+func NewAuditChanges(before, after interface{}) (AuditChanges, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := AuditChanges{}
+	for field, value := range beforeFields {
+		if !bytes.Equal(value, afterFields[field]) {
+			changes[field] = AuditChange{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = AuditChange{After: value}
+		}
+	}
+
+	return changes, nil
+}
+
+func auditFields(entity interface{}) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if entity == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, WrapError(err)
+	}
+
+	err = json.Unmarshal(data, &fields)
+	return fields, WrapError(err)
+}
+
+// Value implements the driver.Valuer interface
+func (c AuditChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(c)
+}
+
+// Scan implements the sql.Scanner interface
+func (c *AuditChanges) Scan(src interface{}) error {
+	value := reflect.ValueOf(src)
+	if !value.IsValid() || value.IsNil() {
+		return nil
+	}
+
+	if data, ok := src.([]byte); ok {
+		return json.Unmarshal(data, c)
+	}
+
+	return fail.Errorf("could not not decode type %T -> %T", src, c)
+}
@@ -0,0 +1,66 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tadoku/api/domain"
+)
+
+func TestAuditAction_TargetType(t *testing.T) {
+	assert.Equal(t, "contest", domain.AuditActionContestCreate.TargetType())
+	assert.Equal(t, "user", domain.AuditActionUserDisable.TargetType())
+}
+
+func TestNewAuditChanges(t *testing.T) {
+	before := domain.Contest{
+		ID:          1,
+		Description: "Round 1",
+		Start:       time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC),
+		Open:        true,
+	}
+
+	{
+		after := before
+		after.Open = false
+
+		changes, err := domain.NewAuditChanges(before, after)
+		require.NoError(t, err)
+		assert.Equal(t, domain.AuditChanges{
+			"open": {Before: json.RawMessage("true"), After: json.RawMessage("false")},
+		}, changes)
+	}
+
+	{
+		changes, err := domain.NewAuditChanges(before, before)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	}
+
+	{
+		changes, err := domain.NewAuditChanges(nil, before)
+		require.NoError(t, err)
+		assert.Len(t, changes, 5)
+		assert.Nil(t, changes["description"].Before)
+		assert.Equal(t, json.RawMessage(`"Round 1"`), changes["description"].After)
+	}
+}
+
+func TestAuditChanges_Value(t *testing.T) {
+	var empty domain.AuditChanges
+	value, err := empty.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("{}"), value)
+
+	changes := domain.AuditChanges{"role": {Before: json.RawMessage("1"), After: json.RawMessage("2")}}
+	value, err = changes.Value()
+	assert.NoError(t, err)
+
+	decoded := domain.AuditChanges{}
+	assert.NoError(t, decoded.Scan(value))
+	assert.Equal(t, changes, decoded)
+}
@@ -26,14 +26,16 @@ var ErrContestInvalidDateTooOld = fail.New("contest must end in the future")
 
 // Validate a contest
 func (c Contest) Validate() (bool, error) {
+	var errs FieldErrors
+
 	if c.Start.After(c.End) {
-		return false, ErrContestInvalidDateOrder
+		errs = append(errs, NewFieldError("end", "after_start", ErrContestInvalidDateOrder))
 	}
 	if c.End.Before(time.Now()) {
-		return false, ErrContestInvalidDateTooOld
+		errs = append(errs, NewFieldError("end", "future", ErrContestInvalidDateTooOld))
 	}
 
-	return true, nil
+	return errs.Result()
 }
 
 // ContestID is a container for contest ids with some domain logic
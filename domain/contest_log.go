@@ -23,11 +23,13 @@ type ContestLogs []ContestLog
 
 // Validate a contest log
 func (c ContestLog) Validate() (bool, error) {
+	var errs FieldErrors
+
 	if valid, err := c.MediumID.Validate(); !valid {
-		return valid, err
+		errs = append(errs, NewFieldError("medium_id", "medium", err))
 	}
 
-	return true, nil
+	return errs.Result()
 }
 
 // AdjustedAmount gives the amount after having taken into account the medium
@@ -35,8 +37,8 @@ func (c ContestLog) AdjustedAmount() float32 {
 	return c.MediumID.AdjustedAmount(c.Amount)
 }
 
-// GetView gets the external view representation of a contest log
-func (c ContestLog) GetView() ContestLogView {
+// GetView gets the external view representation of a contest log, the display amount is in the given units
+func (c ContestLog) GetView(units Units) ContestLogView {
 	return ContestLogView{
 		ID:             c.ID,
 		ContestID:      c.ContestID,
@@ -45,17 +47,19 @@ func (c ContestLog) GetView() ContestLogView {
 		MediumID:       c.MediumID,
 		Amount:         c.Amount,
 		AdjustedAmount: c.AdjustedAmount(),
+		DisplayAmount:  units.Amount(c.AdjustedAmount(), c.Amount),
+		Units:          units,
 		Description:    c.Description,
 		Date:           c.CreatedAt,
 	}
 }
 
 // GetView gets the external view representation of a contest log collection
-func (c ContestLogs) GetView() []ContestLogView {
+func (c ContestLogs) GetView(units Units) []ContestLogView {
 	result := make([]ContestLogView, len(c))
 
 	for i, val := range c {
-		result[i] = val.GetView()
+		result[i] = val.GetView(units)
 	}
 
 	return result
@@ -70,6 +74,8 @@ type ContestLogView struct {
 	MediumID       MediumID     `json:"medium_id"`
 	Amount         float32      `json:"amount"`
 	AdjustedAmount float32      `json:"adjusted_amount"`
+	DisplayAmount  float32      `json:"display_amount"`
+	Units          Units        `json:"units"`
 	Description    string       `json:"description"`
 	Date           time.Time    `json:"date"`
 }
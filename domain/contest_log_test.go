@@ -34,6 +34,26 @@ func TestContestLog_Validate(t *testing.T) {
 
 		valid, err := log.Validate()
 		assert.Equal(t, false, valid)
-		assert.Equal(t, ErrMediumNotFound, err)
+		assert.Equal(t, FieldErrors{NewFieldError("medium_id", "medium", ErrMediumNotFound)}, err)
+	}
+}
+
+func TestContestLog_GetView(t *testing.T) {
+	log := ContestLog{ContestID: 1, UserID: 1, Language: Japanese, Amount: 10, MediumID: MediumComic}
+
+	// In points
+	{
+		view := log.GetView(UnitsPoints)
+		assert.Equal(t, float32(10), view.Amount)
+		assert.Equal(t, float32(2), view.AdjustedAmount)
+		assert.Equal(t, float32(2), view.DisplayAmount)
+		assert.Equal(t, UnitsPoints, view.Units)
+	}
+
+	// In pages
+	{
+		view := log.GetView(UnitsPages)
+		assert.Equal(t, float32(10), view.DisplayAmount)
+		assert.Equal(t, UnitsPages, view.Units)
 	}
 }
@@ -12,6 +12,12 @@ var ErrNotFound = sql.ErrNoRows
 // ErrInsufficientPermissions for when access to a resource is denied
 var ErrInsufficientPermissions = fail.New("need higher permissions for this resource")
 
+// ErrAlreadyExists for when an entity conflicts with one that has already been stored
+var ErrAlreadyExists = fail.New("entity already exists")
+
+// ErrDeadlineExceeded for when the database didn't respond before the deadline of the request
+var ErrDeadlineExceeded = fail.New("database took too long to respond")
+
 // WrapError wraps errors except for domain logic related ones
 func WrapError(err error, annotators ...fail.Annotator) error {
 	if err == ErrNotFound {
@@ -20,6 +26,9 @@ func WrapError(err error, annotators ...fail.Annotator) error {
 	if err == ErrInsufficientPermissions {
 		return err
 	}
+	if err == ErrAlreadyExists {
+		return err
+	}
 
 	return fail.Wrap(err, annotators...)
 }
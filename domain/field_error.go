@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"strings"
+)
+
+// FieldError describes why a single field is invalid, rule is a machine readable name of the check that failed
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// FieldErrors holds every field that is invalid, it can be returned as an error
+type FieldErrors []FieldError
+
+// NewFieldError points out the field a domain error was caused by
+func NewFieldError(field, rule string, err error) FieldError {
+	return FieldError{Field: field, Rule: rule, Message: err.Error()}
+}
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Message
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Result turns the errors into the return values of Validate()
+func (e FieldErrors) Result() (bool, error) {
+	if len(e) == 0 {
+		return true, nil
+	}
+
+	return false, e
+}
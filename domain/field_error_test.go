@@ -0,0 +1,30 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+)
+
+func TestFieldErrors_Result(t *testing.T) {
+	{
+		// Happy path: no errors
+		valid, err := domain.FieldErrors{}.Result()
+		assert.True(t, valid)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: all messages are included in the error
+		errs := domain.FieldErrors{
+			domain.NewFieldError("end", "after_start", domain.ErrContestInvalidDateOrder),
+			domain.NewFieldError("end", "future", domain.ErrContestInvalidDateTooOld),
+		}
+
+		valid, err := errs.Result()
+		assert.False(t, valid)
+		assert.EqualError(t, err, "contest must start before it can end; contest must end in the future")
+	}
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// LoginAttempt keeps track of failed logins for an account or an IP address
+type LoginAttempt struct {
+	Key          string    `db:"key"`
+	Failures     int       `db:"failures"`
+	LastFailedAt time.Time `db:"last_failed_at"`
+}
+
+// LoginAttemptAccountKey is the key failed logins for an email address are tracked under. The address is hashed
+// so the key has a fixed length, no matter what gets typed into the login form, and doesn't hold on to personal data.
+func LoginAttemptAccountKey(email string) string {
+	return "account:" + HashToken(strings.ToLower(strings.TrimSpace(email)))
+}
+
+// LoginAttemptIPKey is the key failed logins from an IP address are tracked under
+func LoginAttemptIPKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
+
+// LockedUntil tells you when logging in is allowed again. The first couple of failures are free,
+// every failure after that doubles the lockout up to the given maximum.
+func (a LoginAttempt) LockedUntil(freeAttempts int, baseLockout, maxLockout time.Duration) time.Time {
+	if a.Failures <= freeAttempts {
+		return time.Time{}
+	}
+
+	lockout := baseLockout
+	for n := freeAttempts + 1; n < a.Failures && lockout < maxLockout; n++ {
+		lockout *= 2
+	}
+	if lockout > maxLockout {
+		lockout = maxLockout
+	}
+
+	return a.LastFailedAt.Add(lockout)
+}
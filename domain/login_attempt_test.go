@@ -0,0 +1,37 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+)
+
+func TestLoginAttempt_LockedUntil(t *testing.T) {
+	failedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		failures int
+		expected time.Time
+	}{
+		{failures: 0, expected: time.Time{}},
+		{failures: 5, expected: time.Time{}},
+		{failures: 6, expected: failedAt.Add(1 * time.Second)},
+		{failures: 7, expected: failedAt.Add(2 * time.Second)},
+		{failures: 8, expected: failedAt.Add(4 * time.Second)},
+		{failures: 12, expected: failedAt.Add(60 * time.Second)},
+		{failures: 1000, expected: failedAt.Add(60 * time.Second)},
+	} {
+		attempt := domain.LoginAttempt{Failures: tc.failures, LastFailedAt: failedAt}
+		assert.Equal(t, tc.expected, attempt.LockedUntil(5, time.Second, time.Minute), "%d failures", tc.failures)
+	}
+}
+
+func TestLoginAttemptKeys(t *testing.T) {
+	assert.Equal(t, "account:"+domain.HashToken("foo@bar.com"), domain.LoginAttemptAccountKey(" Foo@Bar.com"))
+	assert.Len(t, domain.LoginAttemptAccountKey(strings.Repeat("a", 1000)+"@bar.com"), len("account:")+64)
+	assert.Equal(t, "ip:127.0.0.1", domain.LoginAttemptIPKey("127.0.0.1"))
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+)
+
+// TokenPurpose describes what a one-time token can be exchanged for
+type TokenPurpose string
+
+// All purposes a one-time token can be issued for
+const (
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposeEmailChange       TokenPurpose = "email_change"
+)
+
+// OneTimeToken is mailed to a user to prove they have access to an inbox, it can only be used once
+type OneTimeToken struct {
+	ID      uint64       `json:"id" db:"id"`
+	UserID  uint64       `json:"user_id" db:"user_id"`
+	Purpose TokenPurpose `json:"purpose" db:"purpose"`
+	// Payload holds what the token confirms, such as the new address of an email change
+	Payload   string     `json:"payload" db:"payload"`
+	Hash      string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsUsable tells you if the token can still be used at the given time
+func (t OneTimeToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,34 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+)
+
+func TestOneTimeToken_IsUsable(t *testing.T) {
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	usedAt := now.Add(-1 * time.Minute)
+
+	var tests = []struct {
+		token    domain.OneTimeToken
+		expected bool
+	}{
+		{domain.OneTimeToken{ExpiresAt: now.Add(1 * time.Hour)}, true},
+		{domain.OneTimeToken{ExpiresAt: now.Add(-1 * time.Hour)}, false},
+		{domain.OneTimeToken{ExpiresAt: now}, false},
+		{domain.OneTimeToken{ExpiresAt: now.Add(1 * time.Hour), UsedAt: &usedAt}, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.token.IsUsable(now), "expected IsUsable of %v to be %v", test.token, test.expected)
+	}
+}
+
+func TestToken_HashToken(t *testing.T) {
+	assert.Equal(t, domain.HashToken("foobar"), domain.HashToken("foobar"))
+	assert.NotEqual(t, domain.HashToken("foobar"), domain.HashToken("barfoo"))
+	assert.NotContains(t, domain.HashToken("foobar"), "foobar")
+}
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"github.com/srvc/fail"
+)
+
+// PersonalAccessTokenPrefix is put in front of every personal access token so they can't be mistaken for session tokens
+const PersonalAccessTokenPrefix = "tdk_"
+
+// PersonalAccessToken lets a user authenticate scripts and integrations without handing over their session
+type PersonalAccessToken struct {
+	ID         uint64     `json:"id" db:"id"`
+	UserID     uint64     `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name" valid:"required,length(1|100)"`
+	Hash       string     `json:"-" db:"token_hash"`
+	Scopes     Scopes     `json:"scopes" db:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PersonalAccessTokens is a collection of personal access tokens
+type PersonalAccessTokens []PersonalAccessToken
+
+// Validate a personal access token
+func (t PersonalAccessToken) Validate() (bool, error) {
+	var errs FieldErrors
+
+	if valid, err := t.Scopes.Validate(); !valid {
+		errs = append(errs, NewFieldError("scopes", "scopes", err))
+	}
+
+	return errs.Result()
+}
+
+// IsActive tells you if the token can still be used to authenticate
+func (t PersonalAccessToken) IsActive() bool {
+	return t.RevokedAt == nil
+}
+
+// Scope limits what a personal access token can be used for
+type Scope string
+
+// These are all the scopes a personal access token can be granted
+const (
+	ScopeLogsWrite    Scope = "logs:write"
+	ScopeRankingsRead Scope = "rankings:read"
+)
+
+// AllScopes is an array with all existing scopes
+var AllScopes = []Scope{
+	ScopeLogsWrite,
+	ScopeRankingsRead,
+}
+
+// ErrScopeNotFound for when a given scope does not exist
+var ErrScopeNotFound = fail.New("scope does not exist")
+
+// ErrScopesEmpty for when a token would be granted no scopes at all
+var ErrScopesEmpty = fail.New("at least one scope is required")
+
+// Validate a scope
+func (s Scope) Validate() (bool, error) {
+	for _, scope := range AllScopes {
+		if scope == s {
+			return true, nil
+		}
+	}
+
+	return false, ErrScopeNotFound
+}
+
+// Scopes is a set of scopes, stored space separated like OAuth does
+type Scopes []Scope
+
+// Validate all scopes
+func (s Scopes) Validate() (bool, error) {
+	if len(s) == 0 {
+		return false, ErrScopesEmpty
+	}
+
+	for _, scope := range s {
+		if valid, err := scope.Validate(); !valid {
+			return valid, err
+		}
+	}
+
+	return true, nil
+}
+
+// Includes tells you if the given scope has been granted
+func (s Scopes) Includes(scope Scope) bool {
+	for _, granted := range s {
+		if granted == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Value implements the driver.Valuer interface
+func (s Scopes) Value() (driver.Value, error) {
+	values := make([]string, len(s))
+	for i, scope := range s {
+		values[i] = string(scope)
+	}
+
+	return strings.Join(values, " "), nil
+}
+
+// Scan implements the sql.Scanner interface
+func (s *Scopes) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	case nil:
+		return nil
+	default:
+		return fail.Errorf("could not not decode type %T -> %T", src, s)
+	}
+
+	*s = Scopes{}
+	for _, scope := range strings.Fields(value) {
+		*s = append(*s, Scope(scope))
+	}
+
+	return nil
+}
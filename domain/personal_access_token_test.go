@@ -0,0 +1,46 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+)
+
+func TestScopes_Validate(t *testing.T) {
+	{
+		_, err := domain.Scopes{}.Validate()
+		assert.EqualError(t, err, domain.ErrScopesEmpty.Error())
+	}
+
+	{
+		_, err := domain.Scopes{domain.ScopeLogsWrite, "contests:write"}.Validate()
+		assert.EqualError(t, err, domain.ErrScopeNotFound.Error())
+	}
+
+	{
+		valid, err := domain.Scopes{domain.ScopeLogsWrite, domain.ScopeRankingsRead}.Validate()
+		assert.True(t, valid)
+		assert.NoError(t, err)
+	}
+}
+
+func TestScopes_Includes(t *testing.T) {
+	scopes := domain.Scopes{domain.ScopeLogsWrite}
+
+	assert.True(t, scopes.Includes(domain.ScopeLogsWrite))
+	assert.False(t, scopes.Includes(domain.ScopeRankingsRead))
+}
+
+func TestScopes_ValueScan(t *testing.T) {
+	scopes := domain.Scopes{domain.ScopeLogsWrite, domain.ScopeRankingsRead}
+
+	value, err := scopes.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "logs:write rankings:read", value)
+
+	var scanned domain.Scopes
+	err = scanned.Scan([]byte("logs:write rankings:read"))
+	assert.NoError(t, err)
+	assert.Equal(t, scopes, scanned)
+}
@@ -4,12 +4,105 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"reflect"
+	"time"
 
 	"github.com/srvc/fail"
 )
 
+// Units decides how amounts are shown to a user
+type Units string
+
+// These are all the possible values for Units
+const (
+	// UnitsPoints shows amounts after they have been adjusted for the medium
+	UnitsPoints Units = "points"
+	// UnitsPages shows amounts as they were logged
+	UnitsPages Units = "pages"
+)
+
+// Amount picks the amount to show out of the one adjusted for the medium and the one as it was logged
+func (u Units) Amount(adjusted float32, logged float32) float32 {
+	if u == UnitsPages {
+		return logged
+	}
+
+	return adjusted
+}
+
+// ErrTimezoneInvalid for when a timezone is not in the IANA time zone database
+var ErrTimezoneInvalid = fail.New("timezone is invalid")
+
+// ErrUnitsInvalid for when a units preference is not supported
+var ErrUnitsInvalid = fail.New("units are invalid")
+
 // Preferences holds user specific preferences like timezone and privacy
-type Preferences struct{}
+type Preferences struct {
+	// Timezone is an IANA time zone name, dates are shown in UTC when it's empty
+	Timezone string `json:"timezone,omitempty"`
+	// HideFromRankings leaves the user out of public rankings
+	HideFromRankings bool `json:"hide_from_rankings"`
+
+	// Used for new logs that don't specify them
+	DefaultLanguage LanguageCode `json:"default_language_code,omitempty"`
+	DefaultMedium   MediumID     `json:"default_medium_id,omitempty"`
+
+	Units Units `json:"units,omitempty"`
+}
+
+// Validate preferences, every setting is optional
+func (p Preferences) Validate() (bool, error) {
+	var errs FieldErrors
+
+	if p.Timezone != "" {
+		if _, err := time.LoadLocation(p.Timezone); err != nil {
+			errs = append(errs, NewFieldError("timezone", "timezone", ErrTimezoneInvalid))
+		}
+	}
+
+	// Global is only used for totals, logs always need a real language
+	if p.DefaultLanguage == Global {
+		errs = append(errs, NewFieldError("default_language_code", "language", ErrInvalidLanguage))
+	} else if p.DefaultLanguage != "" {
+		if valid, err := p.DefaultLanguage.Validate(); !valid {
+			errs = append(errs, NewFieldError("default_language_code", "language", err))
+		}
+	}
+
+	if p.DefaultMedium != 0 {
+		if valid, err := p.DefaultMedium.Validate(); !valid {
+			errs = append(errs, NewFieldError("default_medium_id", "medium", err))
+		}
+	}
+
+	if p.Units != "" && p.Units != UnitsPoints && p.Units != UnitsPages {
+		errs = append(errs, NewFieldError("units", "units", ErrUnitsInvalid))
+	}
+
+	return errs.Result()
+}
+
+// Location gives the timezone of the user, falling back to UTC
+func (p *Preferences) Location() *time.Location {
+	if p == nil || p.Timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// AmountUnits gives the units the user wants to see amounts in, falling back to points
+func (p *Preferences) AmountUnits() Units {
+	if p == nil || p.Units == "" {
+		return UnitsPoints
+	}
+
+	return p.Units
+}
 
 // Value implements the driver.Valuer interface
 func (p Preferences) Value() (driver.Value, error) {
@@ -0,0 +1,99 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+)
+
+func TestPreferences_Validate(t *testing.T) {
+	var tests = []struct {
+		preferences    domain.Preferences
+		expectedErrors domain.FieldErrors
+	}{
+		{domain.Preferences{}, nil},
+		{
+			domain.Preferences{
+				Timezone:         "Asia/Tokyo",
+				HideFromRankings: true,
+				DefaultLanguage:  domain.Japanese,
+				DefaultMedium:    domain.MediumBook,
+				Units:            domain.UnitsPages,
+			},
+			nil,
+		},
+		{
+			domain.Preferences{Timezone: "Mars/Olympus_Mons"},
+			domain.FieldErrors{domain.NewFieldError("timezone", "timezone", domain.ErrTimezoneInvalid)},
+		},
+		{
+			domain.Preferences{DefaultLanguage: "foo"},
+			domain.FieldErrors{domain.NewFieldError("default_language_code", "language", domain.ErrInvalidLanguage)},
+		},
+		{
+			domain.Preferences{DefaultLanguage: domain.Global},
+			domain.FieldErrors{domain.NewFieldError("default_language_code", "language", domain.ErrInvalidLanguage)},
+		},
+		{
+			domain.Preferences{DefaultMedium: 20},
+			domain.FieldErrors{domain.NewFieldError("default_medium_id", "medium", domain.ErrMediumNotFound)},
+		},
+		{
+			domain.Preferences{Units: "furlongs"},
+			domain.FieldErrors{domain.NewFieldError("units", "units", domain.ErrUnitsInvalid)},
+		},
+		{
+			domain.Preferences{Timezone: "Mars/Olympus_Mons", Units: "furlongs"},
+			domain.FieldErrors{
+				domain.NewFieldError("timezone", "timezone", domain.ErrTimezoneInvalid),
+				domain.NewFieldError("units", "units", domain.ErrUnitsInvalid),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		valid, err := test.preferences.Validate()
+		if test.expectedErrors == nil {
+			assert.NoError(t, err, "%+v", test.preferences)
+		} else {
+			assert.Equal(t, test.expectedErrors, err, "%+v", test.preferences)
+		}
+		assert.Equal(t, test.expectedErrors == nil, valid, "%+v", test.preferences)
+	}
+}
+
+func TestPreferences_Location(t *testing.T) {
+	var preferences *domain.Preferences
+	assert.Equal(t, time.UTC, preferences.Location())
+	assert.Equal(t, time.UTC, (&domain.Preferences{}).Location())
+	assert.Equal(t, "Asia/Tokyo", (&domain.Preferences{Timezone: "Asia/Tokyo"}).Location().String())
+}
+
+func TestPreferences_AmountUnits(t *testing.T) {
+	var preferences *domain.Preferences
+	assert.Equal(t, domain.UnitsPoints, preferences.AmountUnits())
+	assert.Equal(t, domain.UnitsPoints, (&domain.Preferences{}).AmountUnits())
+	assert.Equal(t, domain.UnitsPages, (&domain.Preferences{Units: domain.UnitsPages}).AmountUnits())
+}
+
+func TestUnits_Amount(t *testing.T) {
+	assert.Equal(t, float32(2), domain.UnitsPoints.Amount(2, 10))
+	assert.Equal(t, float32(10), domain.UnitsPages.Amount(2, 10))
+}
+
+func TestPreferences_Scan(t *testing.T) {
+	preferences := &domain.Preferences{}
+	err := preferences.Scan([]byte(`{"timezone": "Europe/Amsterdam", "hide_from_rankings": true, "default_medium_id": 2}`))
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Preferences{Timezone: "Europe/Amsterdam", HideFromRankings: true, DefaultMedium: domain.MediumComic}, *preferences)
+
+	value, err := preferences.Value()
+	assert.NoError(t, err)
+
+	roundTrip := domain.Preferences{}
+	assert.NoError(t, json.Unmarshal(value.([]byte), &roundTrip))
+	assert.Equal(t, *preferences, roundTrip)
+}
@@ -16,16 +16,19 @@ type Ranking struct {
 
 	// Optional fields
 	UserDisplayName string `json:"user_display_name" db:"user_display_name"`
+	// LoggedAmount is the sum of the logs before they were adjusted for their medium
+	LoggedAmount float32 `json:"logged_amount" db:"logged_amount"`
 }
 
-// GetView gets the external view representation of a Ranking
-func (r Ranking) GetView() RankingView {
+// GetView gets the external view representation of a Ranking, with the amount in the given units
+func (r Ranking) GetView(units Units) RankingView {
 	return RankingView{
 		ContestID:       r.ContestID,
 		UserID:          r.UserID,
 		UserDisplayName: r.UserDisplayName,
 		Language:        r.Language,
-		Amount:          r.Amount,
+		Amount:          units.Amount(r.Amount, r.LoggedAmount),
+		Units:           units,
 	}
 }
 
@@ -33,11 +36,11 @@ func (r Ranking) GetView() RankingView {
 type Rankings []Ranking
 
 // GetView gets the external view representation of a Rankings collection
-func (r Rankings) GetView() []RankingView {
+func (r Rankings) GetView(units Units) []RankingView {
 	result := make([]RankingView, len(r))
 
 	for i, val := range r {
-		result[i] = val.GetView()
+		result[i] = val.GetView(units)
 	}
 
 	return result
@@ -50,6 +53,7 @@ type RankingView struct {
 	UserDisplayName string       `json:"user_display_name"`
 	Language        LanguageCode `json:"language_code"`
 	Amount          float32      `json:"amount"`
+	Units           Units        `json:"units"`
 }
 
 // RankingRegistration holds the current contest registration
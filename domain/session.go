@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+)
+
+// Session is a single login of a user, it stays alive for as long as its refresh tokens keep being rotated
+type Session struct {
+	ID                uint64     `json:"id" db:"id"`
+	UserID            uint64     `json:"user_id" db:"user_id"`
+	UserAgent         string     `json:"user_agent" db:"user_agent"`
+	IPAddress         string     `json:"ip_address" db:"ip_address"`
+	TwoFactorVerified bool       `json:"two_factor_verified" db:"two_factor_verified"`
+	ExpiresAt         time.Time  `json:"expires_at" db:"expires_at"`
+	LastSeenAt        time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	RevokedAt         *time.Time `json:"revoked_at" db:"revoked_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Sessions is a collection of sessions
+type Sessions []Session
+
+// IsActive tells you if the session can still be used at the given time
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// sessionSeenPrecision is how outdated LastSeenAt is allowed to be, so not every request has to write to the session
+const sessionSeenPrecision = 5 * time.Minute
+
+// NeedsTouch tells you if the session has been used long enough ago that LastSeenAt should be moved up
+func (s Session) NeedsTouch(now time.Time) bool {
+	return now.Sub(s.LastSeenAt) >= sessionSeenPrecision
+}
+
+// RefreshToken can be exchanged once for a new access token within a session
+type RefreshToken struct {
+	ID        uint64     `json:"id" db:"id"`
+	SessionID uint64     `json:"session_id" db:"session_id"`
+	Hash      string     `json:"-" db:"token_hash"`
+	UsedAt    *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsUsed tells you if the token has already been exchanged, using it again means it has leaked
+func (t RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
@@ -0,0 +1,44 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+)
+
+func TestSession_IsActive(t *testing.T) {
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	revokedAt := now.Add(-1 * time.Minute)
+
+	var tests = []struct {
+		session  domain.Session
+		expected bool
+	}{
+		{domain.Session{ExpiresAt: now.Add(1 * time.Hour)}, true},
+		{domain.Session{ExpiresAt: now.Add(-1 * time.Hour)}, false},
+		{domain.Session{ExpiresAt: now.Add(1 * time.Hour), RevokedAt: &revokedAt}, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.session.IsActive(now), "expected IsActive of %v to be %v", test.session, test.expected)
+	}
+}
+
+func TestSession_NeedsTouch(t *testing.T) {
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		session  domain.Session
+		expected bool
+	}{
+		{domain.Session{LastSeenAt: now.Add(-1 * time.Minute)}, false},
+		{domain.Session{LastSeenAt: now.Add(-5 * time.Minute)}, true},
+		{domain.Session{}, true},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.session.NeedsTouch(now), "expected NeedsTouch of %v to be %v", test.session, test.expected)
+	}
+}
@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken creates a digest of a secret token so it can be stored without exposing the original
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
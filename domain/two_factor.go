@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"time"
+)
+
+// MaxTwoFactorAttempts is how many wrong codes can be tried before a challenge has to be started over
+const MaxTwoFactorAttempts = 5
+
+// TwoFactorAuthentication holds the shared secret a user's authenticator app generates codes with
+type TwoFactorAuthentication struct {
+	UserID       uint64     `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	ConfirmedAt  *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsEnabled tells you if the user has proven their authenticator app works, until then it's not required to log in
+func (t TwoFactorAuthentication) IsEnabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// TwoFactorChallenge is handed out after a correct password, and can be exchanged for a session together with a code
+type TwoFactorChallenge struct {
+	ID             uint64     `json:"id" db:"id"`
+	UserID         uint64     `json:"user_id" db:"user_id"`
+	Hash           string     `json:"-" db:"token_hash"`
+	FailedAttempts int        `json:"failed_attempts" db:"failed_attempts"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt         *time.Time `json:"used_at" db:"used_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsUsable tells you if a code can still be tried for this challenge at the given time
+func (c TwoFactorChallenge) IsUsable(now time.Time) bool {
+	return c.UsedAt == nil && now.Before(c.ExpiresAt) && c.FailedAttempts < MaxTwoFactorAttempts
+}
@@ -0,0 +1,35 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+)
+
+func TestTwoFactorAuthentication_IsEnabled(t *testing.T) {
+	confirmedAt := time.Now()
+
+	assert.False(t, domain.TwoFactorAuthentication{}.IsEnabled())
+	assert.True(t, domain.TwoFactorAuthentication{ConfirmedAt: &confirmedAt}.IsEnabled())
+}
+
+func TestTwoFactorChallenge_IsUsable(t *testing.T) {
+	now := time.Date(2019, 1, 1, 12, 0, 0, 0, time.UTC)
+	usedAt := now.Add(-1 * time.Minute)
+
+	var tests = []struct {
+		challenge domain.TwoFactorChallenge
+		expected  bool
+	}{
+		{domain.TwoFactorChallenge{ExpiresAt: now.Add(5 * time.Minute)}, true},
+		{domain.TwoFactorChallenge{ExpiresAt: now.Add(-5 * time.Minute)}, false},
+		{domain.TwoFactorChallenge{ExpiresAt: now.Add(5 * time.Minute), UsedAt: &usedAt}, false},
+		{domain.TwoFactorChallenge{ExpiresAt: now.Add(5 * time.Minute), FailedAttempts: domain.MaxTwoFactorAttempts}, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.challenge.IsUsable(now), "expected IsUsable of %v to be %v", test.challenge, test.expected)
+	}
+}
@@ -1,8 +1,11 @@
 package domain
 
 import (
+	"net/mail"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/srvc/fail"
 )
@@ -18,8 +21,15 @@ type User struct {
 	Role        Role         `json:"role" db:"role"`
 	Preferences *Preferences `json:"preferences" db:"preferences"`
 
-	// Password max runelength is an arbitrary high number that in theory should never be hit
-	Password         Password `json:"password" db:"password" valid:"optional,runelength(6|99999999)"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+
+	// Only set when an admin has disabled the account, enabling it gives the user their disabled role back
+	DisabledReason string     `json:"disabled_reason,omitempty" db:"disabled_reason"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+	DisabledRole   Role       `json:"-" db:"disabled_role"`
+
+	Password         Password `json:"password" db:"password"`
 	isPasswordHashed bool
 }
 
@@ -29,9 +39,28 @@ type Users []User
 // ErrUserMissingPassword for when a new user is created without a password
 var ErrUserMissingPassword = fail.New("a new user must have a password")
 
+// MinPasswordLength is the least amount of characters a new password needs
+const MinPasswordLength = 6
+
+// ErrPasswordTooShort for when a new password would be too easy to guess
+var ErrPasswordTooShort = fail.New("a password should be at least 6 characters long")
+
 // ErrDisplayNameInvalid for when a display name is incorrect
 var ErrDisplayNameInvalid = fail.New("a display name should consist of letters, numbers and -_")
 
+// ErrEmailInvalid for when an email address can't be used to send mail to
+var ErrEmailInvalid = fail.New("email address is invalid")
+
+// ValidateEmail checks if a bare email address, without a display name, was given
+func ValidateEmail(email string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return ErrEmailInvalid
+	}
+
+	return nil
+}
+
 // NeedsHashing tells you if the password is in need of being hashed
 func (u *User) NeedsHashing() bool {
 	return u.Password != "" && !u.isPasswordHashed
@@ -42,14 +71,45 @@ func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
+// IsDisabled returns true when an admin has disabled the account
+func (u *User) IsDisabled() bool {
+	return u.Role == RoleDisabled
+}
+
+// IsHiddenFrom returns true when the user doesn't want their reading to be seen by the given viewer,
+// users can always see their own reading and guests have no id
+func (u *User) IsHiddenFrom(viewerID uint64) bool {
+	return u.Preferences != nil && u.Preferences.HideFromRankings && u.ID != viewerID
+}
+
+// IsEmailVerified returns true when the user has proven to own their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 // Validate a user
 func (u User) Validate() (bool, error) {
+	var errs FieldErrors
+
 	if !validateDisplayName(u.DisplayName) {
-		return false, ErrDisplayNameInvalid
+		errs = append(errs, NewFieldError("display_name", "display_name", ErrDisplayNameInvalid))
 	}
 
 	if u.ID == 0 && u.Password == "" {
-		return false, ErrUserMissingPassword
+		errs = append(errs, NewFieldError("password", "required", ErrUserMissingPassword))
+	} else if u.NeedsHashing() {
+		if _, err := u.Password.Validate(); err != nil {
+			errs = append(errs, err.(FieldErrors)...)
+		}
+	}
+
+	return errs.Result()
+}
+
+// Validate a new password before it gets hashed
+func (p Password) Validate() (bool, error) {
+	if utf8.RuneCountInString(string(p)) < MinPasswordLength {
+		return FieldErrors{NewFieldError("password", "min_length", ErrPasswordTooShort)}.Result()
 	}
 
 	return true, nil
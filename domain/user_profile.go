@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// ProfileRanking is a ranking of a user together with the contest it belongs to and where they placed
+type ProfileRanking struct {
+	ContestID          uint64       `db:"contest_id"`
+	ContestDescription string       `db:"contest_description"`
+	ContestStart       time.Time    `db:"contest_start"`
+	ContestEnd         time.Time    `db:"contest_end"`
+	Language           LanguageCode `db:"language_code"`
+	Amount             float32      `db:"amount"`
+	LoggedAmount       float32      `db:"logged_amount"`
+	Rank               int          `db:"rank"`
+}
+
+// ContestLogTotal is the sum of everything a user has logged for a language and medium combination
+type ContestLogTotal struct {
+	Language LanguageCode `db:"language_code"`
+	MediumID MediumID     `db:"medium_id"`
+	Amount   float32      `db:"amount"`
+}
+
+// UserProfile is the public overview of everything a user has done across contests
+type UserProfile struct {
+	UserID         uint64                `json:"user_id"`
+	DisplayName    string                `json:"display_name"`
+	JoinedAt       time.Time             `json:"joined_at"`
+	Contests       []UserProfileContest  `json:"contests"`
+	LanguageTotals []UserProfileLanguage `json:"language_totals"`
+	MediumTotals   []UserProfileMedium   `json:"medium_totals"`
+	// Units is what the amounts of the contests and language totals are in
+	Units Units `json:"units"`
+}
+
+// UserProfileContest is a contest a user has entered
+type UserProfileContest struct {
+	ContestID   uint64                `json:"contest_id"`
+	Description string                `json:"description"`
+	Start       time.Time             `json:"start"`
+	End         time.Time             `json:"end"`
+	Rankings    []UserProfileStanding `json:"rankings"`
+}
+
+// UserProfileStanding is how a user did for a single language in a contest
+type UserProfileStanding struct {
+	Language LanguageCode `json:"language_code"`
+	Amount   float32      `json:"amount"`
+	Rank     int          `json:"rank"`
+}
+
+// UserProfileLanguage is the lifetime total of a user for a language
+type UserProfileLanguage struct {
+	Language LanguageCode `json:"language_code"`
+	Amount   float32      `json:"amount"`
+}
+
+// UserProfileMedium is the lifetime total of a user for a medium
+type UserProfileMedium struct {
+	MediumID       MediumID `json:"medium_id"`
+	Amount         float32  `json:"amount"`
+	AdjustedAmount float32  `json:"adjusted_amount"`
+}
+
+// NewUserProfile aggregates rankings that are sorted by contest and totals of logs into a profile,
+// with the amounts of contests and languages in the given units
+func NewUserProfile(user User, rankings []ProfileRanking, totals []ContestLogTotal, units Units) UserProfile {
+	profile := UserProfile{
+		UserID:         user.ID,
+		DisplayName:    user.DisplayName,
+		JoinedAt:       user.CreatedAt,
+		Contests:       []UserProfileContest{},
+		LanguageTotals: []UserProfileLanguage{},
+		MediumTotals:   []UserProfileMedium{},
+		Units:          units,
+	}
+
+	for _, r := range rankings {
+		last := len(profile.Contests) - 1
+		if last < 0 || profile.Contests[last].ContestID != r.ContestID {
+			profile.Contests = append(profile.Contests, UserProfileContest{
+				ContestID:   r.ContestID,
+				Description: r.ContestDescription,
+				Start:       r.ContestStart,
+				End:         r.ContestEnd,
+				Rankings:    []UserProfileStanding{},
+			})
+			last++
+		}
+
+		contest := &profile.Contests[last]
+		contest.Rankings = append(contest.Rankings, UserProfileStanding{
+			Language: r.Language,
+			Amount:   units.Amount(r.Amount, r.LoggedAmount),
+			Rank:     r.Rank,
+		})
+	}
+
+	languages := make(map[LanguageCode]float32)
+	mediums := make(map[MediumID]*UserProfileMedium)
+	for _, t := range totals {
+		adjusted := t.MediumID.AdjustedAmount(t.Amount)
+		languages[t.Language] += units.Amount(adjusted, t.Amount)
+		languages[Global] += units.Amount(adjusted, t.Amount)
+
+		if _, ok := mediums[t.MediumID]; !ok {
+			mediums[t.MediumID] = &UserProfileMedium{MediumID: t.MediumID}
+		}
+		mediums[t.MediumID].Amount += t.Amount
+		mediums[t.MediumID].AdjustedAmount += adjusted
+	}
+
+	for language, amount := range languages {
+		profile.LanguageTotals = append(profile.LanguageTotals, UserProfileLanguage{Language: language, Amount: amount})
+	}
+	sort.Slice(profile.LanguageTotals, func(i, j int) bool {
+		a, b := profile.LanguageTotals[i], profile.LanguageTotals[j]
+		if a.Amount == b.Amount {
+			return a.Language < b.Language
+		}
+		return a.Amount > b.Amount
+	})
+
+	for _, medium := range mediums {
+		profile.MediumTotals = append(profile.MediumTotals, *medium)
+	}
+	sort.Slice(profile.MediumTotals, func(i, j int) bool {
+		return profile.MediumTotals[i].MediumID < profile.MediumTotals[j].MediumID
+	})
+
+	return profile
+}
@@ -0,0 +1,77 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+)
+
+func TestNewUserProfile(t *testing.T) {
+	joinedAt := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := domain.User{ID: 1, DisplayName: "foo", CreatedAt: joinedAt}
+
+	rankings := []domain.ProfileRanking{
+		{ContestID: 1, ContestDescription: "Round 1", Language: domain.Global, Amount: 12, LoggedAmount: 20, Rank: 3},
+		{ContestID: 1, ContestDescription: "Round 1", Language: domain.Japanese, Amount: 12, LoggedAmount: 20, Rank: 1},
+		{ContestID: 2, ContestDescription: "Round 2", Language: domain.Global, Amount: 0, LoggedAmount: 0, Rank: 7},
+	}
+	totals := []domain.ContestLogTotal{
+		{Language: domain.Japanese, MediumID: domain.MediumBook, Amount: 10},
+		{Language: domain.Japanese, MediumID: domain.MediumComic, Amount: 10},
+		{Language: domain.Korean, MediumID: domain.MediumBook, Amount: 5},
+	}
+
+	profile := domain.NewUserProfile(user, rankings, totals, domain.UnitsPoints)
+
+	assert.Equal(t, user.ID, profile.UserID)
+	assert.Equal(t, user.DisplayName, profile.DisplayName)
+	assert.Equal(t, joinedAt, profile.JoinedAt)
+
+	assert.Len(t, profile.Contests, 2)
+	assert.Equal(t, "Round 1", profile.Contests[0].Description)
+	assert.Equal(t, []domain.UserProfileStanding{
+		{Language: domain.Global, Amount: 12, Rank: 3},
+		{Language: domain.Japanese, Amount: 12, Rank: 1},
+	}, profile.Contests[0].Rankings)
+	assert.Len(t, profile.Contests[1].Rankings, 1)
+
+	assert.Equal(t, []domain.UserProfileLanguage{
+		{Language: domain.Global, Amount: 17},
+		{Language: domain.Japanese, Amount: 12},
+		{Language: domain.Korean, Amount: 5},
+	}, profile.LanguageTotals)
+
+	assert.Equal(t, []domain.UserProfileMedium{
+		{MediumID: domain.MediumBook, Amount: 15, AdjustedAmount: 15},
+		{MediumID: domain.MediumComic, Amount: 10, AdjustedAmount: 2},
+	}, profile.MediumTotals)
+
+	// Amounts can also be shown as they were logged
+	profile = domain.NewUserProfile(user, rankings, totals, domain.UnitsPages)
+
+	assert.Equal(t, domain.UnitsPages, profile.Units)
+	assert.Equal(t, []domain.UserProfileStanding{
+		{Language: domain.Global, Amount: 20, Rank: 3},
+		{Language: domain.Japanese, Amount: 20, Rank: 1},
+	}, profile.Contests[0].Rankings)
+	assert.Equal(t, []domain.UserProfileLanguage{
+		{Language: domain.Global, Amount: 25},
+		{Language: domain.Japanese, Amount: 20},
+		{Language: domain.Korean, Amount: 5},
+	}, profile.LanguageTotals)
+	assert.Equal(t, []domain.UserProfileMedium{
+		{MediumID: domain.MediumBook, Amount: 15, AdjustedAmount: 15},
+		{MediumID: domain.MediumComic, Amount: 10, AdjustedAmount: 2},
+	}, profile.MediumTotals, "medium totals show both amounts regardless")
+}
+
+func TestNewUserProfile_Empty(t *testing.T) {
+	profile := domain.NewUserProfile(domain.User{ID: 1}, nil, nil, domain.UnitsPoints)
+
+	assert.Empty(t, profile.Contests)
+	assert.NotNil(t, profile.Contests, "empty lists should be encoded as [] instead of null")
+	assert.Empty(t, profile.LanguageTotals)
+	assert.Empty(t, profile.MediumTotals)
+}
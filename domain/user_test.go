@@ -2,7 +2,6 @@ package domain_test
 
 import (
 	"encoding/json"
-	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,6 +25,19 @@ func TestUser_PasswordIsNotExported(t *testing.T) {
 	assert.Empty(t, newUser.Password)
 }
 
+func TestUser_IsHiddenFrom(t *testing.T) {
+	hidden := domain.User{ID: 1, Preferences: &domain.Preferences{HideFromRankings: true}}
+	assert.True(t, hidden.IsHiddenFrom(0), "hidden from guests")
+	assert.True(t, hidden.IsHiddenFrom(2), "hidden from other users")
+	assert.False(t, hidden.IsHiddenFrom(1), "users can see themselves")
+
+	visible := domain.User{ID: 1, Preferences: &domain.Preferences{}}
+	assert.False(t, visible.IsHiddenFrom(0))
+
+	withoutPreferences := domain.User{ID: 1}
+	assert.False(t, withoutPreferences.IsHiddenFrom(0))
+}
+
 func TestUser_Validation(t *testing.T) {
 	var tests = []struct {
 		user          domain.User
@@ -46,7 +58,7 @@ func TestUser_Validation(t *testing.T) {
 		},
 		{
 			domain.User{ID: 1, DisplayName: "foobar", Password: "short"},
-			errors.New("password: short does not validate as runelength(6|99999999)"),
+			domain.ErrPasswordTooShort,
 		},
 
 		// DisplayName checks
@@ -90,3 +102,22 @@ func TestUser_Validation(t *testing.T) {
 		}
 	}
 }
+
+func TestPassword_Validate(t *testing.T) {
+	valid, err := domain.Password("foobar").Validate()
+	assert.True(t, valid)
+	assert.NoError(t, err)
+
+	// Characters are counted instead of bytes
+	valid, err = domain.Password("神様神様神").Validate()
+	assert.False(t, valid)
+	assert.EqualError(t, err, domain.ErrPasswordTooShort.Error())
+}
+
+func TestValidateEmail(t *testing.T) {
+	assert.NoError(t, domain.ValidateEmail("foo@example.com"))
+	assert.Equal(t, domain.ErrEmailInvalid, domain.ValidateEmail(""))
+	assert.Equal(t, domain.ErrEmailInvalid, domain.ValidateEmail("foo"))
+	assert.Equal(t, domain.ErrEmailInvalid, domain.ValidateEmail("Foo <foo@example.com>"))
+	assert.Equal(t, domain.ErrEmailInvalid, domain.ValidateEmail(" foo@example.com"))
+}
@@ -18,6 +18,7 @@ require (
 	github.com/labstack/echo/v4 v4.1.16
 	github.com/lib/pq v1.4.0
 	github.com/mattn/go-zglob v0.0.1 // indirect
+	github.com/prometheus/client_golang v1.7.1
 	github.com/srvc/fail v3.1.1+incompatible
 	github.com/stretchr/testify v1.5.1
 	golang.org/x/crypto v0.0.0-20200221231518-2aa609cf4a9d
@@ -1,6 +1,7 @@
 package infra
 
 import (
+	gocontext "context"
 	"strconv"
 
 	jwt "github.com/dgrijalva/jwt-go"
@@ -27,16 +28,36 @@ func (c context) User() (*domain.User, error) {
 	return nil, ErrEmptyUser
 }
 
+// setUser replaces the user in the claims of the current request
+func (c context) setUser(user *domain.User) {
+	if token, ok := c.Get("user").(*jwt.Token); ok {
+		if claims, ok := token.Claims.(*jwtClaims); ok {
+			claims.User = user
+		}
+	}
+}
+
 func (c context) Claims() *usecases.SessionClaims {
 	if token, ok := c.Get("user").(*jwt.Token); ok {
 		claims := token.Claims.(*jwtClaims)
 		if claims != nil {
-			return &claims.SessionClaims
+			return claims.sessionClaims()
 		}
 	}
+	if claims, ok := c.Get(personalAccessTokenClaimsKey).(*usecases.SessionClaims); ok {
+		return claims
+	}
 	return nil
 }
 
+func (c context) RequestContext() gocontext.Context {
+	return c.Request().Context()
+}
+
+func (c context) SetHeader(key, value string) {
+	c.Response().Header().Set(key, value)
+}
+
 func (c context) GetID() (uint64, error) {
 	idFromRoute := c.Param("id")
 	id, err := strconv.ParseUint(idFromRoute, 10, 64)
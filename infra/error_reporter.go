@@ -1,6 +1,8 @@
 package infra
 
 import (
+	"time"
+
 	"github.com/getsentry/sentry-go"
 
 	"github.com/tadoku/api/domain"
@@ -27,6 +29,15 @@ func NewErrorReporter(dsn string) (usecases.ErrorReporter, error) {
 type errorReporter struct {
 }
 
-func (r *errorReporter) Capture(err error) {
-	_ = sentry.CaptureException(err)
+func (r *errorReporter) Capture(err error, requestID string) {
+	sentry.WithScope(func(scope *sentry.Scope) {
+		if requestID != "" {
+			scope.SetTag("request_id", requestID)
+		}
+		_ = sentry.CaptureException(err)
+	})
+}
+
+func (r *errorReporter) Flush(timeout time.Duration) bool {
+	return sentry.Flush(timeout)
 }
@@ -0,0 +1,119 @@
+package infra
+
+import (
+	gocontext "context"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/srvc/fail"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewDatabaseHealthCheck checks that the database can be reached
+func NewDatabaseHealthCheck(db *RDB, timeout time.Duration) usecases.HealthCheck {
+	return &databaseHealthCheck{db: db, timeout: timeout}
+}
+
+type databaseHealthCheck struct {
+	db      *RDB
+	timeout time.Duration
+}
+
+func (c *databaseHealthCheck) Name() string {
+	return "database"
+}
+
+func (c *databaseHealthCheck) Check(ctx gocontext.Context) error {
+	ctx, cancel := gocontext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return domain.WrapError(c.db.PingContext(ctx))
+}
+
+// NewMigrationsHealthCheck checks that every migration in the directory has been applied,
+// so new code doesn't get to run against an old schema
+func NewMigrationsHealthCheck(db *RDB, directory string, timeout time.Duration) usecases.HealthCheck {
+	return &migrationsHealthCheck{db: db, directory: directory, timeout: timeout}
+}
+
+type migrationsHealthCheck struct {
+	db        *RDB
+	directory string
+	timeout   time.Duration
+}
+
+// migrationFileRegularExpression matches the up migrations that gomigrate applies, eg. 202610172000_audit_events_up.sql
+var migrationFileRegularExpression = regexp.MustCompile(`^(\d+)_.+_up\.sql$`)
+
+func (c *migrationsHealthCheck) Name() string {
+	return "migrations"
+}
+
+func (c *migrationsHealthCheck) Check(ctx gocontext.Context) error {
+	files, err := ioutil.ReadDir(c.directory)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	ctx, cancel := gocontext.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var applied []uint64
+	if err := c.db.SelectContext(ctx, &applied, "select migration_id from gomigrate"); err != nil {
+		return domain.WrapError(err)
+	}
+
+	pending := 0
+	for _, file := range files {
+		match := migrationFileRegularExpression.FindStringSubmatch(file.Name())
+		if match == nil {
+			continue
+		}
+
+		id, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+		if !domain.ContainsID(applied, id) {
+			pending++
+		}
+	}
+
+	if pending > 0 {
+		return fail.Errorf("%d migrations have not been applied", pending)
+	}
+
+	return nil
+}
+
+// ErrErrorReporterNotInitialized for when the error reporter was configured, but errors can't be sent
+var ErrErrorReporterNotInitialized = fail.New("error reporter has not been initialized")
+
+// NewErrorReporterHealthCheck reports whether errors are being sent off, the reporter is nil when it's not configured
+func NewErrorReporterHealthCheck(reporter usecases.ErrorReporter) usecases.HealthCheck {
+	return &errorReporterHealthCheck{reporter: reporter}
+}
+
+type errorReporterHealthCheck struct {
+	reporter usecases.ErrorReporter
+}
+
+func (c *errorReporterHealthCheck) Name() string {
+	return "error_reporter"
+}
+
+func (c *errorReporterHealthCheck) Check(ctx gocontext.Context) error {
+	if c.reporter == nil {
+		return usecases.ErrHealthCheckDisabled
+	}
+	if sentry.CurrentHub().Client() == nil {
+		return ErrErrorReporterNotInitialized
+	}
+
+	return nil
+}
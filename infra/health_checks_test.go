@@ -0,0 +1,45 @@
+package infra_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/infra"
+	"github.com/tadoku/api/usecases"
+)
+
+func TestDatabaseHealthCheck(t *testing.T) {
+	// Nothing listens on this port, so connecting fails right away
+	db, err := infra.NewRDB("postgres://localhost:1/tadoku?sslmode=disable", 1, 1)
+	assert.NoError(t, err)
+
+	check := infra.NewDatabaseHealthCheck(db, time.Second)
+	assert.Equal(t, "database", check.Name())
+	assert.Error(t, check.Check(context.Background()))
+}
+
+func TestMigrationsHealthCheck(t *testing.T) {
+	db, err := infra.NewRDB("postgres://localhost:1/tadoku?sslmode=disable", 1, 1)
+	assert.NoError(t, err)
+
+	check := infra.NewMigrationsHealthCheck(db, "./does-not-exist", time.Second)
+	assert.Equal(t, "migrations", check.Name())
+	assert.Error(t, check.Check(context.Background()))
+}
+
+func TestErrorReporterHealthCheck(t *testing.T) {
+	{
+		// Happy path: reporting errors is optional
+		check := infra.NewErrorReporterHealthCheck(nil)
+		assert.Equal(t, usecases.ErrHealthCheckDisabled, check.Check(context.Background()))
+	}
+
+	{
+		// Sad path: configured, but sentry isn't set up
+		check := infra.NewErrorReporterHealthCheck(usecases.NewMockErrorReporter(nil))
+		assert.Equal(t, infra.ErrErrorReporterNotInitialized, check.Check(context.Background()))
+	}
+}
@@ -0,0 +1,49 @@
+package infra
+
+import (
+	"crypto/ed25519"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+// signingMethodEdDSA signs tokens with Ed25519 keys as described in RFC 8037, jwt-go doesn't ship with it
+type signingMethodEdDSA struct{}
+
+var edDSA = &signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(edDSA.Alg(), func() jwt.SigningMethod {
+		return edDSA
+	})
+}
+
+func (m *signingMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (m *signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+func (m *signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	return jwt.EncodeSegment(ed25519.Sign(privateKey, []byte(signingString))), nil
+}
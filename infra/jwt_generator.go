@@ -1,6 +1,7 @@
 package infra
 
 import (
+	"strconv"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
@@ -8,13 +9,13 @@ import (
 )
 
 // NewJWTGenerator intializes a new JWTGenerator
-func NewJWTGenerator(signingKey string) usecases.JWTGenerator {
-	return &jwtGenerator{signingKey: signingKey}
+func NewJWTGenerator(keys *JWTKeys) usecases.JWTGenerator {
+	return &jwtGenerator{keys: keys}
 }
 
 // JWTGenerator makes it easy to generate JWT tokens that expire in a given duration
 type jwtGenerator struct {
-	signingKey string
+	keys *JWTKeys
 }
 
 // NewToken generates a signed JWT token
@@ -22,19 +23,34 @@ func (g *jwtGenerator) NewToken(lifetime time.Duration, src usecases.SessionClai
 	claims := jwtClaims{
 		SessionClaims: src,
 		StandardClaims: jwt.StandardClaims{
+			Id:        strconv.FormatUint(src.SessionID, 10),
 			ExpiresAt: time.Now().Add(lifetime).Unix(),
 		},
 	}
 
-	token := jwt.NewWithClaims(
-		jwt.SigningMethodHS256,
-		claims,
-	)
+	key := g.keys.signing
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.id != "" {
+		token.Header["kid"] = key.id
+	}
+
+	return token.SignedString(key.private)
+}
 
-	return token.SignedString([]byte(g.signingKey))
+// PublicKeys lists all keys that are accepted when verifying tokens
+func (g *jwtGenerator) PublicKeys() []usecases.JSONWebKey {
+	return g.keys.publicKeys()
 }
 
 type jwtClaims struct {
 	usecases.SessionClaims
 	jwt.StandardClaims
 }
+
+// sessionClaims restores the fields of the session claims that are stored in registered claims
+func (c *jwtClaims) sessionClaims() *usecases.SessionClaims {
+	claims := c.SessionClaims
+	claims.SessionID, _ = strconv.ParseUint(c.Id, 10, 64)
+
+	return &claims
+}
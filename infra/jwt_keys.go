@@ -0,0 +1,178 @@
+package infra
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/srvc/fail"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// ErrJWTKeyUnknown for when a token refers to a key that isn't accepted (anymore)
+var ErrJWTKeyUnknown = fail.New("token was signed with an unknown key")
+
+// ErrJWTSigningKeyMissing for when none of the keys can be used to sign new tokens
+var ErrJWTSigningKeyMissing = fail.New("no private key found to sign tokens with")
+
+// JWTKeys contains the key new tokens are signed with, together with every key that is
+// still accepted when verifying tokens. During a rotation old keys stay around as public keys
+// until all tokens signed with them have expired.
+type JWTKeys struct {
+	signing *jwtKey
+	keys    map[string]*jwtKey
+}
+
+type jwtKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+// NewSecretJWTKeys uses a single shared secret to sign and verify tokens with HS256
+func NewSecretJWTKeys(secret string) *JWTKeys {
+	key := &jwtKey{
+		method:  jwt.SigningMethodHS256,
+		private: []byte(secret),
+		public:  []byte(secret),
+	}
+
+	return &JWTKeys{
+		signing: key,
+		keys:    map[string]*jwtKey{key.id: key},
+	}
+}
+
+// LoadJWTKeys reads all PEM encoded RSA and Ed25519 keys in a directory, the file name without
+// the .pem extension is used as the key id. Files with only a public key can be used to verify
+// tokens but not to sign them. When signingKeyID is empty the last private key in alphabetical
+// order is used, so naming keys after the date they were created rotates them automatically.
+func LoadJWTKeys(directory string, signingKeyID string) (*JWTKeys, error) {
+	paths, err := filepath.Glob(filepath.Join(directory, "*.pem"))
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+	sort.Strings(paths)
+
+	keys := &JWTKeys{keys: map[string]*jwtKey{}}
+	for _, path := range paths {
+		key, err := loadJWTKey(path)
+		if err != nil {
+			return nil, domain.WrapError(err, fail.WithParam("path", path))
+		}
+
+		keys.keys[key.id] = key
+		if key.private != nil && (signingKeyID == "" || signingKeyID == key.id) {
+			keys.signing = key
+		}
+	}
+
+	if keys.signing == nil {
+		return nil, ErrJWTSigningKeyMissing
+	}
+
+	return keys, nil
+}
+
+func loadJWTKey(path string) (*jwtKey, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fail.New("file does not contain a PEM encoded key")
+	}
+
+	key := &jwtKey{id: strings.TrimSuffix(filepath.Base(path), ".pem")}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fail.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = edDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = edDSA, k
+	default:
+		return nil, fail.Errorf("unsupported key type %T", parsed)
+	}
+
+	return key, nil
+}
+
+// keyFunc finds the key a token claims to be signed with, and makes sure the algorithm matches that key
+func (k *JWTKeys) keyFunc(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+
+	key, ok := k.keys[id]
+	if !ok || token.Method.Alg() != key.method.Alg() {
+		return nil, ErrJWTKeyUnknown
+	}
+
+	return key.public, nil
+}
+
+// publicKeys describes all asymmetric keys as JSON web keys, shared secrets are never published
+func (k *JWTKeys) publicKeys() []usecases.JSONWebKey {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	result := []usecases.JSONWebKey{}
+	for _, id := range ids {
+		key := k.keys[id]
+		jwk := usecases.JSONWebKey{
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: key.method.Alg(),
+		}
+
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.Modulus = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.Exponent = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+
+		result = append(result, jwk)
+	}
+
+	return result
+}
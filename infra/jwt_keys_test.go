@@ -0,0 +1,142 @@
+package infra_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/infra"
+	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
+)
+
+func writeJWTKey(t *testing.T, directory, id, blockType string, der []byte) {
+	contents := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	err := ioutil.WriteFile(filepath.Join(directory, id+".pem"), contents, 0600)
+	require.NoError(t, err)
+}
+
+func setupJWTKeysDirectory(t *testing.T) (string, func()) {
+	directory, err := ioutil.TempDir("", "jwt_keys")
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writeJWTKey(t, directory, "2020-01-01", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	writeJWTKey(t, directory, "2020-06-01", "PRIVATE KEY", der)
+
+	return directory, func() { os.RemoveAll(directory) }
+}
+
+func TestLoadJWTKeys_PublicKeys(t *testing.T) {
+	directory, cleanup := setupJWTKeysDirectory(t)
+	defer cleanup()
+
+	keys, err := infra.LoadJWTKeys(directory, "")
+	require.NoError(t, err)
+
+	published := infra.NewJWTGenerator(keys).PublicKeys()
+	require.Len(t, published, 2)
+
+	assert.Equal(t, "2020-01-01", published[0].KeyID)
+	assert.Equal(t, "RSA", published[0].KeyType)
+	assert.Equal(t, "RS256", published[0].Algorithm)
+	assert.Equal(t, "AQAB", published[0].Exponent)
+	assert.NotEmpty(t, published[0].Modulus)
+
+	assert.Equal(t, "2020-06-01", published[1].KeyID)
+	assert.Equal(t, "OKP", published[1].KeyType)
+	assert.Equal(t, "EdDSA", published[1].Algorithm)
+	assert.Equal(t, "Ed25519", published[1].Curve)
+	assert.NotEmpty(t, published[1].X)
+
+	assert.Empty(t, infra.NewJWTGenerator(infra.NewSecretJWTKeys("foobar")).PublicKeys())
+}
+
+func TestLoadJWTKeys_MissingSigningKey(t *testing.T) {
+	directory, cleanup := setupJWTKeysDirectory(t)
+	defer cleanup()
+
+	_, err := infra.LoadJWTKeys(directory, "2019-01-01")
+	assert.EqualError(t, err, infra.ErrJWTSigningKeyMissing.Error())
+}
+
+func TestLoadJWTKeys_Rotation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	directory, cleanup := setupJWTKeysDirectory(t)
+	defer cleanup()
+
+	oldKeys, err := infra.LoadJWTKeys(directory, "2020-01-01")
+	require.NoError(t, err)
+	newKeys, err := infra.LoadJWTKeys(directory, "")
+	require.NoError(t, err)
+
+	sessions := usecases.NewMockSessionInteractor(ctrl)
+	sessions.EXPECT().VerifySession(gomock.Any(), uint64(1), gomock.Any()).Return(domain.User{Role: domain.RoleUser}, nil).AnyTimes()
+
+	handler := func(ctx services.Context) error {
+		return ctx.String(200, "test")
+	}
+	routes := []services.Route{
+		{Method: http.MethodGet, Path: "/restricted", HandlerFunc: handler, MinRole: domain.RoleUser},
+	}
+	e := infra.NewRouter("1337", newKeys, nil, nil, nil, nil, nil, 0, sessions, nil, routes...)
+
+	for _, tc := range []struct {
+		keys          *infra.JWTKeys
+		expStatusCode int
+		info          string
+	}{
+		{
+			keys:          oldKeys,
+			expStatusCode: http.StatusOK,
+			info:          "Tokens signed with the previous RS256 key are still accepted",
+		},
+		{
+			keys:          newKeys,
+			expStatusCode: http.StatusOK,
+			info:          "Tokens signed with the current EdDSA key are accepted",
+		},
+		{
+			keys:          infra.NewSecretJWTKeys("foobar"),
+			expStatusCode: http.StatusUnauthorized,
+			info:          "Tokens signed with an unknown key are rejected",
+		},
+	} {
+		claims := usecases.SessionClaims{User: &domain.User{Role: domain.RoleUser}, SessionID: 1}
+		token, err := infra.NewJWTGenerator(tc.keys).NewToken(time.Hour*1, claims)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/restricted", nil)
+		req.Header.Set(echo.HeaderAuthorization, middleware.DefaultJWTConfig.AuthScheme+" "+token)
+
+		res := httptest.NewRecorder()
+
+		e.ServeHTTP(res, req)
+
+		assert.Equal(t, tc.expStatusCode, res.Code, tc.info)
+	}
+}
@@ -0,0 +1,88 @@
+package infra
+
+import (
+	gocontext "context"
+	"sync"
+	"time"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// loginAttemptSweepInterval is how often forgotten failures are cleared out of memory
+const loginAttemptSweepInterval = time.Minute
+
+// NewMemoryLoginAttemptRepository keeps track of failed logins in memory,
+// which is only accurate as long as a single instance of the api is running
+func NewMemoryLoginAttemptRepository() usecases.LoginAttemptRepository {
+	return &memoryLoginAttemptRepository{attempts: map[string]domain.LoginAttempt{}}
+}
+
+type memoryLoginAttemptRepository struct {
+	mu        sync.Mutex
+	attempts  map[string]domain.LoginAttempt
+	lastSwept time.Time
+}
+
+func (r *memoryLoginAttemptRepository) FindByKey(ctx gocontext.Context, key string) (domain.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		return domain.LoginAttempt{}, domain.ErrNotFound
+	}
+
+	return attempt, nil
+}
+
+func (r *memoryLoginAttemptRepository) RecordFailure(ctx gocontext.Context, key string, failedAt time.Time, resetBefore time.Time) (domain.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sweep(failedAt, resetBefore)
+
+	attempt, ok := r.attempts[key]
+	if !ok || attempt.LastFailedAt.Before(resetBefore) {
+		attempt = domain.LoginAttempt{Key: key}
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = failedAt
+	r.attempts[key] = attempt
+
+	return attempt, nil
+}
+
+func (r *memoryLoginAttemptRepository) Delete(ctx gocontext.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) DeleteFailedBefore(ctx gocontext.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deleteFailedBefore(before)
+	return nil
+}
+
+// sweep removes failures that would be forgotten anyway, so memory doesn't grow without bounds
+func (r *memoryLoginAttemptRepository) sweep(now time.Time, resetBefore time.Time) {
+	if now.Sub(r.lastSwept) < loginAttemptSweepInterval {
+		return
+	}
+	r.lastSwept = now
+
+	r.deleteFailedBefore(resetBefore)
+}
+
+func (r *memoryLoginAttemptRepository) deleteFailedBefore(before time.Time) {
+	for key, attempt := range r.attempts {
+		if attempt.LastFailedAt.Before(before) {
+			delete(r.attempts, key)
+		}
+	}
+}
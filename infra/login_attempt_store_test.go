@@ -0,0 +1,56 @@
+package infra_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/infra"
+)
+
+func TestMemoryLoginAttemptRepository_RecordFailure(t *testing.T) {
+	repo := infra.NewMemoryLoginAttemptRepository()
+	key := domain.LoginAttemptIPKey("127.0.0.1")
+	now := time.Now()
+
+	_, err := repo.FindByKey(context.Background(), key)
+	assert.EqualError(t, err, domain.ErrNotFound.Error())
+
+	attempt, err := repo.RecordFailure(context.Background(), key, now, now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LoginAttempt{Key: key, Failures: 1, LastFailedAt: now}, attempt)
+
+	attempt, err = repo.RecordFailure(context.Background(), key, now, now.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempt.Failures)
+
+	later := now.Add(2 * time.Hour)
+	attempt, err = repo.RecordFailure(context.Background(), key, later, later.Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempt.Failures, "old failures are forgotten")
+
+	assert.NoError(t, repo.Delete(context.Background(), key))
+	_, err = repo.FindByKey(context.Background(), key)
+	assert.EqualError(t, err, domain.ErrNotFound.Error())
+}
+
+func TestMemoryLoginAttemptRepository_DeleteFailedBefore(t *testing.T) {
+	repo := infra.NewMemoryLoginAttemptRepository()
+	now := time.Now()
+	stale, recent := domain.LoginAttemptIPKey("127.0.0.1"), domain.LoginAttemptIPKey("127.0.0.2")
+
+	_, err := repo.RecordFailure(context.Background(), stale, now.Add(-2*time.Hour), now.Add(-24*time.Hour))
+	assert.NoError(t, err)
+	_, err = repo.RecordFailure(context.Background(), recent, now, now.Add(-24*time.Hour))
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.DeleteFailedBefore(context.Background(), now.Add(-time.Hour)))
+
+	_, err = repo.FindByKey(context.Background(), stale)
+	assert.EqualError(t, err, domain.ErrNotFound.Error())
+	_, err = repo.FindByKey(context.Background(), recent)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,98 @@
+package infra
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewSMTPMailer creates a mailer that delivers mails through an SMTP server
+func NewSMTPMailer(host string, port int, username, password, from string) usecases.Mailer {
+	return &smtpMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+type smtpMailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+func (m *smtpMailer) Send(mail usecases.Mail) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	err := smtp.SendMail(addr, auth, envelopeAddress(m.from), []string{mail.To}, encodeMail(m.from, mail))
+
+	return domain.WrapError(err)
+}
+
+// NewFileMailer creates a mailer that writes mails to a directory instead of sending them, meant for local development.
+// When no directory is given the mails are written to the log instead.
+func NewFileMailer(directory string, from string) usecases.Mailer {
+	return &fileMailer{directory: directory, from: from}
+}
+
+type fileMailer struct {
+	directory string
+	from      string
+}
+
+func (m *fileMailer) Send(mail usecases.Mail) error {
+	message := encodeMail(m.from, mail)
+
+	if m.directory == "" {
+		log.Printf("Mail not sent, no mailer configured:\n%s\n", message)
+		return nil
+	}
+
+	name := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), strings.Replace(mail.To, "@", "_at_", -1))
+	err := ioutil.WriteFile(filepath.Join(m.directory, name), message, 0644)
+
+	return domain.WrapError(err)
+}
+
+func encodeMail(from string, mail usecases.Mail) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", mail.To)
+	fmt.Fprintf(buf, "Subject: %s\r\n", mail.Subject)
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.Replace(mail.Body, "\n", "\r\n", -1))
+
+	return buf.Bytes()
+}
+
+// envelopeAddress strips the display name from an address such as "Tadoku <hello@tadoku.app>"
+func envelopeAddress(address string) string {
+	parsed, err := netmail.ParseAddress(address)
+	if err != nil {
+		return address
+	}
+
+	return parsed.Address
+}
@@ -0,0 +1,195 @@
+package infra
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/tadoku/api/interfaces/services"
+)
+
+const metricsNamespace = "tadoku"
+
+// Metrics collects everything that's exposed to Prometheus
+type Metrics struct {
+	registry    *prometheus.Registry
+	scrapeToken string
+
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+
+	contestLogsCreated prometheus.Counter
+	contestLogsDeleted prometheus.Counter
+	rankingsRecomputed prometheus.Counter
+	rankingsRepaired   prometheus.Counter
+}
+
+// NewMetrics registers all metrics, the connection pool is optional.
+// Metrics are only served to scrapers that send the token as a bearer token, without one they aren't served at all.
+func NewMetrics(db *RDB, scrapeToken string) *Metrics {
+	m := &Metrics{
+		registry:    prometheus.NewRegistry(),
+		scrapeToken: scrapeToken,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "Requests that have been handled, by route and status code",
+		}, []string{"method", "route", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time it took to handle requests, by route",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		contestLogsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "contest_logs_created_total",
+			Help:      "Contest logs that have been created",
+		}),
+		contestLogsDeleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "contest_logs_deleted_total",
+			Help:      "Contest logs that have been deleted",
+		}),
+		rankingsRecomputed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rankings_recomputed_total",
+			Help:      "Times the rankings of a user in a contest have been recomputed",
+		}),
+		rankingsRepaired: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "rankings_drift_repaired_total",
+			Help:      "Times the rankings of a user in a contest had drifted from their logs and were repaired",
+		}),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.requests,
+		m.latency,
+		m.contestLogsCreated,
+		m.contestLogsDeleted,
+		m.rankingsRecomputed,
+		m.rankingsRepaired,
+	)
+	if db != nil {
+		m.registry.MustRegister(newDBStatsCollector(db))
+	}
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ContestLogCreated counts a new log
+func (m *Metrics) ContestLogCreated() {
+	m.contestLogsCreated.Inc()
+}
+
+// ContestLogDeleted counts a removed log
+func (m *Metrics) ContestLogDeleted() {
+	m.contestLogsDeleted.Inc()
+}
+
+// RankingsRecomputed counts rankings being recomputed from all logs of a user
+func (m *Metrics) RankingsRecomputed() {
+	m.rankingsRecomputed.Inc()
+}
+
+// RankingsDriftRepaired counts rankings that no longer matched their logs
+func (m *Metrics) RankingsDriftRepaired() {
+	m.rankingsRepaired.Inc()
+}
+
+// metricsPath is where Prometheus scrapes the metrics from
+const metricsPath = "/metrics"
+
+// register serves the metrics on the router, they share the public port so scrapers have to authenticate
+func (m *Metrics) register(e *echo.Echo) {
+	if m.scrapeToken == "" {
+		return
+	}
+
+	e.GET(metricsPath, echo.WrapHandler(m.Handler()), middleware.KeyAuth(func(token string, c echo.Context) (bool, error) {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(m.scrapeToken)) == 1, nil
+	}))
+}
+
+// observer records every request by the path of its route instead of the url, so ids don't end up in labels
+func (m *Metrics) observer(routes []services.Route) requestObserver {
+	matcher := newRouteMatcher(routes, metricsPath)
+
+	return func(c echo.Context, start time.Time, err error) {
+		route := matcher.route(c)
+		method := c.Request().Method
+
+		m.requests.WithLabelValues(method, route, strconv.Itoa(c.Response().Status)).Inc()
+		m.latency.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// dbStatsCollector exposes the statistics of the connection pool
+type dbStatsCollector struct {
+	db *RDB
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+func newDBStatsCollector(db *RDB) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "db", name), help, nil, nil)
+	}
+
+	return &dbStatsCollector{
+		db:                db,
+		maxOpen:           desc("max_open_connections", "Maximum number of open connections to the database"),
+		open:              desc("open_connections", "Established connections, both in use and idle"),
+		inUse:             desc("in_use_connections", "Connections currently in use"),
+		idle:              desc("idle_connections", "Idle connections"),
+		waitCount:         desc("wait_count_total", "Connections that had to be waited for"),
+		waitDuration:      desc("wait_duration_seconds_total", "Time spent waiting for new connections"),
+		maxIdleClosed:     desc("max_idle_closed_total", "Connections closed because there were too many idle ones"),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "Connections closed because they reached their maximum lifetime"),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
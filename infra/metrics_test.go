@@ -0,0 +1,88 @@
+package infra_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/infra"
+	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
+)
+
+func TestMetrics(t *testing.T) {
+	db, err := infra.NewRDB("postgres://localhost/tadoku", 1, 2)
+	assert.NoError(t, err)
+
+	metrics := infra.NewMetrics(db, "secret")
+	routes := []services.Route{
+		{Method: http.MethodGet, Path: "/contests/:id", HandlerFunc: func(ctx services.Context) error {
+			return ctx.String(http.StatusOK, "test")
+		}},
+		{Method: http.MethodPost, Path: "/contest_logs", HandlerFunc: func(ctx services.Context) error {
+			return domain.WrapError(usecases.ErrContestIsClosed)
+		}},
+	}
+	e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, nil, metrics, nil, 0, nil, nil, routes...)
+
+	for _, r := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/contests/1"},
+		{http.MethodGet, "/contests/2"},
+		{http.MethodPost, "/contest_logs"},
+		{http.MethodGet, "/missing"},
+	} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(r.method, r.path, nil))
+	}
+	metrics.ContestLogCreated()
+	metrics.RankingsRecomputed()
+	metrics.RankingsDriftRepaired()
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer secret")
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	body := res.Body.String()
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, body, `tadoku_http_requests_total{method="GET",route="/contests/:id",status="200"} 2`, "Requests are grouped by route")
+	assert.Contains(t, body, `tadoku_http_requests_total{method="POST",route="/contest_logs",status="422"} 1`, "Errors are counted with their final status")
+	assert.Contains(t, body, `tadoku_http_requests_total{method="GET",route="unmatched",status="404"} 1`, "Unknown urls don't create new series")
+	assert.Contains(t, body, `tadoku_http_request_duration_seconds_count{method="GET",route="/contests/:id"} 2`)
+	assert.Contains(t, body, "tadoku_contest_logs_created_total 1")
+	assert.Contains(t, body, "tadoku_rankings_recomputed_total 1")
+	assert.Contains(t, body, "tadoku_rankings_drift_repaired_total 1")
+	assert.Contains(t, body, "tadoku_db_max_open_connections 2")
+}
+
+func TestMetrics_Authentication(t *testing.T) {
+	{
+		// Sad path: scrapers need the token
+		e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, nil, infra.NewMetrics(nil, "secret"), nil, 0, nil, nil)
+
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusBadRequest, res.Code, "the token is missing")
+
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer wrong")
+		res = httptest.NewRecorder()
+		e.ServeHTTP(res, req)
+		assert.Equal(t, http.StatusUnauthorized, res.Code)
+	}
+
+	{
+		// Sad path: metrics aren't served without a token
+		e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, nil, infra.NewMetrics(nil, ""), nil, 0, nil, nil)
+
+		res := httptest.NewRecorder()
+		e.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	}
+}
@@ -1,31 +1,205 @@
 package infra
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/srvc/fail"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/tadoku/api/domain"
 	"github.com/tadoku/api/usecases"
 )
 
-// NewPasswordHasher initializes a new password hasher with sane defaults
-func NewPasswordHasher() usecases.PasswordHasher {
-	return &passwordHasher{cost: bcrypt.DefaultCost}
+// These are the algorithms new passwords can be hashed with
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+// ErrPasswordAlgorithmUnknown for when the hasher is configured with an algorithm it doesn't support
+var ErrPasswordAlgorithmUnknown = fail.New("unknown password hashing algorithm")
+
+var errArgon2HashInvalid = fail.New("hash is not a supported argon2id hash")
+
+// PasswordHasherConfig decides how new passwords are hashed, zero values fall back to the defaults
+type PasswordHasherConfig struct {
+	Algorithm string
+
+	BcryptCost int
+
+	Argon2Time    uint32
+	Argon2Memory  uint32 // in KiB
+	Argon2Threads uint8
+}
+
+const (
+	defaultArgon2Time    = 3
+	defaultArgon2Memory  = 64 * 1024
+	defaultArgon2Threads = 2
+	argon2SaltLength     = 16
+	argon2KeyLength      = 32
+)
+
+// NewPasswordHasher initializes a new password hasher, hashes of every supported algorithm can be verified
+// regardless of which algorithm is used for new passwords
+func NewPasswordHasher(config PasswordHasherConfig) (usecases.PasswordHasher, error) {
+	h := &passwordHasher{
+		algorithm:  config.Algorithm,
+		bcryptCost: config.BcryptCost,
+		argon2: argon2Params{
+			time:    config.Argon2Time,
+			memory:  config.Argon2Memory,
+			threads: config.Argon2Threads,
+			keyLen:  argon2KeyLength,
+		},
+	}
+
+	if h.algorithm == "" {
+		h.algorithm = PasswordAlgorithmArgon2id
+	}
+	if h.algorithm != PasswordAlgorithmBcrypt && h.algorithm != PasswordAlgorithmArgon2id {
+		return nil, ErrPasswordAlgorithmUnknown
+	}
+
+	if h.bcryptCost == 0 {
+		h.bcryptCost = bcrypt.DefaultCost
+	}
+	if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+		return nil, fail.Errorf("bcrypt cost should be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	if h.argon2.time == 0 {
+		h.argon2.time = defaultArgon2Time
+	}
+	if h.argon2.memory == 0 {
+		h.argon2.memory = defaultArgon2Memory
+	}
+	if h.argon2.threads == 0 {
+		h.argon2.threads = defaultArgon2Threads
+	}
+
+	return h, nil
 }
 
 type passwordHasher struct {
-	cost int
+	algorithm  string
+	bcryptCost int
+	argon2     argon2Params
+}
+
+type argon2Params struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	keyLen  uint32
 }
 
 func (h *passwordHasher) Hash(value domain.Password) (domain.Password, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(value), h.cost)
-	if err != nil {
+	if h.algorithm == PasswordAlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(value), h.bcryptCost)
+		if err != nil {
+			return "", domain.WrapError(err)
+		}
+
+		return domain.Password(hash), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", domain.WrapError(err)
 	}
 
-	return domain.Password(hash), nil
+	key := h.argon2.key([]byte(value), salt)
+	return domain.Password(h.argon2.encode(salt, key)), nil
 }
 
 func (h *passwordHasher) Compare(hash domain.Password, original string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(original))
-	return err == nil
+	if isBcryptHash(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(original))
+		return err == nil
+	}
+
+	params, salt, key, err := decodeArgon2Hash(string(hash))
+	if err != nil {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(key, params.key([]byte(original), salt)) == 1
+}
+
+func (h *passwordHasher) NeedsRehash(hash domain.Password) bool {
+	if h.algorithm == PasswordAlgorithmBcrypt {
+		if !isBcryptHash(hash) {
+			return true
+		}
+
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.bcryptCost
+	}
+
+	params, _, _, err := decodeArgon2Hash(string(hash))
+	return err != nil || params != h.argon2
+}
+
+func isBcryptHash(hash domain.Password) bool {
+	for _, prefix := range []string{"$2a$", "$2b$", "$2y$"} {
+		if strings.HasPrefix(string(hash), prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p argon2Params) key(password, salt []byte) []byte {
+	return argon2.IDKey(password, salt, p.time, p.memory, p.threads, p.keyLen)
+}
+
+// encode formats a hash as a PHC string: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func (p argon2Params) encode(salt, key []byte) string {
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		p.memory,
+		p.time,
+		p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+}
+
+func decodeArgon2Hash(hash string) (argon2Params, []byte, []byte, error) {
+	// The leading $ results in an empty first part
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return argon2Params{}, nil, nil, errArgon2HashInvalid
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Params{}, nil, nil, errArgon2HashInvalid
+	}
+
+	p := argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return argon2Params{}, nil, nil, domain.WrapError(err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return argon2Params{}, nil, nil, domain.WrapError(err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return argon2Params{}, nil, nil, domain.WrapError(err)
+	}
+	p.keyLen = uint32(len(key))
+
+	return p, salt, key, nil
 }
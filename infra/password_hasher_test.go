@@ -0,0 +1,90 @@
+package infra_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/infra"
+)
+
+// Keep the tests fast, the defaults are meant for production
+var testArgon2Config = infra.PasswordHasherConfig{
+	Algorithm:     infra.PasswordAlgorithmArgon2id,
+	Argon2Time:    1,
+	Argon2Memory:  1024,
+	Argon2Threads: 1,
+}
+
+func TestPasswordHasher_Argon2id(t *testing.T) {
+	hasher, err := infra.NewPasswordHasher(testArgon2Config)
+	require.NoError(t, err)
+
+	hash, err := hasher.Hash("foobar")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(string(hash), "$argon2id$v=19$m=1024,t=1,p=1$"))
+	assert.True(t, hasher.Compare(hash, "foobar"))
+	assert.False(t, hasher.Compare(hash, "barfoo"))
+	assert.False(t, hasher.NeedsRehash(hash))
+
+	other, err := hasher.Hash("foobar")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "every hash gets its own salt")
+
+	assert.False(t, hasher.Compare("$argon2id$v=19$m=1024,t=1,p=1$foo", "foobar"))
+}
+
+func TestPasswordHasher_LegacyBcrypt(t *testing.T) {
+	hasher, err := infra.NewPasswordHasher(testArgon2Config)
+	require.NoError(t, err)
+
+	for _, cost := range []int{bcrypt.MinCost, bcrypt.MinCost + 1} {
+		legacy, err := bcrypt.GenerateFromPassword([]byte("foobar"), cost)
+		require.NoError(t, err)
+
+		assert.True(t, hasher.Compare(domain.Password(legacy), "foobar"))
+		assert.False(t, hasher.Compare(domain.Password(legacy), "barfoo"))
+		assert.True(t, hasher.NeedsRehash(domain.Password(legacy)))
+	}
+}
+
+func TestPasswordHasher_NeedsRehash(t *testing.T) {
+	hasher, err := infra.NewPasswordHasher(testArgon2Config)
+	require.NoError(t, err)
+	hash, err := hasher.Hash("foobar")
+	require.NoError(t, err)
+
+	stronger := testArgon2Config
+	stronger.Argon2Time = 2
+	strongerHasher, err := infra.NewPasswordHasher(stronger)
+	require.NoError(t, err)
+
+	assert.True(t, strongerHasher.Compare(hash, "foobar"))
+	assert.True(t, strongerHasher.NeedsRehash(hash))
+
+	bcryptHasher, err := infra.NewPasswordHasher(infra.PasswordHasherConfig{
+		Algorithm:  infra.PasswordAlgorithmBcrypt,
+		BcryptCost: bcrypt.MinCost,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, bcryptHasher.Compare(hash, "foobar"))
+	assert.True(t, bcryptHasher.NeedsRehash(hash))
+
+	bcryptHash, err := bcryptHasher.Hash("foobar")
+	require.NoError(t, err)
+	assert.False(t, bcryptHasher.NeedsRehash(bcryptHash))
+}
+
+func TestPasswordHasher_InvalidConfig(t *testing.T) {
+	_, err := infra.NewPasswordHasher(infra.PasswordHasherConfig{Algorithm: "md5"})
+	assert.EqualError(t, err, infra.ErrPasswordAlgorithmUnknown.Error())
+
+	_, err = infra.NewPasswordHasher(infra.PasswordHasherConfig{BcryptCost: bcrypt.MaxCost + 1})
+	assert.Error(t, err)
+}
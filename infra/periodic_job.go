@@ -0,0 +1,83 @@
+package infra
+
+import (
+	gocontext "context"
+	"log"
+	"time"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// PeriodicJob runs a task in the background on an interval until it's stopped
+type PeriodicJob struct {
+	name     string
+	interval time.Duration
+	reporter usecases.ErrorReporter
+	task     func(ctx gocontext.Context) error
+
+	cancel gocontext.CancelFunc
+	done   chan struct{}
+}
+
+// NewPeriodicJob creates a job that still has to be started, the error reporter is optional
+func NewPeriodicJob(
+	name string,
+	interval time.Duration,
+	reporter usecases.ErrorReporter,
+	task func(ctx gocontext.Context) error,
+) *PeriodicJob {
+	return &PeriodicJob{name: name, interval: interval, reporter: reporter, task: task}
+}
+
+// Start runs the task after every interval, the first run waits for one interval so booting up stays fast
+func (j *PeriodicJob) Start() {
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	j.cancel = cancel
+	j.done = make(chan struct{})
+
+	go j.run(ctx)
+}
+
+func (j *PeriodicJob) run(ctx gocontext.Context) {
+	defer close(j.done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Runs that got cancelled because of a shutdown aren't worth reporting
+			if err := j.task(ctx); err != nil && ctx.Err() == nil {
+				j.report(err)
+			}
+		}
+	}
+}
+
+func (j *PeriodicJob) report(err error) {
+	if j.reporter == nil {
+		log.Printf("%s failed: %v\n", j.name, err)
+		return
+	}
+
+	j.reporter.Capture(err, "")
+}
+
+// Stop cancels the run in progress and waits for it to finish, or until the context expires
+func (j *PeriodicJob) Stop(ctx gocontext.Context) error {
+	if j.cancel == nil {
+		return nil
+	}
+	j.cancel()
+
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return domain.WrapError(ctx.Err())
+	}
+}
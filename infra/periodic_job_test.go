@@ -0,0 +1,89 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/infra"
+	"github.com/tadoku/api/usecases"
+)
+
+// signal lets the test know a run happened, without blocking the job when nobody is waiting
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func TestPeriodicJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	errFailed := errors.New("connection reset")
+	reporter := usecases.NewMockErrorReporter(ctrl)
+	reporter.EXPECT().Capture(errFailed, "").MinTimes(1)
+
+	runs := make(chan struct{}, 1)
+	job := infra.NewPeriodicJob("test", time.Millisecond, reporter, func(ctx context.Context) error {
+		signal(runs)
+		return errFailed
+	})
+	job.Start()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatal("the task didn't run on its interval")
+		}
+	}
+
+	assert.NoError(t, job.Stop(context.Background()))
+}
+
+func TestPeriodicJob_Stop(t *testing.T) {
+	{
+		// Happy path: jobs that never started can be stopped
+		job := infra.NewPeriodicJob("test", time.Millisecond, nil, func(ctx context.Context) error { return nil })
+		assert.NoError(t, job.Stop(context.Background()))
+	}
+
+	{
+		// Happy path: the run in progress gets cancelled, without reporting an error
+		started := make(chan struct{}, 1)
+		job := infra.NewPeriodicJob("test", time.Millisecond, usecases.NewMockErrorReporter(nil), func(ctx context.Context) error {
+			signal(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		job.Start()
+		<-started
+
+		assert.NoError(t, job.Stop(context.Background()))
+	}
+
+	{
+		// Sad path: runs that ignore being cancelled can't hold up a shutdown forever
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+		defer close(release)
+
+		job := infra.NewPeriodicJob("test", time.Millisecond, nil, func(ctx context.Context) error {
+			signal(started)
+			<-release
+			return nil
+		})
+		job.Start()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.EqualError(t, job.Stop(ctx), context.DeadlineExceeded.Error())
+	}
+}
@@ -0,0 +1,153 @@
+package infra
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/tadoku/api/interfaces/services"
+)
+
+// requestIDRegularExpression limits which request ids are taken over from clients and proxies, so they can't fill logs with garbage
+var requestIDRegularExpression = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+
+// requestID returns the id of the current request, it's set on the response as soon as the request comes in
+func requestID(c echo.Context) string {
+	return c.Response().Header().Get(echo.HeaderXRequestID)
+}
+
+// newRequestIDMiddleware reuses the X-Request-ID of the request when there is one, so requests can be traced across services
+func newRequestIDMiddleware() echo.MiddlewareFunc {
+	generator := NewTokenGenerator(16)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !requestIDRegularExpression.MatchString(id) {
+				var err error
+				if id, err = generator.Generate(); err != nil {
+					return err
+				}
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			return next(c)
+		}
+	}
+}
+
+// requestObserver looks at a request after it has been handled, err is the error that was handled if there was one
+type requestObserver func(c echo.Context, start time.Time, err error)
+
+// newObserverMiddleware handles errors itself so observers see the status that was sent back,
+// otherwise echo only writes it after all middleware is done
+func newObserverMiddleware(observers ...requestObserver) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			for _, observe := range observers {
+				observe(c, start, err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// unmatchedRoute is used for requests that didn't match any route, so random urls don't create new series or log entries that look like routes
+const unmatchedRoute = "unmatched"
+
+// routeMatcher finds the path of the route that handled a request
+type routeMatcher map[string]bool
+
+func newRouteMatcher(routes []services.Route, extra ...string) routeMatcher {
+	known := routeMatcher{}
+	for _, path := range extra {
+		known[path] = true
+	}
+	for _, route := range routes {
+		known[route.Path] = true
+	}
+
+	return known
+}
+
+func (m routeMatcher) route(c echo.Context) string {
+	// Echo uses the url as the path when nothing matched
+	if route := c.Path(); m[route] {
+		return route
+	}
+
+	return unmatchedRoute
+}
+
+// accessLogEntry is written as a single line of json for every request
+type accessLogEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	URI       string    `json:"uri"`
+	Status    int       `json:"status"`
+	LatencyMS float64   `json:"latency_ms"`
+	UserID    uint64    `json:"user_id,omitempty"`
+	RemoteIP  string    `json:"remote_ip"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type accessLogger struct {
+	out     io.Writer
+	lock    sync.Mutex
+	matcher routeMatcher
+}
+
+// newAccessLogObserver logs every request as structured json, including the error that was handled
+func newAccessLogObserver(out io.Writer, routes []services.Route) requestObserver {
+	l := &accessLogger{out: out, matcher: newRouteMatcher(routes, metricsPath)}
+
+	return func(c echo.Context, start time.Time, err error) {
+		entry := accessLogEntry{
+			Time:      start.UTC(),
+			RequestID: requestID(c),
+			Method:    c.Request().Method,
+			Route:     l.matcher.route(c),
+			URI:       c.Request().RequestURI,
+			Status:    c.Response().Status,
+			LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			RemoteIP:  c.RealIP(),
+		}
+		if user, userErr := (&context{c}).User(); userErr == nil {
+			entry.UserID = user.ID
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+
+		l.write(c, entry)
+	}
+}
+
+func (l *accessLogger) write(c echo.Context, entry accessLogEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		c.Logger().Error(err)
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, err := l.out.Write(append(line, '\n')); err != nil {
+		c.Logger().Error(err)
+	}
+}
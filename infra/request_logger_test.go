@@ -0,0 +1,152 @@
+package infra_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/infra"
+	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
+)
+
+func TestRouter_RequestID(t *testing.T) {
+	routes := []services.Route{
+		{Method: http.MethodGet, Path: "/ping", HandlerFunc: func(ctx services.Context) error {
+			return ctx.String(http.StatusOK, "pong")
+		}},
+	}
+	e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, nil, nil, nil, 0, nil, nil, routes...)
+
+	for _, tc := range []struct {
+		requestID string
+		reused    bool
+		info      string
+	}{
+		{requestID: "", reused: false, info: "A new id is generated when there is none"},
+		{requestID: "abc-123", reused: true, info: "Ids of other services are passed along"},
+		{requestID: "abc\x00123", reused: false, info: "Ids that could mess up logs are replaced"},
+		{requestID: strings.Repeat("a", 129), reused: false, info: "Ids that are too long are replaced"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set(echo.HeaderXRequestID, tc.requestID)
+		res := httptest.NewRecorder()
+
+		e.ServeHTTP(res, req)
+
+		id := res.Header().Get(echo.HeaderXRequestID)
+		assert.NotEmpty(t, id, tc.info)
+		assert.Equal(t, tc.reused, id == tc.requestID, tc.info)
+	}
+}
+
+func TestRouter_AccessLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := domain.User{ID: 1, DisplayName: "foo", Role: domain.RoleUser}
+	tokens := usecases.NewMockPersonalAccessTokenInteractor(ctrl)
+	tokens.EXPECT().Authenticate(gomock.Any(), "tdk_foobar").Return(user, domain.Scopes{domain.ScopeLogsWrite}, nil)
+
+	routes := []services.Route{
+		{Method: http.MethodPost, Path: "/contest_logs/:id", MinRole: domain.RoleUser, Scope: domain.ScopeLogsWrite, HandlerFunc: func(ctx services.Context) error {
+			return ctx.NoContent(http.StatusNoContent)
+		}},
+		{Method: http.MethodGet, Path: "/contests/:id", HandlerFunc: func(ctx services.Context) error {
+			return domain.WrapError(usecases.ErrContestIsClosed)
+		}},
+	}
+	out := &bytes.Buffer{}
+	e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, nil, nil, out, 0, nil, tokens, routes...)
+
+	req := httptest.NewRequest(http.MethodPost, "/contest_logs/5", nil)
+	req.Header.Set(echo.HeaderAuthorization, middleware.DefaultJWTConfig.AuthScheme+" tdk_foobar")
+	req.Header.Set(echo.HeaderXRequestID, "first")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/contests/2?foo=bar", nil)
+	req.Header.Set(echo.HeaderXRequestID, "second")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 3, "Every request is logged on its own line")
+
+	var entries []map[string]interface{}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+
+	{
+		// Happy path: authenticated request
+		assert.Equal(t, "first", entries[0]["request_id"])
+		assert.Equal(t, "POST", entries[0]["method"])
+		assert.Equal(t, "/contest_logs/:id", entries[0]["route"])
+		assert.Equal(t, "/contest_logs/5", entries[0]["uri"])
+		assert.Equal(t, float64(http.StatusNoContent), entries[0]["status"])
+		assert.Equal(t, float64(user.ID), entries[0]["user_id"])
+		assert.Contains(t, entries[0], "latency_ms")
+		assert.NotContains(t, entries[0], "error")
+	}
+
+	{
+		// Sad path: errors are logged with the request they happened in
+		assert.Equal(t, "second", entries[1]["request_id"])
+		assert.Equal(t, "/contests/:id", entries[1]["route"])
+		assert.Equal(t, "/contests/2?foo=bar", entries[1]["uri"])
+		assert.Equal(t, float64(http.StatusUnprocessableEntity), entries[1]["status"])
+		assert.Equal(t, "the given contest is closed", entries[1]["error"])
+		assert.NotContains(t, entries[1], "user_id", "Guests have no user id")
+	}
+
+	{
+		// Sad path: unknown urls
+		assert.Equal(t, "unmatched", entries[2]["route"])
+		assert.Equal(t, float64(http.StatusNotFound), entries[2]["status"])
+		assert.NotEmpty(t, entries[2]["request_id"])
+	}
+}
+
+func TestRouter_ErrorsAreHandledOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	errFailed := errors.New("connection reset")
+	reporter := usecases.NewMockErrorReporter(ctrl)
+	reporter.EXPECT().Capture(errFailed, gomock.Any()).Times(1)
+
+	routes := []services.Route{
+		{Method: http.MethodGet, Path: "/contests/:id", HandlerFunc: func(ctx services.Context) error {
+			return errFailed
+		}},
+	}
+	metrics := infra.NewMetrics(nil, "secret")
+	out := &bytes.Buffer{}
+	e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, reporter, metrics, out, 0, nil, nil, routes...)
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/contests/1", nil))
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, float64(http.StatusInternalServerError), entry["status"])
+	assert.Equal(t, errFailed.Error(), entry["error"])
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer secret")
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Contains(t, res.Body.String(), `tadoku_http_requests_total{method="GET",route="/contests/:id",status="500"} 1`)
+}
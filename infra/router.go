@@ -1,10 +1,16 @@
 package infra
 
 import (
+	gocontext "context"
+	"io"
+	"net"
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
+	jwt "github.com/dgrijalva/jwt-go"
 	sentryecho "github.com/getsentry/sentry-go/echo"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -17,32 +23,68 @@ import (
 // NewRouter instantiates a router
 func NewRouter(
 	port string,
-	jwtSecret string,
+	jwtKeys *JWTKeys,
 	corsAllowedOrigins []string,
+	trustedProxies []*net.IPNet,
 	errorReporter usecases.ErrorReporter,
+	metrics *Metrics,
+	accessLog io.Writer,
+	databaseTimeout time.Duration,
+	sessionInteractor usecases.SessionInteractor,
+	personalAccessTokenInteractor usecases.PersonalAccessTokenInteractor,
 	routes ...services.Route,
 ) services.Router {
 	m := &middlewares{
-		restrict:      newJWTMiddleware(jwtSecret),
-		authenticator: usecases.NewRoleAuthenticator(),
+		restrict:             newJWTMiddleware(jwtKeys),
+		authenticator:        usecases.NewRoleAuthenticator(),
+		sessions:             sessionInteractor,
+		personalAccessTokens: personalAccessTokenInteractor,
 	}
-	e := newEcho(m, corsAllowedOrigins, errorReporter, routes...)
+	e := newEcho(m, corsAllowedOrigins, trustedProxies, errorReporter, metrics, accessLog, databaseTimeout, routes...)
 	return router{e, port}
 }
 
 type middlewares struct {
-	restrict      echo.MiddlewareFunc
-	authenticator usecases.RoleAuthenticator
+	restrict             echo.MiddlewareFunc
+	authenticator        usecases.RoleAuthenticator
+	sessions             usecases.SessionInteractor
+	personalAccessTokens usecases.PersonalAccessTokenInteractor
 }
 
+// personalAccessTokenClaimsKey is where the claims of a request authenticated with a personal access token are stored
+const personalAccessTokenClaimsKey = "personal_access_token_claims"
+
+var bearerPrefix = middleware.DefaultJWTConfig.AuthScheme + " "
+
 func newEcho(
 	m *middlewares,
 	corsAllowedOrigins []string,
+	trustedProxies []*net.IPNet,
 	errorReporter usecases.ErrorReporter,
+	metrics *Metrics,
+	accessLog io.Writer,
+	databaseTimeout time.Duration,
 	routes ...services.Route,
 ) *echo.Echo {
 	e := echo.New()
+	e.IPExtractor = newIPExtractor(trustedProxies)
 	e.HTTPErrorHandler = errorHandler(errorReporter)
+	e.Use(newRequestIDMiddleware())
+
+	var observers []requestObserver
+	if metrics != nil {
+		observers = append(observers, metrics.observer(routes))
+		metrics.register(e)
+	}
+	if accessLog != nil {
+		observers = append(observers, newAccessLogObserver(accessLog, routes))
+	}
+	if len(observers) > 0 {
+		e.Use(newObserverMiddleware(observers...))
+	}
+	if databaseTimeout > 0 {
+		e.Use(newDeadlineMiddleware(databaseTimeout))
+	}
 	e.Use(sentryecho.New(sentryecho.Options{}))
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: corsAllowedOrigins,
@@ -56,51 +98,155 @@ func newEcho(
 	return e
 }
 
-func newJWTMiddleware(secret string) echo.MiddlewareFunc {
-	cfg := middleware.JWTConfig{
-		Claims:     &jwtClaims{},
-		SigningKey: []byte(secret),
+// newIPExtractor only believes `X-Forwarded-For` when the request came in through one of the trusted proxies,
+// otherwise clients could pick the address their failed logins are counted under
+func newIPExtractor(trustedProxies []*net.IPNet) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range trustedProxies {
+		options = append(options, echo.TrustIPRange(proxy))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// newJWTMiddleware only lets requests through with a valid bearer token. Echo's own JWT middleware
+// expects every key to use the same algorithm, which doesn't hold up while rotating between key types.
+func newJWTMiddleware(keys *JWTKeys) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get(echo.HeaderAuthorization)
+			if !strings.HasPrefix(auth, bearerPrefix) {
+				return middleware.ErrJWTMissing
+			}
+
+			token, err := jwt.ParseWithClaims(strings.TrimPrefix(auth, bearerPrefix), &jwtClaims{}, keys.keyFunc)
+			if err != nil || !token.Valid {
+				return &echo.HTTPError{
+					Code:     http.StatusUnauthorized,
+					Message:  "invalid or expired jwt",
+					Internal: err,
+				}
+			}
+
+			c.Set(middleware.DefaultJWTConfig.ContextKey, token)
+			return next(c)
+		}
+	}
+}
+
+// newDeadlineMiddleware cancels queries that are still running once the request has taken too long,
+// so slow queries don't keep hogging connections after the client has given up
+func newDeadlineMiddleware(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx, cancel := gocontext.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
 	}
-	return middleware.JWTWithConfig(cfg)
 }
 
 var errorCodeRegularExpression = regexp.MustCompile("^code=([0-9]{3}).")
 
+// errorHandler responds with a problem for every error, only unknown errors are reported as they point to a bug.
+// Errors end up in the access log, together with the request they belong to.
 func errorHandler(errorReporter usecases.ErrorReporter) func(error, echo.Context) {
 	return func(err error, c echo.Context) {
-		c.Logger().Error(err)
+		if p, ok := services.ProblemFor(err); ok {
+			writeProblem(c, p)
+			return
+		}
 
 		if err == middleware.ErrJWTMissing {
-			c.NoContent(http.StatusUnauthorized)
+			writeProblem(c, services.NewProblem(http.StatusUnauthorized, err))
 			return
 		}
 
 		if match := errorCodeRegularExpression.FindStringSubmatch(err.Error()); len(match) > 1 {
 			if statusCode, errInt := strconv.Atoi(match[1]); errInt == nil {
-				c.NoContent(statusCode)
+				writeProblem(c, services.NewProblem(statusCode, err))
 				return
 			}
 		}
 
-		errorReporter.Capture(err)
-		c.NoContent(http.StatusInternalServerError)
+		errorReporter.Capture(err, requestID(c))
+		writeProblem(c, services.NewProblem(http.StatusInternalServerError, err))
+	}
+}
+
+// writeProblem leaves responses alone that handlers have already written before returning their error
+func writeProblem(c echo.Context, p services.Problem) {
+	if c.Response().Committed {
+		return
 	}
+
+	services.WriteProblem(&context{c}, p)
 }
 
 func (m *middlewares) authenticateRole(c echo.Context, minRole domain.Role) error {
 	u, err := (&context{c}).User()
 	if err == ErrEmptyUser && minRole != domain.RoleGuest {
-		return c.NoContent(http.StatusUnauthorized)
+		return echo.ErrUnauthorized
 	}
 	err = m.authenticator.IsAllowed(u, minRole)
 
 	if err != nil {
-		return c.NoContent(http.StatusForbidden)
+		return echo.ErrForbidden
 	}
 
 	return nil
 }
 
+// verifySession rejects tokens that are still signed correctly but belong to a session that has since been revoked,
+// or that lack two factor authentication while the route requires it.
+// The user in the token is replaced with the current one, so role changes take effect immediately.
+func (m *middlewares) verifySession(minRole domain.Role, next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		claims := (&context{c}).Claims()
+		if claims == nil {
+			return next(c)
+		}
+
+		// Revoked sessions, missing two factor authentication and disabled users are turned into problems by the error handler
+		user, err := m.sessions.VerifySession(c.Request().Context(), claims.SessionID, minRole)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		(&context{c}).setUser(&user)
+		return next(c)
+	}
+}
+
+// authenticatePersonalAccessToken lets scripts in with a personal access token, as long as it has been granted the scope of the route
+func (m *middlewares) authenticatePersonalAccessToken(scope domain.Scope, next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), bearerPrefix)
+
+		user, scopes, err := m.personalAccessTokens.Authenticate(c.Request().Context(), token)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		if scope == "" || !scopes.Includes(scope) {
+			return echo.ErrForbidden
+		}
+
+		c.Set(personalAccessTokenClaimsKey, &usecases.SessionClaims{User: &user})
+		return next(c)
+	}
+}
+
+func hasPersonalAccessToken(c echo.Context) bool {
+	return strings.HasPrefix(c.Request().Header.Get(echo.HeaderAuthorization), bearerPrefix+domain.PersonalAccessTokenPrefix)
+}
+
 func wrap(r services.Route, m *middlewares) echo.HandlerFunc {
 	handler := func(c echo.Context) error {
 		err := m.authenticateRole(c, r.MinRole)
@@ -112,17 +258,57 @@ func wrap(r services.Route, m *middlewares) echo.HandlerFunc {
 	}
 
 	if r.MinRole > domain.RoleGuest {
-		handler = m.restrict(handler)
+		withSession := m.restrict(m.verifySession(r.MinRole, handler))
+		withPersonalAccessToken := m.authenticatePersonalAccessToken(r.Scope, handler)
+
+		handler = func(c echo.Context) error {
+			if hasPersonalAccessToken(c) {
+				return withPersonalAccessToken(c)
+			}
+
+			return withSession(c)
+		}
+	} else {
+		handler = m.identify(handler)
 	}
 
 	return handler
 }
 
+// identify lets public routes know who is asking when the request comes with a valid session,
+// anyone else is still let through as a guest
+func (m *middlewares) identify(next echo.HandlerFunc) echo.HandlerFunc {
+	verify := m.restrict(m.verifySession(domain.RoleGuest, func(echo.Context) error { return nil }))
+
+	return func(c echo.Context) error {
+		if !hasSessionToken(c) {
+			return next(c)
+		}
+
+		// A stale or revoked token shouldn't lock anyone out of a public page
+		if err := verify(c); err != nil {
+			c.Set(middleware.DefaultJWTConfig.ContextKey, nil)
+		}
+
+		return next(c)
+	}
+}
+
+func hasSessionToken(c echo.Context) bool {
+	return strings.HasPrefix(c.Request().Header.Get(echo.HeaderAuthorization), bearerPrefix) && !hasPersonalAccessToken(c)
+}
+
 type router struct {
 	*echo.Echo
 	port string
 }
 
 func (r router) StartListening() error {
-	return r.Start(":" + r.port)
+	err := r.Start(":" + r.port)
+	if err == http.ErrServerClosed {
+		// Not a failure, the router has been shut down
+		return nil
+	}
+
+	return err
 }
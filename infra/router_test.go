@@ -1,11 +1,17 @@
 package infra_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
@@ -16,23 +22,35 @@ import (
 )
 
 func TestRouter_RestrictedRoute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
 	handler := func(ctx services.Context) error {
 		return ctx.String(200, "test")
 	}
-	secret := "foobar"
+	keys := infra.NewSecretJWTKeys("foobar")
 	routes := []services.Route{
 		{Method: http.MethodGet, Path: "/unrestricted", HandlerFunc: handler},
 		{Method: http.MethodGet, Path: "/restricted", HandlerFunc: handler, MinRole: domain.RoleUser},
 		{Method: http.MethodGet, Path: "/registered_only", HandlerFunc: handler, MinRole: domain.RoleUser},
 		{Method: http.MethodGet, Path: "/admin", HandlerFunc: handler, MinRole: domain.RoleAdmin},
 	}
-	e := infra.NewRouter("1337", secret, nil, nil, routes...)
-	gen := infra.NewJWTGenerator(secret)
+	sessions := usecases.NewMockSessionInteractor(ctrl)
+	sessions.EXPECT().VerifySession(gomock.Any(), uint64(0), gomock.Any()).Return(domain.User{}, usecases.ErrSessionRevoked).AnyTimes()
+	sessions.EXPECT().VerifySession(gomock.Any(), uint64(1), gomock.Any()).Return(domain.User{Role: domain.RoleUser}, nil).AnyTimes()
+	sessions.EXPECT().VerifySession(gomock.Any(), uint64(2), gomock.Any()).Return(domain.User{}, usecases.ErrSessionRevoked).AnyTimes()
+	sessions.EXPECT().VerifySession(gomock.Any(), uint64(3), domain.RoleAdmin).Return(domain.User{}, usecases.ErrTwoFactorRequired).AnyTimes()
+	sessions.EXPECT().VerifySession(gomock.Any(), uint64(4), gomock.Any()).Return(domain.User{Role: domain.RoleAdmin}, nil).AnyTimes()
+	sessions.EXPECT().VerifySession(gomock.Any(), uint64(5), gomock.Any()).Return(domain.User{}, usecases.ErrUserDisabled).AnyTimes()
+
+	e := infra.NewRouter("1337", keys, nil, nil, nil, nil, nil, 0, sessions, nil, routes...)
+	gen := infra.NewJWTGenerator(keys)
 
 	for _, tc := range []struct {
 		path          string
 		expStatusCode int
 		user          *domain.User
+		sessionID     uint64
 		info          string
 	}{
 		{
@@ -49,16 +67,46 @@ func TestRouter_RestrictedRoute(t *testing.T) {
 			path:          "/admin",
 			expStatusCode: http.StatusForbidden,
 			user:          &domain.User{Role: domain.RoleUser},
+			sessionID:     1,
 			info:          "No admin access as user",
 		},
 		{
 			path:          "/admin",
 			expStatusCode: http.StatusOK,
 			user:          &domain.User{Role: domain.RoleAdmin},
+			sessionID:     4,
 			info:          "Admin access as admin",
 		},
+		{
+			path:          "/admin",
+			expStatusCode: http.StatusForbidden,
+			user:          &domain.User{Role: domain.RoleAdmin},
+			sessionID:     1,
+			info:          "No admin access after being demoted",
+		},
+		{
+			path:          "/restricted",
+			expStatusCode: http.StatusForbidden,
+			user:          &domain.User{Role: domain.RoleUser},
+			sessionID:     5,
+			info:          "No access after being disabled",
+		},
+		{
+			path:          "/restricted",
+			expStatusCode: http.StatusUnauthorized,
+			user:          &domain.User{Role: domain.RoleUser},
+			sessionID:     2,
+			info:          "No access with a revoked session",
+		},
+		{
+			path:          "/admin",
+			expStatusCode: http.StatusForbidden,
+			user:          &domain.User{Role: domain.RoleAdmin},
+			sessionID:     3,
+			info:          "No admin access without two factor authentication",
+		},
 	} {
-		token, _ := gen.NewToken(time.Hour*1, usecases.SessionClaims{User: tc.user})
+		token, _ := gen.NewToken(time.Hour*1, usecases.SessionClaims{User: tc.user, SessionID: tc.sessionID})
 		authHeader := middleware.DefaultJWTConfig.AuthScheme + " " + token
 
 		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
@@ -71,3 +119,331 @@ func TestRouter_RestrictedRoute(t *testing.T) {
 		assert.Equal(t, tc.expStatusCode, res.Code, tc.info)
 	}
 }
+
+func TestRouter_PublicRouteWithSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := func(ctx services.Context) error {
+		user, err := ctx.User()
+		if err != nil {
+			return ctx.String(200, "guest")
+		}
+		return ctx.String(200, user.DisplayName)
+	}
+	keys := infra.NewSecretJWTKeys("foobar")
+	routes := []services.Route{
+		{Method: http.MethodGet, Path: "/rankings", HandlerFunc: handler},
+	}
+	sessions := usecases.NewMockSessionInteractor(ctrl)
+	sessions.EXPECT().VerifySession(gomock.Any(), uint64(1), domain.RoleGuest).Return(domain.User{ID: 1, DisplayName: "current", Role: domain.RoleUser}, nil).AnyTimes()
+	sessions.EXPECT().VerifySession(gomock.Any(), uint64(2), domain.RoleGuest).Return(domain.User{}, usecases.ErrSessionRevoked).AnyTimes()
+
+	e := infra.NewRouter("1337", keys, nil, nil, nil, nil, nil, 0, sessions, nil, routes...)
+	gen := infra.NewJWTGenerator(keys)
+
+	for _, tc := range []struct {
+		authHeader string
+		sessionID  uint64
+		expBody    string
+		info       string
+	}{
+		{
+			expBody: "guest",
+			info:    "Guests can see public routes",
+		},
+		{
+			sessionID: 1,
+			expBody:   "current",
+			info:      "Public routes know who is asking with a valid session",
+		},
+		{
+			sessionID: 2,
+			expBody:   "guest",
+			info:      "A revoked session is treated like a guest",
+		},
+		{
+			authHeader: middleware.DefaultJWTConfig.AuthScheme + " foobar",
+			expBody:    "guest",
+			info:       "An invalid token is treated like a guest",
+		},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/rankings", nil)
+		if tc.sessionID != 0 {
+			token, _ := gen.NewToken(time.Hour*1, usecases.SessionClaims{User: &domain.User{ID: 1, DisplayName: "stale"}, SessionID: tc.sessionID})
+			tc.authHeader = middleware.DefaultJWTConfig.AuthScheme + " " + token
+		}
+		if tc.authHeader != "" {
+			req.Header.Set(echo.HeaderAuthorization, tc.authHeader)
+		}
+
+		res := httptest.NewRecorder()
+
+		e.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code, tc.info)
+		assert.Equal(t, tc.expBody, res.Body.String(), tc.info)
+	}
+}
+
+func TestRouter_PersonalAccessToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := func(ctx services.Context) error {
+		user, err := ctx.User()
+		if err != nil {
+			return err
+		}
+		return ctx.String(200, user.DisplayName)
+	}
+	routes := []services.Route{
+		{Method: http.MethodPost, Path: "/contest_logs", HandlerFunc: handler, MinRole: domain.RoleUser, Scope: domain.ScopeLogsWrite},
+		{Method: http.MethodGet, Path: "/rankings/current", HandlerFunc: handler, MinRole: domain.RoleUser, Scope: domain.ScopeRankingsRead},
+		{Method: http.MethodGet, Path: "/users/tokens", HandlerFunc: handler, MinRole: domain.RoleUser},
+	}
+
+	user := domain.User{ID: 1, DisplayName: "foo", Role: domain.RoleUser}
+	tokens := usecases.NewMockPersonalAccessTokenInteractor(ctrl)
+	tokens.EXPECT().Authenticate(gomock.Any(), "tdk_foobar").Return(user, domain.Scopes{domain.ScopeLogsWrite}, nil).AnyTimes()
+	tokens.EXPECT().Authenticate(gomock.Any(), "tdk_revoked").Return(domain.User{}, nil, usecases.ErrPersonalAccessTokenRejected).AnyTimes()
+
+	e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, nil, nil, nil, 0, nil, tokens, routes...)
+
+	for _, tc := range []struct {
+		method        string
+		path          string
+		token         string
+		expStatusCode int
+		info          string
+	}{
+		{
+			method:        http.MethodPost,
+			path:          "/contest_logs",
+			token:         "tdk_foobar",
+			expStatusCode: http.StatusOK,
+			info:          "Access with a granted scope",
+		},
+		{
+			method:        http.MethodGet,
+			path:          "/rankings/current",
+			token:         "tdk_foobar",
+			expStatusCode: http.StatusForbidden,
+			info:          "No access without the scope of the route",
+		},
+		{
+			method:        http.MethodGet,
+			path:          "/users/tokens",
+			token:         "tdk_foobar",
+			expStatusCode: http.StatusForbidden,
+			info:          "No access to routes that only accept sessions",
+		},
+		{
+			method:        http.MethodPost,
+			path:          "/contest_logs",
+			token:         "tdk_revoked",
+			expStatusCode: http.StatusUnauthorized,
+			info:          "No access with a revoked token",
+		},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set(echo.HeaderAuthorization, middleware.DefaultJWTConfig.AuthScheme+" "+tc.token)
+
+		res := httptest.NewRecorder()
+
+		e.ServeHTTP(res, req)
+
+		assert.Equal(t, tc.expStatusCode, res.Code, tc.info)
+		if tc.expStatusCode == http.StatusOK {
+			assert.Equal(t, user.DisplayName, res.Body.String(), tc.info)
+		}
+	}
+}
+
+func TestRouter_Problems(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	errorReporter := usecases.NewMockErrorReporter(ctrl)
+	errorReporter.EXPECT().Capture(gomock.Any(), gomock.Not("")).Times(1)
+
+	routes := []services.Route{
+		{Method: http.MethodGet, Path: "/closed", HandlerFunc: func(ctx services.Context) error {
+			return domain.WrapError(usecases.ErrContestIsClosed)
+		}},
+		{Method: http.MethodGet, Path: "/broken", HandlerFunc: func(ctx services.Context) error {
+			return domain.WrapError(errors.New("connection refused"))
+		}},
+	}
+	e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, errorReporter, nil, nil, 0, nil, nil, routes...)
+
+	for _, tc := range []struct {
+		path    string
+		problem services.Problem
+		info    string
+	}{
+		{
+			path: "/closed",
+			problem: services.Problem{
+				Type:   "about:blank",
+				Title:  "Unprocessable Entity",
+				Status: http.StatusUnprocessableEntity,
+				Detail: "the given contest is closed",
+				Code:   "contest_closed",
+			},
+			info: "Known errors are explained",
+		},
+		{
+			path: "/broken",
+			problem: services.Problem{
+				Type:   "about:blank",
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+				Code:   "internal_server_error",
+			},
+			info: "Unknown errors are reported without giving details away",
+		},
+		{
+			path: "/missing",
+			problem: services.Problem{
+				Type:   "about:blank",
+				Title:  "Not Found",
+				Status: http.StatusNotFound,
+				Code:   "not_found",
+			},
+			info: "Errors from the router itself are problems as well",
+		},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		res := httptest.NewRecorder()
+
+		e.ServeHTTP(res, req)
+
+		var p services.Problem
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &p), tc.info)
+		assert.Equal(t, tc.problem, p, tc.info)
+		assert.Equal(t, tc.problem.Status, res.Code, tc.info)
+		assert.Equal(t, services.ProblemContentType, res.Header().Get(echo.HeaderContentType), tc.info)
+	}
+}
+
+func TestRouter_Shutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	port := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	assert.NoError(t, listener.Close())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := func(ctx services.Context) error {
+		close(started)
+		<-release
+		return ctx.String(200, "done")
+	}
+	r := infra.NewRouter(port, infra.NewSecretJWTKeys("foobar"), nil, nil, nil, nil, nil, 0, nil, nil, services.Route{Method: http.MethodGet, Path: "/slow", HandlerFunc: handler})
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- r.StartListening()
+	}()
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		for {
+			res, err := http.Get("http://localhost:" + port + "/slow")
+			if err == nil {
+				responses <- res
+				return
+			}
+			// The server might not be listening yet
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- r.Shutdown(context.Background())
+	}()
+
+	select {
+	case <-shutdown:
+		t.Fatal("shutdown didn't wait for the request in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	res := <-responses
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	assert.NoError(t, <-shutdown)
+	assert.NoError(t, <-stopped, "shutting down isn't a failure")
+}
+
+func TestRouter_DatabaseDeadline(t *testing.T) {
+	var deadline time.Time
+	var ok bool
+	routes := []services.Route{
+		{Method: http.MethodGet, Path: "/deadline", HandlerFunc: func(ctx services.Context) error {
+			deadline, ok = ctx.RequestContext().Deadline()
+			return ctx.NoContent(http.StatusNoContent)
+		}},
+	}
+
+	{
+		// Happy path: requests get a deadline
+		e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, nil, nil, nil, time.Minute, nil, nil, routes...)
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/deadline", nil))
+
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	}
+
+	{
+		// Happy path: no deadline when it's not configured
+		e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, nil, nil, nil, 0, nil, nil, routes...)
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/deadline", nil))
+
+		assert.False(t, ok)
+	}
+}
+
+func TestRouter_RealIP(t *testing.T) {
+	var ip string
+	routes := []services.Route{
+		{Method: http.MethodGet, Path: "/ip", HandlerFunc: func(ctx services.Context) error {
+			ip = ctx.RealIP()
+			return ctx.NoContent(http.StatusNoContent)
+		}},
+	}
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+
+	request := func(remoteAddr string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+		return req
+	}
+
+	{
+		// Happy path: the address a trusted proxy forwarded
+		e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, []*net.IPNet{proxies}, nil, nil, nil, 0, nil, nil, routes...)
+		e.ServeHTTP(httptest.NewRecorder(), request("10.1.2.3:1234"))
+		assert.Equal(t, "203.0.113.7", ip)
+	}
+
+	{
+		// Sad path: anyone else can't spoof their address
+		e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, []*net.IPNet{proxies}, nil, nil, nil, 0, nil, nil, routes...)
+		e.ServeHTTP(httptest.NewRecorder(), request("192.0.2.1:1234"))
+		assert.Equal(t, "192.0.2.1", ip)
+	}
+
+	{
+		// Sad path: without trusted proxies the header is ignored
+		e := infra.NewRouter("1337", infra.NewSecretJWTKeys("foobar"), nil, nil, nil, nil, nil, 0, nil, nil, routes...)
+		e.ServeHTTP(httptest.NewRecorder(), request("10.1.2.3:1234"))
+		assert.Equal(t, "10.1.2.3", ip)
+	}
+}
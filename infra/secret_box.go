@@ -0,0 +1,79 @@
+package infra
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"github.com/srvc/fail"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// sealedPrefix marks secrets that have been sealed, and leaves room for another format when the cipher changes
+const sealedPrefix = "v1:"
+
+// ErrSecretKeyInvalid for when the key for sealing secrets isn't 32 bytes encoded as base64
+var ErrSecretKeyInvalid = fail.New("secret key has to be 32 bytes encoded as base64")
+
+// ErrSealedSecretInvalid for when a sealed secret has been tampered with or was sealed with another key
+var ErrSealedSecretInvalid = fail.New("sealed secret could not be opened")
+
+// NewSecretBox initializes a SecretBox that seals secrets with AES-256-GCM
+func NewSecretBox(key string) (usecases.SecretBox, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != 32 {
+		return nil, ErrSecretKeyInvalid
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return &secretBox{aead: aead}, nil
+}
+
+type secretBox struct {
+	aead cipher.AEAD
+}
+
+func (b *secretBox) Seal(secret string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", domain.WrapError(err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return sealedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (b *secretBox) Open(sealed string) (string, error) {
+	if !b.IsSealed(sealed) {
+		return "", ErrSealedSecretInvalid
+	}
+
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrSealedSecretInvalid
+	}
+
+	nonce, ciphertext := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	secret, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrSealedSecretInvalid
+	}
+
+	return string(secret), nil
+}
+
+func (b *secretBox) IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
@@ -0,0 +1,58 @@
+package infra_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/infra"
+)
+
+const secretKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestSecretBox(t *testing.T) {
+	box, err := infra.NewSecretBox(secretKey)
+	assert.NoError(t, err)
+
+	{
+		// Happy path: sealed secrets can be opened again, without giving them away
+		sealed, err := box.Seal("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+		assert.NoError(t, err)
+		assert.True(t, box.IsSealed(sealed))
+		assert.NotContains(t, sealed, "GEZDGNBVGY3TQOJQ")
+		assert.LessOrEqual(t, len(sealed), 255)
+
+		secret, err := box.Open(sealed)
+		assert.NoError(t, err)
+		assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", secret)
+
+		again, err := box.Seal("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+		assert.NoError(t, err)
+		assert.NotEqual(t, sealed, again)
+	}
+
+	{
+		// Sad path: secrets from before they were sealed
+		assert.False(t, box.IsSealed("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"))
+		_, err := box.Open("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+		assert.EqualError(t, err, infra.ErrSealedSecretInvalid.Error())
+	}
+
+	{
+		// Sad path: secrets sealed with another key
+		other, err := infra.NewSecretBox("ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=")
+		assert.NoError(t, err)
+		sealed, err := other.Seal("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+		assert.NoError(t, err)
+
+		_, err = box.Open(sealed)
+		assert.EqualError(t, err, infra.ErrSealedSecretInvalid.Error())
+	}
+
+	{
+		// Sad path: keys have to be 32 bytes
+		_, err := infra.NewSecretBox("c2hvcnQ=")
+		assert.EqualError(t, err, infra.ErrSecretKeyInvalid.Error())
+		_, err = infra.NewSecretBox("")
+		assert.EqualError(t, err, infra.ErrSecretKeyInvalid.Error())
+	}
+}
@@ -1,11 +1,11 @@
 package infra
 
 import (
+	gocontext "context"
 	"database/sql"
 
 	"github.com/jmoiron/sqlx"
-	// Postgres driver that's used to connect to the db
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 
 	"github.com/tadoku/api/domain"
 	"github.com/tadoku/api/interfaces/rdb"
@@ -24,53 +24,81 @@ type sqlHandler struct {
 	db *RDB
 }
 
-func (handler *sqlHandler) Execute(statement string, args ...interface{}) (rdb.Result, error) {
+func (handler *sqlHandler) Execute(ctx gocontext.Context, statement string, args ...interface{}) (rdb.Result, error) {
+	if tx, ok := rdb.TransactionFromContext(ctx); ok {
+		return tx.Execute(ctx, statement, args...)
+	}
+
 	res := sqlResult{}
-	result, err := handler.db.Exec(statement, args...)
+	result, err := handler.db.ExecContext(ctx, statement, args...)
 	if err != nil {
-		return res, domain.WrapError(err)
+		return res, domain.WrapError(translateError(ctx, err))
 	}
 	res.Result = result
 
 	return res, nil
 }
 
-func (handler *sqlHandler) NamedExecute(statement string, arg interface{}) (rdb.Result, error) {
+func (handler *sqlHandler) NamedExecute(ctx gocontext.Context, statement string, arg interface{}) (rdb.Result, error) {
+	if tx, ok := rdb.TransactionFromContext(ctx); ok {
+		return tx.NamedExecute(ctx, statement, arg)
+	}
+
 	res := sqlResult{}
-	result, err := handler.db.NamedExec(statement, arg)
+	result, err := handler.db.NamedExecContext(ctx, statement, arg)
 	if err != nil {
-		return res, domain.WrapError(err)
+		return res, domain.WrapError(translateError(ctx, err))
 	}
 	res.Result = result
 
 	return res, nil
 }
 
-func (handler *sqlHandler) Query(statement string, args ...interface{}) (rdb.Rows, error) {
+func (handler *sqlHandler) Query(ctx gocontext.Context, statement string, args ...interface{}) (rdb.Rows, error) {
+	if tx, ok := rdb.TransactionFromContext(ctx); ok {
+		return tx.Query(ctx, statement, args...)
+	}
+
 	row := new(sqlRows)
-	rows, err := handler.db.Queryx(statement, args...)
+	rows, err := handler.db.QueryxContext(ctx, statement, args...)
 	if err != nil {
-		return row, domain.WrapError(err)
+		return row, domain.WrapError(translateError(ctx, err))
 	}
 	row.Rows = rows
 
 	return row, nil
 }
 
-func (handler *sqlHandler) QueryRow(statement string, args ...interface{}) rdb.Row {
-	return sqlRow{Row: handler.db.QueryRowx(statement, args...)}
+func (handler *sqlHandler) QueryRow(ctx gocontext.Context, statement string, args ...interface{}) rdb.Row {
+	if tx, ok := rdb.TransactionFromContext(ctx); ok {
+		return tx.QueryRow(ctx, statement, args...)
+	}
+
+	return sqlRow{Row: handler.db.QueryRowxContext(ctx, statement, args...)}
 }
 
-func (handler *sqlHandler) Get(dest interface{}, query string, args ...interface{}) error {
-	return handler.db.Get(dest, query, args...)
+func (handler *sqlHandler) Get(ctx gocontext.Context, dest interface{}, query string, args ...interface{}) error {
+	if tx, ok := rdb.TransactionFromContext(ctx); ok {
+		return tx.Get(ctx, dest, query, args...)
+	}
+
+	return translateError(ctx, handler.db.GetContext(ctx, dest, query, args...))
 }
 
-func (handler *sqlHandler) Select(dest interface{}, query string, args ...interface{}) error {
-	return handler.db.Select(dest, query, args...)
+func (handler *sqlHandler) Select(ctx gocontext.Context, dest interface{}, query string, args ...interface{}) error {
+	if tx, ok := rdb.TransactionFromContext(ctx); ok {
+		return tx.Select(ctx, dest, query, args...)
+	}
+
+	return translateError(ctx, handler.db.SelectContext(ctx, dest, query, args...))
 }
 
-func (handler *sqlHandler) Begin() (rdb.TxHandler, error) {
-	tx, err := handler.db.Beginx()
+func (handler *sqlHandler) Begin(ctx gocontext.Context) (rdb.TxHandler, error) {
+	if tx, ok := rdb.TransactionFromContext(ctx); ok {
+		return joinedTxHandler{tx}, nil
+	}
+
+	tx, err := handler.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return nil, domain.WrapError(err)
 	}
@@ -78,6 +106,21 @@ func (handler *sqlHandler) Begin() (rdb.TxHandler, error) {
 	return &txHandler{tx: tx}, nil
 }
 
+// uniqueViolation is the postgres error code for when a unique constraint would be violated
+const uniqueViolation = "23505"
+
+// translateError turns driver specific errors into domain errors where the repositories care about them
+func translateError(ctx gocontext.Context, err error) error {
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		return domain.ErrAlreadyExists
+	}
+	if err != nil && ctx.Err() == gocontext.DeadlineExceeded {
+		return domain.ErrDeadlineExceeded
+	}
+
+	return err
+}
+
 // -----------------------------------------------------------------
 // Transaction - Tx
 // -----------------------------------------------------------------
@@ -85,49 +128,49 @@ type txHandler struct {
 	tx *sqlx.Tx
 }
 
-func (handler *txHandler) Execute(statement string, args ...interface{}) (rdb.Result, error) {
+func (handler *txHandler) Execute(ctx gocontext.Context, statement string, args ...interface{}) (rdb.Result, error) {
 	res := sqlResult{}
-	result, err := handler.tx.Exec(statement, args...)
+	result, err := handler.tx.ExecContext(ctx, statement, args...)
 	if err != nil {
-		return res, domain.WrapError(err)
+		return res, domain.WrapError(translateError(ctx, err))
 	}
 	res.Result = result
 
 	return res, nil
 }
 
-func (handler *txHandler) NamedExecute(statement string, arg interface{}) (rdb.Result, error) {
+func (handler *txHandler) NamedExecute(ctx gocontext.Context, statement string, arg interface{}) (rdb.Result, error) {
 	res := sqlResult{}
-	result, err := handler.tx.NamedExec(statement, arg)
+	result, err := handler.tx.NamedExecContext(ctx, statement, arg)
 	if err != nil {
-		return res, domain.WrapError(err)
+		return res, domain.WrapError(translateError(ctx, err))
 	}
 	res.Result = result
 
 	return res, nil
 }
 
-func (handler *txHandler) Query(statement string, args ...interface{}) (rdb.Rows, error) {
+func (handler *txHandler) Query(ctx gocontext.Context, statement string, args ...interface{}) (rdb.Rows, error) {
 	row := new(sqlRows)
-	rows, err := handler.tx.Queryx(statement, args...)
+	rows, err := handler.tx.QueryxContext(ctx, statement, args...)
 	if err != nil {
-		return row, domain.WrapError(err)
+		return row, domain.WrapError(translateError(ctx, err))
 	}
 	row.Rows = rows
 
 	return row, nil
 }
 
-func (handler *txHandler) QueryRow(statement string, args ...interface{}) rdb.Row {
-	return sqlRow{Row: handler.tx.QueryRowx(statement, args...)}
+func (handler *txHandler) QueryRow(ctx gocontext.Context, statement string, args ...interface{}) rdb.Row {
+	return sqlRow{Row: handler.tx.QueryRowxContext(ctx, statement, args...)}
 }
 
-func (handler *txHandler) Get(dest interface{}, query string, args ...interface{}) error {
-	return handler.Get(dest, query, args...)
+func (handler *txHandler) Get(ctx gocontext.Context, dest interface{}, query string, args ...interface{}) error {
+	return translateError(ctx, handler.tx.GetContext(ctx, dest, query, args...))
 }
 
-func (handler *txHandler) Select(dest interface{}, query string, args ...interface{}) error {
-	return handler.Select(dest, query, args...)
+func (handler *txHandler) Select(ctx gocontext.Context, dest interface{}, query string, args ...interface{}) error {
+	return translateError(ctx, handler.tx.SelectContext(ctx, dest, query, args...))
 }
 
 func (handler *txHandler) Commit() error {
@@ -148,6 +191,20 @@ func (handler *txHandler) Rollback() error {
 	return nil
 }
 
+// joinedTxHandler is handed out when a transaction is started inside a unit of work,
+// the transaction is only committed or rolled back once the unit of work is done
+type joinedTxHandler struct {
+	rdb.TxHandler
+}
+
+func (handler joinedTxHandler) Commit() error {
+	return nil
+}
+
+func (handler joinedTxHandler) Rollback() error {
+	return nil
+}
+
 // -----------------------------------------------------------------
 // Result
 // -----------------------------------------------------------------
@@ -0,0 +1,72 @@
+package infra_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/infra"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/interfaces/services"
+)
+
+func TestSQLHandler_DeadlineExceeded(t *testing.T) {
+	db, err := infra.NewRDB("postgres://localhost:1/tadoku?sslmode=disable", 1, 1)
+	assert.NoError(t, err)
+	handler := infra.NewSQLHandler(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	var count int
+	assert.Equal(t, domain.ErrDeadlineExceeded, handler.Get(ctx, &count, "select 1"))
+	assert.Equal(t, domain.ErrDeadlineExceeded, handler.Select(ctx, &[]int{}, "select 1"))
+
+	_, err = handler.Execute(ctx, "select 1")
+	p, ok := services.ProblemFor(err)
+	assert.True(t, ok, "Timeouts aren't reported as bugs")
+	assert.Equal(t, http.StatusServiceUnavailable, p.Status)
+}
+
+// recordingTxHandler remembers what's been run in the transaction
+type recordingTxHandler struct {
+	rdb.TxHandler
+	statements []string
+	committed  bool
+}
+
+func (tx *recordingTxHandler) Execute(ctx context.Context, statement string, args ...interface{}) (rdb.Result, error) {
+	tx.statements = append(tx.statements, statement)
+	return nil, nil
+}
+
+func (tx *recordingTxHandler) Commit() error {
+	tx.committed = true
+	return nil
+}
+
+func TestSQLHandler_JoinsTransaction(t *testing.T) {
+	db, err := infra.NewRDB("postgres://localhost:1/tadoku?sslmode=disable", 1, 1)
+	assert.NoError(t, err)
+	handler := infra.NewSQLHandler(db)
+
+	tx := &recordingTxHandler{}
+	ctx := rdb.WithTransaction(context.Background(), tx)
+
+	_, err = handler.Execute(ctx, "delete from rankings")
+	assert.NoError(t, err, "Queries go through the running transaction instead of a new connection")
+
+	nested, err := handler.Begin(ctx)
+	assert.NoError(t, err)
+	_, err = nested.Execute(ctx, "delete from contest_logs")
+	assert.NoError(t, err)
+	assert.NoError(t, nested.Commit())
+
+	assert.Equal(t, []string{"delete from rankings", "delete from contest_logs"}, tx.statements)
+	assert.False(t, tx.committed, "Only the unit of work commits")
+}
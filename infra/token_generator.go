@@ -0,0 +1,27 @@
+package infra
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewTokenGenerator initializes a generator for random tokens of the given amount of bytes
+func NewTokenGenerator(size int) usecases.TokenGenerator {
+	return &tokenGenerator{size: size}
+}
+
+type tokenGenerator struct {
+	size int
+}
+
+func (g *tokenGenerator) Generate() (string, error) {
+	b := make([]byte, g.size)
+	if _, err := rand.Read(b); err != nil {
+		return "", domain.WrapError(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,90 @@
+package infra
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// These are the defaults from RFC 6238, which are the only settings every authenticator app supports
+const (
+	totpSecretSize = 20
+	totpPeriod     = 30
+	totpDigits     = 6
+	totpSkew       = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPAuthenticator initializes a TOTPAuthenticator, the issuer is shown in authenticator apps
+func NewTOTPAuthenticator(issuer string) usecases.TOTPAuthenticator {
+	return &totpAuthenticator{issuer: issuer}
+}
+
+type totpAuthenticator struct {
+	issuer string
+}
+
+func (a *totpAuthenticator) NewSecret() (string, error) {
+	b := make([]byte, totpSecretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", domain.WrapError(err)
+	}
+
+	return totpEncoding.EncodeToString(b), nil
+}
+
+func (a *totpAuthenticator) URI(secret string, accountName string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", a.issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(a.issuer + ":" + accountName)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Verify accepts codes from one period before and after the current one, to account for clock drift
+func (a *totpAuthenticator) Verify(secret string, code string, now time.Time) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// totpCode calculates the code for a given time step as described in RFC 4226
+func totpCode(key []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%uint32(math.Pow10(totpDigits)))
+}
@@ -0,0 +1,54 @@
+package infra_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/infra"
+)
+
+// Test vectors from RFC 6238, truncated to 6 digits
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPAuthenticator_Verify(t *testing.T) {
+	a := infra.NewTOTPAuthenticator("Tadoku")
+
+	var tests = []struct {
+		now   time.Time
+		code  string
+		step  int64
+		valid bool
+	}{
+		{time.Unix(59, 0), "287082", 1, true},
+		{time.Unix(1111111109, 0), "081804", 37037036, true},
+		{time.Unix(1234567890, 0), "005924", 41152263, true},
+		{time.Unix(1234567890+30, 0), "005924", 41152263, true},
+		{time.Unix(1234567890+90, 0), "005924", 0, false},
+		{time.Unix(1234567890, 0), "005925", 0, false},
+		{time.Unix(1234567890, 0), "5924", 0, false},
+	}
+
+	for _, test := range tests {
+		step, valid := a.Verify(rfcTOTPSecret, test.code, test.now)
+		assert.Equal(t, test.valid, valid, "expected code %v to be valid at %v: %v", test.code, test.now, test.valid)
+		assert.Equal(t, test.step, step)
+	}
+}
+
+func TestTOTPAuthenticator_NewSecretAndURI(t *testing.T) {
+	a := infra.NewTOTPAuthenticator("Tadoku")
+
+	secret, err := a.NewSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	uri, err := url.Parse(a.URI(secret, "foo@bar.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Tadoku:foo@bar.com", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "Tadoku", uri.Query().Get("issuer"))
+}
@@ -1,8 +1,12 @@
 package infra
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/asaskevich/govalidator"
 
+	"github.com/tadoku/api/domain"
 	"github.com/tadoku/api/usecases"
 )
 
@@ -13,14 +17,59 @@ func NewValidator() usecases.Validator {
 
 type validator struct{}
 
-func (v *validator) ValidateStruct(s interface{}) (bool, error) {
-	return govalidator.ValidateStruct(s)
+func (v *validator) ValidateStruct(s interface{}) (bool, domain.FieldErrors) {
+	_, err := govalidator.ValidateStruct(s)
+	violations := fieldErrors(err)
+
+	return len(violations) == 0, violations
+}
+
+// Validate combines the checks of the domain with the ones from the struct tags
+func (v *validator) Validate(target usecases.Validatable) (bool, domain.FieldErrors) {
+	var violations domain.FieldErrors
+
+	if valid, err := target.Validate(); !valid {
+		violations = append(violations, fieldErrors(err)...)
+	}
+
+	// Values like language codes only know how to validate themselves
+	if isStruct(target) {
+		_, structViolations := v.ValidateStruct(target)
+		violations = append(violations, structViolations...)
+	}
+
+	return len(violations) == 0, violations
 }
 
-func (v *validator) Validate(target usecases.Validatable) (bool, error) {
-	if result, err := target.Validate(); err != nil {
-		return result, err
+func isStruct(target interface{}) bool {
+	value := reflect.ValueOf(target)
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
 	}
 
-	return v.ValidateStruct(target)
+	return value.Kind() == reflect.Struct
+}
+
+// fieldErrors flattens the errors of both govalidator and the domain, errors that aren't tied to a field have an empty field
+func fieldErrors(err error) domain.FieldErrors {
+	switch err := err.(type) {
+	case nil:
+		return nil
+	case domain.FieldErrors:
+		return err
+	case govalidator.Errors:
+		var violations domain.FieldErrors
+		for _, e := range err {
+			violations = append(violations, fieldErrors(e)...)
+		}
+		return violations
+	case govalidator.Error:
+		return domain.FieldErrors{{
+			Field:   strings.Join(append(err.Path, err.Name), "."),
+			Rule:    err.Validator,
+			Message: err.Err.Error(),
+		}}
+	default:
+		return domain.FieldErrors{{Message: err.Error()}}
+	}
 }
@@ -0,0 +1,58 @@
+package infra_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/infra"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	v := infra.NewValidator()
+
+	{
+		// Happy path: valid contest
+		contest := domain.Contest{
+			Description: "Round 1",
+			Start:       time.Now(),
+			End:         time.Now().Add(24 * time.Hour),
+		}
+
+		valid, violations := v.Validate(contest)
+		assert.True(t, valid)
+		assert.Empty(t, violations)
+	}
+
+	{
+		// Sad path: every invalid field is reported
+		contest := domain.Contest{
+			Start: time.Now().Add(24 * time.Hour),
+			End:   time.Now(),
+		}
+
+		valid, violations := v.Validate(contest)
+		assert.False(t, valid)
+		assert.Equal(t, domain.FieldErrors{
+			domain.NewFieldError("end", "after_start", domain.ErrContestInvalidDateOrder),
+			domain.NewFieldError("end", "future", domain.ErrContestInvalidDateTooOld),
+			{Field: "description", Rule: "required", Message: "non zero value required"},
+		}, violations)
+	}
+
+	{
+		// Happy path: values that aren't structs only validate themselves
+		valid, violations := v.Validate(domain.Japanese)
+		assert.True(t, valid)
+		assert.Empty(t, violations)
+	}
+
+	{
+		// Sad path: errors that aren't tied to a field
+		valid, violations := v.Validate(domain.LanguageCode("foo"))
+		assert.False(t, valid)
+		assert.Equal(t, domain.FieldErrors{{Message: domain.ErrInvalidLanguage.Error()}}, violations)
+	}
+}
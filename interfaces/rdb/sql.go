@@ -1,16 +1,18 @@
 package rdb
 
+import "context"
+
 type sqlQueryHandler interface {
-	Query(string, ...interface{}) (Rows, error)
-	QueryRow(string, ...interface{}) Row
+	Query(context.Context, string, ...interface{}) (Rows, error)
+	QueryRow(context.Context, string, ...interface{}) Row
 
-	Get(dest interface{}, query string, args ...interface{}) error
-	Select(dest interface{}, query string, args ...interface{}) error
+	Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error
 }
 
 type sqlQueryExecuter interface {
-	Execute(string, ...interface{}) (Result, error)
-	NamedExecute(string, interface{}) (Result, error)
+	Execute(context.Context, string, ...interface{}) (Result, error)
+	NamedExecute(context.Context, string, interface{}) (Result, error)
 }
 
 // SQLHandler knows how to run queries against itself
@@ -18,7 +20,7 @@ type SQLHandler interface {
 	sqlQueryHandler
 	sqlQueryExecuter
 
-	Begin() (TxHandler, error)
+	Begin(ctx context.Context) (TxHandler, error)
 }
 
 // TxHandler is a SQLHandler in a transaction context
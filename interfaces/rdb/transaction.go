@@ -0,0 +1,16 @@
+package rdb
+
+import "context"
+
+type transactionKey struct{}
+
+// WithTransaction returns a context that makes every query that's run with it part of the transaction
+func WithTransaction(ctx context.Context, tx TxHandler) context.Context {
+	return context.WithValue(ctx, transactionKey{}, tx)
+}
+
+// TransactionFromContext returns the transaction that's running for the given context
+func TransactionFromContext(ctx context.Context) (TxHandler, bool) {
+	tx, ok := ctx.Value(transactionKey{}).(TxHandler)
+	return tx, ok
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewAuditEventRepository instantiates a new audit event repository
+func NewAuditEventRepository(sqlHandler rdb.SQLHandler) usecases.AuditEventRepository {
+	return &auditEventRepository{sqlHandler: sqlHandler}
+}
+
+type auditEventRepository struct {
+	sqlHandler rdb.SQLHandler
+}
+
+func (r *auditEventRepository) Store(ctx context.Context, event *domain.AuditEvent) error {
+	query := `
+		insert into audit_events
+		(actor_id, action, target_type, target_id, changes, ip_address, user_agent, created_at)
+		values ($1, $2, $3, $4, $5, $6, $7, now() at time zone 'utc')
+		returning id
+	`
+
+	row := r.sqlHandler.QueryRow(
+		ctx,
+		query,
+		event.ActorID,
+		event.Action,
+		event.TargetType,
+		event.TargetID,
+		event.Changes,
+		event.IPAddress,
+		event.UserAgent,
+	)
+	err := row.Scan(&event.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return nil
+}
+
+func (r *auditEventRepository) Find(ctx context.Context, filter domain.AuditEventFilter, limit, offset int) (domain.AuditEvents, error) {
+	var events []domain.AuditEvent
+
+	query := `
+		select id, actor_id, action, target_type, target_id, changes, ip_address, user_agent, created_at
+		from audit_events
+		where
+			($1 = 0 or actor_id = $1) and
+			($2 = '' or action = $2) and
+			($3 = '' or target_type = $3) and
+			($4 = 0 or target_id = $4)
+		order by id desc
+		limit $5
+		offset $6
+	`
+	err := r.sqlHandler.Select(
+		ctx,
+		&events,
+		query,
+		filter.ActorID,
+		filter.Action,
+		filter.TargetType,
+		filter.TargetID,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return events, nil
+}
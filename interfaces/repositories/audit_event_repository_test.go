@@ -0,0 +1,73 @@
+package repositories_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestAuditEventRepository_StoreAndFind(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewAuditEventRepository(sqlHandler)
+
+	events := []*domain.AuditEvent{
+		{
+			ActorID:    1,
+			Action:     domain.AuditActionContestCreate,
+			TargetType: domain.AuditActionContestCreate.TargetType(),
+			TargetID:   1,
+			Changes:    domain.AuditChanges{"open": {After: json.RawMessage("true")}},
+			IPAddress:  "127.0.0.1",
+			UserAgent:  "curl/7.64.1",
+		},
+		{ActorID: 1, Action: domain.AuditActionUserDisable, TargetType: "user", TargetID: 2},
+		{ActorID: 3, Action: domain.AuditActionUserEnable, TargetType: "user", TargetID: 2},
+	}
+
+	for _, event := range events {
+		err := repo.Store(context.Background(), event)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uint64(0), event.ID)
+	}
+
+	{
+		found, err := repo.Find(context.Background(), domain.AuditEventFilter{}, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, found, 3)
+		assert.Equal(t, events[2].ID, found[0].ID, "newest events come first")
+		assert.Equal(t, events[0].Changes, found[2].Changes)
+		assert.Equal(t, events[0].IPAddress, found[2].IPAddress)
+	}
+
+	{
+		found, err := repo.Find(context.Background(), domain.AuditEventFilter{ActorID: 1}, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, found, 2)
+	}
+
+	{
+		found, err := repo.Find(context.Background(), domain.AuditEventFilter{TargetType: "user", TargetID: 2}, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, found, 2)
+	}
+
+	{
+		found, err := repo.Find(context.Background(), domain.AuditEventFilter{Action: domain.AuditActionUserEnable}, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, found, 1)
+		assert.Equal(t, events[2].ID, found[0].ID)
+	}
+
+	{
+		found, err := repo.Find(context.Background(), domain.AuditEventFilter{}, 2, 2)
+		assert.NoError(t, err)
+		assert.Len(t, found, 1)
+	}
+}
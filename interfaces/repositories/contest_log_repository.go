@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"github.com/tadoku/api/domain"
 	"github.com/tadoku/api/interfaces/rdb"
 	"github.com/tadoku/api/usecases"
@@ -15,15 +17,15 @@ type contestLogRepository struct {
 	sqlHandler rdb.SQLHandler
 }
 
-func (r *contestLogRepository) Store(contestLog *domain.ContestLog) error {
+func (r *contestLogRepository) Store(ctx context.Context, contestLog *domain.ContestLog) error {
 	if contestLog.ID == 0 {
-		return r.create(contestLog)
+		return r.create(ctx, contestLog)
 	}
 
-	return r.update(contestLog)
+	return r.update(ctx, contestLog)
 }
 
-func (r *contestLogRepository) create(contestLog *domain.ContestLog) error {
+func (r *contestLogRepository) create(ctx context.Context, contestLog *domain.ContestLog) error {
 	query := `
 		insert into contest_logs
 		(contest_id, user_id, language_code, medium_id, amount, description, created_at, updated_at)
@@ -32,6 +34,7 @@ func (r *contestLogRepository) create(contestLog *domain.ContestLog) error {
 	`
 
 	row := r.sqlHandler.QueryRow(
+		ctx,
 		query,
 		contestLog.ContestID,
 		contestLog.UserID,
@@ -48,7 +51,7 @@ func (r *contestLogRepository) create(contestLog *domain.ContestLog) error {
 	return nil
 }
 
-func (r *contestLogRepository) update(contestLog *domain.ContestLog) error {
+func (r *contestLogRepository) update(ctx context.Context, contestLog *domain.ContestLog) error {
 	query := `
 		update contest_logs
 		set amount = :amount, medium_id = :medium_id, language_code = :language_code, description = :description, updated_at = now() at time zone 'utc'
@@ -58,11 +61,23 @@ func (r *contestLogRepository) update(contestLog *domain.ContestLog) error {
 			deleted_at is null
 	`
 
-	_, err := r.sqlHandler.NamedExecute(query, contestLog)
-	return domain.WrapError(err)
+	result, err := r.sqlHandler.NamedExecute(ctx, query, contestLog)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+	if rows != 1 {
+		return domain.ErrNotFound
+	}
+
+	return nil
 }
 
-func (r *contestLogRepository) FindByID(id uint64) (domain.ContestLog, error) {
+func (r *contestLogRepository) FindByID(ctx context.Context, id uint64) (domain.ContestLog, error) {
 	l := domain.ContestLog{}
 
 	query := `
@@ -72,7 +87,7 @@ func (r *contestLogRepository) FindByID(id uint64) (domain.ContestLog, error) {
 			id = $1 and
 			deleted_at is null
 	`
-	err := r.sqlHandler.QueryRow(query, id).StructScan(&l)
+	err := r.sqlHandler.QueryRow(ctx, query, id).StructScan(&l)
 	if err != nil {
 		return l, domain.WrapError(err)
 	}
@@ -83,7 +98,29 @@ func (r *contestLogRepository) FindByID(id uint64) (domain.ContestLog, error) {
 	return l, nil
 }
 
-func (r *contestLogRepository) FindAll(contestID uint64, userID uint64) (domain.ContestLogs, error) {
+func (r *contestLogRepository) FindByIDForUpdate(ctx context.Context, id uint64) (domain.ContestLog, error) {
+	l := domain.ContestLog{}
+
+	query := `
+		select id, contest_id, user_id, language_code, medium_id, amount, description, created_at, updated_at
+		from contest_logs
+		where
+			id = $1 and
+			deleted_at is null
+		for update
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, id).StructScan(&l)
+	if err != nil {
+		return l, domain.WrapError(err)
+	}
+	if l.ID == 0 {
+		return l, domain.ErrNotFound
+	}
+
+	return l, nil
+}
+
+func (r *contestLogRepository) FindAll(ctx context.Context, contestID uint64, userID uint64) (domain.ContestLogs, error) {
 	var logs []domain.ContestLog
 
 	query := `
@@ -96,7 +133,7 @@ func (r *contestLogRepository) FindAll(contestID uint64, userID uint64) (domain.
 			deleted_at is null
 	`
 
-	err := r.sqlHandler.Select(&logs, query, contestID, userID)
+	err := r.sqlHandler.Select(ctx, &logs, query, contestID, userID)
 	if err != nil {
 		return nil, domain.WrapError(err)
 	}
@@ -104,7 +141,27 @@ func (r *contestLogRepository) FindAll(contestID uint64, userID uint64) (domain.
 	return logs, nil
 }
 
-func (r *contestLogRepository) Delete(id uint64) error {
+func (r *contestLogRepository) FindAllForContest(ctx context.Context, contestID uint64) (domain.ContestLogs, error) {
+	var logs []domain.ContestLog
+
+	query := `
+		select
+			id, contest_id, user_id, language_code, medium_id, amount, description, created_at, updated_at
+		from contest_logs
+		where
+			contest_id = $1 and
+			deleted_at is null
+	`
+
+	err := r.sqlHandler.Select(ctx, &logs, query, contestID)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return logs, nil
+}
+
+func (r *contestLogRepository) Delete(ctx context.Context, id uint64) error {
 	query := `
 		update contest_logs
 		set deleted_at = now() at time zone 'utc'
@@ -113,7 +170,7 @@ func (r *contestLogRepository) Delete(id uint64) error {
 			deleted_at is null
 	`
 
-	result, err := r.sqlHandler.Execute(query, id)
+	result, err := r.sqlHandler.Execute(ctx, query, id)
 	if err != nil {
 		return domain.WrapError(err)
 	}
@@ -128,3 +185,48 @@ func (r *contestLogRepository) Delete(id uint64) error {
 
 	return nil
 }
+
+func (r *contestLogRepository) FindAllForUser(ctx context.Context, userID uint64) (domain.ContestLogs, error) {
+	var logs []domain.ContestLog
+
+	query := `
+		select
+			id, contest_id, user_id, language_code, medium_id, amount, description, created_at, updated_at, deleted_at
+		from contest_logs
+		where user_id = $1
+		order by id asc
+	`
+
+	err := r.sqlHandler.Select(ctx, &logs, query, userID)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return logs, nil
+}
+
+func (r *contestLogRepository) TotalsForUser(ctx context.Context, userID uint64) ([]domain.ContestLogTotal, error) {
+	var totals []domain.ContestLogTotal
+
+	query := `
+		select language_code, medium_id, sum(amount) as amount
+		from contest_logs
+		where user_id = $1 and deleted_at is null
+		group by language_code, medium_id
+		order by language_code, medium_id
+	`
+
+	err := r.sqlHandler.Select(ctx, &totals, query, userID)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return totals, nil
+}
+
+func (r *contestLogRepository) Purge(ctx context.Context, userID uint64) error {
+	query := `delete from contest_logs where user_id = $1`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID)
+	return domain.WrapError(err)
+}
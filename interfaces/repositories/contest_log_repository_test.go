@@ -1,6 +1,7 @@
 package repositories_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,7 +24,7 @@ func TestContestLogRepository_StoreUpdateDeleteLog(t *testing.T) {
 	}
 
 	{
-		err := repo.Store(log)
+		err := repo.Store(context.Background(), log)
 		assert.NoError(t, err)
 	}
 
@@ -38,16 +39,26 @@ func TestContestLogRepository_StoreUpdateDeleteLog(t *testing.T) {
 			Description: "foobar 2",
 		}
 		assert.NotEqual(t, 0, updatedLog.ID)
-		err := repo.Store(updatedLog)
+		err := repo.Store(context.Background(), updatedLog)
 		assert.NoError(t, err)
+
+		found, err := repo.FindByIDForUpdate(context.Background(), log.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, float32(20), found.Amount)
 	}
 
 	{
-		err := repo.Delete(log.ID)
+		err := repo.Delete(context.Background(), log.ID)
 		assert.NoError(t, err)
 
-		_, err = repo.FindByID(log.ID)
+		_, err = repo.FindByID(context.Background(), log.ID)
+		assert.EqualError(t, err, domain.ErrNotFound.Error())
+
+		_, err = repo.FindByIDForUpdate(context.Background(), log.ID)
 		assert.EqualError(t, err, domain.ErrNotFound.Error())
+
+		err = repo.Store(context.Background(), log)
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "deleted logs can't be updated")
 	}
 }
 
@@ -83,7 +94,7 @@ func TestContestLogRepository_FindAllByContestAndUser(t *testing.T) {
 				Description: data.description,
 			}
 
-			err := repo.Store(log)
+			err := repo.Store(context.Background(), log)
 			assert.NoError(t, err)
 		}
 	}
@@ -100,12 +111,12 @@ func TestContestLogRepository_FindAllByContestAndUser(t *testing.T) {
 				Description: "barbar",
 			}
 
-			err := repo.Store(log)
+			err := repo.Store(context.Background(), log)
 			assert.NoError(t, err)
 		}
 	}
 
-	logs, err := repo.FindAll(contestID, userID)
+	logs, err := repo.FindAll(context.Background(), contestID, userID)
 	assert.NoError(t, err)
 
 	for _, expected := range expected {
@@ -123,3 +134,114 @@ func TestContestLogRepository_FindAllByContestAndUser(t *testing.T) {
 		assert.Equal(t, userID, log.UserID)
 	}
 }
+
+func TestContestLogRepository_FindAllForContest(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewContestLogRepository(sqlHandler)
+
+	for _, contestID := range []uint64{1, 2} {
+		for _, userID := range []uint64{1, 2} {
+			log := &domain.ContestLog{ContestID: contestID, UserID: userID, Language: domain.Japanese, MediumID: domain.MediumBook, Amount: 10}
+			err := repo.Store(context.Background(), log)
+			assert.NoError(t, err)
+		}
+	}
+
+	logs, err := repo.FindAll(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Delete(context.Background(), logs[0].ID))
+
+	logs, err = repo.FindAllForContest(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, logs, 1)
+	assert.Equal(t, uint64(1), logs[0].ContestID)
+	assert.Equal(t, uint64(2), logs[0].UserID)
+}
+
+func TestContestLogRepository_FindAllForUserAndPurge(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewContestLogRepository(sqlHandler)
+	userID := uint64(1)
+
+	var ids []uint64
+	for _, contestID := range []uint64{1, 2} {
+		log := &domain.ContestLog{
+			ContestID: contestID,
+			UserID:    userID,
+			Language:  domain.Japanese,
+			MediumID:  domain.MediumBook,
+			Amount:    10,
+		}
+		err := repo.Store(context.Background(), log)
+		assert.NoError(t, err)
+		ids = append(ids, log.ID)
+	}
+
+	other := &domain.ContestLog{ContestID: 1, UserID: userID + 1, Language: domain.Japanese, MediumID: domain.MediumBook, Amount: 5}
+	assert.NoError(t, repo.Store(context.Background(), other))
+
+	err := repo.Delete(context.Background(), ids[1])
+	assert.NoError(t, err)
+
+	// Deleted logs are included
+	{
+		logs, err := repo.FindAllForUser(context.Background(), userID)
+		assert.NoError(t, err)
+		assert.Len(t, logs, 2)
+		assert.Nil(t, logs[0].DeletedAt)
+		assert.NotNil(t, logs[1].DeletedAt)
+	}
+
+	// Purging only removes logs of the user
+	{
+		err := repo.Purge(context.Background(), userID)
+		assert.NoError(t, err)
+
+		logs, err := repo.FindAllForUser(context.Background(), userID)
+		assert.NoError(t, err)
+		assert.Empty(t, logs)
+
+		logs, err = repo.FindAllForUser(context.Background(), userID+1)
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+	}
+}
+
+func TestContestLogRepository_TotalsForUser(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewContestLogRepository(sqlHandler)
+	userID := uint64(1)
+
+	for _, data := range []struct {
+		contestID uint64
+		language  domain.LanguageCode
+		medium    domain.MediumID
+		amount    float32
+	}{
+		{1, domain.Japanese, domain.MediumBook, 10},
+		{2, domain.Japanese, domain.MediumBook, 5},
+		{2, domain.Japanese, domain.MediumComic, 20},
+		{2, domain.Korean, domain.MediumBook, 7},
+	} {
+		log := &domain.ContestLog{ContestID: data.contestID, UserID: userID, Language: data.language, MediumID: data.medium, Amount: data.amount}
+		assert.NoError(t, repo.Store(context.Background(), log))
+	}
+
+	deleted := &domain.ContestLog{ContestID: 1, UserID: userID, Language: domain.Korean, MediumID: domain.MediumBook, Amount: 100}
+	assert.NoError(t, repo.Store(context.Background(), deleted))
+	assert.NoError(t, repo.Delete(context.Background(), deleted.ID))
+
+	totals, err := repo.TotalsForUser(context.Background(), userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.ContestLogTotal{
+		{Language: domain.Japanese, MediumID: domain.MediumBook, Amount: 15},
+		{Language: domain.Japanese, MediumID: domain.MediumComic, Amount: 20},
+		{Language: domain.Korean, MediumID: domain.MediumBook, Amount: 7},
+	}, totals)
+}
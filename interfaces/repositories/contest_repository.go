@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"github.com/tadoku/api/domain"
 	"github.com/tadoku/api/interfaces/rdb"
 	"github.com/tadoku/api/usecases"
@@ -15,15 +17,15 @@ type contestRepository struct {
 	sqlHandler rdb.SQLHandler
 }
 
-func (r *contestRepository) Store(contest *domain.Contest) error {
+func (r *contestRepository) Store(ctx context.Context, contest *domain.Contest) error {
 	if contest.ID == 0 {
-		return r.create(contest)
+		return r.create(ctx, contest)
 	}
 
-	return r.update(contest)
+	return r.update(ctx, contest)
 }
 
-func (r *contestRepository) create(contest *domain.Contest) error {
+func (r *contestRepository) create(ctx context.Context, contest *domain.Contest) error {
 	query := `
 		insert into contests
 		(description, start, "end", open)
@@ -31,7 +33,7 @@ func (r *contestRepository) create(contest *domain.Contest) error {
 		returning id
 	`
 
-	row := r.sqlHandler.QueryRow(query, contest.Description, contest.Start, contest.End, contest.Open)
+	row := r.sqlHandler.QueryRow(ctx, query, contest.Description, contest.Start, contest.End, contest.Open)
 	err := row.Scan(&contest.ID)
 	if err != nil {
 		return domain.WrapError(err)
@@ -40,18 +42,18 @@ func (r *contestRepository) create(contest *domain.Contest) error {
 	return nil
 }
 
-func (r *contestRepository) update(contest *domain.Contest) error {
+func (r *contestRepository) update(ctx context.Context, contest *domain.Contest) error {
 	query := `
 		update contests
 		set start = :start, "end" = :end, open = :open
 		where id = :id
 	`
 
-	_, err := r.sqlHandler.NamedExecute(query, contest)
+	_, err := r.sqlHandler.NamedExecute(ctx, query, contest)
 	return domain.WrapError(err)
 }
 
-func (r *contestRepository) GetOpenContests() ([]uint64, error) {
+func (r *contestRepository) GetOpenContests(ctx context.Context) ([]uint64, error) {
 	query := `
 		select id
 		from contests
@@ -59,7 +61,7 @@ func (r *contestRepository) GetOpenContests() ([]uint64, error) {
 	`
 
 	var ids []uint64
-	err := r.sqlHandler.Select(&ids, query)
+	err := r.sqlHandler.Select(ctx, &ids, query)
 	if err != nil {
 		return nil, domain.WrapError(err)
 	}
@@ -67,7 +69,7 @@ func (r *contestRepository) GetOpenContests() ([]uint64, error) {
 	return ids, nil
 }
 
-func (r *contestRepository) GetRunningContests() ([]uint64, error) {
+func (r *contestRepository) GetRunningContests(ctx context.Context) ([]uint64, error) {
 	query := `
 		select id
 		from contests
@@ -78,7 +80,7 @@ func (r *contestRepository) GetRunningContests() ([]uint64, error) {
 	`
 
 	var ids []uint64
-	err := r.sqlHandler.Select(&ids, query)
+	err := r.sqlHandler.Select(ctx, &ids, query)
 	if err != nil {
 		return nil, domain.WrapError(err)
 	}
@@ -86,7 +88,7 @@ func (r *contestRepository) GetRunningContests() ([]uint64, error) {
 	return ids, nil
 }
 
-func (r *contestRepository) FindAll() ([]domain.Contest, error) {
+func (r *contestRepository) FindAll(ctx context.Context) ([]domain.Contest, error) {
 	query := `
 		select id, description, start, "end", open
 		from contests
@@ -94,7 +96,7 @@ func (r *contestRepository) FindAll() ([]domain.Contest, error) {
 	`
 
 	var contests []domain.Contest
-	err := r.sqlHandler.Select(&contests, query)
+	err := r.sqlHandler.Select(ctx, &contests, query)
 	if err != nil {
 		return contests, domain.WrapError(err)
 	}
@@ -106,7 +108,7 @@ func (r *contestRepository) FindAll() ([]domain.Contest, error) {
 	return contests, nil
 }
 
-func (r *contestRepository) FindRecent(count int) ([]domain.Contest, error) {
+func (r *contestRepository) FindRecent(ctx context.Context, count int) ([]domain.Contest, error) {
 	query := `
 		select id, description, start, "end", open
 		from contests
@@ -115,7 +117,7 @@ func (r *contestRepository) FindRecent(count int) ([]domain.Contest, error) {
 	`
 
 	var contests []domain.Contest
-	err := r.sqlHandler.Select(&contests, query, count)
+	err := r.sqlHandler.Select(ctx, &contests, query, count)
 	if err != nil {
 		return contests, domain.WrapError(err)
 	}
@@ -123,7 +125,7 @@ func (r *contestRepository) FindRecent(count int) ([]domain.Contest, error) {
 	return contests, nil
 }
 
-func (r *contestRepository) FindByID(id uint64) (domain.Contest, error) {
+func (r *contestRepository) FindByID(ctx context.Context, id uint64) (domain.Contest, error) {
 	query := `
 		select id, description, start, "end", open
 		from contests
@@ -132,7 +134,7 @@ func (r *contestRepository) FindByID(id uint64) (domain.Contest, error) {
 	`
 
 	var contest domain.Contest
-	err := r.sqlHandler.Get(&contest, query, id)
+	err := r.sqlHandler.Get(ctx, &contest, query, id)
 	if err != nil {
 		return contest, domain.WrapError(err)
 	}
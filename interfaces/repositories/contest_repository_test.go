@@ -1,6 +1,7 @@
 package repositories_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -23,7 +24,7 @@ func TestContestRepository_StoreContest(t *testing.T) {
 	}
 
 	{
-		err := repo.Store(contest)
+		err := repo.Store(context.Background(), contest)
 		assert.NoError(t, err)
 		assert.NotEqual(t, 0, contest.ID)
 	}
@@ -36,7 +37,7 @@ func TestContestRepository_StoreContest(t *testing.T) {
 			End:         time.Date(2019, 1, 30, 0, 0, 0, 0, time.UTC),
 			Open:        false,
 		}
-		err := repo.Store(updatedContest)
+		err := repo.Store(context.Background(), updatedContest)
 		assert.NoError(t, err)
 	}
 }
@@ -48,14 +49,14 @@ func TestContestRepository_GetOpenContests(t *testing.T) {
 	repo := repositories.NewContestRepository(sqlHandler)
 
 	{
-		ids, err := repo.GetOpenContests()
+		ids, err := repo.GetOpenContests(context.Background())
 		assert.NoError(t, err)
 		assert.Empty(t, ids, "no open contests should exist")
 
-		err = repo.Store(&domain.Contest{Start: time.Now(), End: time.Now(), Open: true})
+		err = repo.Store(context.Background(), &domain.Contest{Start: time.Now(), End: time.Now(), Open: true})
 		assert.NoError(t, err)
 
-		ids, err = repo.GetOpenContests()
+		ids, err = repo.GetOpenContests(context.Background())
 		assert.Equal(t, 1, len(ids), "an open contest should exist")
 		assert.NoError(t, err)
 	}
@@ -68,7 +69,7 @@ func TestContestRepository_GetRunningContests(t *testing.T) {
 	repo := repositories.NewContestRepository(sqlHandler)
 
 	{
-		ids, err := repo.GetRunningContests()
+		ids, err := repo.GetRunningContests(context.Background())
 		assert.NoError(t, err)
 		assert.Empty(t, ids, "no running contests should exist")
 
@@ -77,11 +78,11 @@ func TestContestRepository_GetRunningContests(t *testing.T) {
 			{Start: time.Now().Add(-1 * time.Hour), End: time.Now().Add(1 * time.Hour), Open: false},
 			{Start: time.Now().Add(-5 * time.Hour), End: time.Now().Add(-1 * time.Hour), Open: false},
 		} {
-			err = repo.Store(contest)
+			err = repo.Store(context.Background(), contest)
 			assert.NoError(t, err, "saving seed contest should return no error")
 		}
 
-		ids, err = repo.GetOpenContests()
+		ids, err = repo.GetOpenContests(context.Background())
 		assert.Equal(t, 1, len(ids), "only one running contest should exist")
 		assert.NoError(t, err)
 	}
@@ -94,17 +95,17 @@ func TestContestRepository_FindAll(t *testing.T) {
 	repo := repositories.NewContestRepository(sqlHandler)
 
 	{
-		contest, err := repo.FindAll()
+		contest, err := repo.FindAll(context.Background())
 		assert.EqualError(t, err, domain.ErrNotFound.Error())
 		assert.Empty(t, contest, "no contests should be found")
 	}
 
 	{
 		expected := domain.Contest{Description: "Foo 2019", Start: time.Now(), End: time.Now(), Open: true}
-		err := repo.Store(&expected)
+		err := repo.Store(context.Background(), &expected)
 		assert.NoError(t, err)
 
-		contest, err := repo.FindAll()
+		contest, err := repo.FindAll(context.Background())
 		assert.Equal(t, expected.Description, contest[0].Description, "contest should have the same description")
 		assert.Equal(t, expected.Open, contest[0].Open, "contest should both be open")
 		assert.NoError(t, err)
@@ -112,10 +113,10 @@ func TestContestRepository_FindAll(t *testing.T) {
 
 	{
 		expected := domain.Contest{Description: "Foo 2019 2", Start: time.Now(), End: time.Now(), Open: true}
-		err := repo.Store(&expected)
+		err := repo.Store(context.Background(), &expected)
 		assert.NoError(t, err)
 
-		contest, err := repo.FindAll()
+		contest, err := repo.FindAll(context.Background())
 		assert.Equal(t, expected.Description, contest[0].Description, "contest should have the same description")
 		assert.Equal(t, expected.Open, contest[0].Open, "contest should both be open")
 		assert.NoError(t, err)
@@ -135,12 +136,12 @@ func TestContestRepository_FindRecent(t *testing.T) {
 			{Description: "Foo 2019", Start: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2020, 1, 30, 0, 0, 0, 0, time.UTC), Open: false},
 		}
 		for _, contest := range contests {
-			err := repo.Store(&contest)
+			err := repo.Store(context.Background(), &contest)
 			assert.NoError(t, err)
 		}
 
 		expected := contests[1:]
-		result, err := repo.FindRecent(2)
+		result, err := repo.FindRecent(context.Background(), 2)
 		assert.Equal(t, len(expected), len(result))
 		assert.NoError(t, err)
 	}
@@ -153,15 +154,15 @@ func TestContestRepository_FindByID(t *testing.T) {
 	repo := repositories.NewContestRepository(sqlHandler)
 
 	{
-		contest, err := repo.FindByID(0)
+		contest, err := repo.FindByID(context.Background(), 0)
 		assert.EqualError(t, err, domain.ErrNotFound.Error())
 		assert.Empty(t, contest, "no contests should be found")
 
 		expected := domain.Contest{Description: "Foo 2019", Start: time.Now(), End: time.Now(), Open: true}
-		err = repo.Store(&expected)
+		err = repo.Store(context.Background(), &expected)
 		assert.NoError(t, err)
 
-		contest, err = repo.FindByID(expected.ID)
+		contest, err = repo.FindByID(context.Background(), expected.ID)
 		assert.Equal(t, expected.Description, contest.Description, "contest should have the same description")
 		assert.Equal(t, expected.Open, contest.Open, "contest should both be open")
 		assert.NoError(t, err)
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewLoginAttemptRepository instantiates a new login attempt repository,
+// which shares failed logins between all instances of the api
+func NewLoginAttemptRepository(sqlHandler rdb.SQLHandler) usecases.LoginAttemptRepository {
+	return &loginAttemptRepository{sqlHandler: sqlHandler}
+}
+
+type loginAttemptRepository struct {
+	sqlHandler rdb.SQLHandler
+}
+
+func (r *loginAttemptRepository) FindByKey(ctx context.Context, key string) (domain.LoginAttempt, error) {
+	a := domain.LoginAttempt{}
+
+	query := `
+		select key, failures, last_failed_at
+		from login_attempts
+		where key = $1
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, key).StructScan(&a)
+	if err != nil {
+		return a, domain.WrapError(err)
+	}
+
+	return a, nil
+}
+
+func (r *loginAttemptRepository) RecordFailure(ctx context.Context, key string, failedAt time.Time, resetBefore time.Time) (domain.LoginAttempt, error) {
+	a := domain.LoginAttempt{}
+
+	// Counting happens in a single statement so concurrent logins can't lose any failures
+	query := `
+		insert into login_attempts
+		(key, failures, last_failed_at)
+		values ($1, 1, $2)
+		on conflict (key) do update set
+			failures = case
+				when login_attempts.last_failed_at < $3 then 1
+				else login_attempts.failures + 1
+			end,
+			last_failed_at = excluded.last_failed_at
+		returning key, failures, last_failed_at
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, key, failedAt.UTC(), resetBefore.UTC()).StructScan(&a)
+	if err != nil {
+		return a, domain.WrapError(err)
+	}
+
+	return a, nil
+}
+
+func (r *loginAttemptRepository) Delete(ctx context.Context, key string) error {
+	query := `delete from login_attempts where key = $1`
+
+	_, err := r.sqlHandler.Execute(ctx, query, key)
+	return domain.WrapError(err)
+}
+
+func (r *loginAttemptRepository) DeleteFailedBefore(ctx context.Context, before time.Time) error {
+	query := `delete from login_attempts where last_failed_at < $1`
+
+	_, err := r.sqlHandler.Execute(ctx, query, before.UTC())
+	return domain.WrapError(err)
+}
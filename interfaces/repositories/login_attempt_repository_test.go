@@ -0,0 +1,66 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestLoginAttemptRepository_RecordFailure(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewLoginAttemptRepository(sqlHandler)
+	key := domain.LoginAttemptAccountKey("foo@bar.com")
+	now := time.Now().UTC()
+
+	{
+		_, err := repo.FindByKey(context.Background(), key)
+		assert.EqualError(t, err, domain.ErrNotFound.Error())
+	}
+
+	{
+		attempt, err := repo.RecordFailure(context.Background(), key, now, now.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures)
+
+		attempt, err = repo.RecordFailure(context.Background(), key, now, now.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, attempt.Failures)
+
+		found, err := repo.FindByKey(context.Background(), key)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, found.Failures)
+	}
+
+	{
+		later := now.Add(2 * time.Hour)
+		attempt, err := repo.RecordFailure(context.Background(), key, later, later.Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempt.Failures, "old failures are forgotten")
+	}
+
+	{
+		err := repo.Delete(context.Background(), key)
+		assert.NoError(t, err)
+
+		_, err = repo.FindByKey(context.Background(), key)
+		assert.EqualError(t, err, domain.ErrNotFound.Error())
+	}
+
+	{
+		_, err := repo.RecordFailure(context.Background(), key, now, now.Add(-time.Hour))
+		assert.NoError(t, err)
+
+		err = repo.DeleteFailedBefore(context.Background(), now.Add(time.Minute))
+		assert.NoError(t, err)
+
+		_, err = repo.FindByKey(context.Background(), key)
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "stale failures are cleaned up")
+	}
+}
@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewOneTimeTokenRepository instantiates a new one-time token repository
+func NewOneTimeTokenRepository(sqlHandler rdb.SQLHandler) usecases.OneTimeTokenRepository {
+	return &oneTimeTokenRepository{sqlHandler: sqlHandler}
+}
+
+type oneTimeTokenRepository struct {
+	sqlHandler rdb.SQLHandler
+}
+
+func (r *oneTimeTokenRepository) Store(ctx context.Context, token *domain.OneTimeToken) error {
+	query := `
+		insert into one_time_tokens
+		(user_id, purpose, payload, token_hash, expires_at, created_at)
+		values ($1, $2, $3, $4, $5, now() at time zone 'utc')
+		returning id
+	`
+
+	row := r.sqlHandler.QueryRow(ctx, query, token.UserID, token.Purpose, token.Payload, token.Hash, token.ExpiresAt)
+	err := row.Scan(&token.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return nil
+}
+
+func (r *oneTimeTokenRepository) FindByHash(ctx context.Context, purpose domain.TokenPurpose, hash string) (domain.OneTimeToken, error) {
+	token := domain.OneTimeToken{}
+
+	query := `
+		select id, user_id, purpose, payload, token_hash, expires_at, used_at, created_at
+		from one_time_tokens
+		where
+			purpose = $1 and
+			token_hash = $2
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, purpose, hash).StructScan(&token)
+	if err != nil {
+		return token, domain.WrapError(err)
+	}
+
+	return token, nil
+}
+
+func (r *oneTimeTokenRepository) MarkAsUsed(ctx context.Context, id uint64) error {
+	query := `
+		update one_time_tokens
+		set used_at = now() at time zone 'utc'
+		where
+			id = $1 and
+			used_at is null
+	`
+
+	result, err := r.sqlHandler.Execute(ctx, query, id)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+	if rows != 1 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *oneTimeTokenRepository) InvalidateAllForUser(ctx context.Context, purpose domain.TokenPurpose, userID uint64) error {
+	query := `
+		update one_time_tokens
+		set used_at = now() at time zone 'utc'
+		where
+			purpose = $1 and
+			user_id = $2 and
+			used_at is null
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, purpose, userID)
+	return domain.WrapError(err)
+}
+
+func (r *oneTimeTokenRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	query := `
+		delete from one_time_tokens
+		where user_id = $1
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID)
+	return domain.WrapError(err)
+}
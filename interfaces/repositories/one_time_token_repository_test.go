@@ -0,0 +1,100 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestOneTimeTokenRepository_StoreAndUseToken(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewOneTimeTokenRepository(sqlHandler)
+	token := &domain.OneTimeToken{
+		UserID:    1,
+		Purpose:   domain.TokenPurposeEmailChange,
+		Payload:   "new@example.com",
+		Hash:      domain.HashToken("foobar"),
+		ExpiresAt: time.Now().UTC().Add(1 * time.Hour),
+	}
+
+	{
+		err := repo.Store(context.Background(), token)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uint64(0), token.ID)
+	}
+
+	{
+		found, err := repo.FindByHash(context.Background(), domain.TokenPurposeEmailChange, domain.HashToken("foobar"))
+		assert.NoError(t, err)
+		assert.Equal(t, token.ID, found.ID)
+		assert.Equal(t, token.Payload, found.Payload)
+		assert.Nil(t, found.UsedAt)
+	}
+
+	{
+		_, err := repo.FindByHash(context.Background(), domain.TokenPurposePasswordReset, domain.HashToken("foobar"))
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "a token can't be used for another purpose")
+	}
+
+	{
+		err := repo.MarkAsUsed(context.Background(), token.ID)
+		assert.NoError(t, err)
+
+		err = repo.MarkAsUsed(context.Background(), token.ID)
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "a token can only be used once")
+	}
+
+	{
+		other := &domain.OneTimeToken{UserID: 1, Purpose: domain.TokenPurposeEmailChange, Hash: domain.HashToken("barfoo"), ExpiresAt: token.ExpiresAt}
+		reset := &domain.OneTimeToken{UserID: 1, Purpose: domain.TokenPurposePasswordReset, Hash: domain.HashToken("bazfoo"), ExpiresAt: token.ExpiresAt}
+		assert.NoError(t, repo.Store(context.Background(), other))
+		assert.NoError(t, repo.Store(context.Background(), reset))
+
+		err := repo.InvalidateAllForUser(context.Background(), domain.TokenPurposeEmailChange, 1)
+		assert.NoError(t, err)
+
+		found, err := repo.FindByHash(context.Background(), domain.TokenPurposeEmailChange, domain.HashToken("barfoo"))
+		assert.NoError(t, err)
+		assert.NotNil(t, found.UsedAt)
+
+		found, err = repo.FindByHash(context.Background(), domain.TokenPurposePasswordReset, domain.HashToken("bazfoo"))
+		assert.NoError(t, err)
+		assert.Nil(t, found.UsedAt, "tokens for other purposes stay usable")
+	}
+}
+
+func TestOneTimeTokenRepository_DeleteAllForUser(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewOneTimeTokenRepository(sqlHandler)
+
+	tokens := []*domain.OneTimeToken{
+		{UserID: 1, Purpose: domain.TokenPurposePasswordReset, Hash: domain.HashToken("foo"), ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+		{UserID: 1, Purpose: domain.TokenPurposeEmailChange, Payload: "new@example.com", Hash: domain.HashToken("bar"), ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+		{UserID: 2, Purpose: domain.TokenPurposePasswordReset, Hash: domain.HashToken("baz"), ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+	}
+	for _, token := range tokens {
+		err := repo.Store(context.Background(), token)
+		assert.NoError(t, err)
+	}
+
+	err := repo.DeleteAllForUser(context.Background(), 1)
+	assert.NoError(t, err)
+
+	for _, token := range tokens {
+		_, err := repo.FindByHash(context.Background(), token.Purpose, token.Hash)
+		if token.UserID == 1 {
+			assert.EqualError(t, err, domain.ErrNotFound.Error())
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewPersonalAccessTokenRepository instantiates a new personal access token repository
+func NewPersonalAccessTokenRepository(sqlHandler rdb.SQLHandler) usecases.PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{sqlHandler: sqlHandler}
+}
+
+type personalAccessTokenRepository struct {
+	sqlHandler rdb.SQLHandler
+}
+
+func (r *personalAccessTokenRepository) Store(ctx context.Context, token *domain.PersonalAccessToken) error {
+	query := `
+		insert into personal_access_tokens
+		(user_id, name, token_hash, scopes, created_at)
+		values ($1, $2, $3, $4, now() at time zone 'utc')
+		returning id, created_at
+	`
+
+	row := r.sqlHandler.QueryRow(ctx, query, token.UserID, token.Name, token.Hash, token.Scopes)
+	err := row.Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return nil
+}
+
+func (r *personalAccessTokenRepository) FindByID(ctx context.Context, id uint64) (domain.PersonalAccessToken, error) {
+	t := domain.PersonalAccessToken{}
+
+	query := `
+		select id, user_id, name, token_hash, scopes, last_used_at, revoked_at, created_at
+		from personal_access_tokens
+		where id = $1
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, id).StructScan(&t)
+	if err != nil {
+		return t, domain.WrapError(err)
+	}
+
+	return t, nil
+}
+
+func (r *personalAccessTokenRepository) FindByHash(ctx context.Context, hash string) (domain.PersonalAccessToken, error) {
+	t := domain.PersonalAccessToken{}
+
+	query := `
+		select id, user_id, name, token_hash, scopes, last_used_at, revoked_at, created_at
+		from personal_access_tokens
+		where token_hash = $1
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, hash).StructScan(&t)
+	if err != nil {
+		return t, domain.WrapError(err)
+	}
+
+	return t, nil
+}
+
+func (r *personalAccessTokenRepository) FindActiveByUserID(ctx context.Context, userID uint64) (domain.PersonalAccessTokens, error) {
+	var tokens []domain.PersonalAccessToken
+
+	query := `
+		select id, user_id, name, token_hash, scopes, last_used_at, revoked_at, created_at
+		from personal_access_tokens
+		where
+			user_id = $1 and
+			revoked_at is null
+		order by created_at desc
+	`
+	err := r.sqlHandler.Select(ctx, &tokens, query, userID)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return tokens, nil
+}
+
+func (r *personalAccessTokenRepository) UpdateLastUsed(ctx context.Context, id uint64) error {
+	query := `
+		update personal_access_tokens
+		set last_used_at = now() at time zone 'utc'
+		where id = $1
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, id)
+	return domain.WrapError(err)
+}
+
+func (r *personalAccessTokenRepository) Revoke(ctx context.Context, id uint64) error {
+	query := `
+		update personal_access_tokens
+		set revoked_at = now() at time zone 'utc'
+		where
+			id = $1 and
+			revoked_at is null
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, id)
+	return domain.WrapError(err)
+}
+
+func (r *personalAccessTokenRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	query := `
+		delete from personal_access_tokens
+		where user_id = $1
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID)
+	return domain.WrapError(err)
+}
@@ -0,0 +1,102 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestPersonalAccessTokenRepository_StoreAndFind(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewPersonalAccessTokenRepository(sqlHandler)
+	token := &domain.PersonalAccessToken{
+		UserID: 1,
+		Name:   "e-reader export",
+		Hash:   domain.HashToken("tdk_foobar"),
+		Scopes: domain.Scopes{domain.ScopeLogsWrite, domain.ScopeRankingsRead},
+	}
+
+	{
+		err := repo.Store(context.Background(), token)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uint64(0), token.ID)
+	}
+
+	{
+		found, err := repo.FindByHash(context.Background(), token.Hash)
+		assert.NoError(t, err)
+		assert.Equal(t, token.ID, found.ID)
+		assert.Equal(t, token.Scopes, found.Scopes)
+		assert.Nil(t, found.LastUsedAt)
+	}
+
+	{
+		err := repo.UpdateLastUsed(context.Background(), token.ID)
+		assert.NoError(t, err)
+
+		found, err := repo.FindByID(context.Background(), token.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, found.LastUsedAt)
+	}
+
+	{
+		_, err := repo.FindByHash(context.Background(), domain.HashToken("tdk_unknown"))
+		assert.EqualError(t, err, domain.ErrNotFound.Error())
+	}
+}
+
+func TestPersonalAccessTokenRepository_FindActiveByUserIDAndRevoke(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewPersonalAccessTokenRepository(sqlHandler)
+
+	tokens := []*domain.PersonalAccessToken{
+		{UserID: 1, Name: "foo", Hash: domain.HashToken("tdk_foo"), Scopes: domain.Scopes{domain.ScopeLogsWrite}},
+		{UserID: 1, Name: "bar", Hash: domain.HashToken("tdk_bar"), Scopes: domain.Scopes{domain.ScopeLogsWrite}},
+		{UserID: 2, Name: "baz", Hash: domain.HashToken("tdk_baz"), Scopes: domain.Scopes{domain.ScopeLogsWrite}},
+	}
+	for _, token := range tokens {
+		err := repo.Store(context.Background(), token)
+		assert.NoError(t, err)
+	}
+
+	err := repo.Revoke(context.Background(), tokens[0].ID)
+	assert.NoError(t, err)
+
+	active, err := repo.FindActiveByUserID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, active, 1)
+	assert.Equal(t, tokens[1].ID, active[0].ID)
+}
+
+func TestPersonalAccessTokenRepository_DeleteAllForUser(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewPersonalAccessTokenRepository(sqlHandler)
+
+	tokens := []*domain.PersonalAccessToken{
+		{UserID: 1, Name: "foo", Hash: domain.HashToken("tdk_foo"), Scopes: domain.Scopes{domain.ScopeLogsWrite}},
+		{UserID: 2, Name: "bar", Hash: domain.HashToken("tdk_bar"), Scopes: domain.Scopes{domain.ScopeLogsWrite}},
+	}
+	for _, token := range tokens {
+		err := repo.Store(context.Background(), token)
+		assert.NoError(t, err)
+	}
+
+	err := repo.DeleteAllForUser(context.Background(), 1)
+	assert.NoError(t, err)
+
+	_, err = repo.FindByID(context.Background(), tokens[0].ID)
+	assert.EqualError(t, err, domain.ErrNotFound.Error())
+
+	_, err = repo.FindByID(context.Background(), tokens[1].ID)
+	assert.NoError(t, err)
+}
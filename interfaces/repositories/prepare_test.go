@@ -1,6 +1,7 @@
 package repositories_test
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -88,7 +89,7 @@ func createTestUsers(t *testing.T, sqlHandler rdb.SQLHandler, count int) []*doma
 			Role:        domain.RoleUser,
 			Preferences: &domain.Preferences{},
 		}
-		err := repo.Store(user)
+		err := repo.Store(context.Background(), user)
 		assert.NoError(t, err)
 
 		users[i] = user
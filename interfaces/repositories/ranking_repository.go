@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+	"sort"
 	"time"
 
 	"github.com/tadoku/api/domain"
@@ -17,42 +19,37 @@ type rankingRepository struct {
 	sqlHandler rdb.SQLHandler
 }
 
-func (r *rankingRepository) Store(ranking domain.Ranking) error {
+func (r *rankingRepository) Store(ctx context.Context, ranking domain.Ranking) error {
 	if ranking.ID == 0 {
-		return r.create(ranking)
+		return r.create(ctx, ranking)
 	}
 
-	return r.update(ranking)
+	return r.update(ctx, ranking)
 }
 
-func (r *rankingRepository) create(ranking domain.Ranking) error {
+func (r *rankingRepository) create(ctx context.Context, ranking domain.Ranking) error {
 	query := `
 		insert into rankings
 		(contest_id, user_id, language_code, amount, created_at, updated_at)
 		values (:contest_id, :user_id, :language_code, :amount, now() at time zone 'utc', now() at time zone 'utc')
 	`
 
-	_, err := r.sqlHandler.NamedExecute(query, ranking)
+	_, err := r.sqlHandler.NamedExecute(ctx, query, ranking)
 	return domain.WrapError(err)
 }
 
-func (r *rankingRepository) update(ranking domain.Ranking) error {
+func (r *rankingRepository) update(ctx context.Context, ranking domain.Ranking) error {
 	query := `
 		update rankings
 		set amount = :amount, updated_at = now() at time zone 'utc'
 		where id = :id
 	`
 
-	_, err := r.sqlHandler.NamedExecute(query, ranking)
+	_, err := r.sqlHandler.NamedExecute(ctx, query, ranking)
 	return domain.WrapError(err)
 }
 
-func (r *rankingRepository) UpdateAmounts(rankings domain.Rankings) error {
-	tx, err := r.sqlHandler.Begin()
-	if err != nil {
-		return domain.WrapError(err)
-	}
-
+func (r *rankingRepository) UpdateAmounts(ctx context.Context, rankings domain.Rankings) error {
 	query := `
 		update rankings
 		set
@@ -62,32 +59,84 @@ func (r *rankingRepository) UpdateAmounts(rankings domain.Rankings) error {
 	`
 
 	for _, ranking := range rankings {
-		_, err := tx.NamedExecute(query, ranking)
+		_, err := r.sqlHandler.NamedExecute(ctx, query, ranking)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+	}
+
+	return nil
+}
+
+func (r *rankingRepository) AddAmounts(
+	ctx context.Context,
+	contestID uint64,
+	userID uint64,
+	amounts map[domain.LanguageCode]float32,
+) error {
+	// Rows are always updated in the same order, so concurrent updates for the same user can't deadlock
+	languages := make([]domain.LanguageCode, 0, len(amounts))
+	for language := range amounts {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool { return languages[i] < languages[j] })
+
+	query := `
+		update rankings
+		set
+			amount = amount + $1,
+			updated_at = now() at time zone 'utc'
+		where contest_id = $2 and user_id = $3 and language_code = $4
+	`
+
+	for _, language := range languages {
+		result, err := r.sqlHandler.Execute(ctx, query, amounts[language], contestID, userID, language)
+		if err != nil {
+			return domain.WrapError(err)
+		}
 
+		rows, err := result.RowsAffected()
 		if err != nil {
-			_ = tx.Rollback()
 			return domain.WrapError(err)
 		}
+		if rows != 1 {
+			return domain.ErrNotFound
+		}
 	}
 
-	return tx.Commit()
+	return nil
 }
 
 func (r *rankingRepository) RankingsForContest(
+	ctx context.Context,
 	contestID uint64,
 	languageCode domain.LanguageCode,
 ) (domain.Rankings, error) {
 	var rankings []domain.Ranking
 
 	query := `
-		select rankings.id as id, rankings.contest_id as contest_id, user_id, language_code, amount, created_at, updated_at, users.display_name as user_display_name
+		select
+			rankings.id as id, rankings.contest_id as contest_id, user_id, language_code, amount, created_at, updated_at,
+			users.display_name as user_display_name,
+			(
+				select coalesce(sum(l.amount), 0)
+				from contest_logs as l
+				where
+					l.contest_id = rankings.contest_id and
+					l.user_id = rankings.user_id and
+					(rankings.language_code = $3 or l.language_code = rankings.language_code) and
+					l.deleted_at is null
+			) as logged_amount
 		from rankings
 		inner join users on users.id = rankings.user_id
-		where contest_id = $1 and language_code = $2
+		where
+			contest_id = $1 and
+			language_code = $2 and
+			not coalesce((users.preferences->>'hide_from_rankings')::boolean, false)
 		order by amount desc, id asc
 	`
 
-	err := r.sqlHandler.Select(&rankings, query, contestID, languageCode)
+	err := r.sqlHandler.Select(ctx, &rankings, query, contestID, languageCode, domain.Global)
 	if err != nil {
 		return nil, err
 	}
@@ -95,19 +144,30 @@ func (r *rankingRepository) RankingsForContest(
 	return rankings, nil
 }
 
-func (r *rankingRepository) GlobalRankings(languageCode domain.LanguageCode) (domain.Rankings, error) {
+func (r *rankingRepository) GlobalRankings(ctx context.Context, languageCode domain.LanguageCode) (domain.Rankings, error) {
 	var rankings []domain.Ranking
 
 	query := `
-		select user_id, language_code, sum(amount) as amount, users.display_name as user_display_name
+		select
+			user_id, language_code, sum(amount) as amount, users.display_name as user_display_name,
+			(
+				select coalesce(sum(l.amount), 0)
+				from contest_logs as l
+				where
+					l.user_id = rankings.user_id and
+					(rankings.language_code = $2 or l.language_code = rankings.language_code) and
+					l.deleted_at is null
+			) as logged_amount
 		from rankings
 		inner join users on users.id = rankings.user_id
-		where language_code = $1
+		where
+			language_code = $1 and
+			not coalesce((users.preferences->>'hide_from_rankings')::boolean, false)
 		group by user_id, user_display_name, language_code
 		order by amount desc
 	`
 
-	err := r.sqlHandler.Select(&rankings, query, languageCode)
+	err := r.sqlHandler.Select(ctx, &rankings, query, languageCode, domain.Global)
 	if err != nil {
 		return nil, err
 	}
@@ -115,18 +175,28 @@ func (r *rankingRepository) GlobalRankings(languageCode domain.LanguageCode) (do
 	return rankings, nil
 }
 
-func (r *rankingRepository) FindAll(contestID uint64, userID uint64) (domain.Rankings, error) {
+func (r *rankingRepository) FindAll(ctx context.Context, contestID uint64, userID uint64) (domain.Rankings, error) {
 	var rankings []domain.Ranking
 
 	query := `
-		select r.id, contest_id, user_id, u.display_name as user_display_name, language_code, amount, created_at, updated_at
+		select
+			r.id, contest_id, user_id, u.display_name as user_display_name, language_code, amount, created_at, updated_at,
+			(
+				select coalesce(sum(l.amount), 0)
+				from contest_logs as l
+				where
+					l.contest_id = r.contest_id and
+					l.user_id = r.user_id and
+					(r.language_code = $3 or l.language_code = r.language_code) and
+					l.deleted_at is null
+			) as logged_amount
 		from rankings as r
 		inner join users as u on u.id = r.user_id
 		where contest_id = $1 and user_id = $2
 		order by id asc
 	`
 
-	err := r.sqlHandler.Select(&rankings, query, contestID, userID)
+	err := r.sqlHandler.Select(ctx, &rankings, query, contestID, userID, domain.Global)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +204,115 @@ func (r *rankingRepository) FindAll(contestID uint64, userID uint64) (domain.Ran
 	return rankings, nil
 }
 
-func (r *rankingRepository) GetAllLanguagesForContestAndUser(contestID uint64, userID uint64) (domain.LanguageCodes, error) {
+func (r *rankingRepository) FindAllForUpdate(ctx context.Context, contestID uint64, userID uint64) (domain.Rankings, error) {
+	var rankings []domain.Ranking
+
+	query := `
+		select id, contest_id, user_id, language_code, amount, created_at, updated_at
+		from rankings
+		where contest_id = $1 and user_id = $2
+		order by language_code asc
+		for update
+	`
+
+	err := r.sqlHandler.Select(ctx, &rankings, query, contestID, userID)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return rankings, nil
+}
+
+func (r *rankingRepository) FindAllForContest(ctx context.Context, contestID uint64) (domain.Rankings, error) {
+	var rankings []domain.Ranking
+
+	query := `
+		select id, contest_id, user_id, language_code, amount, created_at, updated_at
+		from rankings
+		where contest_id = $1
+		order by user_id asc, id asc
+	`
+
+	err := r.sqlHandler.Select(ctx, &rankings, query, contestID)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return rankings, nil
+}
+
+func (r *rankingRepository) FindAllForUser(ctx context.Context, userID uint64) (domain.Rankings, error) {
+	var rankings []domain.Ranking
+
+	query := `
+		select r.id, contest_id, user_id, u.display_name as user_display_name, language_code, amount, created_at, updated_at
+		from rankings as r
+		inner join users as u on u.id = r.user_id
+		where user_id = $1
+		order by contest_id asc, id asc
+	`
+
+	err := r.sqlHandler.Select(ctx, &rankings, query, userID)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return rankings, nil
+}
+
+func (r *rankingRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	query := `
+		delete from rankings
+		where user_id = $1
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID)
+	return domain.WrapError(err)
+}
+
+func (r *rankingRepository) FindProfileRankings(ctx context.Context, userID uint64) ([]domain.ProfileRanking, error) {
+	var rankings []domain.ProfileRanking
+
+	query := `
+		select
+			contest_id, contest_description, contest_start, contest_end, language_code, amount, rank,
+			(
+				select coalesce(sum(l.amount), 0)
+				from contest_logs as l
+				where
+					l.contest_id = ranked.contest_id and
+					l.user_id = ranked.user_id and
+					(ranked.language_code = $2 or l.language_code = ranked.language_code) and
+					l.deleted_at is null
+			) as logged_amount
+		from (
+			select
+				r.contest_id,
+				c.description as contest_description,
+				c.start as contest_start,
+				c."end" as contest_end,
+				r.user_id,
+				r.language_code,
+				r.amount,
+				rank() over (partition by r.contest_id, r.language_code order by r.amount desc) as rank
+			from rankings as r
+			inner join contests as c on c.id = r.contest_id
+			inner join users as u on u.id = r.user_id
+			where r.user_id = $1 or not coalesce((u.preferences->>'hide_from_rankings')::boolean, false)
+		) as ranked
+		where user_id = $1
+		order by contest_start asc, contest_id asc, language_code asc
+	`
+
+	err := r.sqlHandler.Select(ctx, &rankings, query, userID, domain.Global)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return rankings, nil
+}
+
+func (r *rankingRepository) GetAllLanguagesForContestAndUser(ctx context.Context, contestID uint64, userID uint64) (domain.LanguageCodes, error) {
 	var codes []domain.LanguageCode
 
 	query := `
@@ -146,7 +324,7 @@ func (r *rankingRepository) GetAllLanguagesForContestAndUser(contestID uint64, u
 			and language_code != $3
 	`
 
-	err := r.sqlHandler.Select(&codes, query, contestID, userID, domain.Global)
+	err := r.sqlHandler.Select(ctx, &codes, query, contestID, userID, domain.Global)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +332,7 @@ func (r *rankingRepository) GetAllLanguagesForContestAndUser(contestID uint64, u
 	return codes, nil
 }
 
-func (r *rankingRepository) CurrentRegistration(userID uint64) (domain.RankingRegistration, error) {
+func (r *rankingRepository) CurrentRegistration(ctx context.Context, userID uint64) (domain.RankingRegistration, error) {
 	var rows []struct {
 		ID           uint64
 		Start        time.Time
@@ -173,7 +351,7 @@ func (r *rankingRepository) CurrentRegistration(userID uint64) (domain.RankingRe
 		where rankings.user_id = $1 and rankings.language_code != 'GLO'
 	`
 
-	err := r.sqlHandler.Select(&rows, query, userID)
+	err := r.sqlHandler.Select(ctx, &rows, query, userID)
 	if err != nil {
 		return domain.RankingRegistration{}, domain.WrapError(err)
 	}
@@ -1,6 +1,7 @@
 package repositories_test
 
 import (
+	"context"
 	"sort"
 	"testing"
 	"time"
@@ -27,7 +28,7 @@ func TestRankingRepository_StoreRanking(t *testing.T) {
 	}
 
 	{
-		err := repo.Store(*ranking)
+		err := repo.Store(context.Background(), *ranking)
 		assert.NoError(t, err)
 	}
 
@@ -36,7 +37,7 @@ func TestRankingRepository_StoreRanking(t *testing.T) {
 			ID:     1,
 			Amount: 2,
 		}
-		err := repo.Store(*updatedRanking)
+		err := repo.Store(context.Background(), *updatedRanking)
 		assert.NoError(t, err)
 	}
 }
@@ -81,13 +82,13 @@ func TestRankingRepository_GetAllLanguagesForContestAndUser(t *testing.T) {
 
 	{
 		for _, r := range []*domain.Ranking{rankingJapanese, rankingChinese, rankingGlobal, rankingSingleLanguage} {
-			err := repo.Store(*r)
+			err := repo.Store(context.Background(), *r)
 			assert.NoError(t, err)
 		}
 	}
 
 	{
-		languages, err := repo.GetAllLanguagesForContestAndUser(1, 1)
+		languages, err := repo.GetAllLanguagesForContestAndUser(context.Background(), 1, 1)
 		assert.NoError(t, err)
 		assert.Equal(t, len(languages), 2)
 		assert.Equal(t, languages[0], domain.Japanese)
@@ -95,7 +96,7 @@ func TestRankingRepository_GetAllLanguagesForContestAndUser(t *testing.T) {
 	}
 
 	{
-		languages, err := repo.GetAllLanguagesForContestAndUser(1, 2)
+		languages, err := repo.GetAllLanguagesForContestAndUser(context.Background(), 1, 2)
 		assert.NoError(t, err)
 		assert.Equal(t, len(languages), 1)
 		assert.Equal(t, languages[0], domain.Chinese)
@@ -134,7 +135,7 @@ func TestRankingRepository_RankingsForContest(t *testing.T) {
 				Amount:    data.amount,
 			}
 
-			err := repo.Store(*ranking)
+			err := repo.Store(context.Background(), *ranking)
 			assert.NoError(t, err)
 		}
 	}
@@ -154,12 +155,12 @@ func TestRankingRepository_RankingsForContest(t *testing.T) {
 				Amount:    0,
 			}
 
-			err := repo.Store(*ranking)
+			err := repo.Store(context.Background(), *ranking)
 			assert.NoError(t, err)
 		}
 	}
 
-	rankings, err := repo.RankingsForContest(contestID, domain.Global)
+	rankings, err := repo.RankingsForContest(context.Background(), contestID, domain.Global)
 	assert.NoError(t, err)
 
 	assert.Equal(t, len(expected), len(rankings))
@@ -215,12 +216,12 @@ func TestRankingRepository_GlobalRankings(t *testing.T) {
 				Amount:    data.amount,
 			}
 
-			err := repo.Store(*ranking)
+			err := repo.Store(context.Background(), *ranking)
 			assert.NoError(t, err)
 		}
 	}
 
-	rankings, err := repo.GlobalRankings(domain.Global)
+	rankings, err := repo.GlobalRankings(context.Background(), domain.Global)
 	assert.NoError(t, err)
 
 	assert.Equal(t, len(expected), len(rankings))
@@ -234,6 +235,150 @@ func TestRankingRepository_GlobalRankings(t *testing.T) {
 		assert.Equal(t, expected.userDisplayName, ranking.UserDisplayName)
 	}
 }
+func TestRankingRepository_LoggedAmount(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewRankingRepository(sqlHandler)
+	logRepo := repositories.NewContestLogRepository(sqlHandler)
+
+	contestID := uint64(1)
+	user := createTestUsers(t, sqlHandler, 1)[0]
+
+	for _, ranking := range []domain.Ranking{
+		{ContestID: contestID, UserID: user.ID, Language: domain.Japanese, Amount: 12},
+		{ContestID: contestID, UserID: user.ID, Language: domain.Korean, Amount: 5},
+		{ContestID: contestID, UserID: user.ID, Language: domain.Global, Amount: 17},
+	} {
+		assert.NoError(t, repo.Store(context.Background(), ranking))
+	}
+
+	deleted := &domain.ContestLog{ContestID: contestID, UserID: user.ID, Language: domain.Japanese, MediumID: domain.MediumBook, Amount: 100}
+	for _, log := range []*domain.ContestLog{
+		{ContestID: contestID, UserID: user.ID, Language: domain.Japanese, MediumID: domain.MediumBook, Amount: 10},
+		{ContestID: contestID, UserID: user.ID, Language: domain.Japanese, MediumID: domain.MediumComic, Amount: 10},
+		{ContestID: contestID, UserID: user.ID, Language: domain.Korean, MediumID: domain.MediumBook, Amount: 5},
+		deleted,
+	} {
+		assert.NoError(t, logRepo.Store(context.Background(), log))
+	}
+	assert.NoError(t, logRepo.Delete(context.Background(), deleted.ID))
+
+	{
+		rankings, err := repo.FindAll(context.Background(), contestID, user.ID)
+		assert.NoError(t, err)
+
+		logged := map[domain.LanguageCode]float32{}
+		for _, ranking := range rankings {
+			logged[ranking.Language] = ranking.LoggedAmount
+		}
+		assert.Equal(t, map[domain.LanguageCode]float32{domain.Japanese: 20, domain.Korean: 5, domain.Global: 25}, logged)
+	}
+
+	{
+		rankings, err := repo.RankingsForContest(context.Background(), contestID, domain.Japanese)
+		assert.NoError(t, err)
+		assert.Len(t, rankings, 1)
+		assert.Equal(t, float32(20), rankings[0].LoggedAmount)
+	}
+
+	{
+		rankings, err := repo.GlobalRankings(context.Background(), domain.Global)
+		assert.NoError(t, err)
+		assert.Len(t, rankings, 1)
+		assert.Equal(t, float32(25), rankings[0].LoggedAmount)
+	}
+}
+
+func TestRankingRepository_HiddenUsers(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewRankingRepository(sqlHandler)
+	userRepo := repositories.NewUserRepository(sqlHandler)
+
+	contestID := uint64(1)
+	users := createTestUsers(t, sqlHandler, 2)
+
+	for _, user := range users {
+		err := repo.Store(context.Background(), domain.Ranking{ContestID: contestID, UserID: user.ID, Language: domain.Global, Amount: 10})
+		assert.NoError(t, err)
+	}
+
+	err := userRepo.UpdatePreferences(context.Background(), users[1].ID, domain.Preferences{HideFromRankings: true})
+	assert.NoError(t, err)
+
+	{
+		rankings, err := repo.RankingsForContest(context.Background(), contestID, domain.Global)
+		assert.NoError(t, err)
+		assert.Len(t, rankings, 1)
+		assert.Equal(t, users[0].ID, rankings[0].UserID)
+	}
+
+	{
+		rankings, err := repo.GlobalRankings(context.Background(), domain.Global)
+		assert.NoError(t, err)
+		assert.Len(t, rankings, 1)
+		assert.Equal(t, users[0].ID, rankings[0].UserID)
+	}
+
+	{
+		rankings, err := repo.FindAll(context.Background(), contestID, users[1].ID)
+		assert.NoError(t, err)
+		assert.Len(t, rankings, 1, "hidden users can still see their own rankings")
+	}
+}
+
+func TestRankingRepository_FindProfileRankings(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewRankingRepository(sqlHandler)
+	contestRepo := repositories.NewContestRepository(sqlHandler)
+	users := createTestUsers(t, sqlHandler, 3)
+
+	contest := &domain.Contest{
+		Description: "Round 2019-01",
+		Start:       time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2019, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	assert.NoError(t, contestRepo.Store(context.Background(), contest))
+
+	for i, amount := range []float32{10, 30, 20} {
+		err := repo.Store(context.Background(), domain.Ranking{ContestID: contest.ID, UserID: users[i].ID, Language: domain.Global, Amount: amount})
+		assert.NoError(t, err)
+	}
+
+	{
+		rankings, err := repo.FindProfileRankings(context.Background(), users[2].ID)
+		assert.NoError(t, err)
+		assert.Len(t, rankings, 1)
+		assert.Equal(t, contest.ID, rankings[0].ContestID)
+		assert.Equal(t, "Round 2019-01", rankings[0].ContestDescription)
+		assert.Equal(t, float32(20), rankings[0].Amount)
+		assert.Equal(t, 2, rankings[0].Rank)
+	}
+
+	{
+		// Hidden users don't count towards the rank of others
+		err := repositories.NewUserRepository(sqlHandler).UpdatePreferences(context.Background(), users[1].ID, domain.Preferences{HideFromRankings: true})
+		assert.NoError(t, err)
+
+		rankings, err := repo.FindProfileRankings(context.Background(), users[2].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, rankings[0].Rank)
+	}
+
+	{
+		// Hidden users still see their own rankings
+		rankings, err := repo.FindProfileRankings(context.Background(), users[1].ID)
+		assert.NoError(t, err)
+		assert.Len(t, rankings, 1)
+		assert.Equal(t, float32(30), rankings[0].Amount)
+		assert.Equal(t, 1, rankings[0].Rank)
+	}
+}
+
 func TestRankingRepository_FindAllByContestAndUser(t *testing.T) {
 	sqlHandler, cleanup := setupTestingSuite(t)
 	defer cleanup()
@@ -262,7 +407,7 @@ func TestRankingRepository_FindAllByContestAndUser(t *testing.T) {
 				Amount:    data.amount,
 			}
 
-			err := repo.Store(*ranking)
+			err := repo.Store(context.Background(), *ranking)
 			assert.NoError(t, err)
 		}
 	}
@@ -277,12 +422,12 @@ func TestRankingRepository_FindAllByContestAndUser(t *testing.T) {
 				Amount:    0,
 			}
 
-			err := repo.Store(*ranking)
+			err := repo.Store(context.Background(), *ranking)
 			assert.NoError(t, err)
 		}
 	}
 
-	rankings, err := repo.FindAll(contestID, users[0].ID)
+	rankings, err := repo.FindAll(context.Background(), contestID, users[0].ID)
 	assert.NoError(t, err)
 
 	for _, expected := range expected {
@@ -300,12 +445,45 @@ func TestRankingRepository_FindAllByContestAndUser(t *testing.T) {
 	}
 
 	{
-		rankings, err := repo.FindAll(0, 0)
+		rankings, err := repo.FindAll(context.Background(), 0, 0)
 		assert.NoError(t, err)
 		assert.Equal(t, 0, len(rankings))
 	}
 }
 
+func TestRankingRepository_FindAllForUser(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewRankingRepository(sqlHandler)
+	users := createTestUsers(t, sqlHandler, 2)
+
+	for _, contestID := range []uint64{1, 2} {
+		for _, user := range users {
+			err := repo.Store(context.Background(), domain.Ranking{ContestID: contestID, UserID: user.ID, Language: domain.Global})
+			assert.NoError(t, err)
+		}
+	}
+
+	rankings, err := repo.FindAllForUser(context.Background(), users[0].ID)
+	assert.NoError(t, err)
+	assert.Len(t, rankings, 2)
+	for _, ranking := range rankings {
+		assert.Equal(t, users[0].ID, ranking.UserID)
+	}
+
+	err = repo.DeleteAllForUser(context.Background(), users[0].ID)
+	assert.NoError(t, err)
+
+	rankings, err = repo.FindAllForUser(context.Background(), users[0].ID)
+	assert.NoError(t, err)
+	assert.Len(t, rankings, 0)
+
+	rankings, err = repo.FindAllForContest(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, rankings, 1, "rankings of other users stay")
+}
+
 func TestRankingRepository_UpdateAmounts(t *testing.T) {
 	sqlHandler, cleanup := setupTestingSuite(t)
 	defer cleanup()
@@ -324,14 +502,14 @@ func TestRankingRepository_UpdateAmounts(t *testing.T) {
 			Amount:    float32(i),
 		}
 
-		err := repo.Store(ranking)
+		err := repo.Store(context.Background(), ranking)
 		assert.NoError(t, err)
 	}
 
 	// Update rankings
 	updatedRankings := domain.Rankings{}
 	{
-		rankings, err := repo.FindAll(contestID, userID)
+		rankings, err := repo.FindAll(context.Background(), contestID, userID)
 		assert.NoError(t, err)
 
 		for _, r := range rankings {
@@ -341,13 +519,13 @@ func TestRankingRepository_UpdateAmounts(t *testing.T) {
 			})
 		}
 
-		err = repo.UpdateAmounts(updatedRankings)
+		err = repo.UpdateAmounts(context.Background(), updatedRankings)
 		assert.NoError(t, err)
 	}
 
 	// Check updated content
 	{
-		rankings, err := repo.FindAll(contestID, userID)
+		rankings, err := repo.FindAll(context.Background(), contestID, userID)
 		assert.NoError(t, err)
 
 		assert.Equal(t, len(updatedRankings), len(rankings))
@@ -363,6 +541,67 @@ func TestRankingRepository_UpdateAmounts(t *testing.T) {
 	}
 }
 
+func TestRankingRepository_AddAmounts(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewRankingRepository(sqlHandler)
+
+	contestID := uint64(1)
+	userID := uint64(1)
+
+	for _, language := range []domain.LanguageCode{domain.Japanese, domain.Korean, domain.Global} {
+		err := repo.Store(context.Background(), domain.Ranking{ContestID: contestID, UserID: userID, Language: language, Amount: 10})
+		assert.NoError(t, err)
+	}
+
+	// Happy path: amounts are added to what's already there
+	{
+		err := repo.AddAmounts(context.Background(), contestID, userID, map[domain.LanguageCode]float32{
+			domain.Japanese: 5,
+			domain.Korean:   -2,
+			domain.Global:   3,
+		})
+		assert.NoError(t, err)
+
+		rankings, err := repo.FindAllForUpdate(context.Background(), contestID, userID)
+		assert.NoError(t, err)
+
+		amounts := map[domain.LanguageCode]float32{}
+		for _, ranking := range rankings {
+			amounts[ranking.Language] = ranking.Amount
+		}
+		assert.Equal(t, map[domain.LanguageCode]float32{domain.Japanese: 15, domain.Korean: 8, domain.Global: 13}, amounts)
+	}
+
+	// Sad path: the user didn't sign up for the language
+	{
+		err := repo.AddAmounts(context.Background(), contestID, userID, map[domain.LanguageCode]float32{domain.Chinese: 5})
+		assert.EqualError(t, err, domain.ErrNotFound.Error())
+	}
+}
+
+func TestRankingRepository_FindAllForContest(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewRankingRepository(sqlHandler)
+
+	for _, contestID := range []uint64{1, 2} {
+		for _, userID := range []uint64{1, 2} {
+			err := repo.Store(context.Background(), domain.Ranking{ContestID: contestID, UserID: userID, Language: domain.Global})
+			assert.NoError(t, err)
+		}
+	}
+
+	rankings, err := repo.FindAllForContest(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, rankings, 2)
+	for _, ranking := range rankings {
+		assert.Equal(t, uint64(1), ranking.ContestID)
+	}
+}
+
 func TestRankingRepository_CurrentRegistration(t *testing.T) {
 	sqlHandler, cleanup := setupTestingSuite(t)
 	defer cleanup()
@@ -380,13 +619,13 @@ func TestRankingRepository_CurrentRegistration(t *testing.T) {
 	}
 
 	{
-		err := contestRepo.Store(contest)
+		err := contestRepo.Store(context.Background(), contest)
 		assert.NoError(t, err)
 	}
 
 	{
 		for _, l := range languages {
-			err := repo.Store(domain.Ranking{
+			err := repo.Store(context.Background(), domain.Ranking{
 				ContestID: contest.ID,
 				UserID:    user.ID,
 				Language:  l,
@@ -397,7 +636,7 @@ func TestRankingRepository_CurrentRegistration(t *testing.T) {
 	}
 
 	{
-		registration, err := repo.CurrentRegistration(user.ID)
+		registration, err := repo.CurrentRegistration(context.Background(), user.ID)
 		assert.NoError(t, err)
 		assert.Equal(t, contest.ID, registration.ContestID)
 		assert.Equal(t, contest.Start.UTC(), registration.Start.UTC())
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewRecoveryCodeRepository instantiates a new recovery code repository
+func NewRecoveryCodeRepository(sqlHandler rdb.SQLHandler) usecases.RecoveryCodeRepository {
+	return &recoveryCodeRepository{sqlHandler: sqlHandler}
+}
+
+type recoveryCodeRepository struct {
+	sqlHandler rdb.SQLHandler
+}
+
+func (r *recoveryCodeRepository) ReplaceAllForUser(ctx context.Context, userID uint64, hashes []string) error {
+	tx, err := r.sqlHandler.Begin(ctx)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	_, err = tx.Execute(ctx, `delete from recovery_codes where user_id = $1`, userID)
+	if err != nil {
+		_ = tx.Rollback()
+		return domain.WrapError(err)
+	}
+
+	query := `
+		insert into recovery_codes
+		(user_id, code_hash, created_at)
+		values ($1, $2, now() at time zone 'utc')
+	`
+
+	for _, hash := range hashes {
+		_, err := tx.Execute(ctx, query, userID, hash)
+
+		if err != nil {
+			_ = tx.Rollback()
+			return domain.WrapError(err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *recoveryCodeRepository) MarkAsUsed(ctx context.Context, userID uint64, hash string) error {
+	query := `
+		update recovery_codes
+		set used_at = now() at time zone 'utc'
+		where
+			user_id = $1 and
+			code_hash = $2 and
+			used_at is null
+	`
+
+	result, err := r.sqlHandler.Execute(ctx, query, userID, hash)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+	if rows != 1 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *recoveryCodeRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	query := `
+		delete from recovery_codes
+		where user_id = $1
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID)
+	return domain.WrapError(err)
+}
@@ -0,0 +1,48 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestRecoveryCodeRepository_ReplaceAndUse(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewRecoveryCodeRepository(sqlHandler)
+
+	{
+		err := repo.ReplaceAllForUser(context.Background(), 1, []string{domain.HashToken("foo"), domain.HashToken("bar")})
+		assert.NoError(t, err)
+
+		err = repo.MarkAsUsed(context.Background(), 1, domain.HashToken("foo"))
+		assert.NoError(t, err)
+
+		err = repo.MarkAsUsed(context.Background(), 1, domain.HashToken("foo"))
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "a code can only be used once")
+
+		err = repo.MarkAsUsed(context.Background(), 2, domain.HashToken("bar"))
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "codes belong to a single user")
+	}
+
+	{
+		err := repo.ReplaceAllForUser(context.Background(), 1, []string{domain.HashToken("baz")})
+		assert.NoError(t, err)
+
+		err = repo.MarkAsUsed(context.Background(), 1, domain.HashToken("bar"))
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "old codes are replaced")
+	}
+
+	{
+		err := repo.DeleteAllForUser(context.Background(), 1)
+		assert.NoError(t, err)
+
+		err = repo.MarkAsUsed(context.Background(), 1, domain.HashToken("baz"))
+		assert.EqualError(t, err, domain.ErrNotFound.Error())
+	}
+}
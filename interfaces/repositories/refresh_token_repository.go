@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewRefreshTokenRepository instantiates a new refresh token repository
+func NewRefreshTokenRepository(sqlHandler rdb.SQLHandler) usecases.RefreshTokenRepository {
+	return &refreshTokenRepository{sqlHandler: sqlHandler}
+}
+
+type refreshTokenRepository struct {
+	sqlHandler rdb.SQLHandler
+}
+
+func (r *refreshTokenRepository) Store(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		insert into refresh_tokens
+		(session_id, token_hash, created_at)
+		values ($1, $2, now() at time zone 'utc')
+		returning id
+	`
+
+	row := r.sqlHandler.QueryRow(ctx, query, token.SessionID, token.Hash)
+	err := row.Scan(&token.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return nil
+}
+
+func (r *refreshTokenRepository) FindByHash(ctx context.Context, hash string) (domain.RefreshToken, error) {
+	t := domain.RefreshToken{}
+
+	query := `
+		select id, session_id, token_hash, used_at, created_at
+		from refresh_tokens
+		where token_hash = $1
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, hash).StructScan(&t)
+	if err != nil {
+		return t, domain.WrapError(err)
+	}
+
+	return t, nil
+}
+
+func (r *refreshTokenRepository) MarkAsUsed(ctx context.Context, id uint64) error {
+	query := `
+		update refresh_tokens
+		set used_at = now() at time zone 'utc'
+		where
+			id = $1 and
+			used_at is null
+	`
+
+	result, err := r.sqlHandler.Execute(ctx, query, id)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+	if rows != 1 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestRefreshTokenRepository_StoreAndUseToken(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewRefreshTokenRepository(sqlHandler)
+	token := &domain.RefreshToken{
+		SessionID: 1,
+		Hash:      domain.HashToken("foobar"),
+	}
+
+	{
+		err := repo.Store(context.Background(), token)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uint64(0), token.ID)
+	}
+
+	{
+		found, err := repo.FindByHash(context.Background(), domain.HashToken("foobar"))
+		assert.NoError(t, err)
+		assert.Equal(t, token.ID, found.ID)
+		assert.Equal(t, token.SessionID, found.SessionID)
+		assert.False(t, found.IsUsed())
+	}
+
+	{
+		err := repo.MarkAsUsed(context.Background(), token.ID)
+		assert.NoError(t, err)
+
+		err = repo.MarkAsUsed(context.Background(), token.ID)
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "a token can only be exchanged once")
+
+		found, err := repo.FindByHash(context.Background(), domain.HashToken("foobar"))
+		assert.NoError(t, err)
+		assert.True(t, found.IsUsed())
+	}
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewSessionRepository instantiates a new session repository
+func NewSessionRepository(sqlHandler rdb.SQLHandler) usecases.SessionRepository {
+	return &sessionRepository{sqlHandler: sqlHandler}
+}
+
+type sessionRepository struct {
+	sqlHandler rdb.SQLHandler
+}
+
+func (r *sessionRepository) Store(ctx context.Context, session *domain.Session) error {
+	query := `
+		insert into sessions
+		(user_id, user_agent, ip_address, two_factor_verified, expires_at, last_seen_at, created_at)
+		values ($1, $2, $3, $4, $5, now() at time zone 'utc', now() at time zone 'utc')
+		returning id
+	`
+
+	row := r.sqlHandler.QueryRow(
+		ctx,
+		query,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.TwoFactorVerified,
+		session.ExpiresAt,
+	)
+	err := row.Scan(&session.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) FindByID(ctx context.Context, id uint64) (domain.Session, error) {
+	s := domain.Session{}
+
+	query := `
+		select id, user_id, user_agent, ip_address, two_factor_verified, expires_at, last_seen_at, revoked_at, created_at
+		from sessions
+		where id = $1
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, id).StructScan(&s)
+	if err != nil {
+		return s, domain.WrapError(err)
+	}
+
+	return s, nil
+}
+
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID uint64) (domain.Sessions, error) {
+	var sessions []domain.Session
+
+	query := `
+		select id, user_id, user_agent, ip_address, two_factor_verified, expires_at, last_seen_at, revoked_at, created_at
+		from sessions
+		where
+			user_id = $1 and
+			revoked_at is null and
+			expires_at > now() at time zone 'utc'
+		order by last_seen_at desc
+	`
+	err := r.sqlHandler.Select(ctx, &sessions, query, userID)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return sessions, nil
+}
+
+func (r *sessionRepository) Extend(ctx context.Context, session domain.Session) error {
+	query := `
+		update sessions
+		set
+			expires_at = $2,
+			user_agent = $3,
+			ip_address = $4,
+			last_seen_at = now() at time zone 'utc'
+		where
+			id = $1 and
+			revoked_at is null
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, session.ID, session.ExpiresAt, session.UserAgent, session.IPAddress)
+	return domain.WrapError(err)
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, id uint64) error {
+	query := `
+		update sessions
+		set last_seen_at = now() at time zone 'utc'
+		where
+			id = $1 and
+			revoked_at is null
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, id)
+	return domain.WrapError(err)
+}
+
+func (r *sessionRepository) Revoke(ctx context.Context, id uint64) error {
+	query := `
+		update sessions
+		set revoked_at = now() at time zone 'utc'
+		where
+			id = $1 and
+			revoked_at is null
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, id)
+	return domain.WrapError(err)
+}
+
+func (r *sessionRepository) RevokeAllForUser(ctx context.Context, userID uint64) error {
+	query := `
+		update sessions
+		set revoked_at = now() at time zone 'utc'
+		where
+			user_id = $1 and
+			revoked_at is null
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID)
+	return domain.WrapError(err)
+}
+
+func (r *sessionRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	query := `
+		with deleted as (
+			delete from sessions
+			where user_id = $1
+			returning id
+		)
+		delete from refresh_tokens
+		where session_id in (select id from deleted)
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID)
+	return domain.WrapError(err)
+}
@@ -0,0 +1,150 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestSessionRepository_StoreExtendRevoke(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewSessionRepository(sqlHandler)
+	session := &domain.Session{
+		UserID:    1,
+		UserAgent: "Mozilla/5.0",
+		IPAddress: "127.0.0.1",
+		ExpiresAt: time.Now().UTC().Add(1 * time.Hour),
+	}
+
+	{
+		err := repo.Store(context.Background(), session)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uint64(0), session.ID)
+	}
+
+	{
+		extended := *session
+		extended.ExpiresAt = time.Now().UTC().Add(2 * time.Hour)
+		extended.IPAddress = "10.0.0.1"
+		err := repo.Extend(context.Background(), extended)
+		assert.NoError(t, err)
+
+		found, err := repo.FindByID(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, extended.ExpiresAt, found.ExpiresAt, time.Second)
+		assert.Equal(t, "Mozilla/5.0", found.UserAgent)
+		assert.Equal(t, "10.0.0.1", found.IPAddress)
+		assert.True(t, found.IsActive(time.Now()))
+	}
+
+	{
+		err := repo.Touch(context.Background(), session.ID)
+		assert.NoError(t, err)
+
+		found, err := repo.FindByID(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().UTC(), found.LastSeenAt, time.Minute)
+	}
+
+	{
+		err := repo.Revoke(context.Background(), session.ID)
+		assert.NoError(t, err)
+
+		found, err := repo.FindByID(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.False(t, found.IsActive(time.Now()))
+	}
+}
+
+func TestSessionRepository_RevokeAllForUser(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewSessionRepository(sqlHandler)
+
+	sessions := []*domain.Session{
+		{UserID: 1, ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+		{UserID: 1, ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+		{UserID: 2, ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+	}
+	for _, session := range sessions {
+		err := repo.Store(context.Background(), session)
+		assert.NoError(t, err)
+	}
+
+	err := repo.RevokeAllForUser(context.Background(), 1)
+	assert.NoError(t, err)
+
+	for _, session := range sessions {
+		found, err := repo.FindByID(context.Background(), session.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, session.UserID != 1, found.IsActive(time.Now()))
+	}
+}
+
+func TestSessionRepository_FindActiveByUserID(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewSessionRepository(sqlHandler)
+
+	sessions := []*domain.Session{
+		{UserID: 1, ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+		{UserID: 1, ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+		{UserID: 1, ExpiresAt: time.Now().UTC().Add(-1 * time.Hour)},
+		{UserID: 2, ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+	}
+	for _, session := range sessions {
+		err := repo.Store(context.Background(), session)
+		assert.NoError(t, err)
+	}
+
+	err := repo.Revoke(context.Background(), sessions[1].ID)
+	assert.NoError(t, err)
+
+	active, err := repo.FindActiveByUserID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, active, 1)
+	assert.Equal(t, sessions[0].ID, active[0].ID)
+}
+
+func TestSessionRepository_DeleteAllForUser(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewSessionRepository(sqlHandler)
+	refreshRepo := repositories.NewRefreshTokenRepository(sqlHandler)
+
+	sessions := []*domain.Session{
+		{UserID: 1, ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+		{UserID: 2, ExpiresAt: time.Now().UTC().Add(1 * time.Hour)},
+	}
+	hashes := []string{domain.HashToken("a"), domain.HashToken("b")}
+	for i, session := range sessions {
+		err := repo.Store(context.Background(), session)
+		assert.NoError(t, err)
+
+		err = refreshRepo.Store(context.Background(), &domain.RefreshToken{SessionID: session.ID, Hash: hashes[i]})
+		assert.NoError(t, err)
+	}
+
+	err := repo.DeleteAllForUser(context.Background(), 1)
+	assert.NoError(t, err)
+
+	_, err = repo.FindByID(context.Background(), sessions[0].ID)
+	assert.EqualError(t, err, domain.ErrNotFound.Error())
+	_, err = refreshRepo.FindByHash(context.Background(), hashes[0])
+	assert.EqualError(t, err, domain.ErrNotFound.Error())
+
+	_, err = repo.FindByID(context.Background(), sessions[1].ID)
+	assert.NoError(t, err)
+	_, err = refreshRepo.FindByHash(context.Background(), hashes[1])
+	assert.NoError(t, err)
+}
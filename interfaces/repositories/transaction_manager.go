@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewTransactionManager instantiates a new transaction manager
+func NewTransactionManager(sqlHandler rdb.SQLHandler) usecases.TransactionManager {
+	return &transactionManager{sqlHandler: sqlHandler}
+}
+
+type transactionManager struct {
+	sqlHandler rdb.SQLHandler
+}
+
+func (m *transactionManager) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := rdb.TransactionFromContext(ctx); ok {
+		// Whoever started the transaction gets to commit it
+		return fn(ctx)
+	}
+
+	tx, err := m.sqlHandler.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(rdb.WithTransaction(ctx, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
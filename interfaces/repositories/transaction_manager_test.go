@@ -0,0 +1,79 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestTransactionManager_Run(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	transactions := repositories.NewTransactionManager(sqlHandler)
+	contestRepo := repositories.NewContestRepository(sqlHandler)
+	rankingRepo := repositories.NewRankingRepository(sqlHandler)
+	users := createTestUsers(t, sqlHandler, 1)
+
+	contest := &domain.Contest{
+		Description: "Round 2019-05",
+		Start:       time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2019, 5, 31, 0, 0, 0, 0, time.UTC),
+		Open:        true,
+	}
+	assert.NoError(t, contestRepo.Store(context.Background(), contest))
+
+	{
+		// Sad path: nothing is stored when the unit of work fails
+		err := transactions.Run(context.Background(), func(ctx context.Context) error {
+			ranking := domain.Ranking{ContestID: contest.ID, UserID: users[0].ID, Language: domain.Japanese}
+			if err := rankingRepo.Store(ctx, ranking); err != nil {
+				return err
+			}
+
+			return errors.New("something went wrong")
+		})
+		assert.EqualError(t, err, "something went wrong")
+
+		rankings, err := rankingRepo.FindAll(context.Background(), contest.ID, users[0].ID)
+		assert.NoError(t, err)
+		assert.Empty(t, rankings)
+	}
+
+	{
+		// Happy path: changes of every repository are committed together
+		err := transactions.Run(context.Background(), func(ctx context.Context) error {
+			for _, language := range []domain.LanguageCode{domain.Japanese, domain.Global} {
+				ranking := domain.Ranking{ContestID: contest.ID, UserID: users[0].ID, Language: language}
+				if err := rankingRepo.Store(ctx, ranking); err != nil {
+					return err
+				}
+			}
+
+			rankings, err := rankingRepo.FindAll(ctx, contest.ID, users[0].ID)
+			if err != nil {
+				return err
+			}
+			for i := range rankings {
+				rankings[i].Amount = 10
+			}
+
+			// Uses the transaction that's running
+			return rankingRepo.UpdateAmounts(ctx, rankings)
+		})
+		assert.NoError(t, err)
+
+		rankings, err := rankingRepo.FindAll(context.Background(), contest.ID, users[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, rankings, 2)
+		for _, ranking := range rankings {
+			assert.Equal(t, float32(10), ranking.Amount)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewTwoFactorAuthenticationRepository instantiates a new two factor authentication repository
+func NewTwoFactorAuthenticationRepository(sqlHandler rdb.SQLHandler) usecases.TwoFactorAuthenticationRepository {
+	return &twoFactorAuthenticationRepository{sqlHandler: sqlHandler}
+}
+
+type twoFactorAuthenticationRepository struct {
+	sqlHandler rdb.SQLHandler
+}
+
+// Store starts a new enrollment, any previous secret for the user is replaced
+func (r *twoFactorAuthenticationRepository) Store(ctx context.Context, twoFactor *domain.TwoFactorAuthentication) error {
+	query := `
+		insert into two_factor_authentications
+		(user_id, secret, last_used_step, confirmed_at, created_at)
+		values ($1, $2, 0, null, now() at time zone 'utc')
+		on conflict (user_id) do update set
+			secret = excluded.secret,
+			last_used_step = excluded.last_used_step,
+			confirmed_at = excluded.confirmed_at,
+			created_at = excluded.created_at
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, twoFactor.UserID, twoFactor.Secret)
+	return domain.WrapError(err)
+}
+
+func (r *twoFactorAuthenticationRepository) FindByUserID(ctx context.Context, userID uint64) (domain.TwoFactorAuthentication, error) {
+	t := domain.TwoFactorAuthentication{}
+
+	query := `
+		select user_id, secret, last_used_step, confirmed_at, created_at
+		from two_factor_authentications
+		where user_id = $1
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, userID).StructScan(&t)
+	if err != nil {
+		return t, domain.WrapError(err)
+	}
+
+	return t, nil
+}
+
+func (r *twoFactorAuthenticationRepository) Confirm(ctx context.Context, userID uint64) error {
+	query := `
+		update two_factor_authentications
+		set confirmed_at = now() at time zone 'utc'
+		where user_id = $1
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID)
+	return domain.WrapError(err)
+}
+
+func (r *twoFactorAuthenticationRepository) UpdateSecret(ctx context.Context, userID uint64, secret string) error {
+	query := `
+		update two_factor_authentications
+		set secret = $2
+		where user_id = $1
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID, secret)
+	return domain.WrapError(err)
+}
+
+// UpdateLastUsedStep only moves forward, so a code can't be used twice even by concurrent requests
+func (r *twoFactorAuthenticationRepository) UpdateLastUsedStep(ctx context.Context, userID uint64, step int64) error {
+	query := `
+		update two_factor_authentications
+		set last_used_step = $2
+		where
+			user_id = $1 and
+			last_used_step < $2
+	`
+
+	result, err := r.sqlHandler.Execute(ctx, query, userID, step)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+	if rows != 1 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *twoFactorAuthenticationRepository) Delete(ctx context.Context, userID uint64) error {
+	query := `
+		delete from two_factor_authentications
+		where user_id = $1
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, userID)
+	return domain.WrapError(err)
+}
@@ -0,0 +1,72 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestTwoFactorAuthenticationRepository_Enrollment(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewTwoFactorAuthenticationRepository(sqlHandler)
+
+	{
+		err := repo.Store(context.Background(), &domain.TwoFactorAuthentication{UserID: 1, Secret: "FOOBAR"})
+		assert.NoError(t, err)
+
+		found, err := repo.FindByUserID(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "FOOBAR", found.Secret)
+		assert.False(t, found.IsEnabled())
+	}
+
+	{
+		err := repo.Confirm(context.Background(), 1)
+		assert.NoError(t, err)
+
+		err = repo.UpdateLastUsedStep(context.Background(), 1, 10)
+		assert.NoError(t, err)
+
+		err = repo.UpdateLastUsedStep(context.Background(), 1, 10)
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "a code can only be used once")
+
+		found, err := repo.FindByUserID(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.True(t, found.IsEnabled())
+		assert.Equal(t, int64(10), found.LastUsedStep)
+	}
+
+	{
+		err := repo.UpdateSecret(context.Background(), 1, "v1:FOOBAR")
+		assert.NoError(t, err)
+
+		found, err := repo.FindByUserID(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "v1:FOOBAR", found.Secret)
+		assert.True(t, found.IsEnabled(), "changing the secret keeps two factor authentication enabled")
+	}
+
+	{
+		err := repo.Store(context.Background(), &domain.TwoFactorAuthentication{UserID: 1, Secret: "BARBAR"})
+		assert.NoError(t, err)
+
+		found, err := repo.FindByUserID(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "BARBAR", found.Secret)
+		assert.False(t, found.IsEnabled(), "enrolling again starts over")
+	}
+
+	{
+		err := repo.Delete(context.Background(), 1)
+		assert.NoError(t, err)
+
+		_, err = repo.FindByUserID(context.Background(), 1)
+		assert.EqualError(t, err, domain.ErrNotFound.Error())
+	}
+}
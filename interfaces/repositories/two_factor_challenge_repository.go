@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/rdb"
+	"github.com/tadoku/api/usecases"
+)
+
+// NewTwoFactorChallengeRepository instantiates a new two factor challenge repository
+func NewTwoFactorChallengeRepository(sqlHandler rdb.SQLHandler) usecases.TwoFactorChallengeRepository {
+	return &twoFactorChallengeRepository{sqlHandler: sqlHandler}
+}
+
+type twoFactorChallengeRepository struct {
+	sqlHandler rdb.SQLHandler
+}
+
+func (r *twoFactorChallengeRepository) Store(ctx context.Context, challenge *domain.TwoFactorChallenge) error {
+	query := `
+		insert into two_factor_challenges
+		(user_id, token_hash, expires_at, created_at)
+		values ($1, $2, $3, now() at time zone 'utc')
+		returning id
+	`
+
+	row := r.sqlHandler.QueryRow(ctx, query, challenge.UserID, challenge.Hash, challenge.ExpiresAt)
+	err := row.Scan(&challenge.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return nil
+}
+
+func (r *twoFactorChallengeRepository) FindByHash(ctx context.Context, hash string) (domain.TwoFactorChallenge, error) {
+	c := domain.TwoFactorChallenge{}
+
+	query := `
+		select id, user_id, token_hash, failed_attempts, expires_at, used_at, created_at
+		from two_factor_challenges
+		where token_hash = $1
+	`
+	err := r.sqlHandler.QueryRow(ctx, query, hash).StructScan(&c)
+	if err != nil {
+		return c, domain.WrapError(err)
+	}
+
+	return c, nil
+}
+
+func (r *twoFactorChallengeRepository) RecordFailedAttempt(ctx context.Context, id uint64) error {
+	query := `
+		update two_factor_challenges
+		set failed_attempts = failed_attempts + 1
+		where id = $1
+	`
+
+	_, err := r.sqlHandler.Execute(ctx, query, id)
+	return domain.WrapError(err)
+}
+
+func (r *twoFactorChallengeRepository) MarkAsUsed(ctx context.Context, id uint64) error {
+	query := `
+		update two_factor_challenges
+		set used_at = now() at time zone 'utc'
+		where
+			id = $1 and
+			used_at is null
+	`
+
+	result, err := r.sqlHandler.Execute(ctx, query, id)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+	if rows != 1 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/repositories"
+)
+
+func TestTwoFactorChallengeRepository_StoreAndUse(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewTwoFactorChallengeRepository(sqlHandler)
+	challenge := &domain.TwoFactorChallenge{
+		UserID:    1,
+		Hash:      domain.HashToken("foobar"),
+		ExpiresAt: time.Now().UTC().Add(5 * time.Minute),
+	}
+
+	{
+		err := repo.Store(context.Background(), challenge)
+		assert.NoError(t, err)
+		assert.NotEqual(t, uint64(0), challenge.ID)
+	}
+
+	{
+		err := repo.RecordFailedAttempt(context.Background(), challenge.ID)
+		assert.NoError(t, err)
+
+		found, err := repo.FindByHash(context.Background(), domain.HashToken("foobar"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, found.FailedAttempts)
+		assert.True(t, found.IsUsable(time.Now()))
+	}
+
+	{
+		err := repo.MarkAsUsed(context.Background(), challenge.ID)
+		assert.NoError(t, err)
+
+		err = repo.MarkAsUsed(context.Background(), challenge.ID)
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "a challenge can only be completed once")
+	}
+}
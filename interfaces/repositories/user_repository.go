@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"context"
+	"strings"
+
 	"github.com/tadoku/api/domain"
 	"github.com/tadoku/api/interfaces/rdb"
 	"github.com/tadoku/api/usecases"
@@ -15,15 +18,15 @@ type userRepository struct {
 	sqlHandler rdb.SQLHandler
 }
 
-func (r *userRepository) Store(user *domain.User) error {
+func (r *userRepository) Store(ctx context.Context, user *domain.User) error {
 	if user.ID == 0 {
-		return r.create(user)
+		return r.create(ctx, user)
 	}
 
-	return r.update(user)
+	return r.update(ctx, user)
 }
 
-func (r *userRepository) create(user *domain.User) error {
+func (r *userRepository) create(ctx context.Context, user *domain.User) error {
 	query := `
 		insert into users
 		(email, display_name, password, role, preferences)
@@ -31,7 +34,7 @@ func (r *userRepository) create(user *domain.User) error {
 		returning id
 	`
 
-	row := r.sqlHandler.QueryRow(query, user.Email, user.DisplayName, user.Password, user.Role, user.Preferences)
+	row := r.sqlHandler.QueryRow(ctx, query, user.Email, user.DisplayName, user.Password, user.Role, user.Preferences)
 	err := row.Scan(&user.ID)
 	if err != nil {
 		return domain.WrapError(err)
@@ -40,7 +43,7 @@ func (r *userRepository) create(user *domain.User) error {
 	return nil
 }
 
-func (r *userRepository) update(user *domain.User) error {
+func (r *userRepository) update(ctx context.Context, user *domain.User) error {
 	query := `
 		update users
 		set
@@ -48,30 +51,30 @@ func (r *userRepository) update(user *domain.User) error {
 			preferences = :preferences
 		where id = :id
 	`
-	_, err := r.sqlHandler.NamedExecute(query, user)
+	_, err := r.sqlHandler.NamedExecute(ctx, query, user)
 	return domain.WrapError(err)
 }
 
-func (r *userRepository) UpdatePassword(user *domain.User) error {
+func (r *userRepository) UpdatePassword(ctx context.Context, user *domain.User) error {
 	query := `
 		update users
 		set
 			password = :password
 		where id = :id
 	`
-	_, err := r.sqlHandler.NamedExecute(query, user)
+	_, err := r.sqlHandler.NamedExecute(ctx, query, user)
 	return domain.WrapError(err)
 }
 
-func (r *userRepository) FindByID(id uint64) (domain.User, error) {
+func (r *userRepository) FindByID(ctx context.Context, id uint64) (domain.User, error) {
 	u := domain.User{}
 
 	query := `
-		select id, email, display_name, role, preferences
+		select id, email, display_name, role, preferences, email_verified_at, disabled_reason, disabled_at, disabled_role, created_at
 		from users
 		where id = $1
 	`
-	err := r.sqlHandler.QueryRow(query, id).StructScan(&u)
+	err := r.sqlHandler.QueryRow(ctx, query, id).StructScan(&u)
 	if err != nil {
 		return u, domain.WrapError(err)
 	}
@@ -79,18 +82,155 @@ func (r *userRepository) FindByID(id uint64) (domain.User, error) {
 	return u, nil
 }
 
-func (r *userRepository) FindByEmail(email string) (domain.User, error) {
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (domain.User, error) {
 	u := domain.User{}
 
 	query := `
-		select id, email, password, display_name, role, preferences
+		select id, email, password, display_name, role, preferences, email_verified_at, disabled_reason, disabled_at, disabled_role, created_at
 		from users
 		where email = $1
 	`
-	err := r.sqlHandler.QueryRow(query, email).StructScan(&u)
+	err := r.sqlHandler.QueryRow(ctx, query, email).StructScan(&u)
 	if err != nil {
 		return u, domain.WrapError(err)
 	}
 
 	return u, nil
 }
+
+func (r *userRepository) MarkEmailAsVerified(ctx context.Context, id uint64) error {
+	query := `
+		update users
+		set email_verified_at = now() at time zone 'utc'
+		where
+			id = $1 and
+			email_verified_at is null
+	`
+	_, err := r.sqlHandler.Execute(ctx, query, id)
+	return domain.WrapError(err)
+}
+
+// likeEscaper makes sure user input is matched literally in a like pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *userRepository) Search(ctx context.Context, search string, limit, offset int) (domain.Users, error) {
+	var users []domain.User
+
+	query := `
+		select id, email, display_name, role, preferences, email_verified_at, disabled_reason, disabled_at, disabled_role, created_at
+		from users
+		where email ilike $1 or display_name ilike $1
+		order by id asc
+		limit $2
+		offset $3
+	`
+	pattern := "%" + likeEscaper.Replace(strings.TrimSpace(search)) + "%"
+	err := r.sqlHandler.Select(ctx, &users, query, pattern, limit, offset)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return users, nil
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id uint64, role domain.Role) error {
+	query := `
+		update users
+		set role = $2
+		where
+			id = $1 and
+			role <> $3
+	`
+	return r.executeForUser(ctx, query, id, role, domain.RoleDisabled)
+}
+
+func (r *userRepository) Disable(ctx context.Context, id uint64, reason string) error {
+	// Disabling twice shouldn't make the user forget the role they had
+	query := `
+		update users
+		set
+			disabled_role = case when role = $2 then disabled_role else role end,
+			role = $2,
+			disabled_reason = $3,
+			disabled_at = now() at time zone 'utc'
+		where id = $1
+	`
+	return r.executeForUser(ctx, query, id, domain.RoleDisabled, reason)
+}
+
+func (r *userRepository) Enable(ctx context.Context, id uint64) error {
+	// Deleted accounts don't have a role to go back to
+	query := `
+		update users
+		set
+			role = disabled_role,
+			disabled_role = $3,
+			disabled_reason = '',
+			disabled_at = null
+		where
+			id = $1 and
+			role = $2 and
+			disabled_role >= $4
+	`
+	return r.executeForUser(ctx, query, id, domain.RoleDisabled, domain.RoleGuest, domain.RoleUser)
+}
+
+func (r *userRepository) UpdatePreferences(ctx context.Context, id uint64, preferences domain.Preferences) error {
+	query := `
+		update users
+		set preferences = $2
+		where id = $1
+	`
+	return r.executeForUser(ctx, query, id, preferences)
+}
+
+func (r *userRepository) UpdateEmail(ctx context.Context, id uint64, email string) error {
+	// The new address has been confirmed by following the link that was sent to it, disabled and deleted accounts keep theirs
+	query := `
+		update users
+		set
+			email = $2,
+			email_verified_at = now() at time zone 'utc'
+		where
+			id = $1 and
+			role <> $3
+	`
+	return r.executeForUser(ctx, query, id, email, domain.RoleDisabled)
+}
+
+func (r *userRepository) Anonymize(ctx context.Context, id uint64) error {
+	// The email address has to stay unique, and nobody should be able to log in anymore
+	query := `
+		update users
+		set
+			email = 'deleted-' || id || '@users.tadoku.invalid',
+			display_name = 'Deleted user',
+			password = '',
+			role = $2,
+			preferences = '{}'::jsonb,
+			email_verified_at = null,
+			disabled_reason = 'account deleted',
+			disabled_at = now() at time zone 'utc',
+			disabled_role = $3
+		where id = $1
+	`
+	return r.executeForUser(ctx, query, id, domain.RoleDisabled, domain.RoleGuest)
+}
+
+// executeForUser runs an update for a single user, and fails with domain.ErrNotFound when nothing was updated
+func (r *userRepository) executeForUser(ctx context.Context, query string, id uint64, args ...interface{}) error {
+	result, err := r.sqlHandler.Execute(ctx, query, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+	if rows != 1 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package repositories_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,18 +23,18 @@ func TestUserRepository_StoreUser(t *testing.T) {
 	}
 
 	{
-		err := repo.Store(user)
+		err := repo.Store(context.Background(), user)
 		assert.NoError(t, err)
 	}
 
 	{
 		user.DisplayName = "John Smith"
-		err := repo.Store(user)
+		err := repo.Store(context.Background(), user)
 		assert.NoError(t, err)
 	}
 
 	{
-		dbUser, err := repo.FindByID(user.ID)
+		dbUser, err := repo.FindByID(context.Background(), user.ID)
 		assert.NoError(t, err)
 		assert.Equal(t, dbUser, domain.User{
 			ID:          user.ID,
@@ -42,11 +43,12 @@ func TestUserRepository_StoreUser(t *testing.T) {
 			Password:    "",
 			Role:        user.Role,
 			Preferences: &domain.Preferences{},
+			CreatedAt:   dbUser.CreatedAt,
 		})
 	}
 
 	{
-		dbUser, err := repo.FindByEmail("foo@example.com")
+		dbUser, err := repo.FindByEmail(context.Background(), "foo@example.com")
 		assert.NoError(t, err)
 		assert.Equal(t, dbUser, domain.User{
 			ID:          user.ID,
@@ -55,12 +57,210 @@ func TestUserRepository_StoreUser(t *testing.T) {
 			Password:    "foobar",
 			Role:        user.Role,
 			Preferences: &domain.Preferences{},
+			CreatedAt:   dbUser.CreatedAt,
 		})
 	}
 
+	{
+		dbUser, err := repo.FindByID(context.Background(), user.ID)
+		assert.NoError(t, err)
+		assert.False(t, dbUser.CreatedAt.IsZero())
+	}
+
 	{
 		user.Password = "barfoo"
-		err := repo.UpdatePassword(user)
+		err := repo.UpdatePassword(context.Background(), user)
+		assert.NoError(t, err)
+	}
+}
+
+func TestUserRepository_MarkEmailAsVerified(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewUserRepository(sqlHandler)
+	user := createTestUsers(t, sqlHandler, 1)[0]
+
+	{
+		dbUser, err := repo.FindByID(context.Background(), user.ID)
+		assert.NoError(t, err)
+		assert.False(t, dbUser.IsEmailVerified())
+	}
+
+	{
+		err := repo.MarkEmailAsVerified(context.Background(), user.ID)
+		assert.NoError(t, err)
+
+		dbUser, err := repo.FindByID(context.Background(), user.ID)
+		assert.NoError(t, err)
+		assert.True(t, dbUser.IsEmailVerified())
+	}
+}
+
+func TestUserRepository_Search(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewUserRepository(sqlHandler)
+	users := createTestUsers(t, sqlHandler, 3)
+
+	{
+		found, err := repo.Search(context.Background(), "", 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, found, 3)
+	}
+
+	{
+		found, err := repo.Search(context.Background(), "foo+1@", 10, 0)
 		assert.NoError(t, err)
+		assert.Len(t, found, 1)
+		assert.Equal(t, users[1].ID, found[0].ID)
 	}
+
+	{
+		found, err := repo.Search(context.Background(), "%", 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, found, "wildcards are matched literally")
+	}
+
+	{
+		found, err := repo.Search(context.Background(), "", 2, 2)
+		assert.NoError(t, err)
+		assert.Len(t, found, 1)
+	}
+}
+
+func TestUserRepository_DisableAndEnable(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewUserRepository(sqlHandler)
+	user := createTestUsers(t, sqlHandler, 1)[0]
+
+	{
+		err := repo.Disable(context.Background(), user.ID, "spam")
+		assert.NoError(t, err)
+
+		dbUser, err := repo.FindByID(context.Background(), user.ID)
+		assert.NoError(t, err)
+		assert.True(t, dbUser.IsDisabled())
+		assert.Equal(t, "spam", dbUser.DisabledReason)
+		assert.NotNil(t, dbUser.DisabledAt)
+	}
+
+	{
+		err := repo.Enable(context.Background(), user.ID)
+		assert.NoError(t, err)
+
+		dbUser, err := repo.FindByID(context.Background(), user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RoleUser, dbUser.Role)
+		assert.Empty(t, dbUser.DisabledReason)
+		assert.Nil(t, dbUser.DisabledAt)
+
+		err = repo.Enable(context.Background(), user.ID)
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "only disabled users can be enabled")
+	}
+
+	{
+		err := repo.UpdateRole(context.Background(), user.ID, domain.RoleAdmin)
+		assert.NoError(t, err)
+
+		dbUser, err := repo.FindByID(context.Background(), user.ID)
+		assert.NoError(t, err)
+		assert.True(t, dbUser.IsAdmin())
+	}
+
+	{
+		// Admins stay admin after getting disabled twice and enabled again
+		assert.NoError(t, repo.Disable(context.Background(), user.ID, "spam"))
+		assert.NoError(t, repo.Disable(context.Background(), user.ID, "more spam"))
+
+		err := repo.UpdateRole(context.Background(), user.ID, domain.RoleUser)
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "disabled users can't change role")
+
+		err = repo.Enable(context.Background(), user.ID)
+		assert.NoError(t, err)
+
+		dbUser, err := repo.FindByID(context.Background(), user.ID)
+		assert.NoError(t, err)
+		assert.True(t, dbUser.IsAdmin())
+		assert.Equal(t, domain.RoleGuest, dbUser.DisabledRole)
+	}
+}
+
+func TestUserRepository_Anonymize(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewUserRepository(sqlHandler)
+	user := createTestUsers(t, sqlHandler, 1)[0]
+
+	err := repo.Anonymize(context.Background(), user.ID)
+	assert.NoError(t, err)
+
+	dbUser, err := repo.FindByID(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, user.Email, dbUser.Email)
+	assert.Equal(t, "Deleted user", dbUser.DisplayName)
+	assert.True(t, dbUser.IsDisabled())
+
+	_, err = repo.FindByEmail(context.Background(), user.Email)
+	assert.EqualError(t, err, domain.ErrNotFound.Error())
+
+	err = repo.Enable(context.Background(), user.ID)
+	assert.EqualError(t, err, domain.ErrNotFound.Error(), "deleted users can't be enabled")
+
+	err = repo.Anonymize(context.Background(), user.ID+100)
+	assert.EqualError(t, err, domain.ErrNotFound.Error())
+}
+
+func TestUserRepository_UpdateEmail(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewUserRepository(sqlHandler)
+	users := createTestUsers(t, sqlHandler, 2)
+
+	{
+		err := repo.UpdateEmail(context.Background(), users[0].ID, "new@example.com")
+		assert.NoError(t, err)
+
+		dbUser, err := repo.FindByID(context.Background(), users[0].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", dbUser.Email)
+		assert.True(t, dbUser.IsEmailVerified())
+	}
+
+	{
+		err := repo.UpdateEmail(context.Background(), users[1].ID, "new@example.com")
+		assert.EqualError(t, err, domain.ErrAlreadyExists.Error())
+	}
+
+	{
+		err := repo.Disable(context.Background(), users[1].ID, "spam")
+		assert.NoError(t, err)
+
+		err = repo.UpdateEmail(context.Background(), users[1].ID, "other@example.com")
+		assert.EqualError(t, err, domain.ErrNotFound.Error(), "disabled users keep their address")
+	}
+}
+
+func TestUserRepository_UpdatePreferences(t *testing.T) {
+	sqlHandler, cleanup := setupTestingSuite(t)
+	defer cleanup()
+
+	repo := repositories.NewUserRepository(sqlHandler)
+	user := createTestUsers(t, sqlHandler, 1)[0]
+
+	preferences := domain.Preferences{Timezone: "Asia/Tokyo", DefaultLanguage: domain.Japanese, Units: domain.UnitsPages}
+	err := repo.UpdatePreferences(context.Background(), user.ID, preferences)
+	assert.NoError(t, err)
+
+	dbUser, err := repo.FindByID(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, preferences, *dbUser.Preferences)
+
+	err = repo.UpdatePreferences(context.Background(), user.ID+100, preferences)
+	assert.EqualError(t, err, domain.ErrNotFound.Error())
 }
@@ -40,7 +40,7 @@ func (s *contestLogService) Create(ctx Context) error {
 
 	log.UserID = user.ID
 
-	if err := s.RankingInteractor.CreateLog(*log); err != nil {
+	if err := s.RankingInteractor.CreateLog(ctx.RequestContext(), *log); err != nil {
 		return domain.WrapError(err)
 	}
 
@@ -53,7 +53,9 @@ func (s *contestLogService) Update(ctx Context) error {
 		return domain.WrapError(err)
 	}
 
-	ctx.BindID(&log.ID)
+	if err := ctx.BindID(&log.ID); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
 
 	user, err := ctx.User()
 	if err != nil {
@@ -62,9 +64,9 @@ func (s *contestLogService) Update(ctx Context) error {
 
 	log.UserID = user.ID
 
-	if err := s.RankingInteractor.UpdateLog(*log); err != nil {
+	if err := s.RankingInteractor.UpdateLog(ctx.RequestContext(), *log); err != nil {
 		if err == domain.ErrInsufficientPermissions {
-			return ctx.NoContent(http.StatusForbidden)
+			return problem(ctx, http.StatusForbidden, err)
 		}
 
 		return domain.WrapError(err)
@@ -75,19 +77,21 @@ func (s *contestLogService) Update(ctx Context) error {
 
 func (s *contestLogService) Delete(ctx Context) error {
 	var id uint64
-	ctx.BindID(&id)
+	if err := ctx.BindID(&id); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
 
 	user, err := ctx.User()
 	if err != nil {
 		return domain.WrapError(err)
 	}
 
-	if err := s.RankingInteractor.DeleteLog(id, user.ID); err != nil {
+	if err := s.RankingInteractor.DeleteLog(ctx.RequestContext(), id, user.ID); err != nil {
 		if err == domain.ErrInsufficientPermissions {
-			return ctx.NoContent(http.StatusForbidden)
+			return problem(ctx, http.StatusForbidden, err)
 		}
 		if err == domain.ErrNotFound {
-			return ctx.NoContent(http.StatusNotFound)
+			return problem(ctx, http.StatusNotFound, err)
 		}
 
 		return domain.WrapError(err)
@@ -99,22 +103,23 @@ func (s *contestLogService) Delete(ctx Context) error {
 func (s *contestLogService) Get(ctx Context) error {
 	contestID, err := strconv.ParseUint(ctx.QueryParam("contest_id"), 10, 64)
 	if err != nil {
-		return domain.WrapError(err)
+		return problem(ctx, http.StatusBadRequest, err)
 	}
 
 	userID, err := strconv.ParseUint(ctx.QueryParam("user_id"), 10, 64)
 	if err != nil {
-		return domain.WrapError(err)
+		return problem(ctx, http.StatusBadRequest, err)
 	}
 
-	logs, err := s.RankingInteractor.ContestLogs(contestID, userID)
+	viewerID, units := viewer(ctx)
+	logs, err := s.RankingInteractor.ContestLogs(ctx.RequestContext(), contestID, userID, viewerID)
 	if err != nil {
 		if err == usecases.ErrNoContestLogsFound {
-			return ctx.NoContent(http.StatusNotFound)
+			return problem(ctx, http.StatusNotFound, err)
 		}
 
 		return domain.WrapError(err)
 	}
 
-	return ctx.JSON(http.StatusOK, logs.GetView())
+	return ctx.JSON(http.StatusOK, logs.GetView(units))
 }
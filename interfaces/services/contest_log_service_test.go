@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -22,13 +23,13 @@ func TestContestLogService_Create(t *testing.T) {
 		MediumID:  1,
 	}
 
-	ctx := services.NewMockContext(ctrl)
+	ctx := newMockContext(ctrl)
 	ctx.EXPECT().NoContent(201)
 	ctx.EXPECT().User().Return(&domain.User{ID: 1}, nil)
 	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *log)
 
 	i := usecases.NewMockRankingInteractor(ctrl)
-	i.EXPECT().CreateLog(domain.ContestLog{ContestID: 1, UserID: 1, Language: domain.Japanese, Amount: 10, MediumID: 1}).Return(nil)
+	i.EXPECT().CreateLog(gomock.Any(), domain.ContestLog{ContestID: 1, UserID: 1, Language: domain.Japanese, Amount: 10, MediumID: 1}).Return(nil)
 
 	s := services.NewContestLogService(i)
 	err := s.Create(ctx)
@@ -48,14 +49,14 @@ func TestContestLogService_Update(t *testing.T) {
 			MediumID:  1,
 		}
 
-		ctx := services.NewMockContext(ctrl)
+		ctx := newMockContext(ctrl)
 		ctx.EXPECT().NoContent(204)
 		ctx.EXPECT().User().Return(&domain.User{ID: 1}, nil)
 		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *log)
 		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, uint64(1))
 
 		i := usecases.NewMockRankingInteractor(ctrl)
-		i.EXPECT().UpdateLog(domain.ContestLog{ID: 1, ContestID: 1, UserID: 1, Language: domain.Japanese, Amount: 10, MediumID: 1}).Return(nil)
+		i.EXPECT().UpdateLog(gomock.Any(), domain.ContestLog{ID: 1, ContestID: 1, UserID: 1, Language: domain.Japanese, Amount: 10, MediumID: 1}).Return(nil)
 
 		s := services.NewContestLogService(i)
 		err := s.Update(ctx)
@@ -71,14 +72,14 @@ func TestContestLogService_Update(t *testing.T) {
 			MediumID:  1,
 		}
 
-		ctx := services.NewMockContext(ctrl)
-		ctx.EXPECT().NoContent(403)
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 403, "insufficient_permissions")
 		ctx.EXPECT().User().Return(&domain.User{ID: 1}, nil)
 		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *log)
 		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, uint64(1))
 
 		i := usecases.NewMockRankingInteractor(ctrl)
-		i.EXPECT().UpdateLog(domain.ContestLog{ID: 1, ContestID: 1, UserID: 1, Language: domain.Japanese, Amount: 10, MediumID: 1}).Return(domain.ErrInsufficientPermissions)
+		i.EXPECT().UpdateLog(gomock.Any(), domain.ContestLog{ID: 1, ContestID: 1, UserID: 1, Language: domain.Japanese, Amount: 10, MediumID: 1}).Return(domain.ErrInsufficientPermissions)
 
 		s := services.NewContestLogService(i)
 		err := s.Update(ctx)
@@ -96,13 +97,13 @@ func TestContestLogService_Delete(t *testing.T) {
 
 	// Happy path
 	{
-		ctx := services.NewMockContext(ctrl)
+		ctx := newMockContext(ctrl)
 		ctx.EXPECT().NoContent(200)
 		ctx.EXPECT().User().Return(&domain.User{ID: userID}, nil)
 		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, logID)
 
 		i := usecases.NewMockRankingInteractor(ctrl)
-		i.EXPECT().DeleteLog(logID, userID).Return(nil)
+		i.EXPECT().DeleteLog(gomock.Any(), logID, userID).Return(nil)
 
 		s := services.NewContestLogService(i)
 		err := s.Delete(ctx)
@@ -112,13 +113,13 @@ func TestContestLogService_Delete(t *testing.T) {
 
 	// Sad path: log is not the user's
 	{
-		ctx := services.NewMockContext(ctrl)
-		ctx.EXPECT().NoContent(403)
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 403, "insufficient_permissions")
 		ctx.EXPECT().User().Return(&domain.User{ID: userID}, nil)
 		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, logID)
 
 		i := usecases.NewMockRankingInteractor(ctrl)
-		i.EXPECT().DeleteLog(logID, userID).Return(domain.ErrInsufficientPermissions)
+		i.EXPECT().DeleteLog(gomock.Any(), logID, userID).Return(domain.ErrInsufficientPermissions)
 
 		s := services.NewContestLogService(i)
 		err := s.Delete(ctx)
@@ -128,17 +129,48 @@ func TestContestLogService_Delete(t *testing.T) {
 
 	// Sad path: log does not exist
 	{
-		ctx := services.NewMockContext(ctrl)
-		ctx.EXPECT().NoContent(404)
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 404, "not_found")
 		ctx.EXPECT().User().Return(&domain.User{ID: userID}, nil)
 		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, logID)
 
 		i := usecases.NewMockRankingInteractor(ctrl)
-		i.EXPECT().DeleteLog(logID, userID).Return(domain.ErrNotFound)
+		i.EXPECT().DeleteLog(gomock.Any(), logID, userID).Return(domain.ErrNotFound)
 
 		s := services.NewContestLogService(i)
 		err := s.Delete(ctx)
 
 		assert.NoError(t, err)
 	}
+
+	// Sad path: id is not a number
+	{
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 400, "bad_request")
+		ctx.EXPECT().BindID(gomock.Any()).Return(errors.New("invalid id"))
+
+		s := services.NewContestLogService(usecases.NewMockRankingInteractor(ctrl))
+		err := s.Delete(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestContestLogService_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i := usecases.NewMockRankingInteractor(ctrl)
+	s := services.NewContestLogService(i)
+
+	// Sad path: ids are not numbers
+	for _, params := range [][]string{{"foo", "2"}, {"1", "foo"}} {
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().QueryParam("contest_id").Return(params[0])
+		ctx.EXPECT().QueryParam("user_id").Return(params[1]).MaxTimes(1)
+		expectProblem(t, ctx, 400, "bad_request")
+
+		err := s.Get(ctx)
+		assert.NoError(t, err)
+	}
 }
@@ -33,7 +33,12 @@ func (s *contestService) Create(ctx Context) error {
 		return domain.WrapError(err)
 	}
 
-	if err := s.ContestInteractor.CreateContest(*contest); err != nil {
+	actor, err := auditActor(ctx)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if err := s.ContestInteractor.CreateContest(ctx.RequestContext(), *contest, actor); err != nil {
 		return domain.WrapError(err)
 	}
 
@@ -46,9 +51,20 @@ func (s *contestService) Update(ctx Context) error {
 		return domain.WrapError(err)
 	}
 
-	ctx.BindID(&contest.ID)
+	if err := ctx.BindID(&contest.ID); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+
+	actor, err := auditActor(ctx)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if err := s.ContestInteractor.UpdateContest(ctx.RequestContext(), *contest, actor); err != nil {
+		if err == usecases.ErrContestNotFound {
+			return problem(ctx, http.StatusNotFound, err)
+		}
 
-	if err := s.ContestInteractor.UpdateContest(*contest); err != nil {
 		return domain.WrapError(err)
 	}
 
@@ -60,11 +76,11 @@ func (s *contestService) All(ctx Context) error {
 	if err != nil {
 		limit = 0
 	}
-	contests, err := s.ContestInteractor.Recent(int(limit))
+	contests, err := s.ContestInteractor.Recent(ctx.RequestContext(), int(limit))
 
 	if err != nil {
 		if err == usecases.ErrContestNotFound {
-			return ctx.NoContent(http.StatusNotFound)
+			return problem(ctx, http.StatusNotFound, err)
 		}
 
 		return domain.WrapError(err)
@@ -75,13 +91,15 @@ func (s *contestService) All(ctx Context) error {
 
 func (s *contestService) Get(ctx Context) error {
 	var contestID uint64
-	ctx.BindID(&contestID)
+	if err := ctx.BindID(&contestID); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
 
-	contest, err := s.ContestInteractor.Find(contestID)
+	contest, err := s.ContestInteractor.Find(ctx.RequestContext(), contestID)
 
 	if err != nil {
 		if err == usecases.ErrContestNotFound {
-			return ctx.NoContent(http.StatusNotFound)
+			return problem(ctx, http.StatusNotFound, err)
 		}
 
 		return domain.WrapError(err)
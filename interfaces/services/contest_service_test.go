@@ -22,12 +22,13 @@ func TestContestService_Create(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := services.NewMockContext(ctrl)
+	ctx := newMockContext(ctrl)
 	ctx.EXPECT().NoContent(201)
 	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *contest)
+	actor := expectAuditActor(ctx, &domain.User{ID: 1, Role: domain.RoleAdmin})
 
 	i := usecases.NewMockContestInteractor(ctrl)
-	i.EXPECT().CreateContest(*contest).Return(nil)
+	i.EXPECT().CreateContest(gomock.Any(), *contest, actor).Return(nil)
 
 	s := services.NewContestService(i)
 	err := s.Create(ctx)
@@ -46,13 +47,14 @@ func TestContestService_Update(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := services.NewMockContext(ctrl)
+	ctx := newMockContext(ctrl)
 	ctx.EXPECT().NoContent(204)
 	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *contest)
 	ctx.EXPECT().BindID(&contest.ID).Return(nil)
+	actor := expectAuditActor(ctx, &domain.User{ID: 1, Role: domain.RoleAdmin})
 
 	i := usecases.NewMockContestInteractor(ctrl)
-	i.EXPECT().UpdateContest(*contest).Return(nil)
+	i.EXPECT().UpdateContest(gomock.Any(), *contest, actor).Return(nil)
 
 	s := services.NewContestService(i)
 	err := s.Update(ctx)
@@ -80,12 +82,12 @@ func TestContestService_All(t *testing.T) {
 	defer ctrl.Finish()
 
 	{
-		ctx := services.NewMockContext(ctrl)
+		ctx := newMockContext(ctrl)
 		ctx.EXPECT().JSON(200, contests[1:])
 		ctx.EXPECT().QueryParam("limit").Return("1")
 
 		i := usecases.NewMockContestInteractor(ctrl)
-		i.EXPECT().Recent(1).Return(contests[1:], nil)
+		i.EXPECT().Recent(gomock.Any(), 1).Return(contests[1:], nil)
 
 		s := services.NewContestService(i)
 		err := s.All(ctx)
@@ -94,12 +96,12 @@ func TestContestService_All(t *testing.T) {
 	}
 
 	{
-		ctx := services.NewMockContext(ctrl)
+		ctx := newMockContext(ctrl)
 		ctx.EXPECT().QueryParam("limit").Return("")
 		ctx.EXPECT().JSON(200, contests)
 
 		i := usecases.NewMockContestInteractor(ctrl)
-		i.EXPECT().Recent(0).Return(contests, nil)
+		i.EXPECT().Recent(gomock.Any(), 0).Return(contests, nil)
 
 		s := services.NewContestService(i)
 		err := s.All(ctx)
@@ -108,12 +110,12 @@ func TestContestService_All(t *testing.T) {
 	}
 
 	{
-		ctx := services.NewMockContext(ctrl)
+		ctx := newMockContext(ctrl)
 		ctx.EXPECT().QueryParam("limit").Return("5")
-		ctx.EXPECT().NoContent(404)
+		expectProblem(t, ctx, 404, "contest_not_found")
 
 		i := usecases.NewMockContestInteractor(ctrl)
-		i.EXPECT().Recent(5).Return(nil, usecases.ErrContestNotFound)
+		i.EXPECT().Recent(gomock.Any(), 5).Return(nil, usecases.ErrContestNotFound)
 
 		s := services.NewContestService(i)
 		err := s.All(ctx)
@@ -135,12 +137,12 @@ func TestContestService_Get(t *testing.T) {
 			Open:  true,
 		}
 
-		ctx := services.NewMockContext(ctrl)
+		ctx := newMockContext(ctrl)
 		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, contestID)
 		ctx.EXPECT().JSON(200, contest)
 
 		i := usecases.NewMockContestInteractor(ctrl)
-		i.EXPECT().Find(contestID).Return(contest, nil)
+		i.EXPECT().Find(gomock.Any(), contestID).Return(contest, nil)
 
 		s := services.NewContestService(i)
 		err := s.Get(ctx)
@@ -150,12 +152,12 @@ func TestContestService_Get(t *testing.T) {
 
 	{
 		contestID := uint64(1)
-		ctx := services.NewMockContext(ctrl)
+		ctx := newMockContext(ctrl)
 		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, contestID)
-		ctx.EXPECT().NoContent(404)
+		expectProblem(t, ctx, 404, "contest_not_found")
 
 		i := usecases.NewMockContestInteractor(ctrl)
-		i.EXPECT().Find(contestID).Return(nil, usecases.ErrContestNotFound)
+		i.EXPECT().Find(gomock.Any(), contestID).Return(nil, usecases.ErrContestNotFound)
 
 		s := services.NewContestService(i)
 		err := s.Get(ctx)
@@ -3,6 +3,9 @@
 package services
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/tadoku/api/domain"
 	"github.com/tadoku/api/usecases"
 )
@@ -11,9 +14,22 @@ import (
 
 // Context is a subset of the echo framework context, so we are not directly depending on it
 type Context interface {
+	// Request returns `*http.Request`.
+	Request() *http.Request
+
+	// RequestContext is done when the client went away or the database deadline of the request has passed.
+	RequestContext() context.Context
+
+	// RealIP returns the client's network address, `X-Forwarded-For` is only used
+	// when the request came in through a trusted proxy.
+	RealIP() string
+
 	// QueryParam returns the query param for the provided name.
 	QueryParam(name string) string
 
+	// SetHeader sets a header on the response.
+	SetHeader(key, value string)
+
 	// Get retrieves data from the context.
 	Get(key string) interface{}
 
@@ -33,6 +49,9 @@ type Context interface {
 	// JSON sends a JSON response with status code.
 	JSON(code int, i interface{}) error
 
+	// Blob sends a blob response with status code and content type.
+	Blob(code int, contentType string, b []byte) error
+
 	// Claims gets all the user Claims
 	Claims() *usecases.SessionClaims
 
@@ -44,3 +63,14 @@ type Context interface {
 
 	BindID(*uint64) error
 }
+
+// viewer gives the id of whoever is signed in and the units they want to see amounts in,
+// guests don't have an id and see points
+func viewer(ctx Context) (uint64, domain.Units) {
+	user, err := ctx.User()
+	if err != nil || user == nil {
+		return 0, domain.UnitsPoints
+	}
+
+	return user.ID, user.Preferences.AmountUnits()
+}
@@ -5,9 +5,11 @@
 package services
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/tadoku/api/domain"
 	usecases "github.com/tadoku/api/usecases"
+	http "net/http"
 	reflect "reflect"
 )
 
@@ -34,6 +36,48 @@ func (m *MockContext) EXPECT() *MockContextMockRecorder {
 	return m.recorder
 }
 
+// Request mocks base method
+func (m *MockContext) Request() *http.Request {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Request")
+	ret0, _ := ret[0].(*http.Request)
+	return ret0
+}
+
+// Request indicates an expected call of Request
+func (mr *MockContextMockRecorder) Request() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockContext)(nil).Request))
+}
+
+// RequestContext mocks base method
+func (m *MockContext) RequestContext() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestContext")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// RequestContext indicates an expected call of RequestContext
+func (mr *MockContextMockRecorder) RequestContext() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestContext", reflect.TypeOf((*MockContext)(nil).RequestContext))
+}
+
+// RealIP mocks base method
+func (m *MockContext) RealIP() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RealIP")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// RealIP indicates an expected call of RealIP
+func (mr *MockContextMockRecorder) RealIP() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RealIP", reflect.TypeOf((*MockContext)(nil).RealIP))
+}
+
 // QueryParam mocks base method
 func (m *MockContext) QueryParam(name string) string {
 	m.ctrl.T.Helper()
@@ -48,6 +92,18 @@ func (mr *MockContextMockRecorder) QueryParam(name interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryParam", reflect.TypeOf((*MockContext)(nil).QueryParam), name)
 }
 
+// SetHeader mocks base method
+func (m *MockContext) SetHeader(key, value string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetHeader", key, value)
+}
+
+// SetHeader indicates an expected call of SetHeader
+func (mr *MockContextMockRecorder) SetHeader(key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockContext)(nil).SetHeader), key, value)
+}
+
 // Get mocks base method
 func (m *MockContext) Get(key string) interface{} {
 	m.ctrl.T.Helper()
@@ -130,6 +186,20 @@ func (mr *MockContextMockRecorder) JSON(code, i interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JSON", reflect.TypeOf((*MockContext)(nil).JSON), code, i)
 }
 
+// Blob mocks base method
+func (m *MockContext) Blob(code int, contentType string, b []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Blob", code, contentType, b)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Blob indicates an expected call of Blob
+func (mr *MockContextMockRecorder) Blob(code, contentType, b interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Blob", reflect.TypeOf((*MockContext)(nil).Blob), code, contentType, b)
+}
+
 // Claims mocks base method
 func (m *MockContext) Claims() *usecases.SessionClaims {
 	m.ctrl.T.Helper()
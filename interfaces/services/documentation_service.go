@@ -0,0 +1,36 @@
+package services
+
+import (
+	"net/http"
+	"sync"
+)
+
+// DocumentationService is responsible for describing the api to its clients
+type DocumentationService interface {
+	OpenAPI(ctx Context) error
+}
+
+// NewDocumentationService initializer, routes is only called once the specification is first requested
+// as the routes depend on every other service
+func NewDocumentationService(version string, routes func() []Route) DocumentationService {
+	return &documentationService{
+		version: version,
+		routes:  routes,
+	}
+}
+
+type documentationService struct {
+	version string
+	routes  func() []Route
+
+	document OpenAPIDocument
+	once     sync.Once
+}
+
+func (s *documentationService) OpenAPI(ctx Context) error {
+	s.once.Do(func() {
+		s.document = NewOpenAPIDocument(s.version, s.routes())
+	})
+
+	return ctx.JSON(http.StatusOK, s.document)
+}
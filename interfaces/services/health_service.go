@@ -2,22 +2,45 @@ package services
 
 import (
 	"net/http"
+
+	"github.com/tadoku/api/usecases"
 )
 
 // HealthService is responsible for metrics about the health of the service
 type HealthService interface {
 	// Ping is only used to see if the service is online, it doesn't do any health checks
 	Ping(ctx Context) error
+	// Live tells the orchestrator the process is running, it should only fail when the process needs to be restarted
+	Live(ctx Context) error
+	// Ready tells the orchestrator whether requests can be routed to this instance
+	Ready(ctx Context) error
 }
 
 // NewHealthService initializer
-func NewHealthService() HealthService {
-	return &healthService{}
+func NewHealthService(healthInteractor usecases.HealthInteractor) HealthService {
+	return &healthService{
+		HealthInteractor: healthInteractor,
+	}
 }
 
-type healthService struct{}
+type healthService struct {
+	HealthInteractor usecases.HealthInteractor
+}
 
 func (s *healthService) Ping(ctx Context) error {
 	ctx.String(http.StatusOK, "pong")
 	return nil
 }
+
+func (s *healthService) Live(ctx Context) error {
+	return ctx.JSON(http.StatusOK, map[string]usecases.HealthStatus{"status": usecases.HealthStatusOK})
+}
+
+func (s *healthService) Ready(ctx Context) error {
+	report := s.HealthInteractor.Readiness(ctx.RequestContext())
+	if !report.Ready() {
+		return ctx.JSON(http.StatusServiceUnavailable, report)
+	}
+
+	return ctx.JSON(http.StatusOK, report)
+}
@@ -6,17 +6,69 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
 )
 
 func TestHealthService_Ping(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := services.NewMockContext(ctrl)
+	ctx := newMockContext(ctrl)
 	ctx.EXPECT().String(200, "pong")
 
-	s := services.NewHealthService()
+	s := services.NewHealthService(usecases.NewMockHealthInteractor(ctrl))
 	err := s.Ping(ctx)
 
 	assert.NoError(t, err)
 }
+
+func TestHealthService_Live(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().JSON(200, map[string]usecases.HealthStatus{"status": usecases.HealthStatusOK})
+
+	s := services.NewHealthService(usecases.NewMockHealthInteractor(ctrl))
+	err := s.Live(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestHealthService_Ready(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i := usecases.NewMockHealthInteractor(ctrl)
+	s := services.NewHealthService(i)
+
+	{
+		// Happy path: every check passes
+		report := usecases.HealthReport{
+			Status: usecases.HealthStatusOK,
+			Checks: map[string]usecases.HealthCheckResult{"database": {Status: usecases.HealthStatusOK}},
+		}
+		i.EXPECT().Readiness(gomock.Any()).Return(report)
+
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().JSON(200, report)
+
+		err := s.Ready(ctx)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: the database can't be reached
+		report := usecases.HealthReport{
+			Status: usecases.HealthStatusFailing,
+			Checks: map[string]usecases.HealthCheckResult{"database": {Status: usecases.HealthStatusFailing, Error: "connection refused"}},
+		}
+		i.EXPECT().Readiness(gomock.Any()).Return(report)
+
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().JSON(503, report)
+
+		err := s.Ready(ctx)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/tadoku/api/usecases"
+)
+
+// KeyService is responsible for publishing the keys that can be used to verify our tokens
+type KeyService interface {
+	JWKS(ctx Context) error
+}
+
+// NewKeyService initializer
+func NewKeyService(jwtGenerator usecases.JWTGenerator) KeyService {
+	return &keyService{
+		JWTGenerator: jwtGenerator,
+	}
+}
+
+type keyService struct {
+	JWTGenerator usecases.JWTGenerator
+}
+
+func (s *keyService) JWKS(ctx Context) error {
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"keys": s.JWTGenerator.PublicKeys(),
+	})
+}
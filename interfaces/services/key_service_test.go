@@ -0,0 +1,31 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
+)
+
+func TestKeyService_JWKS(t *testing.T) {
+	keys := []usecases.JSONWebKey{
+		{KeyType: "OKP", KeyID: "2020-06-01", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: "foobar"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().JSON(200, map[string]interface{}{"keys": keys})
+
+	g := usecases.NewMockJWTGenerator(ctrl)
+	g.EXPECT().PublicKeys().Return(keys)
+
+	s := services.NewKeyService(g)
+	err := s.JWKS(ctx)
+
+	assert.NoError(t, err)
+}
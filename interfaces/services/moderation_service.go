@@ -0,0 +1,192 @@
+package services
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// ModerationService is responsible for admins managing users
+type ModerationService interface {
+	Users(ctx Context) error
+	UpdateRole(ctx Context) error
+	Disable(ctx Context) error
+	Enable(ctx Context) error
+	Audit(ctx Context) error
+}
+
+// NewModerationService initializer
+func NewModerationService(moderationInteractor usecases.ModerationInteractor) ModerationService {
+	return &moderationService{
+		ModerationInteractor: moderationInteractor,
+	}
+}
+
+type moderationService struct {
+	ModerationInteractor usecases.ModerationInteractor
+}
+
+// ModerationRoleBody is the data that's needed to change the role of a user
+type ModerationRoleBody struct {
+	Role domain.Role `json:"role"`
+}
+
+// ModerationDisableBody is the data that's needed to disable a user
+type ModerationDisableBody struct {
+	Reason string `json:"reason"`
+}
+
+func (s *moderationService) Users(ctx Context) error {
+	page, err := strconv.ParseInt(ctx.QueryParam("page"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	users, err := s.ModerationInteractor.Users(ctx.RequestContext(), ctx.QueryParam("query"), int(page))
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if users == nil {
+		users = domain.Users{}
+	}
+
+	return ctx.JSON(http.StatusOK, users)
+}
+
+func (s *moderationService) UpdateRole(ctx Context) error {
+	moderator, err := auditActor(ctx)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	var id uint64
+	if err := ctx.BindID(&id); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+
+	b := &ModerationRoleBody{}
+	err = ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = s.ModerationInteractor.UpdateRole(ctx.RequestContext(), moderator, id, b.Role)
+	if err != nil {
+		return moderationError(ctx, err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (s *moderationService) Disable(ctx Context) error {
+	moderator, err := auditActor(ctx)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	var id uint64
+	if err := ctx.BindID(&id); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+
+	b := &ModerationDisableBody{}
+	err = ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = s.ModerationInteractor.DisableUser(ctx.RequestContext(), moderator, id, b.Reason)
+	if err != nil {
+		return moderationError(ctx, err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (s *moderationService) Enable(ctx Context) error {
+	moderator, err := auditActor(ctx)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	var id uint64
+	if err := ctx.BindID(&id); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+
+	err = s.ModerationInteractor.EnableUser(ctx.RequestContext(), moderator, id)
+	if err != nil {
+		return moderationError(ctx, err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (s *moderationService) Audit(ctx Context) error {
+	page, err := strconv.ParseInt(ctx.QueryParam("page"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	filter := domain.AuditEventFilter{
+		Action:     domain.AuditAction(ctx.QueryParam("action")),
+		TargetType: ctx.QueryParam("target_type"),
+	}
+	if filter.ActorID, err = parseOptionalID(ctx.QueryParam("actor_id")); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+	if filter.TargetID, err = parseOptionalID(ctx.QueryParam("target_id")); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+
+	events, err := s.ModerationInteractor.AuditEvents(ctx.RequestContext(), filter, int(page))
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if events == nil {
+		events = domain.AuditEvents{}
+	}
+
+	return ctx.JSON(http.StatusOK, events)
+}
+
+// parseOptionalID treats a missing id as 0, which matches everything in filters
+func parseOptionalID(value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// auditActor describes the current user and where the request came from, for when the action ends up in the audit log
+func auditActor(ctx Context) (usecases.AuditActor, error) {
+	user, err := ctx.User()
+	if err != nil {
+		return usecases.AuditActor{}, err
+	}
+
+	return usecases.AuditActor{
+		UserID:    user.ID,
+		IPAddress: ctx.RealIP(),
+		UserAgent: ctx.Request().UserAgent(),
+	}, nil
+}
+
+func moderationError(ctx Context, err error) error {
+	if err == usecases.ErrModerationRoleInvalid || err == usecases.ErrModerationReasonMissing {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+	if err == usecases.ErrModerationSelf {
+		return problem(ctx, http.StatusForbidden, err)
+	}
+	if err == usecases.ErrUserDoesNotExist {
+		return problem(ctx, http.StatusNotFound, err)
+	}
+
+	return domain.WrapError(err)
+}
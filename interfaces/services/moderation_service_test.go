@@ -0,0 +1,194 @@
+package services_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
+)
+
+// expectAuditActor sets up the calls that are needed to find out who is doing something from where
+func expectAuditActor(ctx *services.MockContext, user *domain.User) usecases.AuditActor {
+	actor := usecases.AuditActor{UserID: user.ID, IPAddress: "127.0.0.1", UserAgent: "Mozilla/5.0"}
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("User-Agent", actor.UserAgent)
+
+	ctx.EXPECT().User().Return(user, nil)
+	ctx.EXPECT().RealIP().Return(actor.IPAddress)
+	ctx.EXPECT().Request().Return(req)
+
+	return actor
+}
+
+func TestModerationService_Users(t *testing.T) {
+	users := domain.Users{{ID: 2, DisplayName: "foo"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().QueryParam("page").Return("2")
+	ctx.EXPECT().QueryParam("query").Return("foo")
+	ctx.EXPECT().JSON(200, users)
+
+	i := usecases.NewMockModerationInteractor(ctrl)
+	i.EXPECT().Users(gomock.Any(), "foo", 2).Return(users, nil)
+
+	s := services.NewModerationService(i)
+	err := s.Users(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestModerationService_UpdateRole(t *testing.T) {
+	admin := &domain.User{ID: 1, Role: domain.RoleAdmin}
+	b := &services.ModerationRoleBody{Role: domain.RoleAdmin}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: role gets changed
+	{
+		ctx := newMockContext(ctrl)
+		actor := expectAuditActor(ctx, admin)
+		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, uint64(2))
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().NoContent(204)
+
+		i := usecases.NewMockModerationInteractor(ctrl)
+		i.EXPECT().UpdateRole(gomock.Any(), actor, uint64(2), domain.RoleAdmin).Return(nil)
+
+		s := services.NewModerationService(i)
+		err := s.UpdateRole(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: unknown user
+	{
+		ctx := newMockContext(ctrl)
+		actor := expectAuditActor(ctx, admin)
+		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, uint64(3))
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		expectProblem(t, ctx, 404, "user_not_found")
+
+		i := usecases.NewMockModerationInteractor(ctrl)
+		i.EXPECT().UpdateRole(gomock.Any(), actor, uint64(3), domain.RoleAdmin).Return(usecases.ErrUserDoesNotExist)
+
+		s := services.NewModerationService(i)
+		err := s.UpdateRole(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestModerationService_Disable(t *testing.T) {
+	admin := &domain.User{ID: 1, Role: domain.RoleAdmin}
+	b := &services.ModerationDisableBody{Reason: "spam"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: user gets disabled
+	{
+		ctx := newMockContext(ctrl)
+		actor := expectAuditActor(ctx, admin)
+		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, uint64(2))
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().NoContent(204)
+
+		i := usecases.NewMockModerationInteractor(ctrl)
+		i.EXPECT().DisableUser(gomock.Any(), actor, uint64(2), "spam").Return(nil)
+
+		s := services.NewModerationService(i)
+		err := s.Disable(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: admins can't disable themselves
+	{
+		ctx := newMockContext(ctrl)
+		actor := expectAuditActor(ctx, admin)
+		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, admin.ID)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		expectProblem(t, ctx, 403, "moderation_self")
+
+		i := usecases.NewMockModerationInteractor(ctrl)
+		i.EXPECT().DisableUser(gomock.Any(), actor, admin.ID, "spam").Return(usecases.ErrModerationSelf)
+
+		s := services.NewModerationService(i)
+		err := s.Disable(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestModerationService_Enable(t *testing.T) {
+	admin := &domain.User{ID: 1, Role: domain.RoleAdmin}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	actor := expectAuditActor(ctx, admin)
+	ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, uint64(2))
+	ctx.EXPECT().NoContent(204)
+
+	i := usecases.NewMockModerationInteractor(ctrl)
+	i.EXPECT().EnableUser(gomock.Any(), actor, uint64(2)).Return(nil)
+
+	s := services.NewModerationService(i)
+	err := s.Enable(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestModerationService_Audit(t *testing.T) {
+	events := domain.AuditEvents{{ID: 1, ActorID: 1, Action: domain.AuditActionUserDisable, TargetType: "user", TargetID: 2}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: filtered events
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().QueryParam("page").Return("")
+		ctx.EXPECT().QueryParam("action").Return("")
+		ctx.EXPECT().QueryParam("target_type").Return("user")
+		ctx.EXPECT().QueryParam("actor_id").Return("")
+		ctx.EXPECT().QueryParam("target_id").Return("2")
+		ctx.EXPECT().JSON(200, events)
+
+		i := usecases.NewMockModerationInteractor(ctrl)
+		i.EXPECT().AuditEvents(gomock.Any(), domain.AuditEventFilter{TargetType: "user", TargetID: 2}, 1).Return(events, nil)
+
+		s := services.NewModerationService(i)
+		err := s.Audit(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: invalid filter
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().QueryParam("page").Return("2")
+		ctx.EXPECT().QueryParam("action").Return("")
+		ctx.EXPECT().QueryParam("target_type").Return("")
+		ctx.EXPECT().QueryParam("actor_id").Return("foo")
+		expectProblem(t, ctx, 400, "bad_request")
+
+		i := usecases.NewMockModerationInteractor(ctrl)
+
+		s := services.NewModerationService(i)
+		err := s.Audit(ctx)
+
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/tadoku/api/domain"
+)
+
+// OpenAPIVersion is the version of the specification the document follows
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPIDocument describes the whole api, see https://spec.openapis.org/oas/v3.0.3
+type OpenAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       OpenAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                      `json:"components"`
+}
+
+// OpenAPIInfo contains metadata about the api
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIComponents holds the schemas that are shared between operations
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+// OpenAPISecurityScheme describes how requests can be authenticated
+type OpenAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIOperation is a single route
+type OpenAPIOperation struct {
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description,omitempty"`
+	OperationID string                     `json:"operationId"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+// OpenAPIParameter is a path or query parameter
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPIRequestBody is the body a route accepts
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIResponse is a possible response of a route
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPIMediaType links a content type to its schema
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+// OpenAPISchema describes the shape of a value
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+// bearerAuth is the name of the security scheme used for sessions and personal access tokens
+const bearerAuth = "bearerAuth"
+
+// NewOpenAPIDocument generates the specification of the given routes
+func NewOpenAPIDocument(version string, routes []Route) OpenAPIDocument {
+	g := &openAPIGenerator{schemas: map[string]*OpenAPISchema{}}
+
+	doc := OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    OpenAPIInfo{Title: "Tadoku API", Version: version},
+		Paths:   map[string]map[string]OpenAPIOperation{},
+		Components: OpenAPIComponents{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				bearerAuth: {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "Access token of a session, or a personal access token on routes that accept a scope",
+				},
+			},
+		},
+	}
+
+	problem := g.schema(reflect.TypeOf(Problem{}))
+	for _, route := range routes {
+		path, params := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]OpenAPIOperation{}
+		}
+
+		doc.Paths[path][strings.ToLower(route.Method)] = g.operation(route, params, problem)
+	}
+
+	return doc
+}
+
+type openAPIGenerator struct {
+	schemas map[string]*OpenAPISchema
+}
+
+func (g *openAPIGenerator) operation(route Route, params []OpenAPIParameter, problem *OpenAPISchema) OpenAPIOperation {
+	op := OpenAPIOperation{
+		Summary:     route.Summary,
+		OperationID: operationID(route),
+		Tags:        []string{strings.Split(strings.TrimPrefix(route.Path, "/"), "/")[0]},
+		Parameters:  params,
+		Responses: map[string]OpenAPIResponse{
+			"default": {
+				Description: "Problem",
+				Content:     map[string]OpenAPIMediaType{ProblemContentType: {Schema: problem}},
+			},
+		},
+	}
+
+	for _, name := range route.Query {
+		op.Parameters = append(op.Parameters, OpenAPIParameter{Name: name, In: "query", Schema: &OpenAPISchema{Type: "string"}})
+	}
+
+	if route.MinRole != domain.RoleGuest {
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+		op.Description = fmt.Sprintf("Requires the %s role.", roleName(route.MinRole))
+	}
+	if route.Scope != "" {
+		op.Description += fmt.Sprintf(" Personal access tokens need the %s scope.", route.Scope)
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  map[string]OpenAPIMediaType{"application/json": {Schema: g.schema(reflect.TypeOf(route.Request))}},
+		}
+	}
+
+	status := route.SuccessStatus()
+	response := OpenAPIResponse{Description: http.StatusText(status)}
+	if route.Response != nil {
+		contentType := "application/json"
+		if _, ok := route.Response.(string); ok {
+			contentType = "text/plain"
+		}
+		response.Content = map[string]OpenAPIMediaType{contentType: {Schema: g.schema(reflect.TypeOf(route.Response))}}
+	}
+	op.Responses[fmt.Sprint(status)] = response
+
+	return op
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	marshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schema describes a type the way encoding/json would encode it, named structs are shared through components
+func (g *openAPIGenerator) schema(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case t == rawMessageType || t.Kind() == reflect.Interface:
+		return &OpenAPISchema{}
+	case t.Implements(marshalerType):
+		// Values that encode themselves, such as passwords, are only ever sent as strings
+		return &OpenAPISchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "byte"}
+		}
+		return &OpenAPISchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+
+		name := schemaName(t)
+		if _, ok := g.schemas[name]; !ok {
+			// Reserve the name first, so types that refer to themselves don't recurse forever
+			g.schemas[name] = &OpenAPISchema{}
+			*g.schemas[name] = *g.object(t)
+		}
+		return &OpenAPISchema{Ref: "#/components/schemas/" + name}
+	}
+
+	return &OpenAPISchema{}
+}
+
+// object lists the fields of a struct, embedded structs are flattened like encoding/json does
+func (g *openAPIGenerator) object(t reflect.Type) *OpenAPISchema {
+	s := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for name, property := range g.object(embedded).Properties {
+					s.Properties[name] = property
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = g.schema(field.Type)
+	}
+
+	return s
+}
+
+// schemaName prefixes types with their package, so domain.User and a service's User don't clash
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	return strings.Title(pkg[strings.LastIndex(pkg, "/")+1:]) + t.Name()
+}
+
+// openAPIPath turns echo's :param placeholders into {param}
+func openAPIPath(path string) (string, []OpenAPIParameter) {
+	var params []OpenAPIParameter
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+
+		name := strings.TrimPrefix(segment, ":")
+		segments[i] = "{" + name + "}"
+		params = append(params, OpenAPIParameter{Name: name, In: "path", Required: true, Schema: &OpenAPISchema{Type: "string"}})
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// operationID is a unique name for a route, eg. GET /users/:id/profile becomes get_users_id_profile
+func operationID(route Route) string {
+	replacer := strings.NewReplacer("/", "_", ":", "", ".", "_")
+	return strings.ToLower(route.Method) + replacer.Replace(strings.TrimSuffix(route.Path, "/"))
+}
+
+func roleName(role domain.Role) string {
+	switch role {
+	case domain.RoleUser:
+		return "user"
+	case domain.RoleAdmin:
+		return "admin"
+	}
+
+	return fmt.Sprint(int(role))
+}
@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/services"
+)
+
+func TestNewOpenAPIDocument(t *testing.T) {
+	routes := []services.Route{
+		{Method: http.MethodGet, Path: "/contests/:id", Summary: "A single contest", Response: domain.Contest{}},
+		{Method: http.MethodPut, Path: "/contest_logs/:id", MinRole: domain.RoleUser, Scope: domain.ScopeLogsWrite, Summary: "Update a log", Request: domain.ContestLog{}},
+		{Method: http.MethodGet, Path: "/rankings", Summary: "Leaderboard", Response: []domain.RankingView{}, Query: []string{"contest_id"}},
+	}
+
+	doc := services.NewOpenAPIDocument("1.0.0", routes)
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, "1.0.0", doc.Info.Version)
+
+	{
+		// Happy path: path parameters and shared schemas
+		op := doc.Paths["/contests/{id}"]["get"]
+		assert.Equal(t, "A single contest", op.Summary)
+		assert.Equal(t, "get_contests_id", op.OperationID)
+		assert.Equal(t, []services.OpenAPIParameter{
+			{Name: "id", In: "path", Required: true, Schema: &services.OpenAPISchema{Type: "string"}},
+		}, op.Parameters)
+		assert.Equal(t, "#/components/schemas/DomainContest", op.Responses["200"].Content["application/json"].Schema.Ref)
+		assert.Equal(t, "#/components/schemas/ServicesProblem", op.Responses["default"].Content[services.ProblemContentType].Schema.Ref)
+		assert.Nil(t, op.Security)
+
+		contest := doc.Components.Schemas["DomainContest"]
+		assert.Equal(t, &services.OpenAPISchema{Type: "string", Format: "date-time"}, contest.Properties["start"])
+		assert.Equal(t, &services.OpenAPISchema{Type: "boolean"}, contest.Properties["open"])
+	}
+
+	{
+		// Happy path: authenticated routes without a response
+		op := doc.Paths["/contest_logs/{id}"]["put"]
+		assert.Equal(t, []map[string][]string{{"bearerAuth": {}}}, op.Security)
+		assert.Equal(t, "Requires the user role. Personal access tokens need the logs:write scope.", op.Description)
+		assert.Equal(t, "#/components/schemas/DomainContestLog", op.RequestBody.Content["application/json"].Schema.Ref)
+		assert.Contains(t, op.Responses, "204")
+		assert.Nil(t, op.Responses["204"].Content)
+	}
+
+	{
+		// Happy path: query parameters and collections
+		op := doc.Paths["/rankings"]["get"]
+		assert.Equal(t, "query", op.Parameters[0].In)
+		assert.Equal(t, "contest_id", op.Parameters[0].Name)
+
+		schema := op.Responses["200"].Content["application/json"].Schema
+		assert.Equal(t, "array", schema.Type)
+		assert.Equal(t, "#/components/schemas/DomainRankingView", schema.Items.Ref)
+	}
+}
+
+func TestRoute_Documented(t *testing.T) {
+	assert.True(t, services.Route{Summary: "Check if the api is online"}.Documented())
+	assert.False(t, services.Route{Method: http.MethodGet, Path: "/ping"}.Documented())
+	assert.False(t, services.Route{Summary: "Log in", Response: map[string]interface{}{}}.Documented(), "fields of untyped bodies are unknown")
+	assert.False(t, services.Route{Summary: "Log in", Request: struct{ Data []interface{} }{}}.Documented())
+	assert.True(t, services.Route{Summary: "Log in", Request: services.SessionLoginBody{}, Response: services.SessionLoginResponse{}}.Documented())
+}
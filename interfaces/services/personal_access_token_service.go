@@ -0,0 +1,103 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// PersonalAccessTokenService is responsible for managing the tokens users hand out to their own scripts
+type PersonalAccessTokenService interface {
+	Create(ctx Context) error
+	List(ctx Context) error
+	Revoke(ctx Context) error
+}
+
+// NewPersonalAccessTokenService initializer
+func NewPersonalAccessTokenService(personalAccessTokenInteractor usecases.PersonalAccessTokenInteractor) PersonalAccessTokenService {
+	return &personalAccessTokenService{
+		PersonalAccessTokenInteractor: personalAccessTokenInteractor,
+	}
+}
+
+type personalAccessTokenService struct {
+	PersonalAccessTokenInteractor usecases.PersonalAccessTokenInteractor
+}
+
+// PersonalAccessTokenCreateBody is the data that's needed to create a personal access token
+type PersonalAccessTokenCreateBody struct {
+	Name   string        `json:"name"`
+	Scopes domain.Scopes `json:"scopes"`
+}
+
+// PersonalAccessTokenCreated is only shown once, afterwards the token itself can't be retrieved anymore
+type PersonalAccessTokenCreated struct {
+	domain.PersonalAccessToken
+	Token string `json:"token"`
+}
+
+func (s *personalAccessTokenService) Create(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	b := &PersonalAccessTokenCreateBody{}
+	err = ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	token, created, err := s.PersonalAccessTokenInteractor.CreateToken(ctx.RequestContext(), user.ID, b.Name, b.Scopes)
+	if err != nil {
+		if isError(err, usecases.ErrInvalidPersonalAccessToken) {
+			return problem(ctx, http.StatusBadRequest, err)
+		}
+
+		return domain.WrapError(err)
+	}
+
+	return ctx.JSON(http.StatusCreated, PersonalAccessTokenCreated{PersonalAccessToken: created, Token: token})
+}
+
+func (s *personalAccessTokenService) List(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	tokens, err := s.PersonalAccessTokenInteractor.Tokens(ctx.RequestContext(), user.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if tokens == nil {
+		tokens = domain.PersonalAccessTokens{}
+	}
+
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+func (s *personalAccessTokenService) Revoke(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	var id uint64
+	if err := ctx.BindID(&id); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+
+	err = s.PersonalAccessTokenInteractor.RevokeToken(ctx.RequestContext(), user.ID, id)
+	if err != nil {
+		if err == usecases.ErrPersonalAccessTokenNotFound {
+			return problem(ctx, http.StatusNotFound, err)
+		}
+
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,110 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
+)
+
+func TestPersonalAccessTokenService_Create(t *testing.T) {
+	user := &domain.User{ID: 1, Role: domain.RoleUser}
+	b := &services.PersonalAccessTokenCreateBody{
+		Name:   "kobo",
+		Scopes: domain.Scopes{domain.ScopeLogsWrite},
+	}
+	created := domain.PersonalAccessToken{ID: 2, UserID: user.ID, Name: b.Name, Scopes: b.Scopes}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: token is shown once
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().JSON(201, services.PersonalAccessTokenCreated{PersonalAccessToken: created, Token: "tdk_foobar"})
+
+		i := usecases.NewMockPersonalAccessTokenInteractor(ctrl)
+		i.EXPECT().CreateToken(gomock.Any(), user.ID, b.Name, b.Scopes).Return("tdk_foobar", created, nil)
+
+		s := services.NewPersonalAccessTokenService(i)
+		err := s.Create(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: unknown scopes
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		expectProblem(t, ctx, 400, "personal_access_token_invalid")
+
+		i := usecases.NewMockPersonalAccessTokenInteractor(ctrl)
+		i.EXPECT().CreateToken(gomock.Any(), user.ID, b.Name, b.Scopes).Return("", domain.PersonalAccessToken{}, usecases.ErrInvalidPersonalAccessToken)
+
+		s := services.NewPersonalAccessTokenService(i)
+		err := s.Create(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestPersonalAccessTokenService_List(t *testing.T) {
+	user := &domain.User{ID: 1, Role: domain.RoleUser}
+	tokens := domain.PersonalAccessTokens{{ID: 2, UserID: user.ID, Name: "kobo"}}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().User().Return(user, nil)
+	ctx.EXPECT().JSON(200, tokens)
+
+	i := usecases.NewMockPersonalAccessTokenInteractor(ctrl)
+	i.EXPECT().Tokens(gomock.Any(), user.ID).Return(tokens, nil)
+
+	s := services.NewPersonalAccessTokenService(i)
+	err := s.List(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestPersonalAccessTokenService_Revoke(t *testing.T) {
+	user := &domain.User{ID: 1, Role: domain.RoleUser}
+	tokenID := uint64(2)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, tc := range []struct {
+		interactorErr error
+		expStatusCode int
+		expCode       string
+	}{
+		{nil, 204, ""},
+		{usecases.ErrPersonalAccessTokenNotFound, 404, "personal_access_token_not_found"},
+	} {
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, tokenID)
+		if tc.expCode == "" {
+			ctx.EXPECT().NoContent(tc.expStatusCode)
+		} else {
+			expectProblem(t, ctx, tc.expStatusCode, tc.expCode)
+		}
+
+		i := usecases.NewMockPersonalAccessTokenInteractor(ctrl)
+		i.EXPECT().RevokeToken(gomock.Any(), user.ID, tokenID).Return(tc.interactorErr)
+
+		s := services.NewPersonalAccessTokenService(i)
+		err := s.Revoke(ctx)
+
+		assert.NoError(t, err)
+	}
+}
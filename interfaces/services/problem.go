@@ -0,0 +1,193 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/srvc/fail"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// ProblemContentType is the media type of error responses, see RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// Problem describes why a request failed, following RFC 7807
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+
+	// Code is a stable machine readable identifier, clients should rely on this instead of the title or detail
+	Code string `json:"code"`
+
+	// Errors lists every field that failed validation
+	Errors domain.FieldErrors `json:"errors,omitempty"`
+}
+
+type knownProblem struct {
+	status int
+	code   string
+}
+
+// knownProblems maps errors that can be shown to users to the status they're reported with when a handler
+// doesn't decide on one, and the code clients can check for. Codes should never change once they've been released.
+var knownProblems = map[error]knownProblem{}
+
+func registerProblem(err error, status int, code string) {
+	knownProblems[rootError(err)] = knownProblem{status: status, code: code}
+}
+
+func init() {
+	registerProblem(domain.ErrNotFound, http.StatusNotFound, "not_found")
+	registerProblem(domain.ErrInsufficientPermissions, http.StatusForbidden, "insufficient_permissions")
+	registerProblem(domain.ErrAlreadyExists, http.StatusConflict, "already_exists")
+	registerProblem(domain.ErrEmailInvalid, http.StatusBadRequest, "email_invalid")
+	registerProblem(domain.ErrInvalidLanguage, http.StatusBadRequest, "language_invalid")
+	registerProblem(domain.ErrDeadlineExceeded, http.StatusServiceUnavailable, "database_timeout")
+
+	// Contests
+	registerProblem(usecases.ErrInvalidContest, http.StatusBadRequest, "contest_invalid")
+	registerProblem(usecases.ErrOpenContestAlreadyExists, http.StatusConflict, "open_contest_already_exists")
+	registerProblem(usecases.ErrContestIDMissing, http.StatusBadRequest, "contest_id_missing")
+	registerProblem(usecases.ErrCreateContestHasID, http.StatusBadRequest, "contest_has_id")
+	registerProblem(usecases.ErrContestNotFound, http.StatusNotFound, "contest_not_found")
+
+	// Rankings and contest logs
+	registerProblem(usecases.ErrInvalidRanking, http.StatusBadRequest, "ranking_invalid")
+	registerProblem(usecases.ErrRankingIDMissing, http.StatusBadRequest, "ranking_id_missing")
+	registerProblem(usecases.ErrContestIsClosed, http.StatusUnprocessableEntity, "contest_closed")
+	registerProblem(usecases.ErrNoRankingToCreate, http.StatusConflict, "ranking_already_exists")
+	registerProblem(usecases.ErrNoRankingsFound, http.StatusNotFound, "rankings_not_found")
+	registerProblem(usecases.ErrGlobalIsASystemLanguage, http.StatusBadRequest, "language_global")
+	registerProblem(usecases.ErrInvalidContestLog, http.StatusBadRequest, "contest_log_invalid")
+	registerProblem(usecases.ErrContestLanguageNotSignedUp, http.StatusUnprocessableEntity, "language_not_signed_up")
+	registerProblem(usecases.ErrNoRankingRegistrationFound, http.StatusNotFound, "ranking_registration_not_found")
+	registerProblem(usecases.ErrNoContestLogsFound, http.StatusNotFound, "contest_logs_not_found")
+	registerProblem(usecases.ErrContestLogIDMissing, http.StatusBadRequest, "contest_log_id_missing")
+	registerProblem(usecases.ErrCreateContestLogHasID, http.StatusBadRequest, "contest_log_has_id")
+
+	// Sessions
+	registerProblem(errInvalidCredentials, http.StatusUnauthorized, "invalid_credentials")
+	registerProblem(usecases.ErrLoginThrottled, http.StatusTooManyRequests, "login_throttled")
+	registerProblem(usecases.ErrPasswordIncorrect, http.StatusForbidden, "password_incorrect")
+	registerProblem(usecases.ErrUserDoesNotExist, http.StatusNotFound, "user_not_found")
+	registerProblem(usecases.ErrInvalidUser, http.StatusBadRequest, "user_invalid")
+	registerProblem(usecases.ErrInvalidNewPassword, http.StatusBadRequest, "new_password_invalid")
+	registerProblem(usecases.ErrPasswordResetTokenInvalid, http.StatusBadRequest, "password_reset_token_invalid")
+	registerProblem(usecases.ErrEmailVerificationTokenInvalid, http.StatusBadRequest, "email_verification_token_invalid")
+	registerProblem(usecases.ErrEmailNotVerified, http.StatusForbidden, "email_not_verified")
+	registerProblem(usecases.ErrEmailAlreadyVerified, http.StatusConflict, "email_already_verified")
+	registerProblem(usecases.ErrRefreshTokenInvalid, http.StatusUnauthorized, "refresh_token_invalid")
+	registerProblem(usecases.ErrRefreshTokenReused, http.StatusUnauthorized, "refresh_token_reused")
+	registerProblem(usecases.ErrSessionRevoked, http.StatusUnauthorized, "session_revoked")
+	registerProblem(usecases.ErrSessionNotFound, http.StatusNotFound, "session_not_found")
+	registerProblem(usecases.ErrUserDisabled, http.StatusForbidden, "user_disabled")
+
+	// Two factor authentication
+	registerProblem(usecases.ErrTwoFactorChallengeInvalid, http.StatusUnauthorized, "two_factor_challenge_invalid")
+	registerProblem(usecases.ErrTwoFactorRequired, http.StatusForbidden, "two_factor_required")
+	registerProblem(usecases.ErrTwoFactorAlreadyEnabled, http.StatusConflict, "two_factor_already_enabled")
+	registerProblem(usecases.ErrTwoFactorNotEnabled, http.StatusNotFound, "two_factor_not_enabled")
+	registerProblem(usecases.ErrTwoFactorCodeInvalid, http.StatusBadRequest, "two_factor_code_invalid")
+
+	// Personal access tokens
+	registerProblem(usecases.ErrInvalidPersonalAccessToken, http.StatusBadRequest, "personal_access_token_invalid")
+	registerProblem(usecases.ErrPersonalAccessTokenNotFound, http.StatusNotFound, "personal_access_token_not_found")
+	registerProblem(usecases.ErrPersonalAccessTokenRejected, http.StatusUnauthorized, "personal_access_token_rejected")
+
+	// Users
+	registerProblem(usecases.ErrEmailAlreadyInUse, http.StatusConflict, "email_already_in_use")
+	registerProblem(usecases.ErrInvalidPreferences, http.StatusBadRequest, "preferences_invalid")
+	registerProblem(usecases.ErrEmailChangeRequestInvalid, http.StatusBadRequest, "email_change_request_invalid")
+
+	// Moderation
+	registerProblem(usecases.ErrModerationRoleInvalid, http.StatusBadRequest, "role_invalid")
+	registerProblem(usecases.ErrModerationReasonMissing, http.StatusBadRequest, "reason_missing")
+	registerProblem(usecases.ErrModerationSelf, http.StatusForbidden, "moderation_self")
+	registerProblem(usecases.ErrModerationNotDisabled, http.StatusConflict, "user_not_disabled")
+	registerProblem(usecases.ErrModerationUserDisabled, http.StatusConflict, "moderation_user_disabled")
+}
+
+// errInvalidCredentials hides whether it was the email address or password that was wrong when logging in
+var errInvalidCredentials = fail.New("email address or password is incorrect")
+
+// rootError strips the annotations of wrapped errors, so they can be compared with the error they were wrapped from
+func rootError(err error) error {
+	if appErr := fail.Unwrap(err); appErr != nil {
+		err = appErr.Err
+	}
+	if validationErr, ok := err.(*usecases.ValidationError); ok {
+		return rootError(validationErr.Err)
+	}
+
+	return err
+}
+
+// isError checks whether err was caused by target, even when it has been wrapped or carries validation errors
+func isError(err, target error) bool {
+	return rootError(err) == rootError(target)
+}
+
+// violations returns the fields that caused a validation error, if there are any
+func violations(err error) domain.FieldErrors {
+	if appErr := fail.Unwrap(err); appErr != nil {
+		err = appErr.Err
+	}
+	if validationErr, ok := err.(*usecases.ValidationError); ok {
+		return validationErr.Violations
+	}
+
+	return nil
+}
+
+// ProblemFor looks up how a known error should be reported, it returns false for errors that shouldn't be shown to users
+func ProblemFor(err error) (Problem, bool) {
+	known, ok := knownProblems[rootError(err)]
+	if !ok {
+		return Problem{}, false
+	}
+
+	return NewProblem(known.status, err), true
+}
+
+// NewProblem describes an error with the given status, only known errors are explained in detail
+func NewProblem(status int, err error) Problem {
+	p := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   statusCode(status),
+	}
+
+	if known, ok := knownProblems[rootError(err)]; ok {
+		p.Code = known.code
+		p.Detail = rootError(err).Error()
+		p.Errors = violations(err)
+	}
+
+	return p
+}
+
+// statusCode turns a status into a code for errors we don't know anything more about, e.g. 404 becomes not_found
+func statusCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// WriteProblem sends the problem as the response
+func WriteProblem(ctx Context, p Problem) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.Blob(p.Status, ProblemContentType, body)
+}
+
+// problem responds with the given status, and explains what went wrong when it's a known error
+func problem(ctx Context, status int, err error) error {
+	return WriteProblem(ctx, NewProblem(status, err))
+}
@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
+)
+
+// newMockContext is a context of a request that hasn't been cancelled
+func newMockContext(ctrl *gomock.Controller) *services.MockContext {
+	ctx := services.NewMockContext(ctrl)
+	ctx.EXPECT().RequestContext().Return(context.Background()).AnyTimes()
+	return ctx
+}
+
+// errGuest is what the context gives back as the user of requests without a session
+var errGuest = errors.New("no user")
+
+// expectProblem checks that the handler responds with a problem with the given status and code
+func expectProblem(t *testing.T, ctx *services.MockContext, status int, code string) {
+	ctx.EXPECT().Blob(status, services.ProblemContentType, gomock.Any()).DoAndReturn(func(status int, contentType string, body []byte) error {
+		var p services.Problem
+		assert.NoError(t, json.Unmarshal(body, &p))
+		assert.Equal(t, status, p.Status)
+		assert.Equal(t, code, p.Code)
+		return nil
+	})
+}
+
+func TestProblemFor(t *testing.T) {
+	{
+		// Happy path: errors are recognized even after they've been wrapped
+		p, ok := services.ProblemFor(domain.WrapError(usecases.ErrContestLanguageNotSignedUp))
+		assert.True(t, ok)
+		assert.Equal(t, services.Problem{
+			Type:   "about:blank",
+			Title:  "Unprocessable Entity",
+			Status: 422,
+			Detail: "user has not signed up for given language",
+			Code:   "language_not_signed_up",
+		}, p)
+	}
+
+	{
+		// Happy path: errors that aren't created by us
+		p, ok := services.ProblemFor(domain.ErrNotFound)
+		assert.True(t, ok)
+		assert.Equal(t, 404, p.Status)
+		assert.Equal(t, "not_found", p.Code)
+	}
+
+	{
+		// Happy path: validation errors list the fields that are invalid
+		violations := domain.FieldErrors{domain.NewFieldError("timezone", "timezone", domain.ErrTimezoneInvalid)}
+		err := &usecases.ValidationError{Err: usecases.ErrInvalidPreferences, Violations: violations}
+
+		p, ok := services.ProblemFor(domain.WrapError(err))
+		assert.True(t, ok)
+		assert.Equal(t, 400, p.Status)
+		assert.Equal(t, "preferences_invalid", p.Code)
+		assert.Equal(t, violations, p.Errors)
+	}
+
+	{
+		// Sad path: unknown errors
+		_, ok := services.ProblemFor(domain.WrapError(errors.New("connection refused")))
+		assert.False(t, ok)
+	}
+}
+
+func TestNewProblem(t *testing.T) {
+	{
+		// Happy path: handlers can pick a different status for known errors
+		p := services.NewProblem(401, usecases.ErrTwoFactorCodeInvalid)
+		assert.Equal(t, 401, p.Status)
+		assert.Equal(t, "Unauthorized", p.Title)
+		assert.Equal(t, "two_factor_code_invalid", p.Code)
+		assert.Equal(t, "two factor authentication code is invalid", p.Detail)
+	}
+
+	{
+		// Happy path: unknown errors don't give anything away
+		p := services.NewProblem(500, errors.New("pq: password authentication failed"))
+		assert.Equal(t, services.Problem{
+			Type:   "about:blank",
+			Title:  "Internal Server Error",
+			Status: 500,
+			Code:   "internal_server_error",
+		}, p)
+	}
+}
@@ -44,7 +44,7 @@ func (s *rankingService) Create(ctx Context) error {
 		return domain.WrapError(err)
 	}
 
-	if err := s.RankingInteractor.CreateRanking(payload.ContestID, user.ID, payload.Languages); err != nil {
+	if err := s.RankingInteractor.CreateRanking(ctx.RequestContext(), payload.ContestID, user.ID, payload.Languages); err != nil {
 		return domain.WrapError(err)
 	}
 
@@ -54,20 +54,21 @@ func (s *rankingService) Create(ctx Context) error {
 func (s *rankingService) Get(ctx Context) error {
 	contestID, err := strconv.ParseUint(ctx.QueryParam("contest_id"), 10, 64)
 	if err != nil {
-		return domain.WrapError(err)
+		return problem(ctx, http.StatusBadRequest, err)
 	}
 	language := domain.LanguageCode(ctx.QueryParam("language"))
 
-	rankings, err := s.RankingInteractor.RankingsForContest(contestID, language)
+	rankings, err := s.RankingInteractor.RankingsForContest(ctx.RequestContext(), contestID, language)
 	if err != nil {
 		if err == usecases.ErrNoRankingsFound {
-			return ctx.NoContent(http.StatusNotFound)
+			return problem(ctx, http.StatusNotFound, err)
 		}
 
 		return domain.WrapError(err)
 	}
 
-	return ctx.JSON(http.StatusOK, rankings.GetView())
+	_, units := viewer(ctx)
+	return ctx.JSON(http.StatusOK, rankings.GetView(units))
 }
 
 func (s *rankingService) CurrentRegistration(ctx Context) error {
@@ -76,10 +77,10 @@ func (s *rankingService) CurrentRegistration(ctx Context) error {
 		return domain.WrapError(err)
 	}
 
-	registration, err := s.RankingInteractor.CurrentRegistration(user.ID)
+	registration, err := s.RankingInteractor.CurrentRegistration(ctx.RequestContext(), user.ID)
 	if err != nil {
 		if err == usecases.ErrNoRankingRegistrationFound {
-			return ctx.NoContent(http.StatusNotFound)
+			return problem(ctx, http.StatusNotFound, err)
 		}
 
 		return domain.WrapError(err)
@@ -91,21 +92,22 @@ func (s *rankingService) CurrentRegistration(ctx Context) error {
 func (s *rankingService) RankingsForRegistration(ctx Context) error {
 	contestID, err := strconv.ParseUint(ctx.QueryParam("contest_id"), 10, 64)
 	if err != nil {
-		return domain.WrapError(err)
+		return problem(ctx, http.StatusBadRequest, err)
 	}
 	userID, err := strconv.ParseUint(ctx.QueryParam("user_id"), 10, 64)
 	if err != nil {
-		return domain.WrapError(err)
+		return problem(ctx, http.StatusBadRequest, err)
 	}
 
-	rankings, err := s.RankingInteractor.RankingsForRegistration(contestID, userID)
+	viewerID, units := viewer(ctx)
+	rankings, err := s.RankingInteractor.RankingsForRegistration(ctx.RequestContext(), contestID, userID, viewerID)
 	if err != nil {
 		if err == usecases.ErrNoRankingsFound {
-			return ctx.NoContent(http.StatusNotFound)
+			return problem(ctx, http.StatusNotFound, err)
 		}
 
 		return domain.WrapError(err)
 	}
 
-	return ctx.JSON(http.StatusOK, rankings.GetView())
+	return ctx.JSON(http.StatusOK, rankings.GetView(units))
 }
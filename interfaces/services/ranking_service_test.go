@@ -24,13 +24,13 @@ func TestRankingService_Create(t *testing.T) {
 		Languages: domain.LanguageCodes{domain.Japanese},
 	}
 
-	ctx := services.NewMockContext(ctrl)
+	ctx := newMockContext(ctrl)
 	ctx.EXPECT().NoContent(201)
 	ctx.EXPECT().User().Return(&domain.User{ID: userID}, nil)
 	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *payload)
 
 	i := usecases.NewMockRankingInteractor(ctrl)
-	i.EXPECT().CreateRanking(contestID, userID, payload.Languages).Return(nil)
+	i.EXPECT().CreateRanking(gomock.Any(), contestID, userID, payload.Languages).Return(nil)
 
 	s := services.NewRankingService(i)
 	err := s.Create(ctx)
@@ -46,23 +46,57 @@ func TestRankingService_Get(t *testing.T) {
 	language := domain.Global
 
 	expected := domain.Rankings{
-		{ID: 1, ContestID: contestID, UserID: 1, Language: domain.Global, Amount: 15},
-		{ID: 2, ContestID: contestID, UserID: 2, Language: domain.Global, Amount: 12},
-		{ID: 3, ContestID: contestID, UserID: 3, Language: domain.Global, Amount: 11},
+		{ID: 1, ContestID: contestID, UserID: 1, Language: domain.Global, Amount: 15, LoggedAmount: 30},
+		{ID: 2, ContestID: contestID, UserID: 2, Language: domain.Global, Amount: 12, LoggedAmount: 12},
+		{ID: 3, ContestID: contestID, UserID: 3, Language: domain.Global, Amount: 11, LoggedAmount: 20},
 	}
 
-	ctx := services.NewMockContext(ctrl)
-	ctx.EXPECT().QueryParam("contest_id").Return("1")
-	ctx.EXPECT().QueryParam("language").Return(string(domain.Global))
-	ctx.EXPECT().JSON(200, expected.GetView())
-
 	i := usecases.NewMockRankingInteractor(ctrl)
-	i.EXPECT().RankingsForContest(contestID, language).Return(expected, nil)
-
 	s := services.NewRankingService(i)
-	err := s.Get(ctx)
 
-	assert.NoError(t, err)
+	{
+		// Happy path: guests see points
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().QueryParam("contest_id").Return("1")
+		ctx.EXPECT().QueryParam("language").Return(string(domain.Global))
+		ctx.EXPECT().User().Return(nil, errGuest)
+		ctx.EXPECT().JSON(200, expected.GetView(domain.UnitsPoints))
+
+		i.EXPECT().RankingsForContest(gomock.Any(), contestID, language).Return(expected, nil)
+
+		err := s.Get(ctx)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Happy path: amounts are shown in the units of the viewer
+		viewer := &domain.User{ID: 4, Preferences: &domain.Preferences{Units: domain.UnitsPages}}
+
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().QueryParam("contest_id").Return("1")
+		ctx.EXPECT().QueryParam("language").Return(string(domain.Global))
+		ctx.EXPECT().User().Return(viewer, nil)
+		ctx.EXPECT().JSON(200, []domain.RankingView{
+			{ContestID: contestID, UserID: 1, Language: domain.Global, Amount: 30, Units: domain.UnitsPages},
+			{ContestID: contestID, UserID: 2, Language: domain.Global, Amount: 12, Units: domain.UnitsPages},
+			{ContestID: contestID, UserID: 3, Language: domain.Global, Amount: 20, Units: domain.UnitsPages},
+		})
+
+		i.EXPECT().RankingsForContest(gomock.Any(), contestID, language).Return(expected, nil)
+
+		err := s.Get(ctx)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: contest id is not a number
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().QueryParam("contest_id").Return("foo")
+		expectProblem(t, ctx, 400, "bad_request")
+
+		err := s.Get(ctx)
+		assert.NoError(t, err)
+	}
 }
 
 func TestRankingService_CurrentRegistration(t *testing.T) {
@@ -76,12 +110,12 @@ func TestRankingService_CurrentRegistration(t *testing.T) {
 		Languages: domain.LanguageCodes{domain.Japanese, domain.English},
 	}
 
-	ctx := services.NewMockContext(ctrl)
+	ctx := newMockContext(ctrl)
 	ctx.EXPECT().User().Return(&domain.User{ID: userID}, nil)
 	ctx.EXPECT().JSON(200, expected)
 
 	i := usecases.NewMockRankingInteractor(ctrl)
-	i.EXPECT().CurrentRegistration(userID).Return(expected, nil)
+	i.EXPECT().CurrentRegistration(gomock.Any(), userID).Return(expected, nil)
 
 	s := services.NewRankingService(i)
 	err := s.CurrentRegistration(ctx)
@@ -102,16 +136,45 @@ func TestRankingService_RankingsForRegistration(t *testing.T) {
 		{ID: 3, ContestID: contestID, UserID: userID, Language: domain.Korean, Amount: 11},
 	}
 
-	ctx := services.NewMockContext(ctrl)
-	ctx.EXPECT().QueryParam("contest_id").Return("1")
-	ctx.EXPECT().QueryParam("user_id").Return("1")
-	ctx.EXPECT().JSON(200, expected.GetView())
-
 	i := usecases.NewMockRankingInteractor(ctrl)
-	i.EXPECT().RankingsForRegistration(contestID, userID).Return(expected, nil)
-
 	s := services.NewRankingService(i)
-	err := s.RankingsForRegistration(ctx)
 
-	assert.NoError(t, err)
+	{
+		// Happy path: guests have no viewer id
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().QueryParam("contest_id").Return("1")
+		ctx.EXPECT().QueryParam("user_id").Return("1")
+		ctx.EXPECT().User().Return(nil, errGuest)
+		ctx.EXPECT().JSON(200, expected.GetView(domain.UnitsPoints))
+
+		i.EXPECT().RankingsForRegistration(gomock.Any(), contestID, userID, uint64(0)).Return(expected, nil)
+
+		err := s.RankingsForRegistration(ctx)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Happy path: users can see their own rankings
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().QueryParam("contest_id").Return("1")
+		ctx.EXPECT().QueryParam("user_id").Return("1")
+		ctx.EXPECT().User().Return(&domain.User{ID: userID}, nil)
+		ctx.EXPECT().JSON(200, expected.GetView(domain.UnitsPoints))
+
+		i.EXPECT().RankingsForRegistration(gomock.Any(), contestID, userID, userID).Return(expected, nil)
+
+		err := s.RankingsForRegistration(ctx)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: user id is not a number
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().QueryParam("contest_id").Return("1")
+		ctx.EXPECT().QueryParam("user_id").Return("foo")
+		expectProblem(t, ctx, 400, "bad_request")
+
+		err := s.RankingsForRegistration(ctx)
+		assert.NoError(t, err)
+	}
 }
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"net/http"
+	"reflect"
 
 	"github.com/tadoku/api/domain"
 )
@@ -9,6 +11,8 @@ import (
 // Router takes care of all the routing
 type Router interface {
 	StartListening() error
+	// Shutdown stops accepting new connections and waits for requests in flight until the context is done
+	Shutdown(ctx context.Context) error
 	ServeHTTP(w http.ResponseWriter, r *http.Request)
 }
 
@@ -21,4 +25,70 @@ type Route struct {
 	Path        string
 	HandlerFunc HandlerFunc
 	MinRole     domain.Role
+
+	// Scope a personal access token needs to be granted for this route, without one only sessions are accepted
+	Scope domain.Scope
+
+	// Summary explains what the route does, every route needs one to show up in the OpenAPI specification
+	Summary string
+	// Request is a value of the type the body is bound to, nil when the route doesn't accept a body
+	Request interface{}
+	// Response is a value of the type that is sent back on success, nil when there is no body
+	Response interface{}
+	// Query lists the query parameters the route reads
+	Query []string
+	// Status is sent back on success, defaults to 200 with a response and 204 without one
+	Status int
+}
+
+// Documented tells if the route can be described in the OpenAPI specification,
+// bodies of an untyped shape such as map[string]interface{} would leave their fields out
+func (r Route) Documented() bool {
+	return r.Summary != "" && isTyped(r.Request) && isTyped(r.Response)
+}
+
+// isTyped tells if every field of a body is known up front, nil is fine since it means there is no body
+func isTyped(body interface{}) bool {
+	if body == nil {
+		return true
+	}
+
+	return !hasInterface(reflect.TypeOf(body), map[reflect.Type]bool{})
+}
+
+func hasInterface(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasInterface(t.Elem(), seen)
+	case reflect.Map:
+		return hasInterface(t.Key(), seen) || hasInterface(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if (field.PkgPath == "" || field.Anonymous) && field.Tag.Get("json") != "-" && hasInterface(field.Type, seen) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SuccessStatus is the status code the route responds with when nothing went wrong
+func (r Route) SuccessStatus() int {
+	if r.Status != 0 {
+		return r.Status
+	}
+	if r.Response == nil {
+		return http.StatusNoContent
+	}
+
+	return http.StatusOK
 }
@@ -1,7 +1,10 @@
 package services
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/tadoku/api/domain"
 	"github.com/tadoku/api/usecases"
@@ -11,19 +14,30 @@ import (
 // logging in, registering, resetting passwords, requesting new tokens, etc...
 type SessionService interface {
 	Login(ctx Context) error
+	CompleteTwoFactor(ctx Context) error
 	Register(ctx Context) error
 	Refresh(ctx Context) error
+	Logout(ctx Context) error
+	ActiveSessions(ctx Context) error
+	RevokeSession(ctx Context) error
+	RevokeAll(ctx Context) error
+	RequestPasswordReset(ctx Context) error
+	ConfirmPasswordReset(ctx Context) error
+	VerifyEmail(ctx Context) error
+	ResendEmailVerification(ctx Context) error
 }
 
 // NewSessionService initializer
-func NewSessionService(sessionInteractor usecases.SessionInteractor) SessionService {
+func NewSessionService(sessionInteractor usecases.SessionInteractor, loginThrottleInteractor usecases.LoginThrottleInteractor) SessionService {
 	return &sessionService{
-		SessionInteractor: sessionInteractor,
+		SessionInteractor:       sessionInteractor,
+		LoginThrottleInteractor: loginThrottleInteractor,
 	}
 }
 
 type sessionService struct {
-	SessionInteractor usecases.SessionInteractor
+	SessionInteractor       usecases.SessionInteractor
+	LoginThrottleInteractor usecases.LoginThrottleInteractor
 }
 
 // SessionLoginBody is the data that's needed to log in
@@ -39,18 +53,107 @@ func (s *sessionService) Login(ctx Context) error {
 		return domain.WrapError(err)
 	}
 
-	user, token, err := s.SessionInteractor.CreateSession(b.Email, b.Password)
+	client := sessionClient(ctx)
+
+	retryAfter, err := s.LoginThrottleInteractor.Check(ctx.RequestContext(), b.Email, client.IPAddress)
+	if err == usecases.ErrLoginThrottled {
+		return loginThrottled(ctx, retryAfter)
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	user, tokens, err := s.SessionInteractor.CreateSession(ctx.RequestContext(), b.Email, b.Password, client)
+	if err == usecases.ErrLoginThrottled {
+		// This attempt is the one that caused the lockout
+		retryAfter, _ := s.LoginThrottleInteractor.Check(ctx.RequestContext(), b.Email, client.IPAddress)
+		return loginThrottled(ctx, retryAfter)
+	}
+	if err == usecases.ErrEmailNotVerified || err == usecases.ErrUserDisabled {
+		return problem(ctx, http.StatusForbidden, err)
+	}
+	// The failed attempt is still returned, so it shows up in the access log
+	if isError(err, usecases.ErrUserDoesNotExist) || isError(err, usecases.ErrPasswordIncorrect) {
+		problem(ctx, http.StatusUnauthorized, errInvalidCredentials)
+		return domain.WrapError(err)
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if tokens.RequiresTwoFactor() {
+		return ctx.JSON(http.StatusOK, SessionLoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    tokens.ChallengeToken,
+		})
+	}
+
+	session := sessionResponse(user, tokens)
+	return ctx.JSON(http.StatusOK, SessionLoginResponse{SessionResponse: &session})
+}
+
+// loginThrottled tells the client how long to wait before logging in again, when that's known
+func loginThrottled(ctx Context, retryAfter time.Duration) error {
+	if retryAfter > 0 {
+		ctx.SetHeader("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	return problem(ctx, http.StatusTooManyRequests, usecases.ErrLoginThrottled)
+}
+
+// SessionTwoFactorBody is the data that's needed to finish logging in with two factor authentication
+type SessionTwoFactorBody struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+func (s *sessionService) CompleteTwoFactor(ctx Context) error {
+	b := &SessionTwoFactorBody{}
+	err := ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	user, tokens, retryAfter, err := s.SessionInteractor.CompleteTwoFactorChallenge(ctx.RequestContext(), b.ChallengeToken, b.Code, sessionClient(ctx))
+	if err == usecases.ErrLoginThrottled {
+		return loginThrottled(ctx, retryAfter)
+	}
+	if err == usecases.ErrTwoFactorChallengeInvalid || err == usecases.ErrTwoFactorCodeInvalid {
+		return problem(ctx, http.StatusUnauthorized, err)
+	}
 	if err != nil {
-		ctx.NoContent(http.StatusUnauthorized)
 		return domain.WrapError(err)
 	}
 
-	res := map[string]interface{}{
-		"token": token,
-		"user":  user,
+	return ctx.JSON(http.StatusOK, sessionResponse(user, tokens))
+}
+
+// SessionResponse holds the tokens of a new session, together with the user it belongs to
+type SessionResponse struct {
+	Token        string      `json:"token"`
+	RefreshToken string      `json:"refresh_token"`
+	User         domain.User `json:"user"`
+}
+
+// SessionLoginResponse is either a new session, or a challenge to finish when two factor authentication is enabled
+type SessionLoginResponse struct {
+	*SessionResponse
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+}
+
+func sessionResponse(user domain.User, tokens usecases.SessionTokens) SessionResponse {
+	return SessionResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		User:         user,
 	}
+}
 
-	return ctx.JSON(http.StatusOK, res)
+func sessionClient(ctx Context) usecases.SessionClient {
+	return usecases.SessionClient{
+		UserAgent: ctx.Request().UserAgent(),
+		IPAddress: ctx.RealIP(),
+	}
 }
 
 func (s *sessionService) Register(ctx Context) error {
@@ -63,7 +166,14 @@ func (s *sessionService) Register(ctx Context) error {
 	user.Role = domain.RoleUser
 	user.Preferences = &domain.Preferences{}
 
-	err = s.SessionInteractor.CreateUser(*user)
+	err = s.SessionInteractor.CreateUser(ctx.RequestContext(), *user)
+	// The account exists regardless, the error still gets reported while the user can ask for another mail
+	if isError(err, usecases.ErrEmailVerificationNotSent) {
+		if writeErr := ctx.NoContent(http.StatusCreated); writeErr != nil {
+			return domain.WrapError(writeErr)
+		}
+		return domain.WrapError(err)
+	}
 	if err != nil {
 		return domain.WrapError(err)
 	}
@@ -71,22 +181,196 @@ func (s *sessionService) Register(ctx Context) error {
 	return ctx.NoContent(http.StatusCreated)
 }
 
+// SessionRefreshBody is the data that's needed to refresh or end a session
+type SessionRefreshBody struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 func (s *sessionService) Refresh(ctx Context) error {
-	sessionUser, err := ctx.User()
+	b := &SessionRefreshBody{}
+	err := ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	user, tokens, err := s.SessionInteractor.RefreshSession(ctx.RequestContext(), b.RefreshToken, sessionClient(ctx))
+	if isError(err, usecases.ErrRefreshTokenInvalid) || isError(err, usecases.ErrRefreshTokenReused) {
+		problem(ctx, http.StatusUnauthorized, rootError(err))
+		return domain.WrapError(err)
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, sessionResponse(user, tokens))
+}
+
+func (s *sessionService) Logout(ctx Context) error {
+	b := &SessionRefreshBody{}
+	err := ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = s.SessionInteractor.RevokeSession(ctx.RequestContext(), b.RefreshToken)
+	// Logging out of a session that's already gone is fine
+	if err != nil && err != usecases.ErrRefreshTokenInvalid {
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// SessionListEntry is a session as shown to its owner
+type SessionListEntry struct {
+	domain.Session
+	Current bool `json:"current"`
+}
+
+func (s *sessionService) ActiveSessions(ctx Context) error {
+	user, err := ctx.User()
 	if err != nil {
 		return domain.WrapError(err)
 	}
 
-	user, token, err := s.SessionInteractor.RefreshSession(*sessionUser)
+	sessions, err := s.SessionInteractor.ActiveSessions(ctx.RequestContext(), user.ID)
 	if err != nil {
-		ctx.NoContent(http.StatusUnauthorized)
 		return domain.WrapError(err)
 	}
 
-	res := map[string]interface{}{
-		"token": token,
-		"user":  user,
+	currentID := ctx.Claims().SessionID
+	entries := make([]SessionListEntry, len(sessions))
+	for i, session := range sessions {
+		entries[i] = SessionListEntry{Session: session, Current: session.ID == currentID}
+	}
+
+	return ctx.JSON(http.StatusOK, entries)
+}
+
+func (s *sessionService) RevokeSession(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	var id uint64
+	if err := ctx.BindID(&id); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+
+	err = s.SessionInteractor.RevokeUserSession(ctx.RequestContext(), user.ID, id)
+	if err != nil {
+		if err == usecases.ErrSessionNotFound {
+			return problem(ctx, http.StatusNotFound, err)
+		}
+
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (s *sessionService) RevokeAll(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = s.SessionInteractor.RevokeAllSessions(ctx.RequestContext(), user.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// SessionRequestPasswordResetBody is the data that's needed to request a password reset link
+type SessionRequestPasswordResetBody struct {
+	Email string `json:"email"`
+}
+
+func (s *sessionService) RequestPasswordReset(ctx Context) error {
+	b := &SessionRequestPasswordResetBody{}
+	err := ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = s.SessionInteractor.RequestPasswordReset(ctx.RequestContext(), b.Email)
+	// Unknown addresses get the same response so this can't be used to find out who has an account
+	if err != nil && err != usecases.ErrUserDoesNotExist {
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// SessionConfirmPasswordResetBody is the data that's needed to pick a new password with a reset token
+type SessionConfirmPasswordResetBody struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (s *sessionService) ConfirmPasswordReset(ctx Context) error {
+	b := &SessionConfirmPasswordResetBody{}
+	err := ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = s.SessionInteractor.ResetPassword(ctx.RequestContext(), b.Token, b.Password)
+	if err != nil {
+		if err == usecases.ErrPasswordResetTokenInvalid {
+			return problem(ctx, http.StatusBadRequest, err)
+		}
+
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// SessionVerifyEmailBody is the data that's needed to verify an email address
+type SessionVerifyEmailBody struct {
+	Token string `json:"token"`
+}
+
+func (s *sessionService) VerifyEmail(ctx Context) error {
+	b := &SessionVerifyEmailBody{}
+	err := ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = s.SessionInteractor.VerifyEmail(ctx.RequestContext(), b.Token)
+	if err != nil {
+		if err == usecases.ErrEmailVerificationTokenInvalid {
+			return problem(ctx, http.StatusBadRequest, err)
+		}
+
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// SessionResendEmailVerificationBody is the data that's needed to get a new verification link
+type SessionResendEmailVerificationBody struct {
+	Email string `json:"email"`
+}
+
+func (s *sessionService) ResendEmailVerification(ctx Context) error {
+	b := &SessionResendEmailVerificationBody{}
+	err := ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = s.SessionInteractor.ResendEmailVerification(ctx.RequestContext(), b.Email)
+	// Same as with password resets, we don't want to leak which addresses are in use
+	if err != nil && err != usecases.ErrUserDoesNotExist && err != usecases.ErrEmailAlreadyVerified {
+		return domain.WrapError(err)
 	}
 
-	return ctx.JSON(http.StatusOK, res)
+	return ctx.NoContent(http.StatusNoContent)
 }
@@ -1,7 +1,10 @@
 package services_test
 
 import (
+	"errors"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -21,7 +24,7 @@ func TestSessionService_Register(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := services.NewMockContext(ctrl)
+	ctx := newMockContext(ctrl)
 	ctx.EXPECT().NoContent(201)
 	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *user)
 
@@ -29,12 +32,23 @@ func TestSessionService_Register(t *testing.T) {
 	user.Preferences = &domain.Preferences{}
 
 	i := usecases.NewMockSessionInteractor(ctrl)
-	i.EXPECT().CreateUser(*user).Return(nil)
+	i.EXPECT().CreateUser(gomock.Any(), *user).Return(nil)
 
-	s := services.NewSessionService(i)
+	s := services.NewSessionService(i, nil)
 	err := s.Register(ctx)
 
 	assert.NoError(t, err)
+
+	{
+		// Sad path: the account got created without its verification mail, which still gets reported
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().NoContent(201)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *user)
+		i.EXPECT().CreateUser(gomock.Any(), *user).Return(domain.WrapError(usecases.ErrEmailVerificationNotSent))
+
+		err := s.Register(ctx)
+		assert.EqualError(t, err, usecases.ErrEmailVerificationNotSent.Error())
+	}
 }
 
 func TestSessionService_Login(t *testing.T) {
@@ -43,7 +57,11 @@ func TestSessionService_Login(t *testing.T) {
 		DisplayName: "John Doe",
 		Password:    "foobar",
 	}
-	token := "foobar"
+	tokens := usecases.SessionTokens{AccessToken: "foobar", RefreshToken: "barbar"}
+
+	client := usecases.SessionClient{UserAgent: "Mozilla/5.0", IPAddress: "127.0.0.1"}
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("User-Agent", client.UserAgent)
 
 	b := &services.SessionLoginBody{
 		Email:    "foo@bar.com",
@@ -53,22 +71,251 @@ func TestSessionService_Login(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := services.NewMockContext(ctrl)
-	ctx.EXPECT().JSON(200, map[string]interface{}{
-		"token": token,
-		"user":  *user,
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().JSON(200, services.SessionLoginResponse{SessionResponse: &services.SessionResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		User:         *user,
+	}})
+	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+	ctx.EXPECT().Request().Return(req)
+	ctx.EXPECT().RealIP().Return(client.IPAddress)
+
+	i := usecases.NewMockSessionInteractor(ctrl)
+	i.EXPECT().CreateSession(gomock.Any(), b.Email, b.Password, client).Return(*user, tokens, nil)
+
+	throttle := usecases.NewMockLoginThrottleInteractor(ctrl)
+	throttle.EXPECT().Check(gomock.Any(), b.Email, client.IPAddress).Return(time.Duration(0), nil)
+
+	s := services.NewSessionService(i, throttle)
+	err := s.Login(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestSessionService_LoginThrottled(t *testing.T) {
+	client := usecases.SessionClient{UserAgent: "Mozilla/5.0", IPAddress: "127.0.0.1"}
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("User-Agent", client.UserAgent)
+
+	b := &services.SessionLoginBody{
+		Email:    "foo@bar.com",
+		Password: "foobar",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+	ctx.EXPECT().Request().Return(req)
+	ctx.EXPECT().RealIP().Return(client.IPAddress)
+	ctx.EXPECT().SetHeader("Retry-After", "91")
+	expectProblem(t, ctx, 429, "login_throttled")
+
+	i := usecases.NewMockSessionInteractor(ctrl)
+
+	throttle := usecases.NewMockLoginThrottleInteractor(ctrl)
+	throttle.EXPECT().Check(gomock.Any(), b.Email, client.IPAddress).Return(90*time.Second+time.Millisecond, usecases.ErrLoginThrottled)
+
+	s := services.NewSessionService(i, throttle)
+	err := s.Login(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestSessionService_LoginThrottledByThisAttempt(t *testing.T) {
+	client := usecases.SessionClient{UserAgent: "Mozilla/5.0", IPAddress: "127.0.0.1"}
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("User-Agent", client.UserAgent)
+
+	b := &services.SessionLoginBody{
+		Email:    "foo@bar.com",
+		Password: "barbar",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+	ctx.EXPECT().Request().Return(req)
+	ctx.EXPECT().RealIP().Return(client.IPAddress)
+	ctx.EXPECT().SetHeader("Retry-After", "2")
+	expectProblem(t, ctx, 429, "login_throttled")
+
+	i := usecases.NewMockSessionInteractor(ctrl)
+	i.EXPECT().CreateSession(gomock.Any(), b.Email, b.Password, client).Return(domain.User{}, usecases.SessionTokens{}, usecases.ErrLoginThrottled)
+
+	throttle := usecases.NewMockLoginThrottleInteractor(ctrl)
+	gomock.InOrder(
+		throttle.EXPECT().Check(gomock.Any(), b.Email, client.IPAddress).Return(time.Duration(0), nil),
+		throttle.EXPECT().Check(gomock.Any(), b.Email, client.IPAddress).Return(2*time.Second, usecases.ErrLoginThrottled),
+	)
+
+	s := services.NewSessionService(i, throttle)
+	err := s.Login(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestSessionService_LoginInvalidCredentials(t *testing.T) {
+	client := usecases.SessionClient{UserAgent: "Mozilla/5.0", IPAddress: "127.0.0.1"}
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("User-Agent", client.UserAgent)
+
+	b := &services.SessionLoginBody{
+		Email:    "foo@bar.com",
+		Password: "barbar",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Unknown users look the same as wrong passwords
+	for _, interactorErr := range []error{usecases.ErrPasswordIncorrect, usecases.ErrUserDoesNotExist} {
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().Request().Return(req)
+		ctx.EXPECT().RealIP().Return(client.IPAddress)
+		expectProblem(t, ctx, 401, "invalid_credentials")
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().CreateSession(gomock.Any(), b.Email, b.Password, client).Return(domain.User{}, usecases.SessionTokens{}, interactorErr)
+
+		throttle := usecases.NewMockLoginThrottleInteractor(ctrl)
+		throttle.EXPECT().Check(gomock.Any(), b.Email, client.IPAddress).Return(time.Duration(0), nil)
+
+		s := services.NewSessionService(i, throttle)
+		err := s.Login(ctx)
+
+		assert.EqualError(t, err, interactorErr.Error())
+	}
+
+	// Other failures are left to the error handler, instead of being passed off as wrong credentials
+	{
+		interactorErr := errors.New("database went away")
+
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().Request().Return(req)
+		ctx.EXPECT().RealIP().Return(client.IPAddress)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().CreateSession(gomock.Any(), b.Email, b.Password, client).Return(domain.User{}, usecases.SessionTokens{}, interactorErr)
+
+		throttle := usecases.NewMockLoginThrottleInteractor(ctrl)
+		throttle.EXPECT().Check(gomock.Any(), b.Email, client.IPAddress).Return(time.Duration(0), nil)
+
+		s := services.NewSessionService(i, throttle)
+		err := s.Login(ctx)
+
+		assert.EqualError(t, err, interactorErr.Error())
+	}
+}
+
+func TestSessionService_LoginWithTwoFactor(t *testing.T) {
+	client := usecases.SessionClient{UserAgent: "Mozilla/5.0", IPAddress: "127.0.0.1"}
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("User-Agent", client.UserAgent)
+
+	b := &services.SessionLoginBody{
+		Email:    "foo@bar.com",
+		Password: "foobar",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().JSON(200, services.SessionLoginResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    "challenge",
 	})
 	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+	ctx.EXPECT().Request().Return(req)
+	ctx.EXPECT().RealIP().Return(client.IPAddress)
 
 	i := usecases.NewMockSessionInteractor(ctrl)
-	i.EXPECT().CreateSession(b.Email, b.Password).Return(*user, token, nil)
+	i.EXPECT().CreateSession(gomock.Any(), b.Email, b.Password, client).Return(domain.User{}, usecases.SessionTokens{ChallengeToken: "challenge"}, nil)
 
-	s := services.NewSessionService(i)
+	throttle := usecases.NewMockLoginThrottleInteractor(ctrl)
+	throttle.EXPECT().Check(gomock.Any(), b.Email, client.IPAddress).Return(time.Duration(0), nil)
+
+	s := services.NewSessionService(i, throttle)
 	err := s.Login(ctx)
 
 	assert.NoError(t, err)
 }
 
+func TestSessionService_CompleteTwoFactor(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "foo@bar.com", Role: domain.RoleUser}
+	tokens := usecases.SessionTokens{AccessToken: "foobar", RefreshToken: "barbar"}
+	b := &services.SessionTwoFactorBody{ChallengeToken: "challenge", Code: "123456"}
+	client := usecases.SessionClient{UserAgent: "Mozilla/5.0", IPAddress: "127.0.0.1"}
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("User-Agent", client.UserAgent)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: correct code
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().JSON(200, services.SessionResponse{
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			User:         *user,
+		})
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().Request().Return(req)
+		ctx.EXPECT().RealIP().Return(client.IPAddress)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().CompleteTwoFactorChallenge(gomock.Any(), b.ChallengeToken, b.Code, client).Return(*user, tokens, time.Duration(0), nil)
+
+		s := services.NewSessionService(i, nil)
+		err := s.CompleteTwoFactor(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: wrong code
+	{
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 401, "two_factor_code_invalid")
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().Request().Return(req)
+		ctx.EXPECT().RealIP().Return(client.IPAddress)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().CompleteTwoFactorChallenge(gomock.Any(), b.ChallengeToken, b.Code, client).Return(domain.User{}, usecases.SessionTokens{}, time.Duration(0), usecases.ErrTwoFactorCodeInvalid)
+
+		s := services.NewSessionService(i, nil)
+		err := s.CompleteTwoFactor(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: too many wrong codes
+	{
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 429, "login_throttled")
+		ctx.EXPECT().SetHeader("Retry-After", "60")
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().Request().Return(req)
+		ctx.EXPECT().RealIP().Return(client.IPAddress)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().CompleteTwoFactorChallenge(gomock.Any(), b.ChallengeToken, b.Code, client).Return(domain.User{}, usecases.SessionTokens{}, time.Minute, usecases.ErrLoginThrottled)
+
+		s := services.NewSessionService(i, nil)
+		err := s.CompleteTwoFactor(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
 func TestSessionService_Refresh(t *testing.T) {
 	user := &domain.User{
 		ID:          1,
@@ -76,23 +323,331 @@ func TestSessionService_Refresh(t *testing.T) {
 		DisplayName: "John Doe",
 		Role:        domain.RoleUser,
 	}
-	token := "foobar"
+	tokens := usecases.SessionTokens{AccessToken: "foobar", RefreshToken: "barbar"}
+	b := &services.SessionRefreshBody{RefreshToken: "foofoo"}
+	client := usecases.SessionClient{UserAgent: "Mozilla/5.0", IPAddress: "127.0.0.1"}
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("User-Agent", client.UserAgent)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: tokens get rotated
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().JSON(200, services.SessionResponse{
+			Token:        tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			User:         *user,
+		})
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().Request().Return(req)
+		ctx.EXPECT().RealIP().Return(client.IPAddress)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().RefreshSession(gomock.Any(), b.RefreshToken, client).Return(*user, tokens, nil)
+
+		s := services.NewSessionService(i, nil)
+		err := s.Refresh(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: refresh token was already used
+	{
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 401, "refresh_token_reused")
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().Request().Return(req)
+		ctx.EXPECT().RealIP().Return(client.IPAddress)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().RefreshSession(gomock.Any(), b.RefreshToken, client).Return(domain.User{}, usecases.SessionTokens{}, usecases.ErrRefreshTokenReused)
+
+		s := services.NewSessionService(i, nil)
+		err := s.Refresh(ctx)
+
+		assert.EqualError(t, err, usecases.ErrRefreshTokenReused.Error())
+	}
+
+	// Sad path: refresh token doesn't exist
+	{
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 401, "refresh_token_invalid")
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().Request().Return(req)
+		ctx.EXPECT().RealIP().Return(client.IPAddress)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().RefreshSession(gomock.Any(), b.RefreshToken, client).Return(domain.User{}, usecases.SessionTokens{}, usecases.ErrRefreshTokenInvalid)
+
+		s := services.NewSessionService(i, nil)
+		err := s.Refresh(ctx)
+
+		assert.EqualError(t, err, usecases.ErrRefreshTokenInvalid.Error())
+	}
+
+	// Sad path: other failures are left to the error handler
+	for _, interactorErr := range []error{usecases.ErrUserDisabled, errors.New("database went away")} {
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().Request().Return(req)
+		ctx.EXPECT().RealIP().Return(client.IPAddress)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().RefreshSession(gomock.Any(), b.RefreshToken, client).Return(domain.User{}, usecases.SessionTokens{}, interactorErr)
+
+		s := services.NewSessionService(i, nil)
+		err := s.Refresh(ctx)
+
+		assert.EqualError(t, err, interactorErr.Error())
+	}
+}
+
+func TestSessionService_Logout(t *testing.T) {
+	b := &services.SessionRefreshBody{RefreshToken: "foofoo"}
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := services.NewMockContext(ctrl)
-	ctx.EXPECT().JSON(200, map[string]interface{}{
-		"token": token,
-		"user":  *user,
+	for _, interactorErr := range []error{nil, usecases.ErrRefreshTokenInvalid} {
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().NoContent(204)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().RevokeSession(gomock.Any(), b.RefreshToken).Return(interactorErr)
+
+		s := services.NewSessionService(i, nil)
+		err := s.Logout(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestSessionService_ActiveSessions(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "foo@bar.com", Role: domain.RoleUser}
+	sessions := domain.Sessions{
+		{ID: 1, UserID: user.ID, UserAgent: "Mozilla/5.0"},
+		{ID: 2, UserID: user.ID, UserAgent: "curl/7.64.1"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().User().Return(user, nil)
+	ctx.EXPECT().Claims().Return(&usecases.SessionClaims{User: user, SessionID: 2})
+	ctx.EXPECT().JSON(200, []services.SessionListEntry{
+		{Session: sessions[0], Current: false},
+		{Session: sessions[1], Current: true},
 	})
+
+	i := usecases.NewMockSessionInteractor(ctrl)
+	i.EXPECT().ActiveSessions(gomock.Any(), user.ID).Return(sessions, nil)
+
+	s := services.NewSessionService(i, nil)
+	err := s.ActiveSessions(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestSessionService_RevokeSession(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "foo@bar.com", Role: domain.RoleUser}
+	sessionID := uint64(2)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: session gets revoked
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().NoContent(204)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, sessionID)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().RevokeUserSession(gomock.Any(), user.ID, sessionID).Return(nil)
+
+		s := services.NewSessionService(i, nil)
+		err := s.RevokeSession(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: session belongs to someone else
+	{
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 404, "session_not_found")
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, sessionID)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().RevokeUserSession(gomock.Any(), user.ID, sessionID).Return(usecases.ErrSessionNotFound)
+
+		s := services.NewSessionService(i, nil)
+		err := s.RevokeSession(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestSessionService_RevokeAll(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "foo@bar.com", Role: domain.RoleUser}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().NoContent(204)
 	ctx.EXPECT().User().Return(user, nil)
 
 	i := usecases.NewMockSessionInteractor(ctrl)
-	i.EXPECT().RefreshSession(*user).Return(*user, token, nil)
+	i.EXPECT().RevokeAllSessions(gomock.Any(), user.ID).Return(nil)
 
-	s := services.NewSessionService(i)
-	err := s.Refresh(ctx)
+	s := services.NewSessionService(i, nil)
+	err := s.RevokeAll(ctx)
 
 	assert.NoError(t, err)
 }
+
+func TestSessionService_RequestPasswordReset(t *testing.T) {
+	b := &services.SessionRequestPasswordResetBody{
+		Email: "foo@bar.com",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: reset link gets sent
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().NoContent(204)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().RequestPasswordReset(gomock.Any(), b.Email).Return(nil)
+
+		s := services.NewSessionService(i, nil)
+		err := s.RequestPasswordReset(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Unknown email addresses are indistinguishable from known ones
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().NoContent(204)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().RequestPasswordReset(gomock.Any(), b.Email).Return(usecases.ErrUserDoesNotExist)
+
+		s := services.NewSessionService(i, nil)
+		err := s.RequestPasswordReset(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestSessionService_ConfirmPasswordReset(t *testing.T) {
+	b := &services.SessionConfirmPasswordResetBody{
+		Token:    "token",
+		Password: "foobar",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: password gets updated
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().NoContent(204)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().ResetPassword(gomock.Any(), b.Token, b.Password).Return(nil)
+
+		s := services.NewSessionService(i, nil)
+		err := s.ConfirmPasswordReset(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: token is invalid
+	{
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 400, "password_reset_token_invalid")
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().ResetPassword(gomock.Any(), b.Token, b.Password).Return(usecases.ErrPasswordResetTokenInvalid)
+
+		s := services.NewSessionService(i, nil)
+		err := s.ConfirmPasswordReset(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestSessionService_VerifyEmail(t *testing.T) {
+	b := &services.SessionVerifyEmailBody{
+		Token: "token",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: email gets verified
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().NoContent(204)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().VerifyEmail(gomock.Any(), b.Token).Return(nil)
+
+		s := services.NewSessionService(i, nil)
+		err := s.VerifyEmail(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: token is invalid
+	{
+		ctx := newMockContext(ctrl)
+		expectProblem(t, ctx, 400, "email_verification_token_invalid")
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().VerifyEmail(gomock.Any(), b.Token).Return(usecases.ErrEmailVerificationTokenInvalid)
+
+		s := services.NewSessionService(i, nil)
+		err := s.VerifyEmail(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestSessionService_ResendEmailVerification(t *testing.T) {
+	b := &services.SessionResendEmailVerificationBody{
+		Email: "foo@bar.com",
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	for _, interactorErr := range []error{nil, usecases.ErrUserDoesNotExist, usecases.ErrEmailAlreadyVerified} {
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().NoContent(204)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+
+		i := usecases.NewMockSessionInteractor(ctrl)
+		i.EXPECT().ResendEmailVerification(gomock.Any(), b.Email).Return(interactorErr)
+
+		s := services.NewSessionService(i, nil)
+		err := s.ResendEmailVerification(ctx)
+
+		assert.NoError(t, err)
+	}
+}
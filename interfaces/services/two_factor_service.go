@@ -0,0 +1,144 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// TwoFactorService is responsible for setting up and turning off two factor authentication
+type TwoFactorService interface {
+	Status(ctx Context) error
+	Enroll(ctx Context) error
+	Confirm(ctx Context) error
+	Disable(ctx Context) error
+}
+
+// NewTwoFactorService initializer
+func NewTwoFactorService(twoFactorInteractor usecases.TwoFactorInteractor) TwoFactorService {
+	return &twoFactorService{
+		TwoFactorInteractor: twoFactorInteractor,
+	}
+}
+
+type twoFactorService struct {
+	TwoFactorInteractor usecases.TwoFactorInteractor
+}
+
+// TwoFactorCodeBody is the data that's needed to prove you have access to your authenticator app
+type TwoFactorCodeBody struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorEnrollBody is the data that's needed to start enabling two factor authentication
+type TwoFactorEnrollBody struct {
+	Password string `json:"password"`
+}
+
+// TwoFactorDisableBody is the data that's needed to turn off two factor authentication
+type TwoFactorDisableBody struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+func (s *twoFactorService) Status(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	enabled, err := s.TwoFactorInteractor.IsEnabled(ctx.RequestContext(), user.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]bool{"enabled": enabled})
+}
+
+func (s *twoFactorService) Enroll(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	b := &TwoFactorEnrollBody{}
+	err = ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	enrollment, err := s.TwoFactorInteractor.BeginEnrollment(ctx.RequestContext(), *user, b.Password)
+	if err == usecases.ErrPasswordIncorrect {
+		return problem(ctx, http.StatusForbidden, err)
+	}
+	if err == usecases.ErrTwoFactorAlreadyEnabled {
+		return problem(ctx, http.StatusConflict, err)
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, enrollment)
+}
+
+func (s *twoFactorService) Confirm(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	b := &TwoFactorCodeBody{}
+	err = ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	codes, err := s.TwoFactorInteractor.ConfirmEnrollment(ctx.RequestContext(), user.ID, b.Code)
+	if err != nil {
+		if err == usecases.ErrTwoFactorCodeInvalid {
+			return problem(ctx, http.StatusBadRequest, err)
+		}
+		if err == usecases.ErrTwoFactorNotEnabled {
+			return problem(ctx, http.StatusNotFound, err)
+		}
+		if err == usecases.ErrTwoFactorAlreadyEnabled {
+			return problem(ctx, http.StatusConflict, err)
+		}
+
+		return domain.WrapError(err)
+	}
+
+	// Recovery codes are only shown once, they're stored hashed
+	return ctx.JSON(http.StatusOK, map[string][]string{"recovery_codes": codes})
+}
+
+func (s *twoFactorService) Disable(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	b := &TwoFactorDisableBody{}
+	err = ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = s.TwoFactorInteractor.Disable(ctx.RequestContext(), user.ID, b.Password, b.Code)
+	if err != nil {
+		if err == usecases.ErrPasswordIncorrect {
+			return problem(ctx, http.StatusForbidden, err)
+		}
+		if err == usecases.ErrTwoFactorCodeInvalid {
+			return problem(ctx, http.StatusBadRequest, err)
+		}
+		if err == usecases.ErrTwoFactorNotEnabled {
+			return problem(ctx, http.StatusNotFound, err)
+		}
+
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
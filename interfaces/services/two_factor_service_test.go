@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/interfaces/services"
+	"github.com/tadoku/api/usecases"
+)
+
+func TestTwoFactorService_Enroll(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "foo@bar.com", Role: domain.RoleUser}
+	b := &services.TwoFactorEnrollBody{Password: "foobar"}
+	enrollment := usecases.TwoFactorEnrollment{Secret: "SECRET", URI: "otpauth://totp/foo"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: secret is handed out
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().JSON(200, enrollment)
+
+		i := usecases.NewMockTwoFactorInteractor(ctrl)
+		i.EXPECT().BeginEnrollment(gomock.Any(), *user, b.Password).Return(enrollment, nil)
+
+		s := services.NewTwoFactorService(i)
+		err := s.Enroll(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: already enabled
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		expectProblem(t, ctx, 409, "two_factor_already_enabled")
+
+		i := usecases.NewMockTwoFactorInteractor(ctrl)
+		i.EXPECT().BeginEnrollment(gomock.Any(), *user, b.Password).Return(usecases.TwoFactorEnrollment{}, usecases.ErrTwoFactorAlreadyEnabled)
+
+		s := services.NewTwoFactorService(i)
+		err := s.Enroll(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: wrong password
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		expectProblem(t, ctx, 403, "password_incorrect")
+
+		i := usecases.NewMockTwoFactorInteractor(ctrl)
+		i.EXPECT().BeginEnrollment(gomock.Any(), *user, b.Password).Return(usecases.TwoFactorEnrollment{}, usecases.ErrPasswordIncorrect)
+
+		s := services.NewTwoFactorService(i)
+		err := s.Enroll(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestTwoFactorService_Confirm(t *testing.T) {
+	user := &domain.User{ID: 1, Role: domain.RoleUser}
+	b := &services.TwoFactorCodeBody{Code: "123456"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Happy path: recovery codes are shown once
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		ctx.EXPECT().JSON(200, map[string][]string{"recovery_codes": {"foo", "bar"}})
+
+		i := usecases.NewMockTwoFactorInteractor(ctrl)
+		i.EXPECT().ConfirmEnrollment(gomock.Any(), user.ID, b.Code).Return([]string{"foo", "bar"}, nil)
+
+		s := services.NewTwoFactorService(i)
+		err := s.Confirm(ctx)
+
+		assert.NoError(t, err)
+	}
+
+	// Sad path: wrong code
+	{
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+		expectProblem(t, ctx, 400, "two_factor_code_invalid")
+
+		i := usecases.NewMockTwoFactorInteractor(ctrl)
+		i.EXPECT().ConfirmEnrollment(gomock.Any(), user.ID, b.Code).Return(nil, usecases.ErrTwoFactorCodeInvalid)
+
+		s := services.NewTwoFactorService(i)
+		err := s.Confirm(ctx)
+
+		assert.NoError(t, err)
+	}
+}
+
+func TestTwoFactorService_Disable(t *testing.T) {
+	user := &domain.User{ID: 1, Role: domain.RoleUser}
+	b := &services.TwoFactorDisableBody{Password: "foobar", Code: "123456"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().User().Return(user, nil)
+	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
+	ctx.EXPECT().NoContent(204)
+
+	i := usecases.NewMockTwoFactorInteractor(ctrl)
+	i.EXPECT().Disable(gomock.Any(), user.ID, b.Password, b.Code).Return(nil)
+
+	s := services.NewTwoFactorService(i)
+	err := s.Disable(ctx)
+
+	assert.NoError(t, err)
+}
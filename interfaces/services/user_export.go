@@ -0,0 +1,127 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+)
+
+// userExportArchive bundles a user export as a zip file with a csv file for every kind of data
+func userExportArchive(export usecases.UserExport) ([]byte, error) {
+	profile := export.Profile
+	files := []struct {
+		name string
+		rows [][]string
+	}{
+		{
+			name: "profile.csv",
+			rows: [][]string{
+				{"id", "email", "display_name", "role", "email_verified_at"},
+				{
+					formatID(profile.ID),
+					profile.Email,
+					profile.DisplayName,
+					strconv.Itoa(int(profile.Role)),
+					formatTime(profile.EmailVerifiedAt),
+				},
+			},
+		},
+		{name: "preferences.csv", rows: preferenceRows(export.Preferences)},
+		{name: "rankings.csv", rows: rankingRows(export.Rankings)},
+		{name: "contest_logs.csv", rows: contestLogRows(export.ContestLogs)},
+	}
+
+	buf := &bytes.Buffer{}
+	archive := zip.NewWriter(buf)
+
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return nil, domain.WrapError(err)
+		}
+
+		if err := csv.NewWriter(f).WriteAll(file.rows); err != nil {
+			return nil, domain.WrapError(err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func preferenceRows(preferences *domain.Preferences) [][]string {
+	p := domain.Preferences{}
+	if preferences != nil {
+		p = *preferences
+	}
+
+	return [][]string{
+		{"timezone", "hide_from_rankings", "default_language_code", "default_medium_id", "units"},
+		{
+			p.Timezone,
+			strconv.FormatBool(p.HideFromRankings),
+			string(p.DefaultLanguage),
+			formatID(uint64(p.DefaultMedium)),
+			string(p.Units),
+		},
+	}
+}
+
+func rankingRows(rankings domain.Rankings) [][]string {
+	rows := [][]string{{"id", "contest_id", "language_code", "amount", "created_at", "updated_at"}}
+	for _, r := range rankings {
+		rows = append(rows, []string{
+			formatID(r.ID),
+			formatID(r.ContestID),
+			string(r.Language),
+			formatAmount(r.Amount),
+			formatTime(&r.CreatedAt),
+			formatTime(&r.UpdatedAt),
+		})
+	}
+
+	return rows
+}
+
+func contestLogRows(logs domain.ContestLogs) [][]string {
+	rows := [][]string{{"id", "contest_id", "language_code", "medium_id", "amount", "description", "created_at", "updated_at", "deleted_at"}}
+	for _, l := range logs {
+		rows = append(rows, []string{
+			formatID(l.ID),
+			formatID(l.ContestID),
+			string(l.Language),
+			formatID(uint64(l.MediumID)),
+			formatAmount(l.Amount),
+			l.Description,
+			formatTime(&l.CreatedAt),
+			formatTime(&l.UpdatedAt),
+			formatTime(l.DeletedAt),
+		})
+	}
+
+	return rows
+}
+
+func formatID(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
+
+func formatAmount(amount float32) string {
+	return strconv.FormatFloat(float64(amount), 'f', -1, 32)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
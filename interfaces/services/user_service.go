@@ -11,6 +11,13 @@ import (
 type UserService interface {
 	UpdatePassword(ctx Context) error
 	UpdateProfile(ctx Context) error
+	RequestEmailChange(ctx Context) error
+	ConfirmEmailChange(ctx Context) error
+	Profile(ctx Context) error
+	Preferences(ctx Context) error
+	UpdatePreferences(ctx Context) error
+	Export(ctx Context) error
+	Delete(ctx Context) error
 }
 
 // NewUserService initializer
@@ -42,7 +49,7 @@ func (u *userService) UpdatePassword(ctx Context) error {
 		return domain.WrapError(err)
 	}
 
-	err = u.UserInteractor.UpdatePassword(user.Email, b.CurrentPassword, b.NewPassword)
+	err = u.UserInteractor.UpdatePassword(ctx.RequestContext(), user.Email, b.CurrentPassword, b.NewPassword)
 	if err != nil {
 		return domain.WrapError(err)
 	}
@@ -69,7 +76,175 @@ func (u *userService) UpdateProfile(ctx Context) error {
 
 	user.DisplayName = b.DisplayName
 
-	err = u.UserInteractor.UpdateProfile(*user)
+	err = u.UserInteractor.UpdateProfile(ctx.RequestContext(), *user)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusOK)
+}
+
+// UserRequestEmailChangeBody is the data that's needed to change your email address
+type UserRequestEmailChangeBody struct {
+	Password string `json:"password"`
+	Email    string `json:"email"`
+}
+
+func (u *userService) RequestEmailChange(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	b := &UserRequestEmailChangeBody{}
+	err = ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = u.UserInteractor.RequestEmailChange(ctx.RequestContext(), user.ID, b.Password, b.Email)
+	if err == domain.ErrEmailInvalid {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+	if err == usecases.ErrPasswordIncorrect {
+		return problem(ctx, http.StatusForbidden, err)
+	}
+	if err == usecases.ErrEmailAlreadyInUse {
+		return problem(ctx, http.StatusConflict, err)
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusOK)
+}
+
+// UserConfirmEmailChangeBody is the data that's needed to confirm a new email address
+type UserConfirmEmailChangeBody struct {
+	Token string `json:"token"`
+}
+
+func (u *userService) ConfirmEmailChange(ctx Context) error {
+	b := &UserConfirmEmailChangeBody{}
+	err := ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = u.UserInteractor.ConfirmEmailChange(ctx.RequestContext(), b.Token)
+	if err == usecases.ErrEmailChangeRequestInvalid {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+	if err == usecases.ErrEmailAlreadyInUse {
+		return problem(ctx, http.StatusConflict, err)
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.NoContent(http.StatusOK)
+}
+
+func (u *userService) Profile(ctx Context) error {
+	var id uint64
+	if err := ctx.BindID(&id); err != nil {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+
+	viewerID, units := viewer(ctx)
+	profile, err := u.UserInteractor.Profile(ctx.RequestContext(), id, viewerID, units)
+	if err == usecases.ErrUserDoesNotExist {
+		return problem(ctx, http.StatusNotFound, err)
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, profile)
+}
+
+func (u *userService) Preferences(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	preferences, err := u.UserInteractor.Preferences(ctx.RequestContext(), user.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, preferences)
+}
+
+func (u *userService) UpdatePreferences(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	preferences := domain.Preferences{}
+	err = ctx.Bind(&preferences)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = u.UserInteractor.UpdatePreferences(ctx.RequestContext(), user.ID, preferences)
+	if isError(err, usecases.ErrInvalidPreferences) {
+		return problem(ctx, http.StatusBadRequest, err)
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ctx.JSON(http.StatusOK, preferences)
+}
+
+func (u *userService) Export(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	export, err := u.UserInteractor.Export(ctx.RequestContext(), user.ID)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if ctx.QueryParam("format") != "csv" {
+		return ctx.JSON(http.StatusOK, export)
+	}
+
+	archive, err := userExportArchive(export)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	ctx.SetHeader("Content-Disposition", `attachment; filename="tadoku-export.zip"`)
+	return ctx.Blob(http.StatusOK, "application/zip", archive)
+}
+
+// UserDeleteBody is the data that's needed to delete your account
+type UserDeleteBody struct {
+	Password string `json:"password"`
+}
+
+func (u *userService) Delete(ctx Context) error {
+	user, err := ctx.User()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	b := &UserDeleteBody{}
+	err = ctx.Bind(b)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = u.UserInteractor.DeleteAccount(ctx.RequestContext(), user.ID, b.Password)
+	if err == usecases.ErrPasswordIncorrect {
+		return problem(ctx, http.StatusForbidden, err)
+	}
 	if err != nil {
 		return domain.WrapError(err)
 	}
@@ -1,6 +1,9 @@
 package services_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -26,13 +29,13 @@ func TestUserService_UpdatePassword(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := services.NewMockContext(ctrl)
+	ctx := newMockContext(ctrl)
 	ctx.EXPECT().NoContent(200)
 	ctx.EXPECT().User().Return(user, nil)
 	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
 
 	i := usecases.NewMockUserInteractor(ctrl)
-	i.EXPECT().UpdatePassword(user.Email, b.CurrentPassword, b.NewPassword).Return(nil)
+	i.EXPECT().UpdatePassword(gomock.Any(), user.Email, b.CurrentPassword, b.NewPassword).Return(nil)
 
 	s := services.NewUserService(i)
 	err := s.UpdatePassword(ctx)
@@ -57,16 +60,276 @@ func TestUserService_UpdateProfile(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	ctx := services.NewMockContext(ctrl)
+	ctx := newMockContext(ctrl)
 	ctx.EXPECT().NoContent(200)
 	ctx.EXPECT().User().Return(&user, nil)
 	ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, *b)
 
 	i := usecases.NewMockUserInteractor(ctrl)
-	i.EXPECT().UpdateProfile(updatedUser).Return(nil)
+	i.EXPECT().UpdateProfile(gomock.Any(), updatedUser).Return(nil)
 
 	s := services.NewUserService(i)
 	err := s.UpdateProfile(ctx)
 
 	assert.NoError(t, err)
 }
+
+func TestUserService_RequestEmailChange(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "foo@bar.com"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i := usecases.NewMockUserInteractor(ctrl)
+	s := services.NewUserService(i)
+
+	for _, tc := range []struct {
+		err        error
+		statusCode int
+		code       string
+	}{
+		{nil, 200, ""},
+		{domain.ErrEmailInvalid, 400, "email_invalid"},
+		{usecases.ErrPasswordIncorrect, 403, "password_incorrect"},
+		{usecases.ErrEmailAlreadyInUse, 409, "email_already_in_use"},
+	} {
+		b := services.UserRequestEmailChangeBody{Password: "foobar", Email: "new@bar.com"}
+
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, b)
+		if tc.code == "" {
+			ctx.EXPECT().NoContent(tc.statusCode)
+		} else {
+			expectProblem(t, ctx, tc.statusCode, tc.code)
+		}
+		i.EXPECT().RequestEmailChange(gomock.Any(), user.ID, b.Password, b.Email).Return(tc.err)
+
+		err := s.RequestEmailChange(ctx)
+		assert.NoError(t, err)
+	}
+}
+
+func TestUserService_ConfirmEmailChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i := usecases.NewMockUserInteractor(ctrl)
+	s := services.NewUserService(i)
+
+	for _, tc := range []struct {
+		err        error
+		statusCode int
+		code       string
+	}{
+		{nil, 200, ""},
+		{usecases.ErrEmailChangeRequestInvalid, 400, "email_change_request_invalid"},
+		{usecases.ErrEmailAlreadyInUse, 409, "email_already_in_use"},
+	} {
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, services.UserConfirmEmailChangeBody{Token: "token"})
+		if tc.code == "" {
+			ctx.EXPECT().NoContent(tc.statusCode)
+		} else {
+			expectProblem(t, ctx, tc.statusCode, tc.code)
+		}
+		i.EXPECT().ConfirmEmailChange(gomock.Any(), "token").Return(tc.err)
+
+		err := s.ConfirmEmailChange(ctx)
+		assert.NoError(t, err)
+	}
+}
+
+func TestUserService_Profile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i := usecases.NewMockUserInteractor(ctrl)
+	s := services.NewUserService(i)
+
+	{
+		// Happy path: public profile
+		profile := domain.UserProfile{UserID: 1, DisplayName: "foo"}
+
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, uint64(1))
+		ctx.EXPECT().User().Return(&domain.User{ID: 3, Preferences: &domain.Preferences{Units: domain.UnitsPages}}, nil)
+		ctx.EXPECT().JSON(200, profile)
+		i.EXPECT().Profile(gomock.Any(), uint64(1), uint64(3), domain.UnitsPages).Return(profile, nil)
+
+		err := s.Profile(ctx)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: hidden or unknown user
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().BindID(gomock.Any()).Return(nil).SetArg(0, uint64(2))
+		ctx.EXPECT().User().Return(nil, errGuest)
+		expectProblem(t, ctx, 404, "user_not_found")
+		i.EXPECT().Profile(gomock.Any(), uint64(2), uint64(0), domain.UnitsPoints).Return(domain.UserProfile{}, usecases.ErrUserDoesNotExist)
+
+		err := s.Profile(ctx)
+		assert.NoError(t, err)
+	}
+}
+
+func TestUserService_Preferences(t *testing.T) {
+	user := &domain.User{ID: 1}
+	preferences := domain.Preferences{Timezone: "Asia/Tokyo"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := newMockContext(ctrl)
+	ctx.EXPECT().User().Return(user, nil)
+	ctx.EXPECT().JSON(200, preferences)
+
+	i := usecases.NewMockUserInteractor(ctrl)
+	i.EXPECT().Preferences(gomock.Any(), user.ID).Return(preferences, nil)
+
+	s := services.NewUserService(i)
+	err := s.Preferences(ctx)
+
+	assert.NoError(t, err)
+}
+
+func TestUserService_UpdatePreferences(t *testing.T) {
+	user := &domain.User{ID: 1}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i := usecases.NewMockUserInteractor(ctrl)
+	s := services.NewUserService(i)
+
+	{
+		// Happy path: preferences are saved
+		preferences := domain.Preferences{Timezone: "Asia/Tokyo", Units: domain.UnitsPages}
+
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, preferences)
+		ctx.EXPECT().JSON(200, preferences)
+		i.EXPECT().UpdatePreferences(gomock.Any(), user.ID, preferences).Return(nil)
+
+		err := s.UpdatePreferences(ctx)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: invalid preferences
+		preferences := domain.Preferences{Timezone: "foo"}
+
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, preferences)
+		expectProblem(t, ctx, 400, "preferences_invalid")
+		i.EXPECT().UpdatePreferences(gomock.Any(), user.ID, preferences).Return(&usecases.ValidationError{
+			Err:        usecases.ErrInvalidPreferences,
+			Violations: domain.FieldErrors{domain.NewFieldError("timezone", "timezone", domain.ErrTimezoneInvalid)},
+		})
+
+		err := s.UpdatePreferences(ctx)
+		assert.NoError(t, err)
+	}
+}
+
+func TestUserService_Export(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "foo@bar.com", DisplayName: "John Doe"}
+	export := usecases.UserExport{
+		Profile:     *user,
+		Preferences: &domain.Preferences{Timezone: "Asia/Tokyo", Units: domain.UnitsPages},
+		Rankings:    domain.Rankings{{ID: 1, ContestID: 1, UserID: 1, Language: domain.Global, Amount: 10}},
+		ContestLogs: domain.ContestLogs{{ID: 1, ContestID: 1, UserID: 1, Language: domain.Japanese, MediumID: domain.MediumBook, Amount: 10}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i := usecases.NewMockUserInteractor(ctrl)
+	s := services.NewUserService(i)
+
+	{
+		// Happy path: export as json
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().QueryParam("format").Return("")
+		ctx.EXPECT().JSON(200, export)
+		i.EXPECT().Export(gomock.Any(), user.ID).Return(export, nil)
+
+		err := s.Export(ctx)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Happy path: export as a zip of csv files
+		var archive []byte
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().QueryParam("format").Return("csv")
+		ctx.EXPECT().SetHeader("Content-Disposition", gomock.Any())
+		ctx.EXPECT().Blob(200, "application/zip", gomock.Any()).DoAndReturn(func(_ int, _ string, b []byte) error {
+			archive = b
+			return nil
+		})
+		i.EXPECT().Export(gomock.Any(), user.ID).Return(export, nil)
+
+		err := s.Export(ctx)
+		assert.NoError(t, err)
+
+		r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+		assert.NoError(t, err)
+
+		contents := map[string][][]string{}
+		for _, f := range r.File {
+			rc, err := f.Open()
+			assert.NoError(t, err)
+			rows, err := csv.NewReader(rc).ReadAll()
+			assert.NoError(t, err)
+			rc.Close()
+			contents[f.Name] = rows
+		}
+
+		assert.Equal(t, "foo@bar.com", contents["profile.csv"][1][1])
+		assert.Equal(t, "Asia/Tokyo", contents["preferences.csv"][1][0])
+		assert.Equal(t, "pages", contents["preferences.csv"][1][4])
+		assert.Len(t, contents["rankings.csv"], 2)
+		assert.Len(t, contents["contest_logs.csv"], 2)
+		assert.Equal(t, "", contents["contest_logs.csv"][1][8], "logs that weren't deleted have no deletion date")
+	}
+}
+
+func TestUserService_Delete(t *testing.T) {
+	user := &domain.User{ID: 1, Email: "foo@bar.com"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	i := usecases.NewMockUserInteractor(ctrl)
+	s := services.NewUserService(i)
+
+	{
+		// Happy path: correct password
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, services.UserDeleteBody{Password: "foobar"})
+		ctx.EXPECT().NoContent(200)
+		i.EXPECT().DeleteAccount(gomock.Any(), user.ID, "foobar").Return(nil)
+
+		err := s.Delete(ctx)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: incorrect password
+		ctx := newMockContext(ctrl)
+		ctx.EXPECT().User().Return(user, nil)
+		ctx.EXPECT().Bind(gomock.Any()).Return(nil).SetArg(0, services.UserDeleteBody{Password: "barbar"})
+		expectProblem(t, ctx, 403, "password_incorrect")
+		i.EXPECT().DeleteAccount(gomock.Any(), user.ID, "barbar").Return(usecases.ErrPasswordIncorrect)
+
+		err := s.Delete(ctx)
+		assert.NoError(t, err)
+	}
+}
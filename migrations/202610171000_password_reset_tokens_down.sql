@@ -0,0 +1,2 @@
+drop table password_reset_tokens cascade;
+drop sequence if exists password_reset_token_seq;
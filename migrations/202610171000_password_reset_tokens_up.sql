@@ -0,0 +1,16 @@
+drop sequence if exists password_reset_token_seq;
+create sequence password_reset_token_seq;
+
+create table password_reset_tokens (
+  id bigint check (id > 0) not null default nextval ('password_reset_token_seq'),
+  user_id bigint not null,
+  token_hash varchar(64) not null unique,
+  expires_at timestamp not null,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index password_reset_tokens_user_id on password_reset_tokens(user_id);
+
+alter sequence password_reset_token_seq restart with 1;
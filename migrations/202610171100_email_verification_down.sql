@@ -0,0 +1,4 @@
+drop table email_verification_tokens cascade;
+drop sequence if exists email_verification_token_seq;
+
+alter table users drop column email_verified_at;
@@ -0,0 +1,21 @@
+alter table users add column email_verified_at timestamp default null;
+
+-- Everyone who signed up before verification existed is trusted as is
+update users set email_verified_at = now() at time zone 'utc';
+
+drop sequence if exists email_verification_token_seq;
+create sequence email_verification_token_seq;
+
+create table email_verification_tokens (
+  id bigint check (id > 0) not null default nextval ('email_verification_token_seq'),
+  user_id bigint not null,
+  token_hash varchar(64) not null unique,
+  expires_at timestamp not null,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index email_verification_tokens_user_id on email_verification_tokens(user_id);
+
+alter sequence email_verification_token_seq restart with 1;
@@ -0,0 +1,5 @@
+drop table sessions cascade;
+drop table refresh_tokens cascade;
+
+drop sequence if exists session_seq;
+drop sequence if exists refresh_token_seq;
@@ -0,0 +1,29 @@
+drop sequence if exists session_seq;
+drop sequence if exists refresh_token_seq;
+create sequence session_seq;
+create sequence refresh_token_seq;
+
+create table sessions (
+  id bigint check (id > 0) not null default nextval ('session_seq'),
+  user_id bigint not null,
+  expires_at timestamp not null,
+  revoked_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index sessions_user_id on sessions(user_id);
+
+create table refresh_tokens (
+  id bigint check (id > 0) not null default nextval ('refresh_token_seq'),
+  session_id bigint not null,
+  token_hash varchar(64) not null unique,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index refresh_tokens_session_id on refresh_tokens(session_id);
+
+alter sequence session_seq restart with 1;
+alter sequence refresh_token_seq restart with 1;
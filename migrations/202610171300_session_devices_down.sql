@@ -0,0 +1,3 @@
+alter table sessions drop column if exists user_agent;
+alter table sessions drop column if exists ip_address;
+alter table sessions drop column if exists last_seen_at;
@@ -0,0 +1,7 @@
+alter table sessions add column user_agent text not null default '';
+alter table sessions add column ip_address varchar(45) not null default '';
+alter table sessions add column last_seen_at timestamp default null;
+
+update sessions set last_seen_at = created_at;
+
+alter table sessions alter column last_seen_at set not null;
@@ -0,0 +1,3 @@
+drop table personal_access_tokens cascade;
+
+drop sequence if exists personal_access_token_seq;
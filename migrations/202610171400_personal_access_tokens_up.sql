@@ -0,0 +1,18 @@
+drop sequence if exists personal_access_token_seq;
+create sequence personal_access_token_seq;
+
+create table personal_access_tokens (
+  id bigint check (id > 0) not null default nextval ('personal_access_token_seq'),
+  user_id bigint not null,
+  name varchar(100) not null,
+  token_hash varchar(64) not null unique,
+  scopes varchar(255) not null,
+  last_used_at timestamp default null,
+  revoked_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index personal_access_tokens_user_id on personal_access_tokens(user_id);
+
+alter sequence personal_access_token_seq restart with 1;
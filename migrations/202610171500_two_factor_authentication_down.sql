@@ -0,0 +1,8 @@
+drop table two_factor_authentications cascade;
+drop table recovery_codes cascade;
+drop table two_factor_challenges cascade;
+
+drop sequence if exists recovery_code_seq;
+drop sequence if exists two_factor_challenge_seq;
+
+alter table sessions drop column if exists two_factor_verified;
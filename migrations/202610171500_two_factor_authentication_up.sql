@@ -0,0 +1,40 @@
+drop sequence if exists recovery_code_seq;
+drop sequence if exists two_factor_challenge_seq;
+create sequence recovery_code_seq;
+create sequence two_factor_challenge_seq;
+
+create table two_factor_authentications (
+  user_id bigint not null,
+  secret varchar(64) not null,
+  last_used_step bigint not null default 0,
+  confirmed_at timestamp default null,
+  created_at timestamp not null,
+  primary key (user_id)
+);
+
+create table recovery_codes (
+  id bigint check (id > 0) not null default nextval ('recovery_code_seq'),
+  user_id bigint not null,
+  code_hash varchar(64) not null,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index recovery_codes_user_id on recovery_codes(user_id);
+
+create table two_factor_challenges (
+  id bigint check (id > 0) not null default nextval ('two_factor_challenge_seq'),
+  user_id bigint not null,
+  token_hash varchar(64) not null unique,
+  failed_attempts integer not null default 0,
+  expires_at timestamp not null,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+alter table sessions add column two_factor_verified boolean not null default false;
+
+alter sequence recovery_code_seq restart with 1;
+alter sequence two_factor_challenge_seq restart with 1;
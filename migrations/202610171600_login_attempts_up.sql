@@ -0,0 +1,6 @@
+create table login_attempts (
+  key varchar(300) not null,
+  failures integer not null default 0,
+  last_failed_at timestamp not null,
+  primary key (key)
+);
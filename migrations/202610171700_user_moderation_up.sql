@@ -0,0 +1,2 @@
+alter table users add column disabled_reason varchar(500) not null default '';
+alter table users add column disabled_at timestamp default null;
@@ -0,0 +1,2 @@
+drop table email_change_requests cascade;
+drop sequence if exists email_change_request_seq;
@@ -0,0 +1,17 @@
+drop sequence if exists email_change_request_seq;
+create sequence email_change_request_seq;
+
+create table email_change_requests (
+  id bigint check (id > 0) not null default nextval ('email_change_request_seq'),
+  user_id bigint not null,
+  new_email varchar(255) not null,
+  token_hash varchar(64) not null unique,
+  expires_at timestamp not null,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index email_change_requests_user_id on email_change_requests(user_id);
+
+alter sequence email_change_request_seq restart with 1;
@@ -0,0 +1,10 @@
+alter table users add column created_at timestamp;
+
+-- Nobody knows when existing users signed up, their first contest entry is the best guess
+update users set created_at = coalesce(
+  (select min(created_at) from rankings where rankings.user_id = users.id),
+  now() at time zone 'utc'
+);
+
+alter table users alter column created_at set not null;
+alter table users alter column created_at set default (now() at time zone 'utc');
@@ -0,0 +1,2 @@
+drop table audit_events cascade;
+drop sequence if exists audit_event_seq;
@@ -0,0 +1,20 @@
+drop sequence if exists audit_event_seq;
+create sequence audit_event_seq;
+
+create table audit_events (
+  id bigint check (id > 0) not null default nextval ('audit_event_seq'),
+  actor_id bigint not null,
+  action varchar(50) not null,
+  target_type varchar(50) not null,
+  target_id bigint not null,
+  changes jsonb not null default '{}',
+  ip_address varchar(45) not null default '',
+  user_agent text not null default '',
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index audit_events_actor_id on audit_events(actor_id);
+create index audit_events_target on audit_events(target_type, target_id);
+
+alter sequence audit_event_seq restart with 1;
@@ -0,0 +1,58 @@
+-- Recreates the tables exactly as 202610171000, 202610171100 and 202610171800 created them, so their down migrations still apply
+drop sequence if exists password_reset_token_seq;
+create sequence password_reset_token_seq;
+
+create table password_reset_tokens (
+  id bigint check (id > 0) not null default nextval ('password_reset_token_seq'),
+  user_id bigint not null,
+  token_hash varchar(64) not null unique,
+  expires_at timestamp not null,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index password_reset_tokens_user_id on password_reset_tokens(user_id);
+
+drop sequence if exists email_verification_token_seq;
+create sequence email_verification_token_seq;
+
+create table email_verification_tokens (
+  id bigint check (id > 0) not null default nextval ('email_verification_token_seq'),
+  user_id bigint not null,
+  token_hash varchar(64) not null unique,
+  expires_at timestamp not null,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index email_verification_tokens_user_id on email_verification_tokens(user_id);
+
+drop sequence if exists email_change_request_seq;
+create sequence email_change_request_seq;
+
+create table email_change_requests (
+  id bigint check (id > 0) not null default nextval ('email_change_request_seq'),
+  user_id bigint not null,
+  new_email varchar(255) not null,
+  token_hash varchar(64) not null unique,
+  expires_at timestamp not null,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index email_change_requests_user_id on email_change_requests(user_id);
+
+insert into password_reset_tokens (user_id, token_hash, expires_at, used_at, created_at)
+select user_id, token_hash, expires_at, used_at, created_at from one_time_tokens where purpose = 'password_reset';
+
+insert into email_verification_tokens (user_id, token_hash, expires_at, used_at, created_at)
+select user_id, token_hash, expires_at, used_at, created_at from one_time_tokens where purpose = 'email_verification';
+
+insert into email_change_requests (user_id, new_email, token_hash, expires_at, used_at, created_at)
+select user_id, payload, token_hash, expires_at, used_at, created_at from one_time_tokens where purpose = 'email_change';
+
+drop table one_time_tokens cascade;
+drop sequence if exists one_time_token_seq;
@@ -0,0 +1,32 @@
+drop sequence if exists one_time_token_seq;
+create sequence one_time_token_seq;
+
+create table one_time_tokens (
+  id bigint check (id > 0) not null default nextval ('one_time_token_seq'),
+  user_id bigint not null,
+  purpose varchar(30) not null,
+  payload varchar(255) not null default '',
+  token_hash varchar(64) not null unique,
+  expires_at timestamp not null,
+  used_at timestamp default null,
+  created_at timestamp not null,
+  primary key (id)
+);
+
+create index one_time_tokens_user_id_purpose on one_time_tokens(user_id, purpose);
+
+insert into one_time_tokens (user_id, purpose, token_hash, expires_at, used_at, created_at)
+select user_id, 'password_reset', token_hash, expires_at, used_at, created_at from password_reset_tokens;
+
+insert into one_time_tokens (user_id, purpose, token_hash, expires_at, used_at, created_at)
+select user_id, 'email_verification', token_hash, expires_at, used_at, created_at from email_verification_tokens;
+
+insert into one_time_tokens (user_id, purpose, payload, token_hash, expires_at, used_at, created_at)
+select user_id, 'email_change', new_email, token_hash, expires_at, used_at, created_at from email_change_requests;
+
+drop table password_reset_tokens cascade;
+drop sequence if exists password_reset_token_seq;
+drop table email_verification_tokens cascade;
+drop sequence if exists email_verification_token_seq;
+drop table email_change_requests cascade;
+drop sequence if exists email_change_request_seq;
@@ -0,0 +1 @@
+drop index if exists login_attempts_last_failed_at;
@@ -0,0 +1,4 @@
+-- Account keys are hashed from now on, the ones holding plain email addresses would never be looked up again
+delete from login_attempts where key like 'account:%';
+
+create index login_attempts_last_failed_at on login_attempts(last_failed_at);
@@ -0,0 +1,5 @@
+-- The role a user had before they got disabled, so enabling them gives it back
+alter table users add column disabled_role integer not null default 0;
+
+-- Enabling used to always restore the user role
+update users set disabled_role = 2 where role = 1 and disabled_reason <> 'account deleted';
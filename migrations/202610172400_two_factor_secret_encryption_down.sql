@@ -0,0 +1,5 @@
+-- Encrypted secrets don't fit in the old size, so those users have to set up two factor authentication again
+delete from recovery_codes where user_id in (select user_id from two_factor_authentications where length(secret) > 64);
+delete from two_factor_authentications where length(secret) > 64;
+
+alter table two_factor_authentications alter column secret type varchar(64);
@@ -0,0 +1,2 @@
+-- Secrets are encrypted from now on, which doesn't fit in the old size. Plain secrets get encrypted the next time they're used
+alter table two_factor_authentications alter column secret type varchar(255);
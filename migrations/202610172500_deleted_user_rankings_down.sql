@@ -0,0 +1 @@
+-- The rankings of deleted accounts were empty, so there's nothing to bring back
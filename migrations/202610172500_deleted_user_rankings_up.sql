@@ -0,0 +1,2 @@
+-- Accounts that were deleted before their rankings were removed along with them still show up on leaderboards
+delete from rankings where user_id in (select id from users where disabled_reason = 'account deleted');
@@ -0,0 +1,54 @@
+//go:generate gex mockgen -source=audit_logger.go -package usecases -destination=audit_logger_mock.go
+
+package usecases
+
+import (
+	"context"
+
+	"github.com/tadoku/api/domain"
+)
+
+// AuditActor is the user performing a privileged action, and where they did it from
+type AuditActor struct {
+	UserID    uint64
+	IPAddress string
+	UserAgent string
+}
+
+// AuditLogger keeps a record of privileged actions
+type AuditLogger interface {
+	// Log stores what an actor did to a target, before and after are diffed so only the changed fields are kept.
+	// Either of them can be nil for when something was created or removed.
+	Log(ctx context.Context, actor AuditActor, action domain.AuditAction, targetID uint64, before, after interface{}) error
+}
+
+// NewAuditLogger instantiates AuditLogger with all dependencies
+func NewAuditLogger(auditEventRepository AuditEventRepository) AuditLogger {
+	return &auditLogger{
+		auditEventRepository: auditEventRepository,
+	}
+}
+
+type auditLogger struct {
+	auditEventRepository AuditEventRepository
+}
+
+func (l *auditLogger) Log(ctx context.Context, actor AuditActor, action domain.AuditAction, targetID uint64, before, after interface{}) error {
+	changes, err := domain.NewAuditChanges(before, after)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	event := &domain.AuditEvent{
+		ActorID:    actor.UserID,
+		Action:     action,
+		TargetType: action.TargetType(),
+		TargetID:   targetID,
+		Changes:    changes,
+		IPAddress:  actor.IPAddress,
+		UserAgent:  actor.UserAgent,
+	}
+
+	err = l.auditEventRepository.Store(ctx, event)
+	return domain.WrapError(err)
+}
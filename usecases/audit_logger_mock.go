@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: audit_logger.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/tadoku/api/domain"
+	reflect "reflect"
+)
+
+// MockAuditLogger is a mock of AuditLogger interface
+type MockAuditLogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLoggerMockRecorder
+}
+
+// MockAuditLoggerMockRecorder is the mock recorder for MockAuditLogger
+type MockAuditLoggerMockRecorder struct {
+	mock *MockAuditLogger
+}
+
+// NewMockAuditLogger creates a new mock instance
+func NewMockAuditLogger(ctrl *gomock.Controller) *MockAuditLogger {
+	mock := &MockAuditLogger{ctrl: ctrl}
+	mock.recorder = &MockAuditLoggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditLogger) EXPECT() *MockAuditLoggerMockRecorder {
+	return m.recorder
+}
+
+// Log mocks base method
+func (m *MockAuditLogger) Log(ctx context.Context, actor AuditActor, action domain.AuditAction, targetID uint64, before, after interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Log", ctx, actor, action, targetID, before, after)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Log indicates an expected call of Log
+func (mr *MockAuditLoggerMockRecorder) Log(ctx, actor, action, targetID, before, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Log", reflect.TypeOf((*MockAuditLogger)(nil).Log), ctx, actor, action, targetID, before, after)
+}
@@ -0,0 +1,38 @@
+package usecases_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestAuditLogger_Log(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := usecases.NewMockAuditEventRepository(ctrl)
+	logger := usecases.NewAuditLogger(repo)
+
+	actor := usecases.AuditActor{UserID: 1, IPAddress: "127.0.0.1", UserAgent: "curl/7.64.1"}
+	before := domain.Contest{ID: 2, Description: "Round 1", Open: true}
+	after := domain.Contest{ID: 2, Description: "Round 1", Open: false}
+
+	repo.EXPECT().Store(gomock.Any(), &domain.AuditEvent{
+		ActorID:    1,
+		Action:     domain.AuditActionContestUpdate,
+		TargetType: "contest",
+		TargetID:   2,
+		Changes:    domain.AuditChanges{"open": {Before: json.RawMessage("true"), After: json.RawMessage("false")}},
+		IPAddress:  "127.0.0.1",
+		UserAgent:  "curl/7.64.1",
+	}).Return(nil)
+
+	err := logger.Log(context.Background(), actor, domain.AuditActionContestUpdate, 2, before, after)
+	assert.NoError(t, err)
+}
@@ -3,6 +3,8 @@
 package usecases
 
 import (
+	"context"
+
 	"github.com/srvc/fail"
 	"github.com/tadoku/api/domain"
 )
@@ -24,51 +26,72 @@ var ErrContestNotFound = fail.New("no contest could be found")
 
 // ContestInteractor contains all business logic for contests
 type ContestInteractor interface {
-	CreateContest(contest domain.Contest) error
-	UpdateContest(contest domain.Contest) error
-	Recent(count int) ([]domain.Contest, error)
-	Find(contestID uint64) (*domain.Contest, error)
+	CreateContest(ctx context.Context, contest domain.Contest, actor AuditActor) error
+	UpdateContest(ctx context.Context, contest domain.Contest, actor AuditActor) error
+	Recent(ctx context.Context, count int) ([]domain.Contest, error)
+	Find(ctx context.Context, contestID uint64) (*domain.Contest, error)
 }
 
 // NewContestInteractor instantiates ContestInteractor with all dependencies
 func NewContestInteractor(
 	contestRepository ContestRepository,
+	transactions TransactionManager,
+	auditLogger AuditLogger,
 	validator Validator,
 ) ContestInteractor {
 	return &contestInteractor{
 		contestRepository: contestRepository,
+		transactions:      transactions,
+		auditLogger:       auditLogger,
 		validator:         validator,
 	}
 }
 
 type contestInteractor struct {
 	contestRepository ContestRepository
+	transactions      TransactionManager
+	auditLogger       AuditLogger
 	validator         Validator
 }
 
-func (i *contestInteractor) CreateContest(contest domain.Contest) error {
+func (i *contestInteractor) CreateContest(ctx context.Context, contest domain.Contest, actor AuditActor) error {
 	if contest.ID != 0 {
 		return ErrCreateContestHasID
 	}
 
-	return i.saveContest(contest)
+	return i.transactions.Run(ctx, func(ctx context.Context) error {
+		return i.saveContest(ctx, nil, contest, actor)
+	})
 }
 
-func (i *contestInteractor) UpdateContest(contest domain.Contest) error {
+func (i *contestInteractor) UpdateContest(ctx context.Context, contest domain.Contest, actor AuditActor) error {
 	if contest.ID == 0 {
 		return ErrContestIDMissing
 	}
 
-	return i.saveContest(contest)
+	return i.transactions.Run(ctx, func(ctx context.Context) error {
+		before, err := i.contestRepository.FindByID(ctx, contest.ID)
+		if err != nil {
+			if err == domain.ErrNotFound {
+				return ErrContestNotFound
+			}
+
+			return domain.WrapError(err)
+		}
+
+		return i.saveContest(ctx, &before, contest, actor)
+	})
 }
 
-func (i *contestInteractor) saveContest(contest domain.Contest) error {
-	if valid, _ := i.validator.Validate(contest); !valid {
-		return ErrInvalidContest
+// saveContest stores a new contest when there is nothing before it, and updates it otherwise.
+// It has to run in a transaction so the change never gets stored without its audit event.
+func (i *contestInteractor) saveContest(ctx context.Context, before *domain.Contest, contest domain.Contest, actor AuditActor) error {
+	if valid, violations := i.validator.Validate(contest); !valid {
+		return newValidationError(ErrInvalidContest, violations)
 	}
 
 	if contest.Open {
-		ids, err := i.contestRepository.GetOpenContests()
+		ids, err := i.contestRepository.GetOpenContests(ctx)
 		if err != nil {
 			return domain.WrapError(err)
 		}
@@ -81,18 +104,28 @@ func (i *contestInteractor) saveContest(contest domain.Contest) error {
 		}
 	}
 
-	err := i.contestRepository.Store(&contest)
+	err := i.contestRepository.Store(ctx, &contest)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if before == nil {
+		err = i.auditLogger.Log(ctx, actor, domain.AuditActionContestCreate, contest.ID, nil, contest)
+	} else {
+		err = i.auditLogger.Log(ctx, actor, domain.AuditActionContestUpdate, contest.ID, *before, contest)
+	}
+
 	return domain.WrapError(err)
 }
 
-func (i *contestInteractor) Recent(count int) ([]domain.Contest, error) {
+func (i *contestInteractor) Recent(ctx context.Context, count int) ([]domain.Contest, error) {
 	var contests []domain.Contest
 	var err error
 
 	if count == 0 {
-		contests, err = i.contestRepository.FindAll()
+		contests, err = i.contestRepository.FindAll(ctx)
 	} else {
-		contests, err = i.contestRepository.FindRecent(count)
+		contests, err = i.contestRepository.FindRecent(ctx, count)
 	}
 
 	if err != nil {
@@ -106,8 +139,8 @@ func (i *contestInteractor) Recent(count int) ([]domain.Contest, error) {
 	return contests, nil
 }
 
-func (i *contestInteractor) Find(contestID uint64) (*domain.Contest, error) {
-	contest, err := i.contestRepository.FindByID(contestID)
+func (i *contestInteractor) Find(ctx context.Context, contestID uint64) (*domain.Contest, error) {
+	contest, err := i.contestRepository.FindByID(ctx, contestID)
 	if err != nil {
 		if err == domain.ErrNotFound {
 			return nil, ErrContestNotFound
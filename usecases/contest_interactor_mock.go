@@ -5,6 +5,7 @@
 package usecases
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/tadoku/api/domain"
 	reflect "reflect"
@@ -34,59 +35,59 @@ func (m *MockContestInteractor) EXPECT() *MockContestInteractorMockRecorder {
 }
 
 // CreateContest mocks base method
-func (m *MockContestInteractor) CreateContest(contest domain.Contest) error {
+func (m *MockContestInteractor) CreateContest(ctx context.Context, contest domain.Contest, actor AuditActor) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateContest", contest)
+	ret := m.ctrl.Call(m, "CreateContest", ctx, contest, actor)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateContest indicates an expected call of CreateContest
-func (mr *MockContestInteractorMockRecorder) CreateContest(contest interface{}) *gomock.Call {
+func (mr *MockContestInteractorMockRecorder) CreateContest(ctx, contest, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContest", reflect.TypeOf((*MockContestInteractor)(nil).CreateContest), contest)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateContest", reflect.TypeOf((*MockContestInteractor)(nil).CreateContest), ctx, contest, actor)
 }
 
 // UpdateContest mocks base method
-func (m *MockContestInteractor) UpdateContest(contest domain.Contest) error {
+func (m *MockContestInteractor) UpdateContest(ctx context.Context, contest domain.Contest, actor AuditActor) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateContest", contest)
+	ret := m.ctrl.Call(m, "UpdateContest", ctx, contest, actor)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateContest indicates an expected call of UpdateContest
-func (mr *MockContestInteractorMockRecorder) UpdateContest(contest interface{}) *gomock.Call {
+func (mr *MockContestInteractorMockRecorder) UpdateContest(ctx, contest, actor interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContest", reflect.TypeOf((*MockContestInteractor)(nil).UpdateContest), contest)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContest", reflect.TypeOf((*MockContestInteractor)(nil).UpdateContest), ctx, contest, actor)
 }
 
 // Recent mocks base method
-func (m *MockContestInteractor) Recent(count int) ([]domain.Contest, error) {
+func (m *MockContestInteractor) Recent(ctx context.Context, count int) ([]domain.Contest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Recent", count)
+	ret := m.ctrl.Call(m, "Recent", ctx, count)
 	ret0, _ := ret[0].([]domain.Contest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Recent indicates an expected call of Recent
-func (mr *MockContestInteractorMockRecorder) Recent(count interface{}) *gomock.Call {
+func (mr *MockContestInteractorMockRecorder) Recent(ctx, count interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recent", reflect.TypeOf((*MockContestInteractor)(nil).Recent), count)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recent", reflect.TypeOf((*MockContestInteractor)(nil).Recent), ctx, count)
 }
 
 // Find mocks base method
-func (m *MockContestInteractor) Find(contestID uint64) (*domain.Contest, error) {
+func (m *MockContestInteractor) Find(ctx context.Context, contestID uint64) (*domain.Contest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Find", contestID)
+	ret := m.ctrl.Call(m, "Find", ctx, contestID)
 	ret0, _ := ret[0].(*domain.Contest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Find indicates an expected call of Find
-func (mr *MockContestInteractorMockRecorder) Find(contestID interface{}) *gomock.Call {
+func (mr *MockContestInteractorMockRecorder) Find(ctx, contestID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockContestInteractor)(nil).Find), contestID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockContestInteractor)(nil).Find), ctx, contestID)
 }
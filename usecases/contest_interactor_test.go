@@ -1,6 +1,8 @@
 package usecases_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -14,20 +16,24 @@ import (
 func setupContestTest(t *testing.T) (
 	*gomock.Controller,
 	*usecases.MockContestRepository,
+	*usecases.MockAuditLogger,
 	*usecases.MockValidator,
 	usecases.ContestInteractor,
 ) {
 	ctrl := gomock.NewController(t)
 
 	repo := usecases.NewMockContestRepository(ctrl)
+	auditLogger := usecases.NewMockAuditLogger(ctrl)
 	validator := usecases.NewMockValidator(ctrl)
-	interactor := usecases.NewContestInteractor(repo, validator)
+	interactor := usecases.NewContestInteractor(repo, newTransactionManager(ctrl), auditLogger, validator)
 
-	return ctrl, repo, validator, interactor
+	return ctrl, repo, auditLogger, validator, interactor
 }
 
+var contestActor = usecases.AuditActor{UserID: 1, IPAddress: "127.0.0.1", UserAgent: "curl/7.64.1"}
+
 func TestContestInteractor_CreateContest(t *testing.T) {
-	ctrl, repo, validator, interactor := setupContestTest(t)
+	ctrl, repo, auditLogger, validator, interactor := setupContestTest(t)
 	defer ctrl.Finish()
 
 	{
@@ -37,11 +43,12 @@ func TestContestInteractor_CreateContest(t *testing.T) {
 			Open:  true,
 		}
 
-		repo.EXPECT().Store(&contest)
-		repo.EXPECT().GetOpenContests().Return(nil, nil)
+		repo.EXPECT().Store(gomock.Any(), &contest)
+		repo.EXPECT().GetOpenContests(gomock.Any()).Return(nil, nil)
 		validator.EXPECT().Validate(contest).Return(true, nil)
+		auditLogger.EXPECT().Log(gomock.Any(), contestActor, domain.AuditActionContestCreate, contest.ID, nil, contest).Return(nil)
 
-		err := interactor.CreateContest(contest)
+		err := interactor.CreateContest(context.Background(), contest, contestActor)
 
 		assert.NoError(t, err)
 	}
@@ -53,10 +60,10 @@ func TestContestInteractor_CreateContest(t *testing.T) {
 			Open:  true,
 		}
 
-		repo.EXPECT().GetOpenContests().Return([]uint64{1}, usecases.ErrOpenContestAlreadyExists)
+		repo.EXPECT().GetOpenContests(gomock.Any()).Return([]uint64{1}, usecases.ErrOpenContestAlreadyExists)
 		validator.EXPECT().Validate(contest).Return(true, nil)
 
-		err := interactor.CreateContest(contest)
+		err := interactor.CreateContest(context.Background(), contest, contestActor)
 
 		assert.EqualError(t, err, usecases.ErrOpenContestAlreadyExists.Error())
 	}
@@ -68,16 +75,20 @@ func TestContestInteractor_CreateContest(t *testing.T) {
 			Open:  true,
 		}
 
-		validator.EXPECT().Validate(contest).Return(false, usecases.ErrInvalidContest)
+		violations := domain.FieldErrors{domain.NewFieldError("end", "after_start", domain.ErrContestInvalidDateOrder)}
+		validator.EXPECT().Validate(contest).Return(false, violations)
 
-		err := interactor.CreateContest(contest)
+		err := interactor.CreateContest(context.Background(), contest, contestActor)
 
 		assert.EqualError(t, err, usecases.ErrInvalidContest.Error())
+		var validationErr *usecases.ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, violations, validationErr.Violations)
 	}
 }
 
 func TestContestInteractor_UpdateContest(t *testing.T) {
-	ctrl, repo, validator, interactor := setupContestTest(t)
+	ctrl, repo, auditLogger, validator, interactor := setupContestTest(t)
 	defer ctrl.Finish()
 
 	{
@@ -88,14 +99,29 @@ func TestContestInteractor_UpdateContest(t *testing.T) {
 			Open:  false,
 		}
 
-		repo.EXPECT().Store(&contest)
+		before := contest
+		before.Open = true
+
+		repo.EXPECT().FindByID(gomock.Any(), contest.ID).Return(before, nil)
+		repo.EXPECT().Store(gomock.Any(), &contest)
 		validator.EXPECT().Validate(contest).Return(true, nil)
+		auditLogger.EXPECT().Log(gomock.Any(), contestActor, domain.AuditActionContestUpdate, contest.ID, before, contest).Return(nil)
 
-		err := interactor.UpdateContest(contest)
+		err := interactor.UpdateContest(context.Background(), contest, contestActor)
 
 		assert.NoError(t, err)
 	}
 
+	{
+		contest := domain.Contest{ID: 2}
+
+		repo.EXPECT().FindByID(gomock.Any(), contest.ID).Return(domain.Contest{}, domain.ErrNotFound)
+
+		err := interactor.UpdateContest(context.Background(), contest, contestActor)
+
+		assert.EqualError(t, err, usecases.ErrContestNotFound.Error())
+	}
+
 	{
 		contest := domain.Contest{
 			Start: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
@@ -103,14 +129,14 @@ func TestContestInteractor_UpdateContest(t *testing.T) {
 			Open:  false,
 		}
 
-		err := interactor.UpdateContest(contest)
+		err := interactor.UpdateContest(context.Background(), contest, contestActor)
 
 		assert.EqualError(t, err, usecases.ErrContestIDMissing.Error())
 	}
 }
 
 func TestContestInteractor_Recent(t *testing.T) {
-	ctrl, repo, _, interactor := setupContestTest(t)
+	ctrl, repo, _, _, interactor := setupContestTest(t)
 	defer ctrl.Finish()
 
 	// Happy path
@@ -130,24 +156,24 @@ func TestContestInteractor_Recent(t *testing.T) {
 			},
 		}
 
-		repo.EXPECT().FindRecent(2).Return(contests, nil)
+		repo.EXPECT().FindRecent(gomock.Any(), 2).Return(contests, nil)
 
-		found, err := interactor.Recent(2)
+		found, err := interactor.Recent(context.Background(), 2)
 		assert.NoError(t, err)
 		assert.ElementsMatch(t, contests, found)
 	}
 
 	// Sad path: none found
 	{
-		repo.EXPECT().FindRecent(2).Return([]domain.Contest{}, domain.ErrNotFound)
+		repo.EXPECT().FindRecent(gomock.Any(), 2).Return([]domain.Contest{}, domain.ErrNotFound)
 
-		_, err := interactor.Recent(2)
+		_, err := interactor.Recent(context.Background(), 2)
 		assert.EqualError(t, err, usecases.ErrContestNotFound.Error())
 	}
 }
 
 func TestContestInteractor_Find(t *testing.T) {
-	ctrl, repo, _, interactor := setupContestTest(t)
+	ctrl, repo, _, _, interactor := setupContestTest(t)
 	defer ctrl.Finish()
 
 	contestID := uint64(1)
@@ -161,18 +187,18 @@ func TestContestInteractor_Find(t *testing.T) {
 			Open:  false,
 		}
 
-		repo.EXPECT().FindByID(contestID).Return(contest, nil)
+		repo.EXPECT().FindByID(gomock.Any(), contestID).Return(contest, nil)
 
-		found, err := interactor.Find(contestID)
+		found, err := interactor.Find(context.Background(), contestID)
 		assert.NoError(t, err)
 		assert.Equal(t, &contest, found)
 	}
 
 	// Sad path: none found
 	{
-		repo.EXPECT().FindByID(contestID).Return(domain.Contest{}, domain.ErrNotFound)
+		repo.EXPECT().FindByID(gomock.Any(), contestID).Return(domain.Contest{}, domain.ErrNotFound)
 
-		_, err := interactor.Find(contestID)
+		_, err := interactor.Find(context.Background(), contestID)
 		assert.EqualError(t, err, usecases.ErrContestNotFound.Error())
 	}
 }
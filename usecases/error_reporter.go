@@ -2,7 +2,12 @@
 
 package usecases
 
+import "time"
+
 // ErrorReporter sends off errors for later inspection
 type ErrorReporter interface {
-	Capture(err error)
+	// Capture reports an error, the request id links it to the access log when it happened during a request
+	Capture(err error, requestID string)
+	// Flush waits for captured errors to be sent, returns false when the timeout was reached first
+	Flush(timeout time.Duration) bool
 }
@@ -7,6 +7,7 @@ package usecases
 import (
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
+	time "time"
 )
 
 // MockErrorReporter is a mock of ErrorReporter interface
@@ -33,13 +34,27 @@ func (m *MockErrorReporter) EXPECT() *MockErrorReporterMockRecorder {
 }
 
 // Capture mocks base method
-func (m *MockErrorReporter) Capture(err error) {
+func (m *MockErrorReporter) Capture(err error, requestID string) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Capture", err)
+	m.ctrl.Call(m, "Capture", err, requestID)
 }
 
 // Capture indicates an expected call of Capture
-func (mr *MockErrorReporterMockRecorder) Capture(err interface{}) *gomock.Call {
+func (mr *MockErrorReporterMockRecorder) Capture(err, requestID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockErrorReporter)(nil).Capture), err)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockErrorReporter)(nil).Capture), err, requestID)
+}
+
+// Flush mocks base method
+func (m *MockErrorReporter) Flush(timeout time.Duration) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", timeout)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush
+func (mr *MockErrorReporterMockRecorder) Flush(timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockErrorReporter)(nil).Flush), timeout)
 }
@@ -0,0 +1,97 @@
+//go:generate gex mockgen -source=health_interactor.go -package usecases -destination=health_interactor_mock.go
+
+package usecases
+
+import (
+	"context"
+	"sync"
+
+	"github.com/srvc/fail"
+)
+
+// ErrHealthCheckDisabled is returned by checks of optional dependencies that haven't been configured
+var ErrHealthCheckDisabled = fail.New("dependency is not configured")
+
+// HealthCheck verifies that something the api depends on works, it should give up once its timeout has passed
+type HealthCheck interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// HealthStatus is the outcome of a health check
+type HealthStatus string
+
+// All possible outcomes of health checks
+const (
+	HealthStatusOK       HealthStatus = "ok"
+	HealthStatusFailing  HealthStatus = "failing"
+	HealthStatusDisabled HealthStatus = "disabled"
+)
+
+// HealthCheckResult is the outcome of a single check
+type HealthCheckResult struct {
+	Status HealthStatus `json:"status"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// HealthReport is the breakdown of every check, it's only ok when none of them are failing
+type HealthReport struct {
+	Status HealthStatus                 `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks"`
+}
+
+// Ready tells if the api can serve requests
+func (r HealthReport) Ready() bool {
+	return r.Status == HealthStatusOK
+}
+
+// HealthInteractor contains all business logic for checking if the api is healthy
+type HealthInteractor interface {
+	// Readiness runs every check at the same time, so it takes as long as the slowest one
+	Readiness(ctx context.Context) HealthReport
+}
+
+// NewHealthInteractor instantiates HealthInteractor with all dependencies
+func NewHealthInteractor(checks ...HealthCheck) HealthInteractor {
+	return &healthInteractor{checks: checks}
+}
+
+type healthInteractor struct {
+	checks []HealthCheck
+}
+
+func (i *healthInteractor) Readiness(ctx context.Context) HealthReport {
+	report := HealthReport{Status: HealthStatusOK, Checks: map[string]HealthCheckResult{}}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range i.checks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			result := runHealthCheck(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name()] = result
+			if result.Status == HealthStatusFailing {
+				report.Status = HealthStatusFailing
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func runHealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	err := check.Check(ctx)
+	switch {
+	case err == nil:
+		return HealthCheckResult{Status: HealthStatusOK}
+	case err == ErrHealthCheckDisabled:
+		return HealthCheckResult{Status: HealthStatusDisabled}
+	default:
+		return HealthCheckResult{Status: HealthStatusFailing, Error: err.Error()}
+	}
+}
@@ -0,0 +1,99 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: health_interactor.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockHealthCheck is a mock of HealthCheck interface
+type MockHealthCheck struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthCheckMockRecorder
+}
+
+// MockHealthCheckMockRecorder is the mock recorder for MockHealthCheck
+type MockHealthCheckMockRecorder struct {
+	mock *MockHealthCheck
+}
+
+// NewMockHealthCheck creates a new mock instance
+func NewMockHealthCheck(ctrl *gomock.Controller) *MockHealthCheck {
+	mock := &MockHealthCheck{ctrl: ctrl}
+	mock.recorder = &MockHealthCheckMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHealthCheck) EXPECT() *MockHealthCheckMockRecorder {
+	return m.recorder
+}
+
+// Name mocks base method
+func (m *MockHealthCheck) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name
+func (mr *MockHealthCheckMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockHealthCheck)(nil).Name))
+}
+
+// Check mocks base method
+func (m *MockHealthCheck) Check(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check
+func (mr *MockHealthCheckMockRecorder) Check(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockHealthCheck)(nil).Check), ctx)
+}
+
+// MockHealthInteractor is a mock of HealthInteractor interface
+type MockHealthInteractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthInteractorMockRecorder
+}
+
+// MockHealthInteractorMockRecorder is the mock recorder for MockHealthInteractor
+type MockHealthInteractorMockRecorder struct {
+	mock *MockHealthInteractor
+}
+
+// NewMockHealthInteractor creates a new mock instance
+func NewMockHealthInteractor(ctrl *gomock.Controller) *MockHealthInteractor {
+	mock := &MockHealthInteractor{ctrl: ctrl}
+	mock.recorder = &MockHealthInteractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockHealthInteractor) EXPECT() *MockHealthInteractorMockRecorder {
+	return m.recorder
+}
+
+// Readiness mocks base method
+func (m *MockHealthInteractor) Readiness(ctx context.Context) HealthReport {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Readiness", ctx)
+	ret0, _ := ret[0].(HealthReport)
+	return ret0
+}
+
+// Readiness indicates an expected call of Readiness
+func (mr *MockHealthInteractorMockRecorder) Readiness(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Readiness", reflect.TypeOf((*MockHealthInteractor)(nil).Readiness), ctx)
+}
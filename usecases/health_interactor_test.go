@@ -0,0 +1,57 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/usecases"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func newHealthCheck(ctrl *gomock.Controller, name string, err error) *usecases.MockHealthCheck {
+	check := usecases.NewMockHealthCheck(ctrl)
+	check.EXPECT().Name().Return(name).AnyTimes()
+	check.EXPECT().Check(gomock.Any()).Return(err)
+
+	return check
+}
+
+func TestHealthInteractor_Readiness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: optional dependencies don't have to be configured
+		interactor := usecases.NewHealthInteractor(
+			newHealthCheck(ctrl, "database", nil),
+			newHealthCheck(ctrl, "error_reporter", usecases.ErrHealthCheckDisabled),
+		)
+
+		report := interactor.Readiness(context.Background())
+		assert.True(t, report.Ready())
+		assert.Equal(t, usecases.HealthReport{
+			Status: usecases.HealthStatusOK,
+			Checks: map[string]usecases.HealthCheckResult{
+				"database":       {Status: usecases.HealthStatusOK},
+				"error_reporter": {Status: usecases.HealthStatusDisabled},
+			},
+		}, report)
+	}
+
+	{
+		// Sad path: a single failing check makes the api unready
+		interactor := usecases.NewHealthInteractor(
+			newHealthCheck(ctrl, "database", errors.New("context deadline exceeded")),
+			newHealthCheck(ctrl, "migrations", nil),
+		)
+
+		report := interactor.Readiness(context.Background())
+		assert.False(t, report.Ready())
+		assert.Equal(t, usecases.HealthStatusFailing, report.Status)
+		assert.Equal(t, usecases.HealthCheckResult{Status: usecases.HealthStatusFailing, Error: "context deadline exceeded"}, report.Checks["database"])
+		assert.Equal(t, usecases.HealthStatusOK, report.Checks["migrations"].Status)
+	}
+}
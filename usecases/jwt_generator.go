@@ -9,4 +9,23 @@ import (
 // JWTGenerator makes it easy to generate JWT tokens that expire in a given duration
 type JWTGenerator interface {
 	NewToken(lifetime time.Duration, claims SessionClaims) (token string, err error)
+
+	// PublicKeys returns every key that tokens can currently be verified with
+	PublicKeys() []JSONWebKey
+}
+
+// JSONWebKey is a public key as described in RFC 7517, so other services can verify our tokens
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+
+	// RSA keys
+	Modulus  string `json:"n,omitempty"`
+	Exponent string `json:"e,omitempty"`
+
+	// Octet key pairs, eg. Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
 }
@@ -47,3 +47,17 @@ func (mr *MockJWTGeneratorMockRecorder) NewToken(lifetime, claims interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewToken", reflect.TypeOf((*MockJWTGenerator)(nil).NewToken), lifetime, claims)
 }
+
+// PublicKeys mocks base method
+func (m *MockJWTGenerator) PublicKeys() []JSONWebKey {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublicKeys")
+	ret0, _ := ret[0].([]JSONWebKey)
+	return ret0
+}
+
+// PublicKeys indicates an expected call of PublicKeys
+func (mr *MockJWTGeneratorMockRecorder) PublicKeys() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublicKeys", reflect.TypeOf((*MockJWTGenerator)(nil).PublicKeys))
+}
@@ -0,0 +1,133 @@
+//go:generate gex mockgen -source=login_throttle_interactor.go -package usecases -destination=login_throttle_interactor_mock.go
+
+package usecases
+
+import (
+	"context"
+	"time"
+
+	"github.com/srvc/fail"
+	"github.com/tadoku/api/domain"
+)
+
+// ErrLoginThrottled for when there have been too many failed logins for an account or from an IP address
+var ErrLoginThrottled = fail.New("too many failed login attempts")
+
+// LoginThrottleConfig describes how many failed logins are allowed before logging in gets blocked, and for how long
+type LoginThrottleConfig struct {
+	AccountFreeAttempts int
+	IPFreeAttempts      int
+	BaseLockout         time.Duration
+	MaxLockout          time.Duration
+	ResetAfter          time.Duration
+}
+
+// LoginThrottleInteractor slows down guessing passwords by blocking logins with an exponential backoff
+type LoginThrottleInteractor interface {
+	// Check returns ErrLoginThrottled together with how long to wait when logging in is blocked
+	Check(ctx context.Context, email, ipAddress string) (retryAfter time.Duration, err error)
+	// RecordFailure returns ErrLoginThrottled together with how long to wait when this failure blocks logging in.
+	// The lockout is decided from the counts the failure was stored with, so concurrent logins can't slip past it.
+	RecordFailure(ctx context.Context, email, ipAddress string) (retryAfter time.Duration, err error)
+	// RecordSuccess forgets the failures for the account, failures from the IP address keep counting
+	// so one valid account can't be used to keep guessing passwords for others
+	RecordSuccess(ctx context.Context, email string) error
+	// ForgetStaleFailures cleans up failures that have been forgotten already
+	ForgetStaleFailures(ctx context.Context) error
+}
+
+// NewLoginThrottleInteractor instantiates LoginThrottleInteractor with all dependencies
+func NewLoginThrottleInteractor(
+	loginAttemptRepository LoginAttemptRepository,
+	config LoginThrottleConfig,
+) LoginThrottleInteractor {
+	return &loginThrottleInteractor{
+		loginAttemptRepository: loginAttemptRepository,
+		config:                 config,
+	}
+}
+
+type loginThrottleInteractor struct {
+	loginAttemptRepository LoginAttemptRepository
+	config                 LoginThrottleConfig
+}
+
+func (i *loginThrottleInteractor) Check(ctx context.Context, email, ipAddress string) (time.Duration, error) {
+	now := time.Now()
+
+	accountLockedUntil, err := i.lockedUntil(ctx, domain.LoginAttemptAccountKey(email), i.config.AccountFreeAttempts, now)
+	if err != nil {
+		return 0, err
+	}
+	ipLockedUntil, err := i.lockedUntil(ctx, domain.LoginAttemptIPKey(ipAddress), i.config.IPFreeAttempts, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return retryAfter(latest(accountLockedUntil, ipLockedUntil), now)
+}
+
+func (i *loginThrottleInteractor) lockedUntil(ctx context.Context, key string, freeAttempts int, now time.Time) (time.Time, error) {
+	attempt, err := i.loginAttemptRepository.FindByKey(ctx, key)
+	if err == domain.ErrNotFound {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, domain.WrapError(err)
+	}
+
+	if attempt.LastFailedAt.Before(now.Add(-i.config.ResetAfter)) {
+		return time.Time{}, nil
+	}
+
+	return attempt.LockedUntil(freeAttempts, i.config.BaseLockout, i.config.MaxLockout), nil
+}
+
+func (i *loginThrottleInteractor) RecordFailure(ctx context.Context, email, ipAddress string) (time.Duration, error) {
+	now := time.Now().UTC()
+
+	accountLockedUntil, err := i.recordFailure(ctx, domain.LoginAttemptAccountKey(email), i.config.AccountFreeAttempts, now)
+	if err != nil {
+		return 0, err
+	}
+	ipLockedUntil, err := i.recordFailure(ctx, domain.LoginAttemptIPKey(ipAddress), i.config.IPFreeAttempts, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return retryAfter(latest(accountLockedUntil, ipLockedUntil), now)
+}
+
+func (i *loginThrottleInteractor) recordFailure(ctx context.Context, key string, freeAttempts int, now time.Time) (time.Time, error) {
+	attempt, err := i.loginAttemptRepository.RecordFailure(ctx, key, now, now.Add(-i.config.ResetAfter))
+	if err != nil {
+		return time.Time{}, domain.WrapError(err)
+	}
+
+	return attempt.LockedUntil(freeAttempts, i.config.BaseLockout, i.config.MaxLockout), nil
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func retryAfter(lockedUntil time.Time, now time.Time) (time.Duration, error) {
+	if !lockedUntil.After(now) {
+		return 0, nil
+	}
+
+	return lockedUntil.Sub(now), ErrLoginThrottled
+}
+
+func (i *loginThrottleInteractor) RecordSuccess(ctx context.Context, email string) error {
+	err := i.loginAttemptRepository.Delete(ctx, domain.LoginAttemptAccountKey(email))
+	return domain.WrapError(err)
+}
+
+func (i *loginThrottleInteractor) ForgetStaleFailures(ctx context.Context) error {
+	err := i.loginAttemptRepository.DeleteFailedBefore(ctx, time.Now().UTC().Add(-i.config.ResetAfter))
+	return domain.WrapError(err)
+}
@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: login_throttle_interactor.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockLoginThrottleInteractor is a mock of LoginThrottleInteractor interface
+type MockLoginThrottleInteractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginThrottleInteractorMockRecorder
+}
+
+// MockLoginThrottleInteractorMockRecorder is the mock recorder for MockLoginThrottleInteractor
+type MockLoginThrottleInteractorMockRecorder struct {
+	mock *MockLoginThrottleInteractor
+}
+
+// NewMockLoginThrottleInteractor creates a new mock instance
+func NewMockLoginThrottleInteractor(ctrl *gomock.Controller) *MockLoginThrottleInteractor {
+	mock := &MockLoginThrottleInteractor{ctrl: ctrl}
+	mock.recorder = &MockLoginThrottleInteractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoginThrottleInteractor) EXPECT() *MockLoginThrottleInteractorMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method
+func (m *MockLoginThrottleInteractor) Check(ctx context.Context, email, ipAddress string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, email, ipAddress)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check
+func (mr *MockLoginThrottleInteractorMockRecorder) Check(ctx, email, ipAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockLoginThrottleInteractor)(nil).Check), ctx, email, ipAddress)
+}
+
+// RecordFailure mocks base method
+func (m *MockLoginThrottleInteractor) RecordFailure(ctx context.Context, email, ipAddress string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, email, ipAddress)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailure indicates an expected call of RecordFailure
+func (mr *MockLoginThrottleInteractorMockRecorder) RecordFailure(ctx, email, ipAddress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockLoginThrottleInteractor)(nil).RecordFailure), ctx, email, ipAddress)
+}
+
+// RecordSuccess mocks base method
+func (m *MockLoginThrottleInteractor) RecordSuccess(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSuccess", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSuccess indicates an expected call of RecordSuccess
+func (mr *MockLoginThrottleInteractorMockRecorder) RecordSuccess(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSuccess", reflect.TypeOf((*MockLoginThrottleInteractor)(nil).RecordSuccess), ctx, email)
+}
+
+// ForgetStaleFailures mocks base method
+func (m *MockLoginThrottleInteractor) ForgetStaleFailures(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgetStaleFailures", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgetStaleFailures indicates an expected call of ForgetStaleFailures
+func (mr *MockLoginThrottleInteractorMockRecorder) ForgetStaleFailures(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgetStaleFailures", reflect.TypeOf((*MockLoginThrottleInteractor)(nil).ForgetStaleFailures), ctx)
+}
@@ -0,0 +1,141 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+var loginThrottleConfig = usecases.LoginThrottleConfig{
+	AccountFreeAttempts: 5,
+	IPFreeAttempts:      20,
+	BaseLockout:         time.Minute,
+	MaxLockout:          time.Hour,
+	ResetAfter:          24 * time.Hour,
+}
+
+func setupLoginThrottleTest(t *testing.T) (
+	*gomock.Controller,
+	*usecases.MockLoginAttemptRepository,
+	usecases.LoginThrottleInteractor,
+) {
+	ctrl := gomock.NewController(t)
+
+	repo := usecases.NewMockLoginAttemptRepository(ctrl)
+	interactor := usecases.NewLoginThrottleInteractor(repo, loginThrottleConfig)
+
+	return ctrl, repo, interactor
+}
+
+func TestLoginThrottleInteractor_Check(t *testing.T) {
+	ctrl, repo, interactor := setupLoginThrottleTest(t)
+	defer ctrl.Finish()
+
+	accountKey := domain.LoginAttemptAccountKey("foo@bar.com")
+	ipKey := domain.LoginAttemptIPKey("127.0.0.1")
+
+	{
+		// Happy path: no failures yet
+		repo.EXPECT().FindByKey(gomock.Any(), accountKey).Return(domain.LoginAttempt{}, domain.ErrNotFound)
+		repo.EXPECT().FindByKey(gomock.Any(), ipKey).Return(domain.LoginAttempt{}, domain.ErrNotFound)
+
+		retryAfter, err := interactor.Check(context.Background(), "foo@bar.com", "127.0.0.1")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), retryAfter)
+	}
+
+	{
+		// Happy path: lockout has passed
+		repo.EXPECT().FindByKey(gomock.Any(), accountKey).Return(domain.LoginAttempt{Key: accountKey, Failures: 6, LastFailedAt: time.Now().Add(-2 * time.Minute)}, nil)
+		repo.EXPECT().FindByKey(gomock.Any(), ipKey).Return(domain.LoginAttempt{}, domain.ErrNotFound)
+
+		_, err := interactor.Check(context.Background(), "foo@bar.com", "127.0.0.1")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: account is locked
+		repo.EXPECT().FindByKey(gomock.Any(), accountKey).Return(domain.LoginAttempt{Key: accountKey, Failures: 7, LastFailedAt: time.Now()}, nil)
+		repo.EXPECT().FindByKey(gomock.Any(), ipKey).Return(domain.LoginAttempt{}, domain.ErrNotFound)
+
+		retryAfter, err := interactor.Check(context.Background(), "foo@bar.com", "127.0.0.1")
+		assert.EqualError(t, err, usecases.ErrLoginThrottled.Error())
+		assert.InDelta(t, float64(2*time.Minute), float64(retryAfter), float64(time.Second))
+	}
+
+	{
+		// Sad path: IP address is locked
+		repo.EXPECT().FindByKey(gomock.Any(), accountKey).Return(domain.LoginAttempt{}, domain.ErrNotFound)
+		repo.EXPECT().FindByKey(gomock.Any(), ipKey).Return(domain.LoginAttempt{Key: ipKey, Failures: 21, LastFailedAt: time.Now()}, nil)
+
+		retryAfter, err := interactor.Check(context.Background(), "foo@bar.com", "127.0.0.1")
+		assert.EqualError(t, err, usecases.ErrLoginThrottled.Error())
+		assert.True(t, retryAfter > 0)
+	}
+
+	{
+		// Happy path: failures from long ago are forgotten
+		repo.EXPECT().FindByKey(gomock.Any(), accountKey).Return(domain.LoginAttempt{Key: accountKey, Failures: 100, LastFailedAt: time.Now().Add(-48 * time.Hour)}, nil)
+		repo.EXPECT().FindByKey(gomock.Any(), ipKey).Return(domain.LoginAttempt{}, domain.ErrNotFound)
+
+		_, err := interactor.Check(context.Background(), "foo@bar.com", "127.0.0.1")
+		assert.NoError(t, err)
+	}
+}
+
+func TestLoginThrottleInteractor_RecordFailure(t *testing.T) {
+	ctrl, repo, interactor := setupLoginThrottleTest(t)
+	defer ctrl.Finish()
+
+	accountKey := domain.LoginAttemptAccountKey("foo@bar.com")
+	ipKey := domain.LoginAttemptIPKey("127.0.0.1")
+
+	{
+		// Happy path: the failure is still free
+		repo.EXPECT().RecordFailure(gomock.Any(), accountKey, gomock.Any(), gomock.Any()).Return(domain.LoginAttempt{Key: accountKey, Failures: 5, LastFailedAt: time.Now()}, nil)
+		repo.EXPECT().RecordFailure(gomock.Any(), ipKey, gomock.Any(), gomock.Any()).Return(domain.LoginAttempt{Key: ipKey, Failures: 5, LastFailedAt: time.Now()}, nil)
+
+		retryAfter, err := interactor.RecordFailure(context.Background(), "foo@bar.com", "127.0.0.1")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), retryAfter)
+	}
+
+	{
+		// Sad path: the stored count locks the account right away
+		repo.EXPECT().RecordFailure(gomock.Any(), accountKey, gomock.Any(), gomock.Any()).Return(domain.LoginAttempt{Key: accountKey, Failures: 6, LastFailedAt: time.Now()}, nil)
+		repo.EXPECT().RecordFailure(gomock.Any(), ipKey, gomock.Any(), gomock.Any()).Return(domain.LoginAttempt{Key: ipKey, Failures: 6, LastFailedAt: time.Now()}, nil)
+
+		retryAfter, err := interactor.RecordFailure(context.Background(), "foo@bar.com", "127.0.0.1")
+		assert.EqualError(t, err, usecases.ErrLoginThrottled.Error())
+		assert.InDelta(t, float64(time.Minute), float64(retryAfter), float64(time.Second))
+	}
+}
+
+func TestLoginThrottleInteractor_RecordSuccess(t *testing.T) {
+	ctrl, repo, interactor := setupLoginThrottleTest(t)
+	defer ctrl.Finish()
+
+	repo.EXPECT().Delete(gomock.Any(), domain.LoginAttemptAccountKey("foo@bar.com")).Return(nil)
+
+	err := interactor.RecordSuccess(context.Background(), "foo@bar.com")
+	assert.NoError(t, err)
+}
+
+func TestLoginThrottleInteractor_ForgetStaleFailures(t *testing.T) {
+	ctrl, repo, interactor := setupLoginThrottleTest(t)
+	defer ctrl.Finish()
+
+	repo.EXPECT().DeleteFailedBefore(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, before time.Time) error {
+		assert.WithinDuration(t, time.Now().Add(-loginThrottleConfig.ResetAfter), before, time.Second)
+		return nil
+	})
+
+	err := interactor.ForgetStaleFailures(context.Background())
+	assert.NoError(t, err)
+}
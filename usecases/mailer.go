@@ -0,0 +1,15 @@
+//go:generate gex mockgen -source=mailer.go -package usecases -destination=mailer_mock.go
+
+package usecases
+
+// Mail is a plain text email addressed to a single recipient
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails without having to worry about how they get delivered
+type Mailer interface {
+	Send(mail Mail) error
+}
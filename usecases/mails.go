@@ -0,0 +1,81 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tadoku/api/domain"
+)
+
+// tokenLink builds a link to a page of the frontend that consumes a token from an email
+func tokenLink(frontendURL string, path string, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", strings.TrimRight(frontendURL, "/"), path, url.QueryEscape(token))
+}
+
+func newPasswordResetMail(user domain.User, link string, lifetime time.Duration) Mail {
+	body := `Hi %s,
+
+Someone requested a password reset for your Tadoku account. You can pick a new password by following the link below:
+
+%s
+
+This link is valid for %s and can only be used once. If you didn't request a password reset you can safely ignore this email.
+`
+
+	return Mail{
+		To:      user.Email,
+		Subject: "Reset your Tadoku password",
+		Body:    fmt.Sprintf(body, user.DisplayName, link, lifetime),
+	}
+}
+
+func newEmailVerificationMail(user domain.User, link string, lifetime time.Duration) Mail {
+	body := `Hi %s,
+
+Welcome to Tadoku! Please confirm that this is your email address by following the link below:
+
+%s
+
+This link is valid for %s. If you didn't sign up for Tadoku you can safely ignore this email.
+`
+
+	return Mail{
+		To:      user.Email,
+		Subject: "Verify your email address for Tadoku",
+		Body:    fmt.Sprintf(body, user.DisplayName, link, lifetime),
+	}
+}
+
+func newEmailChangeConfirmationMail(user domain.User, newEmail string, link string, lifetime time.Duration) Mail {
+	body := `Hi %s,
+
+You asked to change the email address of your Tadoku account to this one. Please confirm the change by following the link below:
+
+%s
+
+This link is valid for %s and can only be used once. Until then you can keep logging in with your current email address.
+`
+
+	return Mail{
+		To:      newEmail,
+		Subject: "Confirm your new email address for Tadoku",
+		Body:    fmt.Sprintf(body, user.DisplayName, link, lifetime),
+	}
+}
+
+func newEmailChangeNoticeMail(user domain.User, newEmail string) Mail {
+	body := `Hi %s,
+
+Someone asked to change the email address of your Tadoku account to %s. The change only goes through once it has been confirmed from that address.
+
+If this wasn't you, please change your password right away.
+`
+
+	return Mail{
+		To:      user.Email,
+		Subject: "Your Tadoku email address is being changed",
+		Body:    fmt.Sprintf(body, user.DisplayName, newEmail),
+	}
+}
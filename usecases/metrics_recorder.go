@@ -0,0 +1,11 @@
+//go:generate gex mockgen -source=metrics_recorder.go -package usecases -destination=metrics_recorder_mock.go
+
+package usecases
+
+// MetricsRecorder counts what happens in the domain, so it can be monitored
+type MetricsRecorder interface {
+	ContestLogCreated()
+	ContestLogDeleted()
+	RankingsRecomputed()
+	RankingsDriftRepaired()
+}
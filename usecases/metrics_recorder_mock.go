@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: metrics_recorder.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockMetricsRecorder is a mock of MetricsRecorder interface
+type MockMetricsRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricsRecorderMockRecorder
+}
+
+// MockMetricsRecorderMockRecorder is the mock recorder for MockMetricsRecorder
+type MockMetricsRecorderMockRecorder struct {
+	mock *MockMetricsRecorder
+}
+
+// NewMockMetricsRecorder creates a new mock instance
+func NewMockMetricsRecorder(ctrl *gomock.Controller) *MockMetricsRecorder {
+	mock := &MockMetricsRecorder{ctrl: ctrl}
+	mock.recorder = &MockMetricsRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMetricsRecorder) EXPECT() *MockMetricsRecorderMockRecorder {
+	return m.recorder
+}
+
+// ContestLogCreated mocks base method
+func (m *MockMetricsRecorder) ContestLogCreated() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ContestLogCreated")
+}
+
+// ContestLogCreated indicates an expected call of ContestLogCreated
+func (mr *MockMetricsRecorderMockRecorder) ContestLogCreated() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContestLogCreated", reflect.TypeOf((*MockMetricsRecorder)(nil).ContestLogCreated))
+}
+
+// ContestLogDeleted mocks base method
+func (m *MockMetricsRecorder) ContestLogDeleted() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ContestLogDeleted")
+}
+
+// ContestLogDeleted indicates an expected call of ContestLogDeleted
+func (mr *MockMetricsRecorderMockRecorder) ContestLogDeleted() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContestLogDeleted", reflect.TypeOf((*MockMetricsRecorder)(nil).ContestLogDeleted))
+}
+
+// RankingsRecomputed mocks base method
+func (m *MockMetricsRecorder) RankingsRecomputed() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RankingsRecomputed")
+}
+
+// RankingsRecomputed indicates an expected call of RankingsRecomputed
+func (mr *MockMetricsRecorderMockRecorder) RankingsRecomputed() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RankingsRecomputed", reflect.TypeOf((*MockMetricsRecorder)(nil).RankingsRecomputed))
+}
+
+// RankingsDriftRepaired mocks base method
+func (m *MockMetricsRecorder) RankingsDriftRepaired() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RankingsDriftRepaired")
+}
+
+// RankingsDriftRepaired indicates an expected call of RankingsDriftRepaired
+func (mr *MockMetricsRecorderMockRecorder) RankingsDriftRepaired() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RankingsDriftRepaired", reflect.TypeOf((*MockMetricsRecorder)(nil).RankingsDriftRepaired))
+}
@@ -0,0 +1,205 @@
+//go:generate gex mockgen -source=moderation_interactor.go -package usecases -destination=moderation_interactor_mock.go
+
+package usecases
+
+import (
+	"context"
+	"strings"
+
+	"github.com/srvc/fail"
+	"github.com/tadoku/api/domain"
+)
+
+// UsersPageSize is how many users are shown per page when searching through them
+const UsersPageSize = 50
+
+// AuditEventsPageSize is how many events are shown per page when going through the audit log
+const AuditEventsPageSize = 50
+
+// ErrModerationRoleInvalid for when a role is assigned that can't be handed out directly
+var ErrModerationRoleInvalid = fail.New("role can not be assigned")
+
+// ErrModerationReasonMissing for when an account is disabled without saying why
+var ErrModerationReasonMissing = fail.New("a reason is required to disable an account")
+
+// ErrModerationSelf for when admins try to demote or disable their own account
+var ErrModerationSelf = fail.New("admins can not moderate their own account")
+
+// ErrModerationNotDisabled for when an account is enabled that wasn't disabled by an admin, deleted accounts stay disabled
+var ErrModerationNotDisabled = fail.New("only accounts disabled by an admin can be enabled")
+
+// ErrModerationUserDisabled for when the role of a disabled account is changed, it has to be enabled first
+var ErrModerationUserDisabled = fail.New("enable the account before changing its role")
+
+// ModerationInteractor contains all business logic for admins managing users and looking back at what admins did
+type ModerationInteractor interface {
+	Users(ctx context.Context, query string, page int) (domain.Users, error)
+	UpdateRole(ctx context.Context, moderator AuditActor, userID uint64, role domain.Role) error
+	DisableUser(ctx context.Context, moderator AuditActor, userID uint64, reason string) error
+	EnableUser(ctx context.Context, moderator AuditActor, userID uint64) error
+	AuditEvents(ctx context.Context, filter domain.AuditEventFilter, page int) (domain.AuditEvents, error)
+}
+
+// NewModerationInteractor instantiates ModerationInteractor with all dependencies
+func NewModerationInteractor(
+	userRepository UserRepository,
+	sessionRepository SessionRepository,
+	auditEventRepository AuditEventRepository,
+	transactions TransactionManager,
+	auditLogger AuditLogger,
+) ModerationInteractor {
+	return &moderationInteractor{
+		userRepository:       userRepository,
+		sessionRepository:    sessionRepository,
+		auditEventRepository: auditEventRepository,
+		transactions:         transactions,
+		auditLogger:          auditLogger,
+	}
+}
+
+type moderationInteractor struct {
+	userRepository       UserRepository
+	sessionRepository    SessionRepository
+	auditEventRepository AuditEventRepository
+	transactions         TransactionManager
+	auditLogger          AuditLogger
+}
+
+func (i *moderationInteractor) Users(ctx context.Context, query string, page int) (domain.Users, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	users, err := i.userRepository.Search(ctx, query, UsersPageSize, (page-1)*UsersPageSize)
+	return users, domain.WrapError(err)
+}
+
+func (i *moderationInteractor) UpdateRole(ctx context.Context, moderator AuditActor, userID uint64, role domain.Role) error {
+	if role != domain.RoleUser && role != domain.RoleAdmin {
+		return ErrModerationRoleInvalid
+	}
+	if moderator.UserID == userID {
+		return ErrModerationSelf
+	}
+
+	return i.transactions.Run(ctx, func(ctx context.Context) error {
+		before, err := i.findUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if before.IsDisabled() {
+			return ErrModerationUserDisabled
+		}
+
+		err = i.userRepository.UpdateRole(ctx, userID, role)
+		if err == domain.ErrNotFound {
+			// It got disabled in the meantime
+			return ErrModerationUserDisabled
+		}
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		after := before
+		after.Role = role
+
+		err = i.auditLogger.Log(ctx, moderator, domain.AuditActionUserRole, userID, before, after)
+		return domain.WrapError(err)
+	})
+}
+
+func (i *moderationInteractor) DisableUser(ctx context.Context, moderator AuditActor, userID uint64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return ErrModerationReasonMissing
+	}
+	if moderator.UserID == userID {
+		return ErrModerationSelf
+	}
+
+	return i.transactions.Run(ctx, func(ctx context.Context) error {
+		before, err := i.findUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		err = i.userRepository.Disable(ctx, userID, reason)
+		if err == domain.ErrNotFound {
+			return ErrUserDoesNotExist
+		}
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		// Sessions are checked on every request anyway, this makes sure they can't be refreshed after enabling the account again
+		err = i.sessionRepository.RevokeAllForUser(ctx, userID)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		after := before
+		if !before.IsDisabled() {
+			after.DisabledRole = before.Role
+		}
+		after.Role = domain.RoleDisabled
+		after.DisabledReason = reason
+
+		err = i.auditLogger.Log(ctx, moderator, domain.AuditActionUserDisable, userID, before, after)
+		return domain.WrapError(err)
+	})
+}
+
+func (i *moderationInteractor) EnableUser(ctx context.Context, moderator AuditActor, userID uint64) error {
+	if moderator.UserID == userID {
+		return ErrModerationSelf
+	}
+
+	return i.transactions.Run(ctx, func(ctx context.Context) error {
+		before, err := i.findUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if !before.IsDisabled() || before.DisabledRole < domain.RoleUser {
+			return ErrModerationNotDisabled
+		}
+
+		err = i.userRepository.Enable(ctx, userID)
+		if err == domain.ErrNotFound {
+			// It got enabled or deleted in the meantime
+			return ErrModerationNotDisabled
+		}
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		after := before
+		after.Role = before.DisabledRole
+		after.DisabledRole = domain.RoleGuest
+		after.DisabledReason = ""
+		after.DisabledAt = nil
+
+		err = i.auditLogger.Log(ctx, moderator, domain.AuditActionUserEnable, userID, before, after)
+		return domain.WrapError(err)
+	})
+}
+
+// findUser looks up the state of a user before it gets changed, so it ends up in the audit log
+func (i *moderationInteractor) findUser(ctx context.Context, userID uint64) (domain.User, error) {
+	user, err := i.userRepository.FindByID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return user, ErrUserDoesNotExist
+	}
+
+	return user, domain.WrapError(err)
+}
+
+func (i *moderationInteractor) AuditEvents(ctx context.Context, filter domain.AuditEventFilter, page int) (domain.AuditEvents, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	events, err := i.auditEventRepository.Find(ctx, filter, AuditEventsPageSize, (page-1)*AuditEventsPageSize)
+	return events, domain.WrapError(err)
+}
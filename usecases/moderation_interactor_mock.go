@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: moderation_interactor.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/tadoku/api/domain"
+	reflect "reflect"
+)
+
+// MockModerationInteractor is a mock of ModerationInteractor interface
+type MockModerationInteractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationInteractorMockRecorder
+}
+
+// MockModerationInteractorMockRecorder is the mock recorder for MockModerationInteractor
+type MockModerationInteractorMockRecorder struct {
+	mock *MockModerationInteractor
+}
+
+// NewMockModerationInteractor creates a new mock instance
+func NewMockModerationInteractor(ctrl *gomock.Controller) *MockModerationInteractor {
+	mock := &MockModerationInteractor{ctrl: ctrl}
+	mock.recorder = &MockModerationInteractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockModerationInteractor) EXPECT() *MockModerationInteractorMockRecorder {
+	return m.recorder
+}
+
+// Users mocks base method
+func (m *MockModerationInteractor) Users(ctx context.Context, query string, page int) (domain.Users, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Users", ctx, query, page)
+	ret0, _ := ret[0].(domain.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Users indicates an expected call of Users
+func (mr *MockModerationInteractorMockRecorder) Users(ctx, query, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Users", reflect.TypeOf((*MockModerationInteractor)(nil).Users), ctx, query, page)
+}
+
+// UpdateRole mocks base method
+func (m *MockModerationInteractor) UpdateRole(ctx context.Context, moderator AuditActor, userID uint64, role domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, moderator, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole
+func (mr *MockModerationInteractorMockRecorder) UpdateRole(ctx, moderator, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockModerationInteractor)(nil).UpdateRole), ctx, moderator, userID, role)
+}
+
+// DisableUser mocks base method
+func (m *MockModerationInteractor) DisableUser(ctx context.Context, moderator AuditActor, userID uint64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUser", ctx, moderator, userID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableUser indicates an expected call of DisableUser
+func (mr *MockModerationInteractorMockRecorder) DisableUser(ctx, moderator, userID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUser", reflect.TypeOf((*MockModerationInteractor)(nil).DisableUser), ctx, moderator, userID, reason)
+}
+
+// EnableUser mocks base method
+func (m *MockModerationInteractor) EnableUser(ctx context.Context, moderator AuditActor, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUser", ctx, moderator, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableUser indicates an expected call of EnableUser
+func (mr *MockModerationInteractorMockRecorder) EnableUser(ctx, moderator, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUser", reflect.TypeOf((*MockModerationInteractor)(nil).EnableUser), ctx, moderator, userID)
+}
+
+// AuditEvents mocks base method
+func (m *MockModerationInteractor) AuditEvents(ctx context.Context, filter domain.AuditEventFilter, page int) (domain.AuditEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditEvents", ctx, filter, page)
+	ret0, _ := ret[0].(domain.AuditEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditEvents indicates an expected call of AuditEvents
+func (mr *MockModerationInteractorMockRecorder) AuditEvents(ctx, filter, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditEvents", reflect.TypeOf((*MockModerationInteractor)(nil).AuditEvents), ctx, filter, page)
+}
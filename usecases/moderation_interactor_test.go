@@ -0,0 +1,188 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+type moderationTestMocks struct {
+	userRepo       *usecases.MockUserRepository
+	sessionRepo    *usecases.MockSessionRepository
+	auditEventRepo *usecases.MockAuditEventRepository
+	auditLogger    *usecases.MockAuditLogger
+}
+
+func setupModerationTest(t *testing.T) (
+	*gomock.Controller,
+	moderationTestMocks,
+	usecases.ModerationInteractor,
+) {
+	ctrl := gomock.NewController(t)
+
+	m := moderationTestMocks{
+		userRepo:       usecases.NewMockUserRepository(ctrl),
+		sessionRepo:    usecases.NewMockSessionRepository(ctrl),
+		auditEventRepo: usecases.NewMockAuditEventRepository(ctrl),
+		auditLogger:    usecases.NewMockAuditLogger(ctrl),
+	}
+	interactor := usecases.NewModerationInteractor(m.userRepo, m.sessionRepo, m.auditEventRepo, newTransactionManager(ctrl), m.auditLogger)
+
+	return ctrl, m, interactor
+}
+
+var moderator = usecases.AuditActor{UserID: 1, IPAddress: "127.0.0.1", UserAgent: "curl/7.64.1"}
+
+func TestModerationInteractor_Users(t *testing.T) {
+	ctrl, m, interactor := setupModerationTest(t)
+	defer ctrl.Finish()
+
+	expected := domain.Users{{ID: 1}}
+	m.userRepo.EXPECT().Search(gomock.Any(), "foo", usecases.UsersPageSize, usecases.UsersPageSize).Return(expected, nil)
+
+	users, err := interactor.Users(context.Background(), "foo", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, users)
+}
+
+func TestModerationInteractor_UpdateRole(t *testing.T) {
+	ctrl, m, interactor := setupModerationTest(t)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: user gets promoted
+		before := domain.User{ID: 2, Role: domain.RoleUser}
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(2)).Return(before, nil)
+		m.userRepo.EXPECT().UpdateRole(gomock.Any(), uint64(2), domain.RoleAdmin).Return(nil)
+		m.auditLogger.EXPECT().Log(gomock.Any(), moderator, domain.AuditActionUserRole, uint64(2), before, domain.User{ID: 2, Role: domain.RoleAdmin}).Return(nil)
+
+		err := interactor.UpdateRole(context.Background(), moderator, 2, domain.RoleAdmin)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: disabling has to be done with a reason
+		err := interactor.UpdateRole(context.Background(), moderator, 2, domain.RoleDisabled)
+		assert.EqualError(t, err, usecases.ErrModerationRoleInvalid.Error())
+	}
+
+	{
+		// Sad path: admins can't demote themselves
+		err := interactor.UpdateRole(context.Background(), moderator, 1, domain.RoleUser)
+		assert.EqualError(t, err, usecases.ErrModerationSelf.Error())
+	}
+
+	{
+		// Sad path: unknown user
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(3)).Return(domain.User{}, domain.ErrNotFound)
+
+		err := interactor.UpdateRole(context.Background(), moderator, 3, domain.RoleUser)
+		assert.EqualError(t, err, usecases.ErrUserDoesNotExist.Error())
+	}
+
+	{
+		// Sad path: disabled users can't be reactivated by changing their role
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(4)).Return(domain.User{ID: 4, Role: domain.RoleDisabled, DisabledRole: domain.RoleUser}, nil)
+
+		err := interactor.UpdateRole(context.Background(), moderator, 4, domain.RoleUser)
+		assert.EqualError(t, err, usecases.ErrModerationUserDisabled.Error())
+	}
+
+	{
+		// Sad path: user got disabled in the meantime
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(5)).Return(domain.User{ID: 5, Role: domain.RoleUser}, nil)
+		m.userRepo.EXPECT().UpdateRole(gomock.Any(), uint64(5), domain.RoleAdmin).Return(domain.ErrNotFound)
+
+		err := interactor.UpdateRole(context.Background(), moderator, 5, domain.RoleAdmin)
+		assert.EqualError(t, err, usecases.ErrModerationUserDisabled.Error())
+	}
+}
+
+func TestModerationInteractor_DisableUser(t *testing.T) {
+	ctrl, m, interactor := setupModerationTest(t)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: user gets disabled and logged out everywhere
+		before := domain.User{ID: 2, Role: domain.RoleUser}
+		after := domain.User{ID: 2, Role: domain.RoleDisabled, DisabledReason: "spam", DisabledRole: domain.RoleUser}
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(2)).Return(before, nil)
+		m.userRepo.EXPECT().Disable(gomock.Any(), uint64(2), "spam").Return(nil)
+		m.sessionRepo.EXPECT().RevokeAllForUser(gomock.Any(), uint64(2)).Return(nil)
+		m.auditLogger.EXPECT().Log(gomock.Any(), moderator, domain.AuditActionUserDisable, uint64(2), before, after).Return(nil)
+
+		err := interactor.DisableUser(context.Background(), moderator, 2, " spam ")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: no reason given
+		err := interactor.DisableUser(context.Background(), moderator, 2, " ")
+		assert.EqualError(t, err, usecases.ErrModerationReasonMissing.Error())
+	}
+
+	{
+		// Sad path: admins can't disable themselves
+		err := interactor.DisableUser(context.Background(), moderator, 1, "spam")
+		assert.EqualError(t, err, usecases.ErrModerationSelf.Error())
+	}
+}
+
+func TestModerationInteractor_EnableUser(t *testing.T) {
+	ctrl, m, interactor := setupModerationTest(t)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: disabled user gets their role back
+		before := domain.User{ID: 2, Role: domain.RoleDisabled, DisabledReason: "spam", DisabledRole: domain.RoleAdmin}
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(2)).Return(before, nil)
+		m.userRepo.EXPECT().Enable(gomock.Any(), uint64(2)).Return(nil)
+		m.auditLogger.EXPECT().Log(gomock.Any(), moderator, domain.AuditActionUserEnable, uint64(2), before, domain.User{ID: 2, Role: domain.RoleAdmin}).Return(nil)
+
+		err := interactor.EnableUser(context.Background(), moderator, 2)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: user is not disabled
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(3)).Return(domain.User{ID: 3, Role: domain.RoleUser}, nil)
+
+		err := interactor.EnableUser(context.Background(), moderator, 3)
+		assert.EqualError(t, err, usecases.ErrModerationNotDisabled.Error())
+	}
+
+	{
+		// Sad path: deleted accounts can't be enabled
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(4)).Return(domain.User{ID: 4, Role: domain.RoleDisabled, DisabledReason: "account deleted"}, nil)
+
+		err := interactor.EnableUser(context.Background(), moderator, 4)
+		assert.EqualError(t, err, usecases.ErrModerationNotDisabled.Error())
+	}
+
+	{
+		// Sad path: user got enabled in the meantime
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(5)).Return(domain.User{ID: 5, Role: domain.RoleDisabled, DisabledRole: domain.RoleUser}, nil)
+		m.userRepo.EXPECT().Enable(gomock.Any(), uint64(5)).Return(domain.ErrNotFound)
+
+		err := interactor.EnableUser(context.Background(), moderator, 5)
+		assert.EqualError(t, err, usecases.ErrModerationNotDisabled.Error())
+	}
+}
+
+func TestModerationInteractor_AuditEvents(t *testing.T) {
+	ctrl, m, interactor := setupModerationTest(t)
+	defer ctrl.Finish()
+
+	filter := domain.AuditEventFilter{TargetType: "user", TargetID: 2}
+	expected := domain.AuditEvents{{ID: 1, Action: domain.AuditActionUserDisable}}
+	m.auditEventRepo.EXPECT().Find(gomock.Any(), filter, usecases.AuditEventsPageSize, 0).Return(expected, nil)
+
+	events, err := interactor.AuditEvents(context.Background(), filter, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, events)
+}
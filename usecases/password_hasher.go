@@ -10,4 +10,6 @@ import (
 type PasswordHasher interface {
 	Hash(unhashed domain.Password) (hashed domain.Password, err error)
 	Compare(hash domain.Password, original string) bool
+	// NeedsRehash tells if a hash was made with a different algorithm or parameters than new passwords are
+	NeedsRehash(hash domain.Password) bool
 }
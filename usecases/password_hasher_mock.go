@@ -61,3 +61,17 @@ func (mr *MockPasswordHasherMockRecorder) Compare(hash, original interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compare", reflect.TypeOf((*MockPasswordHasher)(nil).Compare), hash, original)
 }
+
+// NeedsRehash mocks base method
+func (m *MockPasswordHasher) NeedsRehash(hash domain.Password) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsRehash", hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsRehash indicates an expected call of NeedsRehash
+func (mr *MockPasswordHasherMockRecorder) NeedsRehash(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsRehash", reflect.TypeOf((*MockPasswordHasher)(nil).NeedsRehash), hash)
+}
@@ -0,0 +1,128 @@
+//go:generate gex mockgen -source=personal_access_token_interactor.go -package usecases -destination=personal_access_token_interactor_mock.go
+
+package usecases
+
+import (
+	"context"
+	"strings"
+
+	"github.com/srvc/fail"
+	"github.com/tadoku/api/domain"
+)
+
+// ErrInvalidPersonalAccessToken for when a token would be created without a name or with unknown scopes
+var ErrInvalidPersonalAccessToken = fail.New("invalid personal access token supplied")
+
+// ErrPersonalAccessTokenNotFound for when a token could not be found for the given user
+var ErrPersonalAccessTokenNotFound = fail.New("personal access token does not exist")
+
+// ErrPersonalAccessTokenRejected for when a token is unknown or has been revoked
+var ErrPersonalAccessTokenRejected = fail.New("personal access token is invalid or has been revoked")
+
+// PersonalAccessTokenInteractor contains all business logic for personal access tokens
+type PersonalAccessTokenInteractor interface {
+	CreateToken(ctx context.Context, userID uint64, name string, scopes domain.Scopes) (token string, created domain.PersonalAccessToken, err error)
+	Tokens(ctx context.Context, userID uint64) (domain.PersonalAccessTokens, error)
+	RevokeToken(ctx context.Context, userID, tokenID uint64) error
+	Authenticate(ctx context.Context, token string) (user domain.User, scopes domain.Scopes, err error)
+}
+
+// NewPersonalAccessTokenInteractor instantiates PersonalAccessTokenInteractor with all dependencies
+func NewPersonalAccessTokenInteractor(
+	personalAccessTokenRepository PersonalAccessTokenRepository,
+	userRepository UserRepository,
+	tokenGenerator TokenGenerator,
+	validator Validator,
+) PersonalAccessTokenInteractor {
+	return &personalAccessTokenInteractor{
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		userRepository:                userRepository,
+		tokenGenerator:                tokenGenerator,
+		validator:                     validator,
+	}
+}
+
+type personalAccessTokenInteractor struct {
+	personalAccessTokenRepository PersonalAccessTokenRepository
+	userRepository                UserRepository
+	tokenGenerator                TokenGenerator
+	validator                     Validator
+}
+
+func (i *personalAccessTokenInteractor) CreateToken(ctx context.Context, userID uint64, name string, scopes domain.Scopes) (string, domain.PersonalAccessToken, error) {
+	created := domain.PersonalAccessToken{
+		UserID: userID,
+		Name:   name,
+		Scopes: scopes,
+	}
+	if valid, violations := i.validator.Validate(created); !valid {
+		return "", domain.PersonalAccessToken{}, newValidationError(ErrInvalidPersonalAccessToken, violations)
+	}
+
+	secret, err := i.tokenGenerator.Generate()
+	if err != nil {
+		return "", domain.PersonalAccessToken{}, domain.WrapError(err)
+	}
+	token := domain.PersonalAccessTokenPrefix + secret
+
+	created.Hash = domain.HashToken(token)
+	if err := i.personalAccessTokenRepository.Store(ctx, &created); err != nil {
+		return "", domain.PersonalAccessToken{}, domain.WrapError(err)
+	}
+
+	return token, created, nil
+}
+
+func (i *personalAccessTokenInteractor) Tokens(ctx context.Context, userID uint64) (domain.PersonalAccessTokens, error) {
+	tokens, err := i.personalAccessTokenRepository.FindActiveByUserID(ctx, userID)
+	return tokens, domain.WrapError(err)
+}
+
+func (i *personalAccessTokenInteractor) RevokeToken(ctx context.Context, userID, tokenID uint64) error {
+	token, err := i.personalAccessTokenRepository.FindByID(ctx, tokenID)
+	if err == domain.ErrNotFound {
+		return ErrPersonalAccessTokenNotFound
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if token.UserID != userID {
+		return ErrPersonalAccessTokenNotFound
+	}
+
+	err = i.personalAccessTokenRepository.Revoke(ctx, token.ID)
+	return domain.WrapError(err)
+}
+
+func (i *personalAccessTokenInteractor) Authenticate(ctx context.Context, token string) (domain.User, domain.Scopes, error) {
+	if !strings.HasPrefix(token, domain.PersonalAccessTokenPrefix) {
+		return domain.User{}, nil, ErrPersonalAccessTokenRejected
+	}
+
+	found, err := i.personalAccessTokenRepository.FindByHash(ctx, domain.HashToken(token))
+	if err == domain.ErrNotFound {
+		return domain.User{}, nil, ErrPersonalAccessTokenRejected
+	}
+	if err != nil {
+		return domain.User{}, nil, domain.WrapError(err)
+	}
+
+	if !found.IsActive() {
+		return domain.User{}, nil, ErrPersonalAccessTokenRejected
+	}
+
+	user, err := i.userRepository.FindByID(ctx, found.UserID)
+	if err != nil {
+		return domain.User{}, nil, domain.WrapError(err)
+	}
+	if user.IsDisabled() {
+		return domain.User{}, nil, ErrPersonalAccessTokenRejected
+	}
+
+	if err := i.personalAccessTokenRepository.UpdateLastUsed(ctx, found.ID); err != nil {
+		return domain.User{}, nil, domain.WrapError(err)
+	}
+
+	return user, found.Scopes, nil
+}
@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: personal_access_token_interactor.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/tadoku/api/domain"
+	reflect "reflect"
+)
+
+// MockPersonalAccessTokenInteractor is a mock of PersonalAccessTokenInteractor interface
+type MockPersonalAccessTokenInteractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockPersonalAccessTokenInteractorMockRecorder
+}
+
+// MockPersonalAccessTokenInteractorMockRecorder is the mock recorder for MockPersonalAccessTokenInteractor
+type MockPersonalAccessTokenInteractorMockRecorder struct {
+	mock *MockPersonalAccessTokenInteractor
+}
+
+// NewMockPersonalAccessTokenInteractor creates a new mock instance
+func NewMockPersonalAccessTokenInteractor(ctrl *gomock.Controller) *MockPersonalAccessTokenInteractor {
+	mock := &MockPersonalAccessTokenInteractor{ctrl: ctrl}
+	mock.recorder = &MockPersonalAccessTokenInteractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPersonalAccessTokenInteractor) EXPECT() *MockPersonalAccessTokenInteractorMockRecorder {
+	return m.recorder
+}
+
+// CreateToken mocks base method
+func (m *MockPersonalAccessTokenInteractor) CreateToken(ctx context.Context, userID uint64, name string, scopes domain.Scopes) (string, domain.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateToken", ctx, userID, name, scopes)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(domain.PersonalAccessToken)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateToken indicates an expected call of CreateToken
+func (mr *MockPersonalAccessTokenInteractorMockRecorder) CreateToken(ctx, userID, name, scopes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateToken", reflect.TypeOf((*MockPersonalAccessTokenInteractor)(nil).CreateToken), ctx, userID, name, scopes)
+}
+
+// Tokens mocks base method
+func (m *MockPersonalAccessTokenInteractor) Tokens(ctx context.Context, userID uint64) (domain.PersonalAccessTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tokens", ctx, userID)
+	ret0, _ := ret[0].(domain.PersonalAccessTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Tokens indicates an expected call of Tokens
+func (mr *MockPersonalAccessTokenInteractorMockRecorder) Tokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tokens", reflect.TypeOf((*MockPersonalAccessTokenInteractor)(nil).Tokens), ctx, userID)
+}
+
+// RevokeToken mocks base method
+func (m *MockPersonalAccessTokenInteractor) RevokeToken(ctx context.Context, userID, tokenID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, userID, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken
+func (mr *MockPersonalAccessTokenInteractorMockRecorder) RevokeToken(ctx, userID, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockPersonalAccessTokenInteractor)(nil).RevokeToken), ctx, userID, tokenID)
+}
+
+// Authenticate mocks base method
+func (m *MockPersonalAccessTokenInteractor) Authenticate(ctx context.Context, token string) (domain.User, domain.Scopes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, token)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(domain.Scopes)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Authenticate indicates an expected call of Authenticate
+func (mr *MockPersonalAccessTokenInteractorMockRecorder) Authenticate(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockPersonalAccessTokenInteractor)(nil).Authenticate), ctx, token)
+}
@@ -0,0 +1,143 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func setupPersonalAccessTokenTest(t *testing.T) (
+	*gomock.Controller,
+	*usecases.MockPersonalAccessTokenRepository,
+	*usecases.MockUserRepository,
+	*usecases.MockTokenGenerator,
+	*usecases.MockValidator,
+	usecases.PersonalAccessTokenInteractor,
+) {
+	ctrl := gomock.NewController(t)
+
+	repo := usecases.NewMockPersonalAccessTokenRepository(ctrl)
+	userRepo := usecases.NewMockUserRepository(ctrl)
+	tokenGen := usecases.NewMockTokenGenerator(ctrl)
+	validator := usecases.NewMockValidator(ctrl)
+	interactor := usecases.NewPersonalAccessTokenInteractor(repo, userRepo, tokenGen, validator)
+
+	return ctrl, repo, userRepo, tokenGen, validator, interactor
+}
+
+func TestPersonalAccessTokenInteractor_CreateToken(t *testing.T) {
+	ctrl, repo, _, tokenGen, validator, interactor := setupPersonalAccessTokenTest(t)
+	defer ctrl.Finish()
+
+	scopes := domain.Scopes{domain.ScopeLogsWrite}
+
+	{
+		// Happy path: token gets stored hashed
+		pat := domain.PersonalAccessToken{UserID: 1, Name: "kobo", Scopes: scopes}
+		validator.EXPECT().Validate(pat).Return(true, nil)
+		tokenGen.EXPECT().Generate().Return("foobar", nil)
+
+		stored := pat
+		stored.Hash = domain.HashToken("tdk_foobar")
+		repo.EXPECT().Store(gomock.Any(), &stored).Return(nil)
+
+		token, created, err := interactor.CreateToken(context.Background(), 1, "kobo", scopes)
+		assert.NoError(t, err)
+		assert.Equal(t, "tdk_foobar", token)
+		assert.Equal(t, stored, created)
+	}
+
+	{
+		// Sad path: invalid scopes
+		pat := domain.PersonalAccessToken{UserID: 1, Name: "kobo"}
+		validator.EXPECT().Validate(pat).Return(false, domain.FieldErrors{domain.NewFieldError("scopes", "scopes", domain.ErrScopesEmpty)})
+
+		_, _, err := interactor.CreateToken(context.Background(), 1, "kobo", nil)
+		assert.EqualError(t, err, usecases.ErrInvalidPersonalAccessToken.Error())
+	}
+}
+
+func TestPersonalAccessTokenInteractor_RevokeToken(t *testing.T) {
+	ctrl, repo, _, _, _, interactor := setupPersonalAccessTokenTest(t)
+	defer ctrl.Finish()
+
+	pat := domain.PersonalAccessToken{ID: 2, UserID: 1}
+
+	{
+		// Happy path: own token gets revoked
+		repo.EXPECT().FindByID(gomock.Any(), pat.ID).Return(pat, nil)
+		repo.EXPECT().Revoke(gomock.Any(), pat.ID).Return(nil)
+
+		err := interactor.RevokeToken(context.Background(), 1, pat.ID)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: token belongs to someone else
+		repo.EXPECT().FindByID(gomock.Any(), pat.ID).Return(pat, nil)
+
+		err := interactor.RevokeToken(context.Background(), 3, pat.ID)
+		assert.EqualError(t, err, usecases.ErrPersonalAccessTokenNotFound.Error())
+	}
+}
+
+func TestPersonalAccessTokenInteractor_Authenticate(t *testing.T) {
+	ctrl, repo, userRepo, _, _, interactor := setupPersonalAccessTokenTest(t)
+	defer ctrl.Finish()
+
+	user := domain.User{ID: 1, Role: domain.RoleUser}
+	scopes := domain.Scopes{domain.ScopeLogsWrite}
+
+	{
+		// Happy path: active token
+		pat := domain.PersonalAccessToken{ID: 2, UserID: user.ID, Scopes: scopes}
+		repo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("tdk_foobar")).Return(pat, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		repo.EXPECT().UpdateLastUsed(gomock.Any(), pat.ID).Return(nil)
+
+		foundUser, foundScopes, err := interactor.Authenticate(context.Background(), "tdk_foobar")
+		assert.NoError(t, err)
+		assert.Equal(t, user, foundUser)
+		assert.Equal(t, scopes, foundScopes)
+	}
+
+	{
+		// Sad path: revoked token
+		revokedAt := time.Now()
+		pat := domain.PersonalAccessToken{ID: 2, UserID: user.ID, Scopes: scopes, RevokedAt: &revokedAt}
+		repo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("tdk_foobar")).Return(pat, nil)
+
+		_, _, err := interactor.Authenticate(context.Background(), "tdk_foobar")
+		assert.EqualError(t, err, usecases.ErrPersonalAccessTokenRejected.Error())
+	}
+
+	{
+		// Sad path: user has been disabled
+		pat := domain.PersonalAccessToken{ID: 2, UserID: user.ID, Scopes: scopes}
+		repo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("tdk_foobar")).Return(pat, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(domain.User{ID: user.ID, Role: domain.RoleDisabled}, nil)
+
+		_, _, err := interactor.Authenticate(context.Background(), "tdk_foobar")
+		assert.EqualError(t, err, usecases.ErrPersonalAccessTokenRejected.Error())
+	}
+
+	{
+		// Sad path: unknown token
+		repo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("tdk_unknown")).Return(domain.PersonalAccessToken{}, domain.ErrNotFound)
+
+		_, _, err := interactor.Authenticate(context.Background(), "tdk_unknown")
+		assert.EqualError(t, err, usecases.ErrPersonalAccessTokenRejected.Error())
+	}
+
+	{
+		// Sad path: not a personal access token at all
+		_, _, err := interactor.Authenticate(context.Background(), "foobar")
+		assert.EqualError(t, err, usecases.ErrPersonalAccessTokenRejected.Error())
+	}
+}
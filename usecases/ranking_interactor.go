@@ -3,6 +3,9 @@
 package usecases
 
 import (
+	"context"
+	"math"
+
 	"github.com/srvc/fail"
 
 	"github.com/tadoku/api/domain"
@@ -47,19 +50,26 @@ var ErrCreateContestLogHasID = fail.New("a contest log can't have an id when bei
 // RankingInteractor contains all business logic for rankings
 type RankingInteractor interface {
 	CreateRanking(
+		ctx context.Context,
 		contestID uint64,
 		userID uint64,
 		languages domain.LanguageCodes,
 	) error
-	CreateLog(log domain.ContestLog) error
-	UpdateLog(log domain.ContestLog) error
-	DeleteLog(logID uint64, userID uint64) error
-	UpdateRanking(contestID uint64, userID uint64) error
-
-	RankingsForRegistration(contestID uint64, userID uint64) (domain.Rankings, error)
-	RankingsForContest(contestID uint64, languageCode domain.LanguageCode) (domain.Rankings, error)
-	CurrentRegistration(userID uint64) (domain.RankingRegistration, error)
-	ContestLogs(contestID uint64, userID uint64) (domain.ContestLogs, error)
+	CreateLog(ctx context.Context, log domain.ContestLog) error
+	UpdateLog(ctx context.Context, log domain.ContestLog) error
+	DeleteLog(ctx context.Context, logID uint64, userID uint64) error
+	// UpdateRanking recomputes the rankings of a user from all of their logs
+	UpdateRanking(ctx context.Context, contestID uint64, userID uint64) error
+	// ReconcileRankings recomputes the rankings of open contests and repairs the ones that drifted from their logs,
+	// it returns how many users needed to be repaired
+	ReconcileRankings(ctx context.Context) (int, error)
+
+	// RankingsForRegistration and ContestLogs of users that hide themselves are only shown to themselves,
+	// guests have a viewerID of 0
+	RankingsForRegistration(ctx context.Context, contestID uint64, userID uint64, viewerID uint64) (domain.Rankings, error)
+	RankingsForContest(ctx context.Context, contestID uint64, languageCode domain.LanguageCode) (domain.Rankings, error)
+	CurrentRegistration(ctx context.Context, userID uint64) (domain.RankingRegistration, error)
+	ContestLogs(ctx context.Context, contestID uint64, userID uint64, viewerID uint64) (domain.ContestLogs, error)
 }
 
 // NewRankingInteractor instantiates RankingInteractor with all dependencies
@@ -68,6 +78,8 @@ func NewRankingInteractor(
 	contestRepository ContestRepository,
 	contestLogRepository ContestLogRepository,
 	userRepository UserRepository,
+	transactions TransactionManager,
+	metrics MetricsRecorder,
 	validator Validator,
 ) RankingInteractor {
 	return &rankingInteractor{
@@ -75,6 +87,8 @@ func NewRankingInteractor(
 		contestRepository:    contestRepository,
 		contestLogRepository: contestLogRepository,
 		userRepository:       userRepository,
+		transactions:         transactions,
+		metrics:              metrics,
 		validator:            validator,
 	}
 }
@@ -84,15 +98,18 @@ type rankingInteractor struct {
 	contestRepository    ContestRepository
 	contestLogRepository ContestLogRepository
 	userRepository       UserRepository
+	transactions         TransactionManager
+	metrics              MetricsRecorder
 	validator            Validator
 }
 
 func (i *rankingInteractor) CreateRanking(
+	ctx context.Context,
 	contestID uint64,
 	userID uint64,
 	languages domain.LanguageCodes,
 ) error {
-	ids, err := i.contestRepository.GetOpenContests()
+	ids, err := i.contestRepository.GetOpenContests(ctx)
 	if err != nil {
 		return domain.WrapError(err)
 	}
@@ -101,11 +118,11 @@ func (i *rankingInteractor) CreateRanking(
 		return ErrContestIsClosed
 	}
 
-	if _, err := i.userRepository.FindByID(userID); err != nil {
+	if _, err := i.userRepository.FindByID(ctx, userID); err != nil {
 		return ErrUserDoesNotExist
 	}
 
-	existingLanguages, err := i.rankingRepository.GetAllLanguagesForContestAndUser(contestID, userID)
+	existingLanguages, err := i.rankingRepository.GetAllLanguagesForContestAndUser(ctx, contestID, userID)
 	if err != nil {
 		return domain.WrapError(err)
 	}
@@ -146,133 +163,311 @@ func (i *rankingInteractor) CreateRanking(
 		}
 	}
 
-	for _, ranking := range rankings {
-		if err := i.rankingRepository.Store(ranking); err != nil {
-			return domain.WrapError(err)
+	// Users shouldn't end up with only some of their languages signed up
+	return i.transactions.Run(ctx, func(ctx context.Context) error {
+		for _, ranking := range rankings {
+			if err := i.rankingRepository.Store(ctx, ranking); err != nil {
+				return domain.WrapError(err)
+			}
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
-func (i *rankingInteractor) CreateLog(log domain.ContestLog) error {
+func (i *rankingInteractor) CreateLog(ctx context.Context, log domain.ContestLog) error {
 	if log.ID != 0 {
 		return ErrCreateContestLogHasID
 	}
 
-	return i.saveLog(log)
+	if log.Language == "" || log.MediumID == 0 {
+		user, err := i.userRepository.FindByID(ctx, log.UserID)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+		if user.Preferences != nil {
+			if log.Language == "" {
+				log.Language = user.Preferences.DefaultLanguage
+			}
+			if log.MediumID == 0 {
+				log.MediumID = user.Preferences.DefaultMedium
+			}
+		}
+	}
+
+	return i.saveLog(ctx, log)
 }
 
-func (i *rankingInteractor) UpdateLog(log domain.ContestLog) error {
+func (i *rankingInteractor) UpdateLog(ctx context.Context, log domain.ContestLog) error {
 	if log.ID == 0 {
 		return ErrContestLogIDMissing
 	}
 
-	return i.saveLog(log)
+	return i.saveLog(ctx, log)
 }
 
-func (i *rankingInteractor) saveLog(log domain.ContestLog) error {
-	if valid, _ := i.validator.Validate(log); !valid {
-		return ErrInvalidContestLog
+func (i *rankingInteractor) saveLog(ctx context.Context, log domain.ContestLog) error {
+	if valid, violations := i.validator.Validate(log); !valid {
+		return newValidationError(ErrInvalidContestLog, violations)
 	}
 
-	if log.ID != 0 {
-		existingLog, err := i.contestLogRepository.FindByID(log.ID)
+	created := log.ID == 0
+	err := i.transactions.Run(ctx, func(ctx context.Context) error {
+		amounts := rankingAmounts{}
+		if !created {
+			// Locked so a concurrent update can't subtract the same old amount twice
+			existingLog, err := i.contestLogRepository.FindByIDForUpdate(ctx, log.ID)
+			if err != nil {
+				return domain.WrapError(err)
+			}
+
+			if existingLog.UserID != log.UserID {
+				return domain.ErrInsufficientPermissions
+			}
+
+			// Logs can't be moved to another contest, so its rankings are the ones to update
+			log.ContestID = existingLog.ContestID
+			amounts.subtract(existingLog)
+		}
+		amounts.add(log)
+
+		if err := i.checkContestIsRunning(ctx, log.ContestID); err != nil {
+			return err
+		}
+
+		languages, err := i.rankingRepository.GetAllLanguagesForContestAndUser(ctx, log.ContestID, log.UserID)
 		if err != nil {
 			return domain.WrapError(err)
 		}
+		if !languages.ContainsLanguage(log.Language) {
+			return ErrContestLanguageNotSignedUp
+		}
 
-		if existingLog.UserID != log.UserID {
-			return domain.ErrInsufficientPermissions
+		if err := i.contestLogRepository.Store(ctx, &log); err != nil {
+			return domain.WrapError(err)
 		}
-	}
 
-	ids, err := i.contestRepository.GetRunningContests()
+		// The log is only kept when the rankings are up to date with it
+		return i.addToRanking(ctx, log.ContestID, log.UserID, amounts)
+	})
 	if err != nil {
-		domain.WrapError(err)
+		return err
 	}
-	if !domain.ContainsID(ids, log.ContestID) {
-		return ErrContestIsClosed
+	if created {
+		i.metrics.ContestLogCreated()
 	}
 
-	languages, err := i.rankingRepository.GetAllLanguagesForContestAndUser(log.ContestID, log.UserID)
-	if err != nil {
-		return domain.WrapError(err)
-	}
-	if !languages.ContainsLanguage(log.Language) {
-		return ErrContestLanguageNotSignedUp
-	}
+	return nil
+}
 
-	err = i.contestLogRepository.Store(&log)
+func (i *rankingInteractor) DeleteLog(ctx context.Context, logID uint64, userID uint64) error {
+	err := i.transactions.Run(ctx, func(ctx context.Context) error {
+		// Locked so a concurrent update can't change the amount that gets subtracted
+		log, err := i.contestLogRepository.FindByIDForUpdate(ctx, logID)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		if log.UserID != userID {
+			return domain.ErrInsufficientPermissions
+		}
+
+		if err := i.checkContestIsRunning(ctx, log.ContestID); err != nil {
+			return err
+		}
+
+		if err := i.contestLogRepository.Delete(ctx, logID); err != nil {
+			return domain.WrapError(err)
+		}
+
+		amounts := rankingAmounts{}
+		amounts.subtract(log)
+		return i.addToRanking(ctx, log.ContestID, log.UserID, amounts)
+	})
 	if err != nil {
-		return domain.WrapError(err)
+		return err
 	}
+	i.metrics.ContestLogDeleted()
 
-	return i.UpdateRanking(log.ContestID, log.UserID)
+	return nil
 }
 
-func (i *rankingInteractor) DeleteLog(logID uint64, userID uint64) error {
-	log, err := i.contestLogRepository.FindByID(logID)
+// checkContestIsRunning makes sure logs only change while their contest is running
+func (i *rankingInteractor) checkContestIsRunning(ctx context.Context, contestID uint64) error {
+	ids, err := i.contestRepository.GetRunningContests(ctx)
 	if err != nil {
 		return domain.WrapError(err)
 	}
-
-	if log.UserID != userID {
-		return domain.ErrInsufficientPermissions
+	if !domain.ContainsID(ids, contestID) {
+		return ErrContestIsClosed
 	}
 
-	ids, err := i.contestRepository.GetRunningContests()
-	if err != nil {
-		domain.WrapError(err)
+	return nil
+}
+
+// rankingAmounts is how much the rankings of a user change per language, the global ranking moves along with every log
+type rankingAmounts map[domain.LanguageCode]float32
+
+func (a rankingAmounts) add(log domain.ContestLog) {
+	amount := log.AdjustedAmount()
+	a[log.Language] += amount
+	a[domain.Global] += amount
+}
+
+func (a rankingAmounts) subtract(log domain.ContestLog) {
+	amount := log.AdjustedAmount()
+	a[log.Language] -= amount
+	a[domain.Global] -= amount
+}
+
+// rankingDriftTolerance is how far apart amounts can be before they're repaired,
+// summing the same logs in a different order doesn't always give the exact same floats
+const rankingDriftTolerance = 0.01
+
+func (a rankingAmounts) differsFrom(rankings domain.Rankings) bool {
+	for _, ranking := range rankings {
+		if math.Abs(float64(ranking.Amount-a[ranking.Language])) > rankingDriftTolerance {
+			return true
+		}
 	}
-	if !domain.ContainsID(ids, log.ContestID) {
-		return ErrContestIsClosed
+
+	return false
+}
+
+// addToRanking only touches the rankings a log change affects, instead of recomputing them from every log
+func (i *rankingInteractor) addToRanking(
+	ctx context.Context,
+	contestID uint64,
+	userID uint64,
+	amounts rankingAmounts,
+) error {
+	err := i.rankingRepository.AddAmounts(ctx, contestID, userID, amounts)
+	if err == domain.ErrNotFound {
+		return ErrNoRankingsFound
 	}
 
-	err = i.contestLogRepository.Delete(logID)
+	return domain.WrapError(err)
+}
+
+func (i *rankingInteractor) UpdateRanking(ctx context.Context, contestID uint64, userID uint64) error {
+	err := i.transactions.Run(ctx, func(ctx context.Context) error {
+		// Locking the rankings first makes logs that are saved in the meantime wait, so their changes can't get lost
+		rankings, err := i.rankingRepository.FindAllForUpdate(ctx, contestID, userID)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		if len(rankings) == 0 {
+			return ErrNoRankingsFound
+		}
+
+		logs, err := i.contestLogRepository.FindAll(ctx, contestID, userID)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		totals := rankingAmounts{}
+		for _, log := range logs {
+			totals.add(log)
+		}
+
+		updatedRankings := domain.Rankings{}
+		for _, ranking := range rankings {
+			ranking.Amount = totals[ranking.Language]
+			updatedRankings = append(updatedRankings, ranking)
+		}
+
+		return domain.WrapError(i.rankingRepository.UpdateAmounts(ctx, updatedRankings))
+	})
 	if err != nil {
-		return domain.WrapError(err)
+		return err
 	}
+	i.metrics.RankingsRecomputed()
 
-	return i.UpdateRanking(log.ContestID, log.UserID)
+	return nil
 }
 
-func (i *rankingInteractor) UpdateRanking(contestID uint64, userID uint64) error {
-	rankings, err := i.rankingRepository.FindAll(contestID, userID)
+func (i *rankingInteractor) ReconcileRankings(ctx context.Context) (int, error) {
+	ids, err := i.contestRepository.GetOpenContests(ctx)
 	if err != nil {
-		return domain.WrapError(err)
+		return 0, domain.WrapError(err)
 	}
 
-	if len(rankings) == 0 {
-		return ErrNoRankingsFound
+	repaired := 0
+	for _, contestID := range ids {
+		drifted, err := i.driftedUsers(ctx, contestID)
+		if err != nil {
+			return repaired, err
+		}
+
+		// The rankings could have changed since they were checked, recomputing them is safe either way
+		for _, userID := range drifted {
+			if err := i.UpdateRanking(ctx, contestID, userID); err != nil {
+				return repaired, err
+			}
+			i.metrics.RankingsDriftRepaired()
+			repaired++
+		}
 	}
 
-	logs, err := i.contestLogRepository.FindAll(contestID, userID)
+	return repaired, nil
+}
+
+// driftedUsers compares the rankings of everyone in a contest against the sum of their logs
+func (i *rankingInteractor) driftedUsers(ctx context.Context, contestID uint64) ([]uint64, error) {
+	rankings, err := i.rankingRepository.FindAllForContest(ctx, contestID)
 	if err != nil {
-		return domain.WrapError(err)
+		return nil, domain.WrapError(err)
 	}
 
-	totals := make(map[domain.LanguageCode]float32)
+	logs, err := i.contestLogRepository.FindAllForContest(ctx, contestID)
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	totals := map[uint64]rankingAmounts{}
 	for _, log := range logs {
-		amount := log.AdjustedAmount()
-		totals[log.Language] += amount
-		totals[domain.Global] += amount
+		if totals[log.UserID] == nil {
+			totals[log.UserID] = rankingAmounts{}
+		}
+		totals[log.UserID].add(log)
 	}
 
-	updatedRankings := domain.Rankings{}
+	rankingsPerUser := map[uint64]domain.Rankings{}
+	var users []uint64
 	for _, ranking := range rankings {
-		ranking.Amount = totals[ranking.Language]
-		updatedRankings = append(updatedRankings, ranking)
+		if rankingsPerUser[ranking.UserID] == nil {
+			users = append(users, ranking.UserID)
+		}
+		rankingsPerUser[ranking.UserID] = append(rankingsPerUser[ranking.UserID], ranking)
 	}
 
-	return i.rankingRepository.UpdateAmounts(updatedRankings)
+	var drifted []uint64
+	for _, userID := range users {
+		if totals[userID].differsFrom(rankingsPerUser[userID]) {
+			drifted = append(drifted, userID)
+		}
+	}
+
+	return drifted, nil
 }
 
 func (i *rankingInteractor) RankingsForRegistration(
+	ctx context.Context,
 	contestID uint64,
 	userID uint64,
+	viewerID uint64,
 ) (domain.Rankings, error) {
-	rankings, err := i.rankingRepository.FindAll(contestID, userID)
+	// Hidden users shouldn't be distinguishable from users that haven't registered
+	user, err := i.userRepository.FindByID(ctx, userID)
+	if err == domain.ErrNotFound || user.IsHiddenFrom(viewerID) {
+		return nil, ErrNoRankingsFound
+	}
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	rankings, err := i.rankingRepository.FindAll(ctx, contestID, userID)
 	if err != nil {
 		return nil, domain.WrapError(err)
 	}
@@ -285,6 +480,7 @@ func (i *rankingInteractor) RankingsForRegistration(
 }
 
 func (i *rankingInteractor) RankingsForContest(
+	ctx context.Context,
 	contestID uint64,
 	languageCode domain.LanguageCode,
 ) (domain.Rankings, error) {
@@ -298,9 +494,9 @@ func (i *rankingInteractor) RankingsForContest(
 	var err error
 
 	if domain.ContestID(contestID).IsGlobal() {
-		rankings, err = i.rankingRepository.GlobalRankings(validatedLanguage)
+		rankings, err = i.rankingRepository.GlobalRankings(ctx, validatedLanguage)
 	} else {
-		rankings, err = i.rankingRepository.RankingsForContest(contestID, validatedLanguage)
+		rankings, err = i.rankingRepository.RankingsForContest(ctx, contestID, validatedLanguage)
 	}
 
 	if err != nil {
@@ -314,8 +510,8 @@ func (i *rankingInteractor) RankingsForContest(
 	return rankings, nil
 }
 
-func (i *rankingInteractor) CurrentRegistration(userID uint64) (domain.RankingRegistration, error) {
-	registration, err := i.rankingRepository.CurrentRegistration(userID)
+func (i *rankingInteractor) CurrentRegistration(ctx context.Context, userID uint64) (domain.RankingRegistration, error) {
+	registration, err := i.rankingRepository.CurrentRegistration(ctx, userID)
 	if err != nil {
 		return registration, domain.WrapError(err)
 	}
@@ -327,8 +523,17 @@ func (i *rankingInteractor) CurrentRegistration(userID uint64) (domain.RankingRe
 	return registration, nil
 }
 
-func (i *rankingInteractor) ContestLogs(contestID uint64, userID uint64) (domain.ContestLogs, error) {
-	logs, err := i.contestLogRepository.FindAll(contestID, userID)
+func (i *rankingInteractor) ContestLogs(ctx context.Context, contestID uint64, userID uint64, viewerID uint64) (domain.ContestLogs, error) {
+	// Hidden users shouldn't be distinguishable from users that haven't logged anything
+	user, err := i.userRepository.FindByID(ctx, userID)
+	if err == domain.ErrNotFound || user.IsHiddenFrom(viewerID) {
+		return nil, ErrNoContestLogsFound
+	}
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	logs, err := i.contestLogRepository.FindAll(ctx, contestID, userID)
 	if err != nil {
 		return logs, domain.WrapError(err)
 	}
@@ -337,5 +542,12 @@ func (i *rankingInteractor) ContestLogs(contestID uint64, userID uint64) (domain
 		return logs, ErrNoContestLogsFound
 	}
 
+	// Dates are shown in the timezone of whoever logged them
+	location := user.Preferences.Location()
+	for n := range logs {
+		logs[n].CreatedAt = logs[n].CreatedAt.In(location)
+		logs[n].UpdatedAt = logs[n].UpdatedAt.In(location)
+	}
+
 	return logs, nil
 }
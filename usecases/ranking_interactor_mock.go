@@ -5,6 +5,7 @@
 package usecases
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/tadoku/api/domain"
 	reflect "reflect"
@@ -34,131 +35,146 @@ func (m *MockRankingInteractor) EXPECT() *MockRankingInteractorMockRecorder {
 }
 
 // CreateRanking mocks base method
-func (m *MockRankingInteractor) CreateRanking(contestID, userID uint64, languages domain.LanguageCodes) error {
+func (m *MockRankingInteractor) CreateRanking(ctx context.Context, contestID, userID uint64, languages domain.LanguageCodes) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRanking", contestID, userID, languages)
+	ret := m.ctrl.Call(m, "CreateRanking", ctx, contestID, userID, languages)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateRanking indicates an expected call of CreateRanking
-func (mr *MockRankingInteractorMockRecorder) CreateRanking(contestID, userID, languages interface{}) *gomock.Call {
+func (mr *MockRankingInteractorMockRecorder) CreateRanking(ctx, contestID, userID, languages interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRanking", reflect.TypeOf((*MockRankingInteractor)(nil).CreateRanking), contestID, userID, languages)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRanking", reflect.TypeOf((*MockRankingInteractor)(nil).CreateRanking), ctx, contestID, userID, languages)
 }
 
 // CreateLog mocks base method
-func (m *MockRankingInteractor) CreateLog(log domain.ContestLog) error {
+func (m *MockRankingInteractor) CreateLog(ctx context.Context, log domain.ContestLog) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateLog", log)
+	ret := m.ctrl.Call(m, "CreateLog", ctx, log)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateLog indicates an expected call of CreateLog
-func (mr *MockRankingInteractorMockRecorder) CreateLog(log interface{}) *gomock.Call {
+func (mr *MockRankingInteractorMockRecorder) CreateLog(ctx, log interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLog", reflect.TypeOf((*MockRankingInteractor)(nil).CreateLog), log)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLog", reflect.TypeOf((*MockRankingInteractor)(nil).CreateLog), ctx, log)
 }
 
 // UpdateLog mocks base method
-func (m *MockRankingInteractor) UpdateLog(log domain.ContestLog) error {
+func (m *MockRankingInteractor) UpdateLog(ctx context.Context, log domain.ContestLog) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateLog", log)
+	ret := m.ctrl.Call(m, "UpdateLog", ctx, log)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateLog indicates an expected call of UpdateLog
-func (mr *MockRankingInteractorMockRecorder) UpdateLog(log interface{}) *gomock.Call {
+func (mr *MockRankingInteractorMockRecorder) UpdateLog(ctx, log interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLog", reflect.TypeOf((*MockRankingInteractor)(nil).UpdateLog), log)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLog", reflect.TypeOf((*MockRankingInteractor)(nil).UpdateLog), ctx, log)
 }
 
 // DeleteLog mocks base method
-func (m *MockRankingInteractor) DeleteLog(logID, userID uint64) error {
+func (m *MockRankingInteractor) DeleteLog(ctx context.Context, logID, userID uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteLog", logID, userID)
+	ret := m.ctrl.Call(m, "DeleteLog", ctx, logID, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteLog indicates an expected call of DeleteLog
-func (mr *MockRankingInteractorMockRecorder) DeleteLog(logID, userID interface{}) *gomock.Call {
+func (mr *MockRankingInteractorMockRecorder) DeleteLog(ctx, logID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLog", reflect.TypeOf((*MockRankingInteractor)(nil).DeleteLog), logID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLog", reflect.TypeOf((*MockRankingInteractor)(nil).DeleteLog), ctx, logID, userID)
 }
 
 // UpdateRanking mocks base method
-func (m *MockRankingInteractor) UpdateRanking(contestID, userID uint64) error {
+func (m *MockRankingInteractor) UpdateRanking(ctx context.Context, contestID, userID uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateRanking", contestID, userID)
+	ret := m.ctrl.Call(m, "UpdateRanking", ctx, contestID, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateRanking indicates an expected call of UpdateRanking
-func (mr *MockRankingInteractorMockRecorder) UpdateRanking(contestID, userID interface{}) *gomock.Call {
+func (mr *MockRankingInteractorMockRecorder) UpdateRanking(ctx, contestID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRanking", reflect.TypeOf((*MockRankingInteractor)(nil).UpdateRanking), contestID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRanking", reflect.TypeOf((*MockRankingInteractor)(nil).UpdateRanking), ctx, contestID, userID)
+}
+
+// ReconcileRankings mocks base method
+func (m *MockRankingInteractor) ReconcileRankings(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileRankings", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileRankings indicates an expected call of ReconcileRankings
+func (mr *MockRankingInteractorMockRecorder) ReconcileRankings(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileRankings", reflect.TypeOf((*MockRankingInteractor)(nil).ReconcileRankings), ctx)
 }
 
 // RankingsForRegistration mocks base method
-func (m *MockRankingInteractor) RankingsForRegistration(contestID, userID uint64) (domain.Rankings, error) {
+func (m *MockRankingInteractor) RankingsForRegistration(ctx context.Context, contestID, userID, viewerID uint64) (domain.Rankings, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RankingsForRegistration", contestID, userID)
+	ret := m.ctrl.Call(m, "RankingsForRegistration", ctx, contestID, userID, viewerID)
 	ret0, _ := ret[0].(domain.Rankings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RankingsForRegistration indicates an expected call of RankingsForRegistration
-func (mr *MockRankingInteractorMockRecorder) RankingsForRegistration(contestID, userID interface{}) *gomock.Call {
+func (mr *MockRankingInteractorMockRecorder) RankingsForRegistration(ctx, contestID, userID, viewerID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RankingsForRegistration", reflect.TypeOf((*MockRankingInteractor)(nil).RankingsForRegistration), contestID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RankingsForRegistration", reflect.TypeOf((*MockRankingInteractor)(nil).RankingsForRegistration), ctx, contestID, userID, viewerID)
 }
 
 // RankingsForContest mocks base method
-func (m *MockRankingInteractor) RankingsForContest(contestID uint64, languageCode domain.LanguageCode) (domain.Rankings, error) {
+func (m *MockRankingInteractor) RankingsForContest(ctx context.Context, contestID uint64, languageCode domain.LanguageCode) (domain.Rankings, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RankingsForContest", contestID, languageCode)
+	ret := m.ctrl.Call(m, "RankingsForContest", ctx, contestID, languageCode)
 	ret0, _ := ret[0].(domain.Rankings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RankingsForContest indicates an expected call of RankingsForContest
-func (mr *MockRankingInteractorMockRecorder) RankingsForContest(contestID, languageCode interface{}) *gomock.Call {
+func (mr *MockRankingInteractorMockRecorder) RankingsForContest(ctx, contestID, languageCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RankingsForContest", reflect.TypeOf((*MockRankingInteractor)(nil).RankingsForContest), contestID, languageCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RankingsForContest", reflect.TypeOf((*MockRankingInteractor)(nil).RankingsForContest), ctx, contestID, languageCode)
 }
 
 // CurrentRegistration mocks base method
-func (m *MockRankingInteractor) CurrentRegistration(userID uint64) (domain.RankingRegistration, error) {
+func (m *MockRankingInteractor) CurrentRegistration(ctx context.Context, userID uint64) (domain.RankingRegistration, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CurrentRegistration", userID)
+	ret := m.ctrl.Call(m, "CurrentRegistration", ctx, userID)
 	ret0, _ := ret[0].(domain.RankingRegistration)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CurrentRegistration indicates an expected call of CurrentRegistration
-func (mr *MockRankingInteractorMockRecorder) CurrentRegistration(userID interface{}) *gomock.Call {
+func (mr *MockRankingInteractorMockRecorder) CurrentRegistration(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentRegistration", reflect.TypeOf((*MockRankingInteractor)(nil).CurrentRegistration), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentRegistration", reflect.TypeOf((*MockRankingInteractor)(nil).CurrentRegistration), ctx, userID)
 }
 
 // ContestLogs mocks base method
-func (m *MockRankingInteractor) ContestLogs(contestID, userID uint64) (domain.ContestLogs, error) {
+func (m *MockRankingInteractor) ContestLogs(ctx context.Context, contestID, userID, viewerID uint64) (domain.ContestLogs, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ContestLogs", contestID, userID)
+	ret := m.ctrl.Call(m, "ContestLogs", ctx, contestID, userID, viewerID)
 	ret0, _ := ret[0].(domain.ContestLogs)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ContestLogs indicates an expected call of ContestLogs
-func (mr *MockRankingInteractorMockRecorder) ContestLogs(contestID, userID interface{}) *gomock.Call {
+func (mr *MockRankingInteractorMockRecorder) ContestLogs(ctx, contestID, userID, viewerID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContestLogs", reflect.TypeOf((*MockRankingInteractor)(nil).ContestLogs), contestID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContestLogs", reflect.TypeOf((*MockRankingInteractor)(nil).ContestLogs), ctx, contestID, userID, viewerID)
 }
@@ -1,6 +1,8 @@
 package usecases_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -11,6 +13,15 @@ import (
 	gomock "github.com/golang/mock/gomock"
 )
 
+// newTransactionManager runs the unit of work right away, committing and rolling back is up to the repositories
+func newTransactionManager(ctrl *gomock.Controller) *usecases.MockTransactionManager {
+	transactions := usecases.NewMockTransactionManager(ctrl)
+	transactions.EXPECT().Run(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+	return transactions
+}
+
 func setupRankingTest(t *testing.T) (
 	*gomock.Controller,
 	*usecases.MockRankingRepository,
@@ -18,6 +29,7 @@ func setupRankingTest(t *testing.T) (
 	*usecases.MockContestLogRepository,
 	*usecases.MockUserRepository,
 	*usecases.MockValidator,
+	*usecases.MockMetricsRecorder,
 	usecases.RankingInteractor,
 ) {
 	ctrl := gomock.NewController(t)
@@ -27,13 +39,14 @@ func setupRankingTest(t *testing.T) (
 	contestLogRepo := usecases.NewMockContestLogRepository(ctrl)
 	userRepo := usecases.NewMockUserRepository(ctrl)
 	validator := usecases.NewMockValidator(ctrl)
-	interactor := usecases.NewRankingInteractor(rankingRepo, contestRepo, contestLogRepo, userRepo, validator)
+	metrics := usecases.NewMockMetricsRecorder(ctrl)
+	interactor := usecases.NewRankingInteractor(rankingRepo, contestRepo, contestLogRepo, userRepo, newTransactionManager(ctrl), metrics, validator)
 
-	return ctrl, rankingRepo, contestRepo, contestLogRepo, userRepo, validator, interactor
+	return ctrl, rankingRepo, contestRepo, contestLogRepo, userRepo, validator, metrics, interactor
 }
 
 func TestRankingInteractor_CreateRanking(t *testing.T) {
-	ctrl, rankingRepo, contestRepo, _, userRepo, _, interactor := setupRankingTest(t)
+	ctrl, rankingRepo, contestRepo, _, userRepo, _, _, interactor := setupRankingTest(t)
 	defer ctrl.Finish()
 
 	contestID := uint64(1)
@@ -42,14 +55,14 @@ func TestRankingInteractor_CreateRanking(t *testing.T) {
 	{
 		languages := domain.LanguageCodes{domain.Japanese, domain.English}
 
-		contestRepo.EXPECT().GetOpenContests().Return([]uint64{contestID}, nil)
-		userRepo.EXPECT().FindByID(userID).Return(domain.User{ID: userID}, nil)
-		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(contestID, userID).Return(nil, nil)
-		rankingRepo.EXPECT().Store(domain.Ranking{ContestID: contestID, UserID: userID, Language: languages[0], Amount: 0}).Return(nil)
-		rankingRepo.EXPECT().Store(domain.Ranking{ContestID: contestID, UserID: userID, Language: languages[1], Amount: 0}).Return(nil)
-		rankingRepo.EXPECT().Store(domain.Ranking{ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 0}).Return(nil)
+		contestRepo.EXPECT().GetOpenContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(nil, nil)
+		rankingRepo.EXPECT().Store(gomock.Any(), domain.Ranking{ContestID: contestID, UserID: userID, Language: languages[0], Amount: 0}).Return(nil)
+		rankingRepo.EXPECT().Store(gomock.Any(), domain.Ranking{ContestID: contestID, UserID: userID, Language: languages[1], Amount: 0}).Return(nil)
+		rankingRepo.EXPECT().Store(gomock.Any(), domain.Ranking{ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 0}).Return(nil)
 
-		err := interactor.CreateRanking(userID, contestID, languages)
+		err := interactor.CreateRanking(context.Background(), userID, contestID, languages)
 
 		assert.NoError(t, err)
 	}
@@ -57,12 +70,12 @@ func TestRankingInteractor_CreateRanking(t *testing.T) {
 	{
 		languages := domain.LanguageCodes{domain.Chinese}
 
-		contestRepo.EXPECT().GetOpenContests().Return([]uint64{contestID}, nil)
-		userRepo.EXPECT().FindByID(userID).Return(domain.User{ID: userID}, nil)
-		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(contestID, userID).Return(domain.LanguageCodes{domain.English}, nil)
-		rankingRepo.EXPECT().Store(domain.Ranking{ContestID: contestID, UserID: userID, Language: languages[0], Amount: 0}).Return(nil)
+		contestRepo.EXPECT().GetOpenContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(domain.LanguageCodes{domain.English}, nil)
+		rankingRepo.EXPECT().Store(gomock.Any(), domain.Ranking{ContestID: contestID, UserID: userID, Language: languages[0], Amount: 0}).Return(nil)
 
-		err := interactor.CreateRanking(userID, contestID, languages)
+		err := interactor.CreateRanking(context.Background(), userID, contestID, languages)
 
 		assert.NoError(t, err)
 	}
@@ -70,11 +83,11 @@ func TestRankingInteractor_CreateRanking(t *testing.T) {
 	{
 		languages := domain.LanguageCodes{domain.English}
 
-		contestRepo.EXPECT().GetOpenContests().Return([]uint64{contestID}, nil)
-		userRepo.EXPECT().FindByID(userID).Return(domain.User{ID: userID}, nil)
-		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(contestID, userID).Return(domain.LanguageCodes{domain.English}, nil)
+		contestRepo.EXPECT().GetOpenContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(domain.LanguageCodes{domain.English}, nil)
 
-		err := interactor.CreateRanking(userID, contestID, languages)
+		err := interactor.CreateRanking(context.Background(), userID, contestID, languages)
 
 		assert.EqualError(t, err, usecases.ErrNoRankingToCreate.Error())
 	}
@@ -82,11 +95,11 @@ func TestRankingInteractor_CreateRanking(t *testing.T) {
 	{
 		languages := domain.LanguageCodes{domain.Global}
 
-		contestRepo.EXPECT().GetOpenContests().Return([]uint64{contestID}, nil)
-		userRepo.EXPECT().FindByID(userID).Return(domain.User{ID: userID}, nil)
-		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(contestID, userID).Return(nil, nil)
+		contestRepo.EXPECT().GetOpenContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(nil, nil)
 
-		err := interactor.CreateRanking(userID, contestID, languages)
+		err := interactor.CreateRanking(context.Background(), userID, contestID, languages)
 
 		assert.EqualError(t, err, usecases.ErrGlobalIsASystemLanguage.Error())
 	}
@@ -94,18 +107,18 @@ func TestRankingInteractor_CreateRanking(t *testing.T) {
 	{
 		languages := domain.LanguageCodes{"xxx"}
 
-		contestRepo.EXPECT().GetOpenContests().Return([]uint64{contestID}, nil)
-		userRepo.EXPECT().FindByID(userID).Return(domain.User{ID: userID}, nil)
-		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(contestID, userID).Return(nil, nil)
+		contestRepo.EXPECT().GetOpenContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(nil, nil)
 
-		err := interactor.CreateRanking(userID, contestID, languages)
+		err := interactor.CreateRanking(context.Background(), userID, contestID, languages)
 
 		assert.EqualError(t, err, domain.ErrInvalidLanguage.Error())
 	}
 }
 
 func TestRankingInteractor_CreateLog(t *testing.T) {
-	ctrl, rankingRepo, contestRepo, contestLogRepo, _, validator, interactor := setupRankingTest(t)
+	ctrl, rankingRepo, contestRepo, contestLogRepo, userRepo, validator, metrics, interactor := setupRankingTest(t)
 	defer ctrl.Finish()
 
 	contestID := uint64(1)
@@ -121,29 +134,60 @@ func TestRankingInteractor_CreateLog(t *testing.T) {
 			MediumID:  domain.MediumComic,
 		}
 
-		rankings := domain.Rankings{
-			{ID: 1, ContestID: contestID, UserID: userID, Language: domain.Japanese, Amount: 0},
-			{ID: 2, ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 0},
+		// Only the rankings of the logged language and global change, by the adjusted amount
+		amounts := map[domain.LanguageCode]float32{domain.Japanese: 2, domain.Global: 2}
+
+		contestLogRepo.EXPECT().Store(gomock.Any(), &log)
+		validator.EXPECT().Validate(log).Return(true, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(domain.LanguageCodes{domain.Japanese}, nil)
+		rankingRepo.EXPECT().AddAmounts(gomock.Any(), contestID, userID, amounts).Return(nil)
+		metrics.EXPECT().ContestLogCreated()
+
+		err := interactor.CreateLog(context.Background(), log)
+		assert.NoError(t, err)
+	}
+
+	// Test failing ranking update
+	{
+		log := domain.ContestLog{
+			ContestID: contestID,
+			UserID:    userID,
+			Language:  domain.Japanese,
+			Amount:    10,
+			MediumID:  domain.MediumComic,
 		}
 
-		expectedRankings := domain.Rankings{
-			{ID: 1, ContestID: contestID, UserID: userID, Language: domain.Japanese, Amount: 2},
-			{ID: 2, ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 2},
+		contestLogRepo.EXPECT().Store(gomock.Any(), &log)
+		validator.EXPECT().Validate(log).Return(true, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(domain.LanguageCodes{domain.Japanese}, nil)
+		rankingRepo.EXPECT().AddAmounts(gomock.Any(), contestID, userID, gomock.Any()).Return(errors.New("connection reset"))
+
+		err := interactor.CreateLog(context.Background(), log)
+		assert.Error(t, err, "the log is rolled back together with the rankings, so it isn't counted either")
+	}
+
+	// Test missing ranking
+	{
+		log := domain.ContestLog{
+			ContestID: contestID,
+			UserID:    userID,
+			Language:  domain.Japanese,
+			Amount:    10,
+			MediumID:  domain.MediumComic,
 		}
 
-		contestLogRepo.EXPECT().Store(&log)
+		contestLogRepo.EXPECT().Store(gomock.Any(), &log)
 		validator.EXPECT().Validate(log).Return(true, nil)
-		contestRepo.EXPECT().GetRunningContests().Return([]uint64{contestID}, nil)
-		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(contestID, userID).Return(domain.LanguageCodes{domain.Japanese}, nil)
-		rankingRepo.EXPECT().FindAll(contestID, userID).Return(rankings, nil)
-		contestLogRepo.EXPECT().FindAll(contestID, userID).Return(domain.ContestLogs{log}, nil)
-		rankingRepo.EXPECT().UpdateAmounts(expectedRankings).Return(nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(domain.LanguageCodes{domain.Japanese}, nil)
+		rankingRepo.EXPECT().AddAmounts(gomock.Any(), contestID, userID, gomock.Any()).Return(domain.ErrNotFound)
 
-		err := interactor.CreateLog(log)
-		assert.NoError(t, err)
+		err := interactor.CreateLog(context.Background(), log)
+		assert.EqualError(t, err, usecases.ErrNoRankingsFound.Error())
 	}
 
-	// Test creation with id
 	{
 		log := domain.ContestLog{
 			ID:        1,
@@ -154,7 +198,7 @@ func TestRankingInteractor_CreateLog(t *testing.T) {
 			MediumID:  20,
 		}
 
-		err := interactor.CreateLog(log)
+		err := interactor.CreateLog(context.Background(), log)
 		assert.EqualError(t, err, usecases.ErrCreateContestLogHasID.Error())
 	}
 
@@ -168,9 +212,9 @@ func TestRankingInteractor_CreateLog(t *testing.T) {
 			MediumID:  20,
 		}
 
-		validator.EXPECT().Validate(log).Return(false, domain.ErrMediumNotFound)
+		validator.EXPECT().Validate(log).Return(false, domain.FieldErrors{domain.NewFieldError("medium_id", "medium", domain.ErrMediumNotFound)})
 
-		err := interactor.CreateLog(log)
+		err := interactor.CreateLog(context.Background(), log)
 		assert.EqualError(t, err, usecases.ErrInvalidContestLog.Error())
 	}
 
@@ -185,9 +229,9 @@ func TestRankingInteractor_CreateLog(t *testing.T) {
 		}
 
 		validator.EXPECT().Validate(log).Return(true, nil)
-		contestRepo.EXPECT().GetRunningContests().Return([]uint64{contestID}, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID}, nil)
 
-		err := interactor.CreateLog(log)
+		err := interactor.CreateLog(context.Background(), log)
 		assert.EqualError(t, err, usecases.ErrContestIsClosed.Error())
 	}
 
@@ -202,10 +246,10 @@ func TestRankingInteractor_CreateLog(t *testing.T) {
 		}
 
 		validator.EXPECT().Validate(log).Return(true, nil)
-		contestRepo.EXPECT().GetRunningContests().Return([]uint64{contestID}, nil)
-		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(contestID, userID).Return(domain.LanguageCodes{domain.Korean}, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(domain.LanguageCodes{domain.Korean}, nil)
 
-		err := interactor.CreateLog(log)
+		err := interactor.CreateLog(context.Background(), log)
 		assert.EqualError(t, err, usecases.ErrContestLanguageNotSignedUp.Error())
 	}
 
@@ -220,16 +264,38 @@ func TestRankingInteractor_CreateLog(t *testing.T) {
 		}
 
 		validator.EXPECT().Validate(log).Return(true, nil)
-		contestRepo.EXPECT().GetRunningContests().Return([]uint64{contestID}, nil)
-		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(contestID, userID).Return(domain.LanguageCodes{domain.Japanese}, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(domain.LanguageCodes{domain.Japanese}, nil)
+
+		err := interactor.CreateLog(context.Background(), log)
+		assert.EqualError(t, err, usecases.ErrContestLanguageNotSignedUp.Error())
+	}
+
+	// Test defaults from the preferences of the user
+	{
+		log := domain.ContestLog{
+			ContestID: contestID,
+			UserID:    userID,
+			Amount:    10,
+		}
+		preferences := &domain.Preferences{DefaultLanguage: domain.Korean, DefaultMedium: domain.MediumNet}
+
+		expectedLog := log
+		expectedLog.Language = domain.Korean
+		expectedLog.MediumID = domain.MediumNet
+
+		userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID, Preferences: preferences}, nil)
+		validator.EXPECT().Validate(expectedLog).Return(true, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(domain.LanguageCodes{domain.Japanese}, nil)
 
-		err := interactor.CreateLog(log)
+		err := interactor.CreateLog(context.Background(), log)
 		assert.EqualError(t, err, usecases.ErrContestLanguageNotSignedUp.Error())
 	}
 }
 
 func TestRankingInteractor_UpdateLog(t *testing.T) {
-	ctrl, rankingRepo, contestRepo, contestLogRepo, _, validator, interactor := setupRankingTest(t)
+	ctrl, rankingRepo, contestRepo, contestLogRepo, _, validator, _, interactor := setupRankingTest(t)
 	defer ctrl.Finish()
 
 	contestID := uint64(1)
@@ -238,33 +304,36 @@ func TestRankingInteractor_UpdateLog(t *testing.T) {
 	{
 		log := domain.ContestLog{
 			ID:        1,
-			ContestID: contestID,
+			ContestID: contestID + 1,
 			UserID:    userID,
 			Language:  domain.Japanese,
 			Amount:    10,
 			MediumID:  domain.MediumComic,
 		}
-
-		rankings := domain.Rankings{
-			{ID: 1, ContestID: contestID, UserID: userID, Language: domain.Japanese, Amount: 0},
-			{ID: 2, ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 0},
+		existingLog := domain.ContestLog{
+			ID:        1,
+			ContestID: contestID,
+			UserID:    userID,
+			Language:  domain.Korean,
+			Amount:    20,
+			MediumID:  domain.MediumBook,
 		}
 
-		expectedRankings := domain.Rankings{
-			{ID: 1, ContestID: contestID, UserID: userID, Language: domain.Japanese, Amount: 2},
-			{ID: 2, ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 2},
-		}
+		// The log stays in its contest, even when another one is given
+		storedLog := log
+		storedLog.ContestID = contestID
+
+		// The old amount moves from korean to japanese, global only changes by the difference
+		amounts := map[domain.LanguageCode]float32{domain.Korean: -20, domain.Japanese: 2, domain.Global: -18}
 
-		contestLogRepo.EXPECT().Store(&log)
+		contestLogRepo.EXPECT().Store(gomock.Any(), &storedLog)
 		validator.EXPECT().Validate(log).Return(true, nil)
-		contestLogRepo.EXPECT().FindByID(log.ID).Return(log, nil)
-		contestRepo.EXPECT().GetRunningContests().Return([]uint64{contestID}, nil)
-		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(contestID, userID).Return(domain.LanguageCodes{domain.Japanese}, nil)
-		rankingRepo.EXPECT().FindAll(contestID, userID).Return(rankings, nil)
-		contestLogRepo.EXPECT().FindAll(contestID, userID).Return(domain.ContestLogs{log}, nil)
-		rankingRepo.EXPECT().UpdateAmounts(expectedRankings).Return(nil)
+		contestLogRepo.EXPECT().FindByIDForUpdate(gomock.Any(), log.ID).Return(existingLog, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		rankingRepo.EXPECT().GetAllLanguagesForContestAndUser(gomock.Any(), contestID, userID).Return(domain.LanguageCodes{domain.Japanese, domain.Korean}, nil)
+		rankingRepo.EXPECT().AddAmounts(gomock.Any(), contestID, userID, amounts).Return(nil)
 
-		err := interactor.UpdateLog(log)
+		err := interactor.UpdateLog(context.Background(), log)
 
 		assert.NoError(t, err)
 	}
@@ -280,9 +349,9 @@ func TestRankingInteractor_UpdateLog(t *testing.T) {
 		}
 
 		validator.EXPECT().Validate(log).Return(true, nil)
-		contestLogRepo.EXPECT().FindByID(log.ID).Return(domain.ContestLog{UserID: userID + 1}, nil)
+		contestLogRepo.EXPECT().FindByIDForUpdate(gomock.Any(), log.ID).Return(domain.ContestLog{UserID: userID + 1}, nil)
 
-		err := interactor.UpdateLog(log)
+		err := interactor.UpdateLog(context.Background(), log)
 
 		assert.EqualError(t, err, domain.ErrInsufficientPermissions.Error())
 	}
@@ -296,14 +365,14 @@ func TestRankingInteractor_UpdateLog(t *testing.T) {
 			MediumID:  domain.MediumComic,
 		}
 
-		err := interactor.UpdateLog(log)
+		err := interactor.UpdateLog(context.Background(), log)
 
 		assert.EqualError(t, err, usecases.ErrContestLogIDMissing.Error())
 	}
 }
 
 func TestRankingInteractor_DeleteLog(t *testing.T) {
-	ctrl, rankingRepo, contestRepo, contestLogRepo, _, _, interactor := setupRankingTest(t)
+	ctrl, rankingRepo, contestRepo, contestLogRepo, _, _, metrics, interactor := setupRankingTest(t)
 	defer ctrl.Finish()
 
 	contestID := uint64(1)
@@ -320,55 +389,55 @@ func TestRankingInteractor_DeleteLog(t *testing.T) {
 
 	// Happy path
 	{
-		rankings := domain.Rankings{
-			{ID: 1, ContestID: contestID, UserID: userID, Language: domain.Japanese, Amount: 10},
-			{ID: 2, ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 10},
-		}
+		amounts := map[domain.LanguageCode]float32{domain.Japanese: -10, domain.Global: -10}
 
-		expectedRankings := domain.Rankings{
-			{ID: 1, ContestID: contestID, UserID: userID, Language: domain.Japanese, Amount: 0},
-			{ID: 2, ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 0},
-		}
-
-		contestLogRepo.EXPECT().Delete(log.ID)
-		contestLogRepo.EXPECT().FindByID(log.ID).Return(log, nil)
-		contestRepo.EXPECT().GetRunningContests().Return([]uint64{contestID}, nil)
-		rankingRepo.EXPECT().FindAll(contestID, userID).Return(rankings, nil)
-		contestLogRepo.EXPECT().FindAll(contestID, userID).Return(domain.ContestLogs{}, nil)
-		rankingRepo.EXPECT().UpdateAmounts(expectedRankings).Return(nil)
+		contestLogRepo.EXPECT().Delete(gomock.Any(), log.ID)
+		contestLogRepo.EXPECT().FindByIDForUpdate(gomock.Any(), log.ID).Return(log, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		rankingRepo.EXPECT().AddAmounts(gomock.Any(), contestID, userID, amounts).Return(nil)
+		metrics.EXPECT().ContestLogDeleted()
 
-		err := interactor.DeleteLog(log.ID, log.UserID)
+		err := interactor.DeleteLog(context.Background(), log.ID, log.UserID)
 		assert.NoError(t, err)
 	}
 
 	// Sad path: different user trying to delete a log
 	{
-		contestLogRepo.EXPECT().FindByID(log.ID).Return(log, nil)
+		contestLogRepo.EXPECT().FindByIDForUpdate(gomock.Any(), log.ID).Return(log, nil)
 
-		err := interactor.DeleteLog(log.ID, log.UserID+1)
+		err := interactor.DeleteLog(context.Background(), log.ID, log.UserID+1)
 		assert.EqualError(t, err, domain.ErrInsufficientPermissions.Error())
 	}
 
 	// Sad path: contest is cloaed
 	{
-		contestLogRepo.EXPECT().FindByID(log.ID).Return(log, nil)
-		contestRepo.EXPECT().GetRunningContests().Return([]uint64{contestID + 1}, nil)
+		contestLogRepo.EXPECT().FindByIDForUpdate(gomock.Any(), log.ID).Return(log, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return([]uint64{contestID + 1}, nil)
 
-		err := interactor.DeleteLog(log.ID, log.UserID)
+		err := interactor.DeleteLog(context.Background(), log.ID, log.UserID)
 		assert.EqualError(t, err, usecases.ErrContestIsClosed.Error())
 	}
 
+	// Sad path: running contests can't be loaded
+	{
+		contestLogRepo.EXPECT().FindByIDForUpdate(gomock.Any(), log.ID).Return(log, nil)
+		contestRepo.EXPECT().GetRunningContests(gomock.Any()).Return(nil, errors.New("connection reset"))
+
+		err := interactor.DeleteLog(context.Background(), log.ID, log.UserID)
+		assert.EqualError(t, err, "connection reset")
+	}
+
 	// Sad path: log does not exist
 	{
-		contestLogRepo.EXPECT().FindByID(log.ID).Return(domain.ContestLog{}, domain.ErrNotFound)
+		contestLogRepo.EXPECT().FindByIDForUpdate(gomock.Any(), log.ID).Return(domain.ContestLog{}, domain.ErrNotFound)
 
-		err := interactor.DeleteLog(log.ID, log.UserID)
+		err := interactor.DeleteLog(context.Background(), log.ID, log.UserID)
 		assert.EqualError(t, err, domain.ErrNotFound.Error())
 	}
 }
 
 func TestRankingInteractor_UpdateRankings(t *testing.T) {
-	ctrl, rankingRepo, _, contestLogRepo, _, _, interactor := setupRankingTest(t)
+	ctrl, rankingRepo, _, contestLogRepo, _, _, metrics, interactor := setupRankingTest(t)
 	defer ctrl.Finish()
 
 	contestID := uint64(1)
@@ -401,28 +470,79 @@ func TestRankingInteractor_UpdateRankings(t *testing.T) {
 			{ID: 3, ContestID: contestID, UserID: userID, Language: domain.German, Amount: 0},
 			{ID: 4, ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 12},
 		}
-		rankingRepo.EXPECT().FindAll(contestID, userID).Return(rankings, nil)
-		contestLogRepo.EXPECT().FindAll(contestID, userID).Return(domain.ContestLogs{logJapaneseBook, logKoreanComic}, nil)
-		rankingRepo.EXPECT().UpdateAmounts(expectedRankings).Return(nil)
+		rankingRepo.EXPECT().FindAllForUpdate(gomock.Any(), contestID, userID).Return(rankings, nil)
+		contestLogRepo.EXPECT().FindAll(gomock.Any(), contestID, userID).Return(domain.ContestLogs{logJapaneseBook, logKoreanComic}, nil)
+		rankingRepo.EXPECT().UpdateAmounts(gomock.Any(), expectedRankings).Return(nil)
+		metrics.EXPECT().RankingsRecomputed()
 
-		err := interactor.UpdateRanking(contestID, userID)
+		err := interactor.UpdateRanking(context.Background(), contestID, userID)
 		assert.NoError(t, err)
 	}
 
 	{
-		rankingRepo.EXPECT().FindAll(contestID, userID).Return(nil, nil)
+		rankingRepo.EXPECT().FindAllForUpdate(gomock.Any(), contestID, userID).Return(nil, nil)
 
-		err := interactor.UpdateRanking(contestID, userID)
+		err := interactor.UpdateRanking(context.Background(), contestID, userID)
 		assert.EqualError(t, err, usecases.ErrNoRankingsFound.Error())
 	}
 }
 
+func TestRankingInteractor_ReconcileRankings(t *testing.T) {
+	ctrl, rankingRepo, contestRepo, contestLogRepo, _, _, metrics, interactor := setupRankingTest(t)
+	defer ctrl.Finish()
+
+	contestID := uint64(1)
+
+	// Happy path: only the user whose rankings drifted from their logs gets repaired
+	{
+		logs := domain.ContestLogs{
+			{ContestID: contestID, UserID: 1, Language: domain.Japanese, Amount: 10, MediumID: domain.MediumBook},
+			{ContestID: contestID, UserID: 2, Language: domain.Japanese, Amount: 10, MediumID: domain.MediumBook},
+		}
+		rankings := domain.Rankings{
+			{ID: 1, ContestID: contestID, UserID: 1, Language: domain.Japanese, Amount: 10.001},
+			{ID: 2, ContestID: contestID, UserID: 1, Language: domain.Global, Amount: 10},
+			{ID: 3, ContestID: contestID, UserID: 2, Language: domain.Japanese, Amount: 12},
+			{ID: 4, ContestID: contestID, UserID: 2, Language: domain.Global, Amount: 12},
+			{ID: 5, ContestID: contestID, UserID: 3, Language: domain.Korean, Amount: 0},
+			{ID: 6, ContestID: contestID, UserID: 3, Language: domain.Global, Amount: 0},
+		}
+		expectedRankings := domain.Rankings{
+			{ID: 3, ContestID: contestID, UserID: 2, Language: domain.Japanese, Amount: 10},
+			{ID: 4, ContestID: contestID, UserID: 2, Language: domain.Global, Amount: 10},
+		}
+
+		contestRepo.EXPECT().GetOpenContests(gomock.Any()).Return([]uint64{contestID}, nil)
+		rankingRepo.EXPECT().FindAllForContest(gomock.Any(), contestID).Return(rankings, nil)
+		contestLogRepo.EXPECT().FindAllForContest(gomock.Any(), contestID).Return(logs, nil)
+		rankingRepo.EXPECT().FindAllForUpdate(gomock.Any(), contestID, uint64(2)).Return(rankings[2:4], nil)
+		contestLogRepo.EXPECT().FindAll(gomock.Any(), contestID, uint64(2)).Return(logs[1:], nil)
+		rankingRepo.EXPECT().UpdateAmounts(gomock.Any(), expectedRankings).Return(nil)
+		metrics.EXPECT().RankingsRecomputed()
+		metrics.EXPECT().RankingsDriftRepaired()
+
+		repaired, err := interactor.ReconcileRankings(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, repaired)
+	}
+
+	// Sad path: contests can't be loaded
+	{
+		contestRepo.EXPECT().GetOpenContests(gomock.Any()).Return(nil, errors.New("connection reset"))
+
+		repaired, err := interactor.ReconcileRankings(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 0, repaired)
+	}
+}
+
 func TestRankingInteractor_RankingsForRegistration(t *testing.T) {
-	ctrl, rankingRepo, _, _, _, _, interactor := setupRankingTest(t)
+	ctrl, rankingRepo, _, _, userRepo, _, _, interactor := setupRankingTest(t)
 	defer ctrl.Finish()
 
 	contestID := uint64(1)
 	userID := uint64(1)
+	userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID}, nil).Times(2)
 
 	{
 		expected := domain.Rankings{
@@ -431,24 +551,44 @@ func TestRankingInteractor_RankingsForRegistration(t *testing.T) {
 			{ID: 3, ContestID: contestID, UserID: userID, Language: domain.German, Amount: 0},
 			{ID: 4, ContestID: contestID, UserID: userID, Language: domain.Global, Amount: 12},
 		}
-		rankingRepo.EXPECT().FindAll(contestID, userID).Return(expected, nil)
+		rankingRepo.EXPECT().FindAll(gomock.Any(), contestID, userID).Return(expected, nil)
 
-		rankings, err := interactor.RankingsForRegistration(contestID, userID)
+		rankings, err := interactor.RankingsForRegistration(context.Background(), contestID, userID, 0)
 		assert.NoError(t, err)
 		assert.Equal(t, expected, rankings)
 	}
 
 	{
-		rankingRepo.EXPECT().FindAll(contestID, userID).Return(nil, nil)
+		rankingRepo.EXPECT().FindAll(gomock.Any(), contestID, userID).Return(nil, nil)
 
-		rankings, err := interactor.RankingsForRegistration(contestID, userID)
+		rankings, err := interactor.RankingsForRegistration(context.Background(), contestID, userID, 0)
 		assert.EqualError(t, err, usecases.ErrNoRankingsFound.Error())
 		assert.Equal(t, 0, len(rankings))
 	}
+
+	{
+		// Sad path: user is hidden from rankings
+		userRepo.EXPECT().FindByID(gomock.Any(), uint64(2)).Return(domain.User{ID: 2, Preferences: &domain.Preferences{HideFromRankings: true}}, nil)
+
+		_, err := interactor.RankingsForRegistration(context.Background(), contestID, 2, userID)
+		assert.EqualError(t, err, usecases.ErrNoRankingsFound.Error())
+	}
+
+	{
+		// Happy path: hidden users can still see their own rankings
+		hidden := domain.User{ID: 2, Preferences: &domain.Preferences{HideFromRankings: true}}
+		expected := domain.Rankings{{ID: 5, ContestID: contestID, UserID: hidden.ID, Language: domain.Global, Amount: 3}}
+		userRepo.EXPECT().FindByID(gomock.Any(), hidden.ID).Return(hidden, nil)
+		rankingRepo.EXPECT().FindAll(gomock.Any(), contestID, hidden.ID).Return(expected, nil)
+
+		rankings, err := interactor.RankingsForRegistration(context.Background(), contestID, hidden.ID, hidden.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, rankings)
+	}
 }
 
 func TestRankingInteractor_RankingsForContest(t *testing.T) {
-	ctrl, rankingRepo, _, _, _, validator, interactor := setupRankingTest(t)
+	ctrl, rankingRepo, _, _, _, validator, _, interactor := setupRankingTest(t)
 	defer ctrl.Finish()
 
 	contestID := uint64(1)
@@ -463,10 +603,10 @@ func TestRankingInteractor_RankingsForContest(t *testing.T) {
 			{ID: 3, ContestID: contestID, UserID: userID + 2, Language: language, Amount: 11},
 			{ID: 4, ContestID: contestID, UserID: userID + 3, Language: language, Amount: 0},
 		}
-		rankingRepo.EXPECT().RankingsForContest(contestID, language).Return(expected, nil)
+		rankingRepo.EXPECT().RankingsForContest(gomock.Any(), contestID, language).Return(expected, nil)
 		validator.EXPECT().Validate(language).Return(true, nil)
 
-		rankings, err := interactor.RankingsForContest(contestID, language)
+		rankings, err := interactor.RankingsForContest(context.Background(), contestID, language)
 		assert.NoError(t, err)
 
 		for i, ranking := range rankings {
@@ -485,10 +625,10 @@ func TestRankingInteractor_RankingsForContest(t *testing.T) {
 			{UserID: userID + 2, Language: language, Amount: 11},
 			{UserID: userID + 3, Language: language, Amount: 0},
 		}
-		rankingRepo.EXPECT().GlobalRankings(language).Return(expected, nil)
+		rankingRepo.EXPECT().GlobalRankings(gomock.Any(), language).Return(expected, nil)
 		validator.EXPECT().Validate(language).Return(true, nil)
 
-		rankings, err := interactor.RankingsForContest(0, language)
+		rankings, err := interactor.RankingsForContest(context.Background(), 0, language)
 		assert.NoError(t, err)
 
 		for i, ranking := range rankings {
@@ -504,10 +644,10 @@ func TestRankingInteractor_RankingsForContest(t *testing.T) {
 		expected := domain.Rankings{
 			{ID: 1, ContestID: contestID, UserID: userID, Language: domain.Japanese, Amount: 15},
 		}
-		rankingRepo.EXPECT().RankingsForContest(contestID, domain.Japanese).Return(expected, nil)
+		rankingRepo.EXPECT().RankingsForContest(gomock.Any(), contestID, domain.Japanese).Return(expected, nil)
 		validator.EXPECT().Validate(domain.Japanese).Return(true, nil)
 
-		_, err := interactor.RankingsForContest(contestID, domain.Japanese)
+		_, err := interactor.RankingsForContest(context.Background(), contestID, domain.Japanese)
 		assert.NoError(t, err)
 	}
 
@@ -517,25 +657,25 @@ func TestRankingInteractor_RankingsForContest(t *testing.T) {
 		expected := domain.Rankings{
 			{ID: 1, ContestID: contestID, UserID: userID, Language: language, Amount: 15},
 		}
-		rankingRepo.EXPECT().RankingsForContest(contestID, language).Return(expected, nil)
-		validator.EXPECT().Validate(invalidLanguage).Return(false, domain.ErrInvalidLanguage)
+		rankingRepo.EXPECT().RankingsForContest(gomock.Any(), contestID, language).Return(expected, nil)
+		validator.EXPECT().Validate(invalidLanguage).Return(false, domain.FieldErrors{{Message: domain.ErrInvalidLanguage.Error()}})
 
-		_, err := interactor.RankingsForContest(contestID, invalidLanguage)
+		_, err := interactor.RankingsForContest(context.Background(), contestID, invalidLanguage)
 		assert.NoError(t, err)
 	}
 
 	// Sad path for no rankings found
 	{
-		rankingRepo.EXPECT().RankingsForContest(contestID, language).Return(nil, nil)
+		rankingRepo.EXPECT().RankingsForContest(gomock.Any(), contestID, language).Return(nil, nil)
 		validator.EXPECT().Validate(language).Return(true, nil)
 
-		_, err := interactor.RankingsForContest(contestID, language)
+		_, err := interactor.RankingsForContest(context.Background(), contestID, language)
 		assert.EqualError(t, err, usecases.ErrNoRankingsFound.Error())
 	}
 }
 
 func TestRankingInteractor_CurrentRegistration(t *testing.T) {
-	ctrl, rankingRepo, _, _, _, _, interactor := setupRankingTest(t)
+	ctrl, rankingRepo, _, _, _, _, _, interactor := setupRankingTest(t)
 	defer ctrl.Finish()
 
 	userID := uint64(1)
@@ -547,25 +687,25 @@ func TestRankingInteractor_CurrentRegistration(t *testing.T) {
 			End:       time.Now(),
 			Languages: domain.LanguageCodes{domain.Japanese, domain.Korean},
 		}
-		rankingRepo.EXPECT().CurrentRegistration(userID).Return(expected, nil)
+		rankingRepo.EXPECT().CurrentRegistration(gomock.Any(), userID).Return(expected, nil)
 
-		registration, err := interactor.CurrentRegistration(userID)
+		registration, err := interactor.CurrentRegistration(context.Background(), userID)
 		assert.NoError(t, err)
 		assert.Equal(t, expected, registration)
 	}
 
 	{
 		// Sad path no registration found
-		rankingRepo.EXPECT().CurrentRegistration(userID).Return(domain.RankingRegistration{}, nil)
+		rankingRepo.EXPECT().CurrentRegistration(gomock.Any(), userID).Return(domain.RankingRegistration{}, nil)
 
-		_, err := interactor.CurrentRegistration(userID)
+		_, err := interactor.CurrentRegistration(context.Background(), userID)
 		assert.EqualError(t, err, usecases.ErrNoRankingRegistrationFound.Error())
 	}
 
 }
 
 func TestRankingInteractor_ContestLogs(t *testing.T) {
-	ctrl, _, _, repo, _, _, interactor := setupRankingTest(t)
+	ctrl, _, _, repo, userRepo, _, _, interactor := setupRankingTest(t)
 	defer ctrl.Finish()
 
 	userID := uint64(1)
@@ -580,18 +720,55 @@ func TestRankingInteractor_ContestLogs(t *testing.T) {
 			{ContestID: contestID, UserID: userID, Language: domain.Korean, Amount: 100, MediumID: domain.MediumNet, CreatedAt: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
 			{ContestID: contestID, UserID: userID, Language: domain.Japanese, Amount: 40, MediumID: domain.MediumBook, CreatedAt: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)},
 		}
-		repo.EXPECT().FindAll(contestID, userID).Return(expected, nil)
+		repo.EXPECT().FindAll(gomock.Any(), contestID, userID).Return(expected, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID, Preferences: &domain.Preferences{}}, nil)
 
-		logs, err := interactor.ContestLogs(contestID, userID)
+		logs, err := interactor.ContestLogs(context.Background(), contestID, userID, 0)
 		assert.NoError(t, err)
 		assert.Equal(t, expected, logs)
 	}
 
+	{
+		// Happy path: dates are in the timezone of the user
+		tokyo, _ := time.LoadLocation("Asia/Tokyo")
+		createdAt := time.Date(2019, 1, 1, 20, 0, 0, 0, time.UTC)
+		logs := domain.ContestLogs{{ContestID: contestID, UserID: userID, CreatedAt: createdAt, UpdatedAt: createdAt}}
+		repo.EXPECT().FindAll(gomock.Any(), contestID, userID).Return(logs, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID, Preferences: &domain.Preferences{Timezone: "Asia/Tokyo"}}, nil)
+
+		result, err := interactor.ContestLogs(context.Background(), contestID, userID, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, tokyo, result[0].CreatedAt.Location())
+		assert.Equal(t, 2, result[0].CreatedAt.Day(), "it's already the next day in Tokyo")
+		assert.True(t, createdAt.Equal(result[0].CreatedAt))
+	}
+
 	{
 		// Sad path no registration found
-		repo.EXPECT().FindAll(contestID, userID).Return(domain.ContestLogs{}, nil)
+		userRepo.EXPECT().FindByID(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+		repo.EXPECT().FindAll(gomock.Any(), contestID, userID).Return(domain.ContestLogs{}, nil)
+
+		_, err := interactor.ContestLogs(context.Background(), contestID, userID, 0)
+		assert.EqualError(t, err, usecases.ErrNoContestLogsFound.Error())
+	}
+
+	{
+		// Sad path: user is hidden from rankings
+		userRepo.EXPECT().FindByID(gomock.Any(), uint64(2)).Return(domain.User{ID: 2, Preferences: &domain.Preferences{HideFromRankings: true}}, nil)
 
-		_, err := interactor.ContestLogs(contestID, userID)
+		_, err := interactor.ContestLogs(context.Background(), contestID, 2, userID)
 		assert.EqualError(t, err, usecases.ErrNoContestLogsFound.Error())
 	}
+
+	{
+		// Happy path: hidden users can still see their own logs
+		hidden := domain.User{ID: 2, Preferences: &domain.Preferences{HideFromRankings: true}}
+		expected := domain.ContestLogs{{ContestID: contestID, UserID: hidden.ID, Language: domain.Japanese, Amount: 10, MediumID: domain.MediumBook}}
+		userRepo.EXPECT().FindByID(gomock.Any(), hidden.ID).Return(hidden, nil)
+		repo.EXPECT().FindAll(gomock.Any(), contestID, hidden.ID).Return(expected, nil)
+
+		logs, err := interactor.ContestLogs(context.Background(), contestID, hidden.ID, hidden.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, logs)
+	}
 }
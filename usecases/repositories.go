@@ -3,43 +3,165 @@
 package usecases
 
 import (
+	"context"
+	"time"
+
 	"github.com/tadoku/api/domain"
 )
 
 // UserRepository handles User related database interactions
 type UserRepository interface {
-	Store(user *domain.User) error
-	UpdatePassword(user *domain.User) error
-	FindByID(id uint64) (domain.User, error)
-	FindByEmail(email string) (domain.User, error)
+	Store(ctx context.Context, user *domain.User) error
+	UpdatePassword(ctx context.Context, user *domain.User) error
+	FindByID(ctx context.Context, id uint64) (domain.User, error)
+	FindByEmail(ctx context.Context, email string) (domain.User, error)
+	MarkEmailAsVerified(ctx context.Context, id uint64) error
+
+	// Search matches the query against email addresses and display names, an empty query matches everyone
+	Search(ctx context.Context, query string, limit, offset int) (domain.Users, error)
+	// UpdateRole returns domain.ErrNotFound for disabled users, they have to be enabled first
+	UpdateRole(ctx context.Context, id uint64, role domain.Role) error
+	Disable(ctx context.Context, id uint64, reason string) error
+	// Enable gives a disabled user the role back they had before, it returns domain.ErrNotFound for users that aren't disabled
+	Enable(ctx context.Context, id uint64) error
+	UpdatePreferences(ctx context.Context, id uint64, preferences domain.Preferences) error
+	// UpdateEmail returns domain.ErrAlreadyExists when another user already has the address, and domain.ErrNotFound for disabled users
+	UpdateEmail(ctx context.Context, id uint64, email string) error
+	// Anonymize removes all personal data of a user, while keeping the row around for their rankings
+	Anonymize(ctx context.Context, id uint64) error
+}
+
+// OneTimeTokenRepository handles OneTimeToken related database interactions
+type OneTimeTokenRepository interface {
+	Store(ctx context.Context, token *domain.OneTimeToken) error
+	FindByHash(ctx context.Context, purpose domain.TokenPurpose, hash string) (domain.OneTimeToken, error)
+	MarkAsUsed(ctx context.Context, id uint64) error
+	InvalidateAllForUser(ctx context.Context, purpose domain.TokenPurpose, userID uint64) error
+	DeleteAllForUser(ctx context.Context, userID uint64) error
+}
+
+// SessionRepository handles Session related database interactions
+type SessionRepository interface {
+	Store(ctx context.Context, session *domain.Session) error
+	FindByID(ctx context.Context, id uint64) (domain.Session, error)
+	FindActiveByUserID(ctx context.Context, userID uint64) (domain.Sessions, error)
+	Extend(ctx context.Context, session domain.Session) error
+	Touch(ctx context.Context, id uint64) error
+	Revoke(ctx context.Context, id uint64) error
+	RevokeAllForUser(ctx context.Context, userID uint64) error
+	// DeleteAllForUser removes the sessions including their refresh tokens, revoking keeps them around for the session overview
+	DeleteAllForUser(ctx context.Context, userID uint64) error
+}
+
+// RefreshTokenRepository handles RefreshToken related database interactions
+type RefreshTokenRepository interface {
+	Store(ctx context.Context, token *domain.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (domain.RefreshToken, error)
+	MarkAsUsed(ctx context.Context, id uint64) error
+}
+
+// TwoFactorAuthenticationRepository handles TwoFactorAuthentication related database interactions
+type TwoFactorAuthenticationRepository interface {
+	Store(ctx context.Context, twoFactor *domain.TwoFactorAuthentication) error
+	FindByUserID(ctx context.Context, userID uint64) (domain.TwoFactorAuthentication, error)
+	Confirm(ctx context.Context, userID uint64) error
+	UpdateSecret(ctx context.Context, userID uint64, secret string) error
+	UpdateLastUsedStep(ctx context.Context, userID uint64, step int64) error
+	Delete(ctx context.Context, userID uint64) error
+}
+
+// RecoveryCodeRepository handles recovery code related database interactions
+type RecoveryCodeRepository interface {
+	ReplaceAllForUser(ctx context.Context, userID uint64, hashes []string) error
+	MarkAsUsed(ctx context.Context, userID uint64, hash string) error
+	DeleteAllForUser(ctx context.Context, userID uint64) error
+}
+
+// TwoFactorChallengeRepository handles TwoFactorChallenge related database interactions
+type TwoFactorChallengeRepository interface {
+	Store(ctx context.Context, challenge *domain.TwoFactorChallenge) error
+	FindByHash(ctx context.Context, hash string) (domain.TwoFactorChallenge, error)
+	RecordFailedAttempt(ctx context.Context, id uint64) error
+	MarkAsUsed(ctx context.Context, id uint64) error
+}
+
+// LoginAttemptRepository keeps track of failed logins, either in memory or in the database
+type LoginAttemptRepository interface {
+	FindByKey(ctx context.Context, key string) (domain.LoginAttempt, error)
+	// RecordFailure counts a failed login, failures from before resetBefore are forgotten first
+	RecordFailure(ctx context.Context, key string, failedAt time.Time, resetBefore time.Time) (domain.LoginAttempt, error)
+	Delete(ctx context.Context, key string) error
+	// DeleteFailedBefore removes every key that hasn't failed since the given time
+	DeleteFailedBefore(ctx context.Context, before time.Time) error
+}
+
+// PersonalAccessTokenRepository handles PersonalAccessToken related database interactions
+type PersonalAccessTokenRepository interface {
+	Store(ctx context.Context, token *domain.PersonalAccessToken) error
+	FindByID(ctx context.Context, id uint64) (domain.PersonalAccessToken, error)
+	FindByHash(ctx context.Context, hash string) (domain.PersonalAccessToken, error)
+	FindActiveByUserID(ctx context.Context, userID uint64) (domain.PersonalAccessTokens, error)
+	UpdateLastUsed(ctx context.Context, id uint64) error
+	Revoke(ctx context.Context, id uint64) error
+	DeleteAllForUser(ctx context.Context, userID uint64) error
+}
+
+// AuditEventRepository handles AuditEvent related database interactions
+type AuditEventRepository interface {
+	Store(ctx context.Context, event *domain.AuditEvent) error
+	// Find lists the newest events first
+	Find(ctx context.Context, filter domain.AuditEventFilter, limit, offset int) (domain.AuditEvents, error)
 }
 
 // ContestRepository handles Contest related database interactions
 type ContestRepository interface {
-	Store(contest *domain.Contest) error
-	GetOpenContests() ([]uint64, error)
-	GetRunningContests() ([]uint64, error)
-	FindAll() ([]domain.Contest, error)
-	FindRecent(count int) ([]domain.Contest, error)
-	FindByID(id uint64) (domain.Contest, error)
+	Store(ctx context.Context, contest *domain.Contest) error
+	GetOpenContests(ctx context.Context) ([]uint64, error)
+	GetRunningContests(ctx context.Context) ([]uint64, error)
+	FindAll(ctx context.Context) ([]domain.Contest, error)
+	FindRecent(ctx context.Context, count int) ([]domain.Contest, error)
+	FindByID(ctx context.Context, id uint64) (domain.Contest, error)
 }
 
 // ContestLogRepository handles ContestLog related database interactions
 type ContestLogRepository interface {
-	Store(contestLog *domain.ContestLog) error
-	FindAll(contestID uint64, userID uint64) (domain.ContestLogs, error)
-	FindByID(id uint64) (domain.ContestLog, error)
-	Delete(id uint64) error
+	Store(ctx context.Context, contestLog *domain.ContestLog) error
+	FindAll(ctx context.Context, contestID uint64, userID uint64) (domain.ContestLogs, error)
+	// FindAllForContest returns the logs of every user in a contest that haven't been deleted
+	FindAllForContest(ctx context.Context, contestID uint64) (domain.ContestLogs, error)
+	FindByID(ctx context.Context, id uint64) (domain.ContestLog, error)
+	// FindByIDForUpdate locks the log until the transaction ends, so its amount can't change while it's being updated or deleted
+	FindByIDForUpdate(ctx context.Context, id uint64) (domain.ContestLog, error)
+	Delete(ctx context.Context, id uint64) error
+
+	// FindAllForUser includes logs that have been deleted
+	FindAllForUser(ctx context.Context, userID uint64) (domain.ContestLogs, error)
+	// TotalsForUser sums up all logs of a user that haven't been deleted per language and medium
+	TotalsForUser(ctx context.Context, userID uint64) ([]domain.ContestLogTotal, error)
+	// Purge removes all logs of a user for good, including the ones that have been deleted
+	Purge(ctx context.Context, userID uint64) error
 }
 
 // RankingRepository handles Ranking related database interactions
 type RankingRepository interface {
-	Store(contest domain.Ranking) error
-	UpdateAmounts(domain.Rankings) error
-
-	RankingsForContest(contestID uint64, languageCode domain.LanguageCode) (domain.Rankings, error)
-	GlobalRankings(languageCode domain.LanguageCode) (domain.Rankings, error)
-	FindAll(contestID uint64, userID uint64) (domain.Rankings, error)
-	GetAllLanguagesForContestAndUser(contestID uint64, userID uint64) (domain.LanguageCodes, error)
-	CurrentRegistration(userID uint64) (domain.RankingRegistration, error)
+	Store(ctx context.Context, contest domain.Ranking) error
+	// UpdateAmounts should run in a transaction, otherwise a failure leaves some of the rankings updated
+	UpdateAmounts(context.Context, domain.Rankings) error
+	// AddAmounts adds to the amounts of the rankings of a user per language, use negative amounts to subtract
+	AddAmounts(ctx context.Context, contestID uint64, userID uint64, amounts map[domain.LanguageCode]float32) error
+
+	// RankingsForContest and GlobalRankings leave out users that hide themselves from rankings
+	RankingsForContest(ctx context.Context, contestID uint64, languageCode domain.LanguageCode) (domain.Rankings, error)
+	GlobalRankings(ctx context.Context, languageCode domain.LanguageCode) (domain.Rankings, error)
+	FindAll(ctx context.Context, contestID uint64, userID uint64) (domain.Rankings, error)
+	// FindAllForUpdate locks the rankings until the transaction ends, so logs can't change them in the meantime
+	FindAllForUpdate(ctx context.Context, contestID uint64, userID uint64) (domain.Rankings, error)
+	// FindAllForContest includes users that hide themselves from rankings
+	FindAllForContest(ctx context.Context, contestID uint64) (domain.Rankings, error)
+	FindAllForUser(ctx context.Context, userID uint64) (domain.Rankings, error)
+	DeleteAllForUser(ctx context.Context, userID uint64) error
+	// FindProfileRankings ranks a user against everyone else that's visible in every contest they entered
+	FindProfileRankings(ctx context.Context, userID uint64) ([]domain.ProfileRanking, error)
+	GetAllLanguagesForContestAndUser(ctx context.Context, contestID uint64, userID uint64) (domain.LanguageCodes, error)
+	CurrentRegistration(ctx context.Context, userID uint64) (domain.RankingRegistration, error)
 }
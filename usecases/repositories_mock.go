@@ -5,9 +5,11 @@
 package usecases
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/tadoku/api/domain"
 	reflect "reflect"
+	time "time"
 )
 
 // MockUserRepository is a mock of UserRepository interface
@@ -34,61 +36,981 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 }
 
 // Store mocks base method
-func (m *MockUserRepository) Store(user *domain.User) error {
+func (m *MockUserRepository) Store(ctx context.Context, user *domain.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Store", user)
+	ret := m.ctrl.Call(m, "Store", ctx, user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Store indicates an expected call of Store
-func (mr *MockUserRepositoryMockRecorder) Store(user interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) Store(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockUserRepository)(nil).Store), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockUserRepository)(nil).Store), ctx, user)
 }
 
 // UpdatePassword mocks base method
-func (m *MockUserRepository) UpdatePassword(user *domain.User) error {
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, user *domain.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePassword", user)
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdatePassword indicates an expected call of UpdatePassword
-func (mr *MockUserRepositoryMockRecorder) UpdatePassword(user interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) UpdatePassword(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepository)(nil).UpdatePassword), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepository)(nil).UpdatePassword), ctx, user)
 }
 
 // FindByID mocks base method
-func (m *MockUserRepository) FindByID(id uint64) (domain.User, error) {
+func (m *MockUserRepository) FindByID(ctx context.Context, id uint64) (domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByID", id)
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
 	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindByID indicates an expected call of FindByID
-func (mr *MockUserRepositoryMockRecorder) FindByID(id interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockUserRepository)(nil).FindByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockUserRepository)(nil).FindByID), ctx, id)
 }
 
 // FindByEmail mocks base method
-func (m *MockUserRepository) FindByEmail(email string) (domain.User, error) {
+func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByEmail", email)
+	ret := m.ctrl.Call(m, "FindByEmail", ctx, email)
 	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindByEmail indicates an expected call of FindByEmail
-func (mr *MockUserRepositoryMockRecorder) FindByEmail(email interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) FindByEmail(ctx, email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockUserRepository)(nil).FindByEmail), email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockUserRepository)(nil).FindByEmail), ctx, email)
+}
+
+// MarkEmailAsVerified mocks base method
+func (m *MockUserRepository) MarkEmailAsVerified(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailAsVerified", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEmailAsVerified indicates an expected call of MarkEmailAsVerified
+func (mr *MockUserRepositoryMockRecorder) MarkEmailAsVerified(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailAsVerified", reflect.TypeOf((*MockUserRepository)(nil).MarkEmailAsVerified), ctx, id)
+}
+
+// Search mocks base method
+func (m *MockUserRepository) Search(ctx context.Context, query string, limit, offset int) (domain.Users, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query, limit, offset)
+	ret0, _ := ret[0].(domain.Users)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search
+func (mr *MockUserRepositoryMockRecorder) Search(ctx, query, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserRepository)(nil).Search), ctx, query, limit, offset)
+}
+
+// UpdateRole mocks base method
+func (m *MockUserRepository) UpdateRole(ctx context.Context, id uint64, role domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, id, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole
+func (mr *MockUserRepositoryMockRecorder) UpdateRole(ctx, id, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockUserRepository)(nil).UpdateRole), ctx, id, role)
+}
+
+// Disable mocks base method
+func (m *MockUserRepository) Disable(ctx context.Context, id uint64, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, id, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable
+func (mr *MockUserRepositoryMockRecorder) Disable(ctx, id, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockUserRepository)(nil).Disable), ctx, id, reason)
+}
+
+// Enable mocks base method
+func (m *MockUserRepository) Enable(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enable", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Enable indicates an expected call of Enable
+func (mr *MockUserRepositoryMockRecorder) Enable(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockUserRepository)(nil).Enable), ctx, id)
+}
+
+// UpdatePreferences mocks base method
+func (m *MockUserRepository) UpdatePreferences(ctx context.Context, id uint64, preferences domain.Preferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", ctx, id, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences
+func (mr *MockUserRepositoryMockRecorder) UpdatePreferences(ctx, id, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockUserRepository)(nil).UpdatePreferences), ctx, id, preferences)
+}
+
+// UpdateEmail mocks base method
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, id uint64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, id, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail
+func (mr *MockUserRepositoryMockRecorder) UpdateEmail(ctx, id, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockUserRepository)(nil).UpdateEmail), ctx, id, email)
+}
+
+// Anonymize mocks base method
+func (m *MockUserRepository) Anonymize(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Anonymize", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Anonymize indicates an expected call of Anonymize
+func (mr *MockUserRepositoryMockRecorder) Anonymize(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Anonymize", reflect.TypeOf((*MockUserRepository)(nil).Anonymize), ctx, id)
+}
+
+// MockOneTimeTokenRepository is a mock of OneTimeTokenRepository interface
+type MockOneTimeTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOneTimeTokenRepositoryMockRecorder
+}
+
+// MockOneTimeTokenRepositoryMockRecorder is the mock recorder for MockOneTimeTokenRepository
+type MockOneTimeTokenRepositoryMockRecorder struct {
+	mock *MockOneTimeTokenRepository
+}
+
+// NewMockOneTimeTokenRepository creates a new mock instance
+func NewMockOneTimeTokenRepository(ctrl *gomock.Controller) *MockOneTimeTokenRepository {
+	mock := &MockOneTimeTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockOneTimeTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockOneTimeTokenRepository) EXPECT() *MockOneTimeTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method
+func (m *MockOneTimeTokenRepository) Store(ctx context.Context, token *domain.OneTimeToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockOneTimeTokenRepositoryMockRecorder) Store(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockOneTimeTokenRepository)(nil).Store), ctx, token)
+}
+
+// FindByHash mocks base method
+func (m *MockOneTimeTokenRepository) FindByHash(ctx context.Context, purpose domain.TokenPurpose, hash string) (domain.OneTimeToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByHash", ctx, purpose, hash)
+	ret0, _ := ret[0].(domain.OneTimeToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByHash indicates an expected call of FindByHash
+func (mr *MockOneTimeTokenRepositoryMockRecorder) FindByHash(ctx, purpose, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByHash", reflect.TypeOf((*MockOneTimeTokenRepository)(nil).FindByHash), ctx, purpose, hash)
+}
+
+// MarkAsUsed mocks base method
+func (m *MockOneTimeTokenRepository) MarkAsUsed(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsUsed", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsUsed indicates an expected call of MarkAsUsed
+func (mr *MockOneTimeTokenRepositoryMockRecorder) MarkAsUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsUsed", reflect.TypeOf((*MockOneTimeTokenRepository)(nil).MarkAsUsed), ctx, id)
+}
+
+// InvalidateAllForUser mocks base method
+func (m *MockOneTimeTokenRepository) InvalidateAllForUser(ctx context.Context, purpose domain.TokenPurpose, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateAllForUser", ctx, purpose, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateAllForUser indicates an expected call of InvalidateAllForUser
+func (mr *MockOneTimeTokenRepositoryMockRecorder) InvalidateAllForUser(ctx, purpose, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateAllForUser", reflect.TypeOf((*MockOneTimeTokenRepository)(nil).InvalidateAllForUser), ctx, purpose, userID)
+}
+
+// DeleteAllForUser mocks base method
+func (m *MockOneTimeTokenRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllForUser indicates an expected call of DeleteAllForUser
+func (mr *MockOneTimeTokenRepositoryMockRecorder) DeleteAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllForUser", reflect.TypeOf((*MockOneTimeTokenRepository)(nil).DeleteAllForUser), ctx, userID)
+}
+
+// MockSessionRepository is a mock of SessionRepository interface
+type MockSessionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionRepositoryMockRecorder
+}
+
+// MockSessionRepositoryMockRecorder is the mock recorder for MockSessionRepository
+type MockSessionRepositoryMockRecorder struct {
+	mock *MockSessionRepository
+}
+
+// NewMockSessionRepository creates a new mock instance
+func NewMockSessionRepository(ctrl *gomock.Controller) *MockSessionRepository {
+	mock := &MockSessionRepository{ctrl: ctrl}
+	mock.recorder = &MockSessionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSessionRepository) EXPECT() *MockSessionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method
+func (m *MockSessionRepository) Store(ctx context.Context, session *domain.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockSessionRepositoryMockRecorder) Store(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockSessionRepository)(nil).Store), ctx, session)
+}
+
+// FindByID mocks base method
+func (m *MockSessionRepository) FindByID(ctx context.Context, id uint64) (domain.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(domain.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID
+func (mr *MockSessionRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockSessionRepository)(nil).FindByID), ctx, id)
+}
+
+// FindActiveByUserID mocks base method
+func (m *MockSessionRepository) FindActiveByUserID(ctx context.Context, userID uint64) (domain.Sessions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActiveByUserID", ctx, userID)
+	ret0, _ := ret[0].(domain.Sessions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActiveByUserID indicates an expected call of FindActiveByUserID
+func (mr *MockSessionRepositoryMockRecorder) FindActiveByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActiveByUserID", reflect.TypeOf((*MockSessionRepository)(nil).FindActiveByUserID), ctx, userID)
+}
+
+// Extend mocks base method
+func (m *MockSessionRepository) Extend(ctx context.Context, session domain.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Extend", ctx, session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Extend indicates an expected call of Extend
+func (mr *MockSessionRepositoryMockRecorder) Extend(ctx, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Extend", reflect.TypeOf((*MockSessionRepository)(nil).Extend), ctx, session)
+}
+
+// Touch mocks base method
+func (m *MockSessionRepository) Touch(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch
+func (mr *MockSessionRepositoryMockRecorder) Touch(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockSessionRepository)(nil).Touch), ctx, id)
+}
+
+// Revoke mocks base method
+func (m *MockSessionRepository) Revoke(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockSessionRepositoryMockRecorder) Revoke(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockSessionRepository)(nil).Revoke), ctx, id)
+}
+
+// RevokeAllForUser mocks base method
+func (m *MockSessionRepository) RevokeAllForUser(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllForUser indicates an expected call of RevokeAllForUser
+func (mr *MockSessionRepositoryMockRecorder) RevokeAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllForUser", reflect.TypeOf((*MockSessionRepository)(nil).RevokeAllForUser), ctx, userID)
+}
+
+// DeleteAllForUser mocks base method
+func (m *MockSessionRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllForUser indicates an expected call of DeleteAllForUser
+func (mr *MockSessionRepositoryMockRecorder) DeleteAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllForUser", reflect.TypeOf((*MockSessionRepository)(nil).DeleteAllForUser), ctx, userID)
+}
+
+// MockRefreshTokenRepository is a mock of RefreshTokenRepository interface
+type MockRefreshTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshTokenRepositoryMockRecorder
+}
+
+// MockRefreshTokenRepositoryMockRecorder is the mock recorder for MockRefreshTokenRepository
+type MockRefreshTokenRepositoryMockRecorder struct {
+	mock *MockRefreshTokenRepository
+}
+
+// NewMockRefreshTokenRepository creates a new mock instance
+func NewMockRefreshTokenRepository(ctrl *gomock.Controller) *MockRefreshTokenRepository {
+	mock := &MockRefreshTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRefreshTokenRepository) EXPECT() *MockRefreshTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method
+func (m *MockRefreshTokenRepository) Store(ctx context.Context, token *domain.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockRefreshTokenRepositoryMockRecorder) Store(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockRefreshTokenRepository)(nil).Store), ctx, token)
+}
+
+// FindByHash mocks base method
+func (m *MockRefreshTokenRepository) FindByHash(ctx context.Context, hash string) (domain.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByHash", ctx, hash)
+	ret0, _ := ret[0].(domain.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByHash indicates an expected call of FindByHash
+func (mr *MockRefreshTokenRepositoryMockRecorder) FindByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByHash", reflect.TypeOf((*MockRefreshTokenRepository)(nil).FindByHash), ctx, hash)
+}
+
+// MarkAsUsed mocks base method
+func (m *MockRefreshTokenRepository) MarkAsUsed(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsUsed", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsUsed indicates an expected call of MarkAsUsed
+func (mr *MockRefreshTokenRepositoryMockRecorder) MarkAsUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsUsed", reflect.TypeOf((*MockRefreshTokenRepository)(nil).MarkAsUsed), ctx, id)
+}
+
+// MockTwoFactorAuthenticationRepository is a mock of TwoFactorAuthenticationRepository interface
+type MockTwoFactorAuthenticationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorAuthenticationRepositoryMockRecorder
+}
+
+// MockTwoFactorAuthenticationRepositoryMockRecorder is the mock recorder for MockTwoFactorAuthenticationRepository
+type MockTwoFactorAuthenticationRepositoryMockRecorder struct {
+	mock *MockTwoFactorAuthenticationRepository
+}
+
+// NewMockTwoFactorAuthenticationRepository creates a new mock instance
+func NewMockTwoFactorAuthenticationRepository(ctrl *gomock.Controller) *MockTwoFactorAuthenticationRepository {
+	mock := &MockTwoFactorAuthenticationRepository{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorAuthenticationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTwoFactorAuthenticationRepository) EXPECT() *MockTwoFactorAuthenticationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method
+func (m *MockTwoFactorAuthenticationRepository) Store(ctx context.Context, twoFactor *domain.TwoFactorAuthentication) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, twoFactor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockTwoFactorAuthenticationRepositoryMockRecorder) Store(ctx, twoFactor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockTwoFactorAuthenticationRepository)(nil).Store), ctx, twoFactor)
+}
+
+// FindByUserID mocks base method
+func (m *MockTwoFactorAuthenticationRepository) FindByUserID(ctx context.Context, userID uint64) (domain.TwoFactorAuthentication, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, userID)
+	ret0, _ := ret[0].(domain.TwoFactorAuthentication)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID
+func (mr *MockTwoFactorAuthenticationRepositoryMockRecorder) FindByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockTwoFactorAuthenticationRepository)(nil).FindByUserID), ctx, userID)
+}
+
+// Confirm mocks base method
+func (m *MockTwoFactorAuthenticationRepository) Confirm(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Confirm indicates an expected call of Confirm
+func (mr *MockTwoFactorAuthenticationRepositoryMockRecorder) Confirm(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockTwoFactorAuthenticationRepository)(nil).Confirm), ctx, userID)
+}
+
+// UpdateSecret mocks base method
+func (m *MockTwoFactorAuthenticationRepository) UpdateSecret(ctx context.Context, userID uint64, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSecret indicates an expected call of UpdateSecret
+func (mr *MockTwoFactorAuthenticationRepositoryMockRecorder) UpdateSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSecret", reflect.TypeOf((*MockTwoFactorAuthenticationRepository)(nil).UpdateSecret), ctx, userID, secret)
+}
+
+// UpdateLastUsedStep mocks base method
+func (m *MockTwoFactorAuthenticationRepository) UpdateLastUsedStep(ctx context.Context, userID uint64, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastUsedStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastUsedStep indicates an expected call of UpdateLastUsedStep
+func (mr *MockTwoFactorAuthenticationRepositoryMockRecorder) UpdateLastUsedStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastUsedStep", reflect.TypeOf((*MockTwoFactorAuthenticationRepository)(nil).UpdateLastUsedStep), ctx, userID, step)
+}
+
+// Delete mocks base method
+func (m *MockTwoFactorAuthenticationRepository) Delete(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockTwoFactorAuthenticationRepositoryMockRecorder) Delete(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTwoFactorAuthenticationRepository)(nil).Delete), ctx, userID)
+}
+
+// MockRecoveryCodeRepository is a mock of RecoveryCodeRepository interface
+type MockRecoveryCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecoveryCodeRepositoryMockRecorder
+}
+
+// MockRecoveryCodeRepositoryMockRecorder is the mock recorder for MockRecoveryCodeRepository
+type MockRecoveryCodeRepositoryMockRecorder struct {
+	mock *MockRecoveryCodeRepository
+}
+
+// NewMockRecoveryCodeRepository creates a new mock instance
+func NewMockRecoveryCodeRepository(ctrl *gomock.Controller) *MockRecoveryCodeRepository {
+	mock := &MockRecoveryCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockRecoveryCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRecoveryCodeRepository) EXPECT() *MockRecoveryCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ReplaceAllForUser mocks base method
+func (m *MockRecoveryCodeRepository) ReplaceAllForUser(ctx context.Context, userID uint64, hashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceAllForUser", ctx, userID, hashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceAllForUser indicates an expected call of ReplaceAllForUser
+func (mr *MockRecoveryCodeRepositoryMockRecorder) ReplaceAllForUser(ctx, userID, hashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceAllForUser", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).ReplaceAllForUser), ctx, userID, hashes)
+}
+
+// MarkAsUsed mocks base method
+func (m *MockRecoveryCodeRepository) MarkAsUsed(ctx context.Context, userID uint64, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsUsed", ctx, userID, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsUsed indicates an expected call of MarkAsUsed
+func (mr *MockRecoveryCodeRepositoryMockRecorder) MarkAsUsed(ctx, userID, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsUsed", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).MarkAsUsed), ctx, userID, hash)
+}
+
+// DeleteAllForUser mocks base method
+func (m *MockRecoveryCodeRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllForUser indicates an expected call of DeleteAllForUser
+func (mr *MockRecoveryCodeRepositoryMockRecorder) DeleteAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllForUser", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).DeleteAllForUser), ctx, userID)
+}
+
+// MockTwoFactorChallengeRepository is a mock of TwoFactorChallengeRepository interface
+type MockTwoFactorChallengeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorChallengeRepositoryMockRecorder
+}
+
+// MockTwoFactorChallengeRepositoryMockRecorder is the mock recorder for MockTwoFactorChallengeRepository
+type MockTwoFactorChallengeRepositoryMockRecorder struct {
+	mock *MockTwoFactorChallengeRepository
+}
+
+// NewMockTwoFactorChallengeRepository creates a new mock instance
+func NewMockTwoFactorChallengeRepository(ctrl *gomock.Controller) *MockTwoFactorChallengeRepository {
+	mock := &MockTwoFactorChallengeRepository{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorChallengeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTwoFactorChallengeRepository) EXPECT() *MockTwoFactorChallengeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method
+func (m *MockTwoFactorChallengeRepository) Store(ctx context.Context, challenge *domain.TwoFactorChallenge) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, challenge)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockTwoFactorChallengeRepositoryMockRecorder) Store(ctx, challenge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockTwoFactorChallengeRepository)(nil).Store), ctx, challenge)
+}
+
+// FindByHash mocks base method
+func (m *MockTwoFactorChallengeRepository) FindByHash(ctx context.Context, hash string) (domain.TwoFactorChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByHash", ctx, hash)
+	ret0, _ := ret[0].(domain.TwoFactorChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByHash indicates an expected call of FindByHash
+func (mr *MockTwoFactorChallengeRepositoryMockRecorder) FindByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByHash", reflect.TypeOf((*MockTwoFactorChallengeRepository)(nil).FindByHash), ctx, hash)
+}
+
+// RecordFailedAttempt mocks base method
+func (m *MockTwoFactorChallengeRepository) RecordFailedAttempt(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailedAttempt", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFailedAttempt indicates an expected call of RecordFailedAttempt
+func (mr *MockTwoFactorChallengeRepositoryMockRecorder) RecordFailedAttempt(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailedAttempt", reflect.TypeOf((*MockTwoFactorChallengeRepository)(nil).RecordFailedAttempt), ctx, id)
+}
+
+// MarkAsUsed mocks base method
+func (m *MockTwoFactorChallengeRepository) MarkAsUsed(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsUsed", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsUsed indicates an expected call of MarkAsUsed
+func (mr *MockTwoFactorChallengeRepositoryMockRecorder) MarkAsUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsUsed", reflect.TypeOf((*MockTwoFactorChallengeRepository)(nil).MarkAsUsed), ctx, id)
+}
+
+// MockLoginAttemptRepository is a mock of LoginAttemptRepository interface
+type MockLoginAttemptRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptRepositoryMockRecorder
+}
+
+// MockLoginAttemptRepositoryMockRecorder is the mock recorder for MockLoginAttemptRepository
+type MockLoginAttemptRepositoryMockRecorder struct {
+	mock *MockLoginAttemptRepository
+}
+
+// NewMockLoginAttemptRepository creates a new mock instance
+func NewMockLoginAttemptRepository(ctrl *gomock.Controller) *MockLoginAttemptRepository {
+	mock := &MockLoginAttemptRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoginAttemptRepository) EXPECT() *MockLoginAttemptRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindByKey mocks base method
+func (m *MockLoginAttemptRepository) FindByKey(ctx context.Context, key string) (domain.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByKey", ctx, key)
+	ret0, _ := ret[0].(domain.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByKey indicates an expected call of FindByKey
+func (mr *MockLoginAttemptRepositoryMockRecorder) FindByKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByKey", reflect.TypeOf((*MockLoginAttemptRepository)(nil).FindByKey), ctx, key)
+}
+
+// RecordFailure mocks base method
+func (m *MockLoginAttemptRepository) RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (domain.LoginAttempt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFailure", ctx, key, failedAt, resetBefore)
+	ret0, _ := ret[0].(domain.LoginAttempt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordFailure indicates an expected call of RecordFailure
+func (mr *MockLoginAttemptRepositoryMockRecorder) RecordFailure(ctx, key, failedAt, resetBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFailure", reflect.TypeOf((*MockLoginAttemptRepository)(nil).RecordFailure), ctx, key, failedAt, resetBefore)
+}
+
+// Delete mocks base method
+func (m *MockLoginAttemptRepository) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockLoginAttemptRepositoryMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockLoginAttemptRepository)(nil).Delete), ctx, key)
+}
+
+// DeleteFailedBefore mocks base method
+func (m *MockLoginAttemptRepository) DeleteFailedBefore(ctx context.Context, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFailedBefore", ctx, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFailedBefore indicates an expected call of DeleteFailedBefore
+func (mr *MockLoginAttemptRepositoryMockRecorder) DeleteFailedBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFailedBefore", reflect.TypeOf((*MockLoginAttemptRepository)(nil).DeleteFailedBefore), ctx, before)
+}
+
+// MockPersonalAccessTokenRepository is a mock of PersonalAccessTokenRepository interface
+type MockPersonalAccessTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPersonalAccessTokenRepositoryMockRecorder
+}
+
+// MockPersonalAccessTokenRepositoryMockRecorder is the mock recorder for MockPersonalAccessTokenRepository
+type MockPersonalAccessTokenRepositoryMockRecorder struct {
+	mock *MockPersonalAccessTokenRepository
+}
+
+// NewMockPersonalAccessTokenRepository creates a new mock instance
+func NewMockPersonalAccessTokenRepository(ctrl *gomock.Controller) *MockPersonalAccessTokenRepository {
+	mock := &MockPersonalAccessTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockPersonalAccessTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockPersonalAccessTokenRepository) EXPECT() *MockPersonalAccessTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method
+func (m *MockPersonalAccessTokenRepository) Store(ctx context.Context, token *domain.PersonalAccessToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockPersonalAccessTokenRepositoryMockRecorder) Store(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockPersonalAccessTokenRepository)(nil).Store), ctx, token)
+}
+
+// FindByID mocks base method
+func (m *MockPersonalAccessTokenRepository) FindByID(ctx context.Context, id uint64) (domain.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
+	ret0, _ := ret[0].(domain.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID
+func (mr *MockPersonalAccessTokenRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockPersonalAccessTokenRepository)(nil).FindByID), ctx, id)
+}
+
+// FindByHash mocks base method
+func (m *MockPersonalAccessTokenRepository) FindByHash(ctx context.Context, hash string) (domain.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByHash", ctx, hash)
+	ret0, _ := ret[0].(domain.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByHash indicates an expected call of FindByHash
+func (mr *MockPersonalAccessTokenRepositoryMockRecorder) FindByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByHash", reflect.TypeOf((*MockPersonalAccessTokenRepository)(nil).FindByHash), ctx, hash)
+}
+
+// FindActiveByUserID mocks base method
+func (m *MockPersonalAccessTokenRepository) FindActiveByUserID(ctx context.Context, userID uint64) (domain.PersonalAccessTokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindActiveByUserID", ctx, userID)
+	ret0, _ := ret[0].(domain.PersonalAccessTokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindActiveByUserID indicates an expected call of FindActiveByUserID
+func (mr *MockPersonalAccessTokenRepositoryMockRecorder) FindActiveByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindActiveByUserID", reflect.TypeOf((*MockPersonalAccessTokenRepository)(nil).FindActiveByUserID), ctx, userID)
+}
+
+// UpdateLastUsed mocks base method
+func (m *MockPersonalAccessTokenRepository) UpdateLastUsed(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLastUsed", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLastUsed indicates an expected call of UpdateLastUsed
+func (mr *MockPersonalAccessTokenRepositoryMockRecorder) UpdateLastUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastUsed", reflect.TypeOf((*MockPersonalAccessTokenRepository)(nil).UpdateLastUsed), ctx, id)
+}
+
+// Revoke mocks base method
+func (m *MockPersonalAccessTokenRepository) Revoke(ctx context.Context, id uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke
+func (mr *MockPersonalAccessTokenRepositoryMockRecorder) Revoke(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockPersonalAccessTokenRepository)(nil).Revoke), ctx, id)
+}
+
+// DeleteAllForUser mocks base method
+func (m *MockPersonalAccessTokenRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllForUser indicates an expected call of DeleteAllForUser
+func (mr *MockPersonalAccessTokenRepositoryMockRecorder) DeleteAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllForUser", reflect.TypeOf((*MockPersonalAccessTokenRepository)(nil).DeleteAllForUser), ctx, userID)
+}
+
+// MockAuditEventRepository is a mock of AuditEventRepository interface
+type MockAuditEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditEventRepositoryMockRecorder
+}
+
+// MockAuditEventRepositoryMockRecorder is the mock recorder for MockAuditEventRepository
+type MockAuditEventRepositoryMockRecorder struct {
+	mock *MockAuditEventRepository
+}
+
+// NewMockAuditEventRepository creates a new mock instance
+func NewMockAuditEventRepository(ctrl *gomock.Controller) *MockAuditEventRepository {
+	mock := &MockAuditEventRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditEventRepository) EXPECT() *MockAuditEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method
+func (m *MockAuditEventRepository) Store(ctx context.Context, event *domain.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockAuditEventRepositoryMockRecorder) Store(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockAuditEventRepository)(nil).Store), ctx, event)
+}
+
+// Find mocks base method
+func (m *MockAuditEventRepository) Find(ctx context.Context, filter domain.AuditEventFilter, limit, offset int) (domain.AuditEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, filter, limit, offset)
+	ret0, _ := ret[0].(domain.AuditEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find
+func (mr *MockAuditEventRepositoryMockRecorder) Find(ctx, filter, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockAuditEventRepository)(nil).Find), ctx, filter, limit, offset)
 }
 
 // MockContestRepository is a mock of ContestRepository interface
@@ -115,92 +1037,92 @@ func (m *MockContestRepository) EXPECT() *MockContestRepositoryMockRecorder {
 }
 
 // Store mocks base method
-func (m *MockContestRepository) Store(contest *domain.Contest) error {
+func (m *MockContestRepository) Store(ctx context.Context, contest *domain.Contest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Store", contest)
+	ret := m.ctrl.Call(m, "Store", ctx, contest)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Store indicates an expected call of Store
-func (mr *MockContestRepositoryMockRecorder) Store(contest interface{}) *gomock.Call {
+func (mr *MockContestRepositoryMockRecorder) Store(ctx, contest interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockContestRepository)(nil).Store), contest)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockContestRepository)(nil).Store), ctx, contest)
 }
 
 // GetOpenContests mocks base method
-func (m *MockContestRepository) GetOpenContests() ([]uint64, error) {
+func (m *MockContestRepository) GetOpenContests(ctx context.Context) ([]uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOpenContests")
+	ret := m.ctrl.Call(m, "GetOpenContests", ctx)
 	ret0, _ := ret[0].([]uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOpenContests indicates an expected call of GetOpenContests
-func (mr *MockContestRepositoryMockRecorder) GetOpenContests() *gomock.Call {
+func (mr *MockContestRepositoryMockRecorder) GetOpenContests(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenContests", reflect.TypeOf((*MockContestRepository)(nil).GetOpenContests))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenContests", reflect.TypeOf((*MockContestRepository)(nil).GetOpenContests), ctx)
 }
 
 // GetRunningContests mocks base method
-func (m *MockContestRepository) GetRunningContests() ([]uint64, error) {
+func (m *MockContestRepository) GetRunningContests(ctx context.Context) ([]uint64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRunningContests")
+	ret := m.ctrl.Call(m, "GetRunningContests", ctx)
 	ret0, _ := ret[0].([]uint64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRunningContests indicates an expected call of GetRunningContests
-func (mr *MockContestRepositoryMockRecorder) GetRunningContests() *gomock.Call {
+func (mr *MockContestRepositoryMockRecorder) GetRunningContests(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningContests", reflect.TypeOf((*MockContestRepository)(nil).GetRunningContests))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningContests", reflect.TypeOf((*MockContestRepository)(nil).GetRunningContests), ctx)
 }
 
 // FindAll mocks base method
-func (m *MockContestRepository) FindAll() ([]domain.Contest, error) {
+func (m *MockContestRepository) FindAll(ctx context.Context) ([]domain.Contest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll")
+	ret := m.ctrl.Call(m, "FindAll", ctx)
 	ret0, _ := ret[0].([]domain.Contest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindAll indicates an expected call of FindAll
-func (mr *MockContestRepositoryMockRecorder) FindAll() *gomock.Call {
+func (mr *MockContestRepositoryMockRecorder) FindAll(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockContestRepository)(nil).FindAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockContestRepository)(nil).FindAll), ctx)
 }
 
 // FindRecent mocks base method
-func (m *MockContestRepository) FindRecent(count int) ([]domain.Contest, error) {
+func (m *MockContestRepository) FindRecent(ctx context.Context, count int) ([]domain.Contest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindRecent", count)
+	ret := m.ctrl.Call(m, "FindRecent", ctx, count)
 	ret0, _ := ret[0].([]domain.Contest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindRecent indicates an expected call of FindRecent
-func (mr *MockContestRepositoryMockRecorder) FindRecent(count interface{}) *gomock.Call {
+func (mr *MockContestRepositoryMockRecorder) FindRecent(ctx, count interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRecent", reflect.TypeOf((*MockContestRepository)(nil).FindRecent), count)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRecent", reflect.TypeOf((*MockContestRepository)(nil).FindRecent), ctx, count)
 }
 
 // FindByID mocks base method
-func (m *MockContestRepository) FindByID(id uint64) (domain.Contest, error) {
+func (m *MockContestRepository) FindByID(ctx context.Context, id uint64) (domain.Contest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByID", id)
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
 	ret0, _ := ret[0].(domain.Contest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindByID indicates an expected call of FindByID
-func (mr *MockContestRepositoryMockRecorder) FindByID(id interface{}) *gomock.Call {
+func (mr *MockContestRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockContestRepository)(nil).FindByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockContestRepository)(nil).FindByID), ctx, id)
 }
 
 // MockContestLogRepository is a mock of ContestLogRepository interface
@@ -227,61 +1149,135 @@ func (m *MockContestLogRepository) EXPECT() *MockContestLogRepositoryMockRecorde
 }
 
 // Store mocks base method
-func (m *MockContestLogRepository) Store(contestLog *domain.ContestLog) error {
+func (m *MockContestLogRepository) Store(ctx context.Context, contestLog *domain.ContestLog) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Store", contestLog)
+	ret := m.ctrl.Call(m, "Store", ctx, contestLog)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Store indicates an expected call of Store
-func (mr *MockContestLogRepositoryMockRecorder) Store(contestLog interface{}) *gomock.Call {
+func (mr *MockContestLogRepositoryMockRecorder) Store(ctx, contestLog interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockContestLogRepository)(nil).Store), contestLog)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockContestLogRepository)(nil).Store), ctx, contestLog)
 }
 
 // FindAll mocks base method
-func (m *MockContestLogRepository) FindAll(contestID, userID uint64) (domain.ContestLogs, error) {
+func (m *MockContestLogRepository) FindAll(ctx context.Context, contestID, userID uint64) (domain.ContestLogs, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll", contestID, userID)
+	ret := m.ctrl.Call(m, "FindAll", ctx, contestID, userID)
 	ret0, _ := ret[0].(domain.ContestLogs)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindAll indicates an expected call of FindAll
-func (mr *MockContestLogRepositoryMockRecorder) FindAll(contestID, userID interface{}) *gomock.Call {
+func (mr *MockContestLogRepositoryMockRecorder) FindAll(ctx, contestID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockContestLogRepository)(nil).FindAll), ctx, contestID, userID)
+}
+
+// FindAllForContest mocks base method
+func (m *MockContestLogRepository) FindAllForContest(ctx context.Context, contestID uint64) (domain.ContestLogs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllForContest", ctx, contestID)
+	ret0, _ := ret[0].(domain.ContestLogs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllForContest indicates an expected call of FindAllForContest
+func (mr *MockContestLogRepositoryMockRecorder) FindAllForContest(ctx, contestID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockContestLogRepository)(nil).FindAll), contestID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllForContest", reflect.TypeOf((*MockContestLogRepository)(nil).FindAllForContest), ctx, contestID)
 }
 
 // FindByID mocks base method
-func (m *MockContestLogRepository) FindByID(id uint64) (domain.ContestLog, error) {
+func (m *MockContestLogRepository) FindByID(ctx context.Context, id uint64) (domain.ContestLog, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindByID", id)
+	ret := m.ctrl.Call(m, "FindByID", ctx, id)
 	ret0, _ := ret[0].(domain.ContestLog)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindByID indicates an expected call of FindByID
-func (mr *MockContestLogRepositoryMockRecorder) FindByID(id interface{}) *gomock.Call {
+func (mr *MockContestLogRepositoryMockRecorder) FindByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockContestLogRepository)(nil).FindByID), ctx, id)
+}
+
+// FindByIDForUpdate mocks base method
+func (m *MockContestLogRepository) FindByIDForUpdate(ctx context.Context, id uint64) (domain.ContestLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDForUpdate", ctx, id)
+	ret0, _ := ret[0].(domain.ContestLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIDForUpdate indicates an expected call of FindByIDForUpdate
+func (mr *MockContestLogRepositoryMockRecorder) FindByIDForUpdate(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockContestLogRepository)(nil).FindByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDForUpdate", reflect.TypeOf((*MockContestLogRepository)(nil).FindByIDForUpdate), ctx, id)
 }
 
 // Delete mocks base method
-func (m *MockContestLogRepository) Delete(id uint64) error {
+func (m *MockContestLogRepository) Delete(ctx context.Context, id uint64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete
-func (mr *MockContestLogRepositoryMockRecorder) Delete(id interface{}) *gomock.Call {
+func (mr *MockContestLogRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockContestLogRepository)(nil).Delete), ctx, id)
+}
+
+// FindAllForUser mocks base method
+func (m *MockContestLogRepository) FindAllForUser(ctx context.Context, userID uint64) (domain.ContestLogs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllForUser", ctx, userID)
+	ret0, _ := ret[0].(domain.ContestLogs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllForUser indicates an expected call of FindAllForUser
+func (mr *MockContestLogRepositoryMockRecorder) FindAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllForUser", reflect.TypeOf((*MockContestLogRepository)(nil).FindAllForUser), ctx, userID)
+}
+
+// TotalsForUser mocks base method
+func (m *MockContestLogRepository) TotalsForUser(ctx context.Context, userID uint64) ([]domain.ContestLogTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TotalsForUser", ctx, userID)
+	ret0, _ := ret[0].([]domain.ContestLogTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TotalsForUser indicates an expected call of TotalsForUser
+func (mr *MockContestLogRepositoryMockRecorder) TotalsForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TotalsForUser", reflect.TypeOf((*MockContestLogRepository)(nil).TotalsForUser), ctx, userID)
+}
+
+// Purge mocks base method
+func (m *MockContestLogRepository) Purge(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockContestLogRepositoryMockRecorder) Purge(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockContestLogRepository)(nil).Delete), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockContestLogRepository)(nil).Purge), ctx, userID)
 }
 
 // MockRankingRepository is a mock of RankingRepository interface
@@ -308,104 +1304,192 @@ func (m *MockRankingRepository) EXPECT() *MockRankingRepositoryMockRecorder {
 }
 
 // Store mocks base method
-func (m *MockRankingRepository) Store(contest domain.Ranking) error {
+func (m *MockRankingRepository) Store(ctx context.Context, contest domain.Ranking) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Store", contest)
+	ret := m.ctrl.Call(m, "Store", ctx, contest)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Store indicates an expected call of Store
-func (mr *MockRankingRepositoryMockRecorder) Store(contest interface{}) *gomock.Call {
+func (mr *MockRankingRepositoryMockRecorder) Store(ctx, contest interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockRankingRepository)(nil).Store), contest)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockRankingRepository)(nil).Store), ctx, contest)
 }
 
 // UpdateAmounts mocks base method
-func (m *MockRankingRepository) UpdateAmounts(arg0 domain.Rankings) error {
+func (m *MockRankingRepository) UpdateAmounts(arg0 context.Context, arg1 domain.Rankings) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAmounts", arg0)
+	ret := m.ctrl.Call(m, "UpdateAmounts", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateAmounts indicates an expected call of UpdateAmounts
-func (mr *MockRankingRepositoryMockRecorder) UpdateAmounts(arg0 interface{}) *gomock.Call {
+func (mr *MockRankingRepositoryMockRecorder) UpdateAmounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAmounts", reflect.TypeOf((*MockRankingRepository)(nil).UpdateAmounts), arg0, arg1)
+}
+
+// AddAmounts mocks base method
+func (m *MockRankingRepository) AddAmounts(ctx context.Context, contestID, userID uint64, amounts map[domain.LanguageCode]float32) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAmounts", ctx, contestID, userID, amounts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddAmounts indicates an expected call of AddAmounts
+func (mr *MockRankingRepositoryMockRecorder) AddAmounts(ctx, contestID, userID, amounts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAmounts", reflect.TypeOf((*MockRankingRepository)(nil).UpdateAmounts), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAmounts", reflect.TypeOf((*MockRankingRepository)(nil).AddAmounts), ctx, contestID, userID, amounts)
 }
 
 // RankingsForContest mocks base method
-func (m *MockRankingRepository) RankingsForContest(contestID uint64, languageCode domain.LanguageCode) (domain.Rankings, error) {
+func (m *MockRankingRepository) RankingsForContest(ctx context.Context, contestID uint64, languageCode domain.LanguageCode) (domain.Rankings, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RankingsForContest", contestID, languageCode)
+	ret := m.ctrl.Call(m, "RankingsForContest", ctx, contestID, languageCode)
 	ret0, _ := ret[0].(domain.Rankings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RankingsForContest indicates an expected call of RankingsForContest
-func (mr *MockRankingRepositoryMockRecorder) RankingsForContest(contestID, languageCode interface{}) *gomock.Call {
+func (mr *MockRankingRepositoryMockRecorder) RankingsForContest(ctx, contestID, languageCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RankingsForContest", reflect.TypeOf((*MockRankingRepository)(nil).RankingsForContest), contestID, languageCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RankingsForContest", reflect.TypeOf((*MockRankingRepository)(nil).RankingsForContest), ctx, contestID, languageCode)
 }
 
 // GlobalRankings mocks base method
-func (m *MockRankingRepository) GlobalRankings(languageCode domain.LanguageCode) (domain.Rankings, error) {
+func (m *MockRankingRepository) GlobalRankings(ctx context.Context, languageCode domain.LanguageCode) (domain.Rankings, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GlobalRankings", languageCode)
+	ret := m.ctrl.Call(m, "GlobalRankings", ctx, languageCode)
 	ret0, _ := ret[0].(domain.Rankings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GlobalRankings indicates an expected call of GlobalRankings
-func (mr *MockRankingRepositoryMockRecorder) GlobalRankings(languageCode interface{}) *gomock.Call {
+func (mr *MockRankingRepositoryMockRecorder) GlobalRankings(ctx, languageCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GlobalRankings", reflect.TypeOf((*MockRankingRepository)(nil).GlobalRankings), languageCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GlobalRankings", reflect.TypeOf((*MockRankingRepository)(nil).GlobalRankings), ctx, languageCode)
 }
 
 // FindAll mocks base method
-func (m *MockRankingRepository) FindAll(contestID, userID uint64) (domain.Rankings, error) {
+func (m *MockRankingRepository) FindAll(ctx context.Context, contestID, userID uint64) (domain.Rankings, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindAll", contestID, userID)
+	ret := m.ctrl.Call(m, "FindAll", ctx, contestID, userID)
 	ret0, _ := ret[0].(domain.Rankings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // FindAll indicates an expected call of FindAll
-func (mr *MockRankingRepositoryMockRecorder) FindAll(contestID, userID interface{}) *gomock.Call {
+func (mr *MockRankingRepositoryMockRecorder) FindAll(ctx, contestID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockRankingRepository)(nil).FindAll), ctx, contestID, userID)
+}
+
+// FindAllForUpdate mocks base method
+func (m *MockRankingRepository) FindAllForUpdate(ctx context.Context, contestID, userID uint64) (domain.Rankings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllForUpdate", ctx, contestID, userID)
+	ret0, _ := ret[0].(domain.Rankings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllForUpdate indicates an expected call of FindAllForUpdate
+func (mr *MockRankingRepositoryMockRecorder) FindAllForUpdate(ctx, contestID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllForUpdate", reflect.TypeOf((*MockRankingRepository)(nil).FindAllForUpdate), ctx, contestID, userID)
+}
+
+// FindAllForContest mocks base method
+func (m *MockRankingRepository) FindAllForContest(ctx context.Context, contestID uint64) (domain.Rankings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllForContest", ctx, contestID)
+	ret0, _ := ret[0].(domain.Rankings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllForContest indicates an expected call of FindAllForContest
+func (mr *MockRankingRepositoryMockRecorder) FindAllForContest(ctx, contestID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllForContest", reflect.TypeOf((*MockRankingRepository)(nil).FindAllForContest), ctx, contestID)
+}
+
+// FindAllForUser mocks base method
+func (m *MockRankingRepository) FindAllForUser(ctx context.Context, userID uint64) (domain.Rankings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAllForUser", ctx, userID)
+	ret0, _ := ret[0].(domain.Rankings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAllForUser indicates an expected call of FindAllForUser
+func (mr *MockRankingRepositoryMockRecorder) FindAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAllForUser", reflect.TypeOf((*MockRankingRepository)(nil).FindAllForUser), ctx, userID)
+}
+
+// DeleteAllForUser mocks base method
+func (m *MockRankingRepository) DeleteAllForUser(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllForUser indicates an expected call of DeleteAllForUser
+func (mr *MockRankingRepositoryMockRecorder) DeleteAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllForUser", reflect.TypeOf((*MockRankingRepository)(nil).DeleteAllForUser), ctx, userID)
+}
+
+// FindProfileRankings mocks base method
+func (m *MockRankingRepository) FindProfileRankings(ctx context.Context, userID uint64) ([]domain.ProfileRanking, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindProfileRankings", ctx, userID)
+	ret0, _ := ret[0].([]domain.ProfileRanking)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindProfileRankings indicates an expected call of FindProfileRankings
+func (mr *MockRankingRepositoryMockRecorder) FindProfileRankings(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAll", reflect.TypeOf((*MockRankingRepository)(nil).FindAll), contestID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindProfileRankings", reflect.TypeOf((*MockRankingRepository)(nil).FindProfileRankings), ctx, userID)
 }
 
 // GetAllLanguagesForContestAndUser mocks base method
-func (m *MockRankingRepository) GetAllLanguagesForContestAndUser(contestID, userID uint64) (domain.LanguageCodes, error) {
+func (m *MockRankingRepository) GetAllLanguagesForContestAndUser(ctx context.Context, contestID, userID uint64) (domain.LanguageCodes, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllLanguagesForContestAndUser", contestID, userID)
+	ret := m.ctrl.Call(m, "GetAllLanguagesForContestAndUser", ctx, contestID, userID)
 	ret0, _ := ret[0].(domain.LanguageCodes)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllLanguagesForContestAndUser indicates an expected call of GetAllLanguagesForContestAndUser
-func (mr *MockRankingRepositoryMockRecorder) GetAllLanguagesForContestAndUser(contestID, userID interface{}) *gomock.Call {
+func (mr *MockRankingRepositoryMockRecorder) GetAllLanguagesForContestAndUser(ctx, contestID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllLanguagesForContestAndUser", reflect.TypeOf((*MockRankingRepository)(nil).GetAllLanguagesForContestAndUser), contestID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllLanguagesForContestAndUser", reflect.TypeOf((*MockRankingRepository)(nil).GetAllLanguagesForContestAndUser), ctx, contestID, userID)
 }
 
 // CurrentRegistration mocks base method
-func (m *MockRankingRepository) CurrentRegistration(userID uint64) (domain.RankingRegistration, error) {
+func (m *MockRankingRepository) CurrentRegistration(ctx context.Context, userID uint64) (domain.RankingRegistration, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CurrentRegistration", userID)
+	ret := m.ctrl.Call(m, "CurrentRegistration", ctx, userID)
 	ret0, _ := ret[0].(domain.RankingRegistration)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CurrentRegistration indicates an expected call of CurrentRegistration
-func (mr *MockRankingRepositoryMockRecorder) CurrentRegistration(userID interface{}) *gomock.Call {
+func (mr *MockRankingRepositoryMockRecorder) CurrentRegistration(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentRegistration", reflect.TypeOf((*MockRankingRepository)(nil).CurrentRegistration), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentRegistration", reflect.TypeOf((*MockRankingRepository)(nil).CurrentRegistration), ctx, userID)
 }
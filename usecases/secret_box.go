@@ -0,0 +1,12 @@
+//go:generate gex mockgen -source=secret_box.go -package usecases -destination=secret_box_mock.go
+
+package usecases
+
+// SecretBox encrypts secrets that have to be read back later on, so they can't be used by anyone who can read the database
+type SecretBox interface {
+	Seal(secret string) (sealed string, err error)
+	Open(sealed string) (secret string, err error)
+
+	// IsSealed tells secrets that have been encrypted apart from the ones that were stored before encryption existed
+	IsSealed(value string) bool
+}
@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: secret_box.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockSecretBox is a mock of SecretBox interface
+type MockSecretBox struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretBoxMockRecorder
+}
+
+// MockSecretBoxMockRecorder is the mock recorder for MockSecretBox
+type MockSecretBoxMockRecorder struct {
+	mock *MockSecretBox
+}
+
+// NewMockSecretBox creates a new mock instance
+func NewMockSecretBox(ctrl *gomock.Controller) *MockSecretBox {
+	mock := &MockSecretBox{ctrl: ctrl}
+	mock.recorder = &MockSecretBoxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSecretBox) EXPECT() *MockSecretBoxMockRecorder {
+	return m.recorder
+}
+
+// Seal mocks base method
+func (m *MockSecretBox) Seal(secret string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Seal", secret)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Seal indicates an expected call of Seal
+func (mr *MockSecretBoxMockRecorder) Seal(secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seal", reflect.TypeOf((*MockSecretBox)(nil).Seal), secret)
+}
+
+// Open mocks base method
+func (m *MockSecretBox) Open(sealed string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", sealed)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open
+func (mr *MockSecretBoxMockRecorder) Open(sealed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockSecretBox)(nil).Open), sealed)
+}
+
+// IsSealed mocks base method
+func (m *MockSecretBox) IsSealed(value string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsSealed", value)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsSealed indicates an expected call of IsSealed
+func (mr *MockSecretBoxMockRecorder) IsSealed(value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSealed", reflect.TypeOf((*MockSecretBox)(nil).IsSealed), value)
+}
@@ -10,4 +10,7 @@ import (
 type SessionClaims struct {
 	User *domain.User
 	Data map[string]interface{}
+
+	// SessionID is sent along as the jti claim so revoked sessions can be rejected
+	SessionID uint64 `json:"-"`
 }
@@ -3,6 +3,7 @@
 package usecases
 
 import (
+	"context"
 	"time"
 
 	"github.com/srvc/fail"
@@ -15,40 +16,165 @@ var ErrPasswordIncorrect = fail.New("invalid password supplied")
 // ErrUserDoesNotExist for when a user could not be found
 var ErrUserDoesNotExist = fail.New("user does not exist")
 
+// ErrInvalidUser for when a user can't be created with the given data
+var ErrInvalidUser = fail.New("invalid user supplied")
+
+// ErrInvalidNewPassword for when a new password doesn't meet the requirements
+var ErrInvalidNewPassword = fail.New("invalid new password supplied")
+
+// ErrPasswordResetTokenInvalid for when a password reset token is unknown, expired or already used
+var ErrPasswordResetTokenInvalid = fail.New("password reset token is invalid or has expired")
+
+// ErrEmailVerificationTokenInvalid for when an email verification token is unknown, expired or already used
+var ErrEmailVerificationTokenInvalid = fail.New("email verification token is invalid or has expired")
+
+// ErrEmailVerificationNotSent for when an account has been created, but its verification email couldn't be sent.
+// The account is there to stay, so the user has to ask for another verification email.
+var ErrEmailVerificationNotSent = fail.New("account has been created, but the verification email could not be sent")
+
+// ErrEmailNotVerified for when a user tries to log in before verifying their email address
+var ErrEmailNotVerified = fail.New("email address has not been verified yet")
+
+// ErrEmailAlreadyVerified for when a user requests a verification email while already being verified
+var ErrEmailAlreadyVerified = fail.New("email address has already been verified")
+
+// ErrRefreshTokenInvalid for when a refresh token is unknown or its session is no longer active
+var ErrRefreshTokenInvalid = fail.New("refresh token is invalid or has expired")
+
+// ErrRefreshTokenReused for when a refresh token that has already been exchanged is used again
+var ErrRefreshTokenReused = fail.New("refresh token has already been used, session has been revoked")
+
+// ErrSessionRevoked for when an access token belongs to a session that has been revoked or has expired
+var ErrSessionRevoked = fail.New("session has been revoked or has expired")
+
+// ErrSessionNotFound for when a session could not be found for the given user
+var ErrSessionNotFound = fail.New("session does not exist")
+
+// ErrUserDisabled for when an admin has disabled the account
+var ErrUserDisabled = fail.New("user has been disabled")
+
+// ErrTwoFactorChallengeInvalid for when a two factor challenge is unknown, expired, already used or had too many wrong codes
+var ErrTwoFactorChallengeInvalid = fail.New("two factor challenge is invalid or has expired")
+
+// ErrTwoFactorRequired for when an admin needs to have logged in with two factor authentication to do something
+var ErrTwoFactorRequired = fail.New("two factor authentication is required")
+
+// SessionClient describes the device a session is being used from
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionTokens are handed out when a session is created or refreshed.
+// The access token authenticates requests and is short-lived, the refresh token can be exchanged
+// once for a new pair of tokens.
+// Users with two factor authentication only get a challenge token after entering their password,
+// which has to be exchanged together with a code for the other tokens.
+type SessionTokens struct {
+	AccessToken    string
+	RefreshToken   string
+	ChallengeToken string
+}
+
+// RequiresTwoFactor tells you if the login still needs to be completed with a code
+func (t SessionTokens) RequiresTwoFactor() bool {
+	return t.ChallengeToken != ""
+}
+
 // SessionInteractor contains all business logic for sessions
 type SessionInteractor interface {
-	CreateUser(user domain.User) error
-	CreateSession(email, password string) (user domain.User, token string, err error)
-	RefreshSession(user domain.User) (latestUser domain.User, token string, err error)
+	CreateUser(ctx context.Context, user domain.User) error
+	// CreateSession keeps track of failed logins, callers need to check LoginThrottleInteractor before calling it.
+	// It returns ErrLoginThrottled when the failure blocks any further logins.
+	CreateSession(ctx context.Context, email, password string, client SessionClient) (user domain.User, tokens SessionTokens, err error)
+	// CompleteTwoFactorChallenge returns how long to wait together with ErrLoginThrottled, as the caller doesn't know the account
+	CompleteTwoFactorChallenge(ctx context.Context, challengeToken, code string, client SessionClient) (user domain.User, tokens SessionTokens, retryAfter time.Duration, err error)
+	RefreshSession(ctx context.Context, refreshToken string, client SessionClient) (latestUser domain.User, tokens SessionTokens, err error)
+	// VerifySession returns the user as they are now, since the role in an access token might be outdated
+	VerifySession(ctx context.Context, sessionID uint64, minRole domain.Role) (domain.User, error)
+	ActiveSessions(ctx context.Context, userID uint64) (domain.Sessions, error)
+	RevokeSession(ctx context.Context, refreshToken string) error
+	RevokeUserSession(ctx context.Context, userID, sessionID uint64) error
+	RevokeAllSessions(ctx context.Context, userID uint64) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, newPassword string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendEmailVerification(ctx context.Context, email string) error
+}
+
+// SessionConfig contains all settings for how accounts and sessions are managed
+type SessionConfig struct {
+	SessionLength              time.Duration
+	AccessTokenLifetime        time.Duration
+	PasswordResetLifetime      time.Duration
+	EmailVerificationLifetime  time.Duration
+	TwoFactorChallengeLifetime time.Duration
+	RequireEmailVerification   bool
+	RequireTwoFactorForAdmins  bool
+	FrontendURL                string
 }
 
 // NewSessionInteractor instantiates SessionInteractor with all dependencies
 func NewSessionInteractor(
 	userRepository UserRepository,
+	oneTimeTokenRepository OneTimeTokenRepository,
+	sessionRepository SessionRepository,
+	refreshTokenRepository RefreshTokenRepository,
+	twoFactorChallengeRepository TwoFactorChallengeRepository,
+	transactions TransactionManager,
+	twoFactorInteractor TwoFactorInteractor,
+	loginThrottleInteractor LoginThrottleInteractor,
 	passwordHasher PasswordHasher,
 	jwtGenerator JWTGenerator,
-	sessionLength time.Duration,
+	tokenGenerator TokenGenerator,
+	mailer Mailer,
+	validator Validator,
+	config SessionConfig,
 ) SessionInteractor {
 	return &sessionInteractor{
-		userRepository: userRepository,
-		passwordHasher: passwordHasher,
-		jwtGenerator:   jwtGenerator,
-		sessionLength:  sessionLength,
+		userRepository:               userRepository,
+		oneTimeTokenRepository:       oneTimeTokenRepository,
+		sessionRepository:            sessionRepository,
+		refreshTokenRepository:       refreshTokenRepository,
+		twoFactorChallengeRepository: twoFactorChallengeRepository,
+		transactions:                 transactions,
+		twoFactorInteractor:          twoFactorInteractor,
+		loginThrottleInteractor:      loginThrottleInteractor,
+		passwordHasher:               passwordHasher,
+		jwtGenerator:                 jwtGenerator,
+		tokenGenerator:               tokenGenerator,
+		mailer:                       mailer,
+		validator:                    validator,
+		config:                       config,
 	}
 }
 
 type sessionInteractor struct {
-	userRepository UserRepository
-	passwordHasher PasswordHasher
-	jwtGenerator   JWTGenerator
-	sessionLength  time.Duration
+	userRepository               UserRepository
+	oneTimeTokenRepository       OneTimeTokenRepository
+	sessionRepository            SessionRepository
+	refreshTokenRepository       RefreshTokenRepository
+	twoFactorChallengeRepository TwoFactorChallengeRepository
+	transactions                 TransactionManager
+	twoFactorInteractor          TwoFactorInteractor
+	loginThrottleInteractor      LoginThrottleInteractor
+	passwordHasher               PasswordHasher
+	jwtGenerator                 JWTGenerator
+	tokenGenerator               TokenGenerator
+	mailer                       Mailer
+	validator                    Validator
+	config                       SessionConfig
 }
 
-func (si *sessionInteractor) CreateUser(user domain.User) error {
+func (si *sessionInteractor) CreateUser(ctx context.Context, user domain.User) error {
 	if user.ID != 0 {
 		return fail.Errorf("User with an ID (%v) could not be created.", user.ID)
 	}
 
+	if valid, violations := si.validator.Validate(user); !valid {
+		return newValidationError(ErrInvalidUser, violations)
+	}
+
 	if user.NeedsHashing() {
 		var err error
 		user.Password, err = si.passwordHasher.Hash(user.Password)
@@ -57,48 +183,532 @@ func (si *sessionInteractor) CreateUser(user domain.User) error {
 		}
 	}
 
-	err := si.userRepository.Store(&user)
+	var mail Mail
+	err := si.transactions.Run(ctx, func(ctx context.Context) error {
+		if err := si.userRepository.Store(ctx, &user); err != nil {
+			return domain.WrapError(err)
+		}
+
+		var err error
+		mail, err = si.newEmailVerification(ctx, user)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Mails can't be taken back, so they only go out once the account has been committed
+	if err := si.mailer.Send(mail); err != nil {
+		return domain.WrapError(ErrEmailVerificationNotSent, fail.WithMessage(err.Error()))
+	}
+
+	return nil
+}
+
+func (si *sessionInteractor) CreateSession(ctx context.Context, email, password string, client SessionClient) (domain.User, SessionTokens, error) {
+	user, err := si.userRepository.FindByEmail(ctx, email)
+	if err != nil && err != domain.ErrNotFound {
+		return domain.User{}, SessionTokens{}, domain.WrapError(err)
+	}
+
+	// Guessing addresses counts as much as guessing passwords, otherwise it would be free to find out who has an account
+	if user.ID == 0 {
+		return domain.User{}, SessionTokens{}, si.failLogin(ctx, email, client, ErrUserDoesNotExist)
+	}
+
+	if !si.passwordHasher.Compare(user.Password, password) {
+		return domain.User{}, SessionTokens{}, si.failLogin(ctx, email, client, ErrPasswordIncorrect)
+	}
+
+	if user.IsDisabled() {
+		return domain.User{}, SessionTokens{}, ErrUserDisabled
+	}
+
+	if si.config.RequireEmailVerification && !user.IsEmailVerified() {
+		return domain.User{}, SessionTokens{}, ErrEmailNotVerified
+	}
+
+	// This is the only moment we have the password, so old hashes get upgraded here
+	if si.passwordHasher.NeedsRehash(user.Password) {
+		if err := si.rehashPassword(ctx, user, password); err != nil {
+			return domain.User{}, SessionTokens{}, err
+		}
+	}
+
+	twoFactorEnabled, err := si.twoFactorInteractor.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return domain.User{}, SessionTokens{}, domain.WrapError(err)
+	}
+	if twoFactorEnabled {
+		tokens, err := si.startTwoFactorChallenge(ctx, user)
+		return domain.User{}, tokens, err
+	}
+
+	return si.startSession(ctx, user, client, false)
+}
+
+func (si *sessionInteractor) rehashPassword(ctx context.Context, user domain.User, password string) error {
+	hash, err := si.passwordHasher.Hash(domain.Password(password))
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	user.Password = hash
+	err = si.userRepository.UpdatePassword(ctx, &user)
+	return domain.WrapError(err)
+}
+
+func (si *sessionInteractor) startTwoFactorChallenge(ctx context.Context, user domain.User) (SessionTokens, error) {
+	token, err := si.tokenGenerator.Generate()
+	if err != nil {
+		return SessionTokens{}, domain.WrapError(err)
+	}
+
+	challenge := domain.TwoFactorChallenge{
+		UserID:    user.ID,
+		Hash:      domain.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(si.config.TwoFactorChallengeLifetime),
+	}
+	if err := si.twoFactorChallengeRepository.Store(ctx, &challenge); err != nil {
+		return SessionTokens{}, domain.WrapError(err)
+	}
+
+	return SessionTokens{ChallengeToken: token}, nil
+}
+
+// failLogin counts a failed login, being locked out takes precedence over the reason logging in failed
+func (si *sessionInteractor) failLogin(ctx context.Context, email string, client SessionClient, reason error) error {
+	if _, err := si.loginThrottleInteractor.RecordFailure(ctx, email, client.IPAddress); err != nil {
+		return err
+	}
+
+	return domain.WrapError(reason, fail.WithIgnorable())
+}
+
+func (si *sessionInteractor) CompleteTwoFactorChallenge(ctx context.Context, challengeToken, code string, client SessionClient) (domain.User, SessionTokens, time.Duration, error) {
+	challenge, err := si.twoFactorChallengeRepository.FindByHash(ctx, domain.HashToken(challengeToken))
+	if err == domain.ErrNotFound {
+		return domain.User{}, SessionTokens{}, 0, ErrTwoFactorChallengeInvalid
+	}
+	if err != nil {
+		return domain.User{}, SessionTokens{}, 0, domain.WrapError(err)
+	}
+
+	if !challenge.IsUsable(time.Now()) {
+		return domain.User{}, SessionTokens{}, 0, ErrTwoFactorChallengeInvalid
+	}
+
+	user, err := si.userRepository.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return domain.User{}, SessionTokens{}, 0, domain.WrapError(err)
+	}
+
+	// Every challenge only gets a couple of guesses, the lockout keeps anyone from starting new challenges to guess on
+	if retryAfter, err := si.loginThrottleInteractor.Check(ctx, user.Email, client.IPAddress); err != nil {
+		return domain.User{}, SessionTokens{}, retryAfter, err
+	}
+
+	err = si.twoFactorInteractor.VerifyCode(ctx, challenge.UserID, code)
+	if err == ErrTwoFactorCodeInvalid {
+		if err := si.twoFactorChallengeRepository.RecordFailedAttempt(ctx, challenge.ID); err != nil {
+			return domain.User{}, SessionTokens{}, 0, domain.WrapError(err)
+		}
+		if retryAfter, err := si.loginThrottleInteractor.RecordFailure(ctx, user.Email, client.IPAddress); err != nil {
+			return domain.User{}, SessionTokens{}, retryAfter, err
+		}
+
+		return domain.User{}, SessionTokens{}, 0, ErrTwoFactorCodeInvalid
+	}
+	if err != nil {
+		return domain.User{}, SessionTokens{}, 0, domain.WrapError(err)
+	}
+
+	err = si.twoFactorChallengeRepository.MarkAsUsed(ctx, challenge.ID)
+	if err == domain.ErrNotFound {
+		return domain.User{}, SessionTokens{}, 0, ErrTwoFactorChallengeInvalid
+	}
+	if err != nil {
+		return domain.User{}, SessionTokens{}, 0, domain.WrapError(err)
+	}
+
+	if user.IsDisabled() {
+		return domain.User{}, SessionTokens{}, 0, ErrUserDisabled
+	}
+
+	user, tokens, err := si.startSession(ctx, user, client, true)
+	return user, tokens, 0, err
+}
+
+func (si *sessionInteractor) startSession(ctx context.Context, user domain.User, client SessionClient, twoFactorVerified bool) (domain.User, SessionTokens, error) {
+	session := domain.Session{
+		UserID:            user.ID,
+		UserAgent:         client.UserAgent,
+		IPAddress:         client.IPAddress,
+		TwoFactorVerified: twoFactorVerified,
+		ExpiresAt:         time.Now().UTC().Add(si.config.SessionLength),
+	}
+	if err := si.sessionRepository.Store(ctx, &session); err != nil {
+		return domain.User{}, SessionTokens{}, domain.WrapError(err)
+	}
+
+	tokens, err := si.issueTokens(ctx, user, session)
+	if err != nil {
+		return domain.User{}, SessionTokens{}, domain.WrapError(err)
+	}
+
+	// Failed logins are only forgotten once someone actually got in
+	if err := si.loginThrottleInteractor.RecordSuccess(ctx, user.Email); err != nil {
+		return domain.User{}, SessionTokens{}, err
+	}
+
+	return user, tokens, nil
+}
+
+func (si *sessionInteractor) RefreshSession(ctx context.Context, refreshToken string, client SessionClient) (domain.User, SessionTokens, error) {
+	token, session, err := si.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return domain.User{}, SessionTokens{}, err
+	}
+
+	// A token that has already been exchanged has leaked somewhere, so nobody can be trusted with this session anymore
+	if token.IsUsed() {
+		return domain.User{}, SessionTokens{}, si.revokeReusedSession(ctx, session)
+	}
+
+	// The old token only gets used up together with handing out new ones, so a failed refresh can be retried
+	var user domain.User
+	var tokens SessionTokens
+	err = si.transactions.Run(ctx, func(ctx context.Context) error {
+		err := si.refreshTokenRepository.MarkAsUsed(ctx, token.ID)
+		if err == domain.ErrNotFound {
+			return ErrRefreshTokenReused
+		}
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		user, err = si.userRepository.FindByID(ctx, session.UserID)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+		if user.IsDisabled() {
+			return ErrUserDisabled
+		}
+
+		session.UserAgent = client.UserAgent
+		session.IPAddress = client.IPAddress
+		session.ExpiresAt = time.Now().UTC().Add(si.config.SessionLength)
+		if err := si.sessionRepository.Extend(ctx, session); err != nil {
+			return domain.WrapError(err)
+		}
+
+		tokens, err = si.issueTokens(ctx, user, session)
+		return domain.WrapError(err)
+	})
+
+	// Revoking happens after the rotation got rolled back, otherwise it would be rolled back along with it
+	if err == ErrRefreshTokenReused {
+		return domain.User{}, SessionTokens{}, si.revokeReusedSession(ctx, session)
+	}
+	if err == ErrUserDisabled {
+		if err := si.sessionRepository.Revoke(ctx, session.ID); err != nil {
+			return domain.User{}, SessionTokens{}, domain.WrapError(err)
+		}
+		return domain.User{}, SessionTokens{}, ErrUserDisabled
+	}
+	if err != nil {
+		return domain.User{}, SessionTokens{}, err
+	}
+
+	return user, tokens, nil
+}
+
+func (si *sessionInteractor) RevokeSession(ctx context.Context, refreshToken string) error {
+	_, session, err := si.findRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+
+	err = si.sessionRepository.Revoke(ctx, session.ID)
+	return domain.WrapError(err)
+}
+
+func (si *sessionInteractor) VerifySession(ctx context.Context, sessionID uint64, minRole domain.Role) (domain.User, error) {
+	session, err := si.sessionRepository.FindByID(ctx, sessionID)
+	if err == domain.ErrNotFound {
+		return domain.User{}, ErrSessionRevoked
+	}
+	if err != nil {
+		return domain.User{}, domain.WrapError(err)
+	}
+
+	if !session.IsActive(time.Now()) {
+		return domain.User{}, ErrSessionRevoked
+	}
+
+	user, err := si.userRepository.FindByID(ctx, session.UserID)
+	if err != nil {
+		return domain.User{}, domain.WrapError(err)
+	}
+	if user.IsDisabled() {
+		return domain.User{}, ErrUserDisabled
+	}
+
+	if si.config.RequireTwoFactorForAdmins && minRole >= domain.RoleAdmin && !session.TwoFactorVerified {
+		return domain.User{}, ErrTwoFactorRequired
+	}
+
+	if session.NeedsTouch(time.Now()) {
+		if err := si.sessionRepository.Touch(ctx, session.ID); err != nil {
+			return domain.User{}, domain.WrapError(err)
+		}
+	}
+
+	return user, nil
+}
+
+func (si *sessionInteractor) ActiveSessions(ctx context.Context, userID uint64) (domain.Sessions, error) {
+	sessions, err := si.sessionRepository.FindActiveByUserID(ctx, userID)
+	return sessions, domain.WrapError(err)
+}
+
+func (si *sessionInteractor) RevokeUserSession(ctx context.Context, userID, sessionID uint64) error {
+	session, err := si.sessionRepository.FindByID(ctx, sessionID)
+	if err == domain.ErrNotFound {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	// Don't give away which session ids exist for other users
+	if session.UserID != userID {
+		return ErrSessionNotFound
+	}
+
+	err = si.sessionRepository.Revoke(ctx, session.ID)
+	return domain.WrapError(err)
+}
+
+func (si *sessionInteractor) RevokeAllSessions(ctx context.Context, userID uint64) error {
+	err := si.sessionRepository.RevokeAllForUser(ctx, userID)
 	return domain.WrapError(err)
 }
 
-func (si *sessionInteractor) CreateSession(email, password string) (domain.User, string, error) {
-	user, err := si.userRepository.FindByEmail(email)
+// findRefreshToken looks up a refresh token together with its session, as long as that session is still active
+func (si *sessionInteractor) findRefreshToken(ctx context.Context, refreshToken string) (domain.RefreshToken, domain.Session, error) {
+	token, err := si.refreshTokenRepository.FindByHash(ctx, domain.HashToken(refreshToken))
+	if err == domain.ErrNotFound {
+		return domain.RefreshToken{}, domain.Session{}, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return domain.RefreshToken{}, domain.Session{}, domain.WrapError(err)
+	}
+
+	session, err := si.sessionRepository.FindByID(ctx, token.SessionID)
+	if err == domain.ErrNotFound {
+		return domain.RefreshToken{}, domain.Session{}, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return domain.RefreshToken{}, domain.Session{}, domain.WrapError(err)
+	}
+
+	if !session.IsActive(time.Now()) {
+		return domain.RefreshToken{}, domain.Session{}, ErrRefreshTokenInvalid
+	}
+
+	return token, session, nil
+}
+
+func (si *sessionInteractor) revokeReusedSession(ctx context.Context, session domain.Session) error {
+	if err := si.sessionRepository.Revoke(ctx, session.ID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	return ErrRefreshTokenReused
+}
+
+// issueTokens creates a new refresh token for the session along with a fresh access token
+func (si *sessionInteractor) issueTokens(ctx context.Context, user domain.User, session domain.Session) (SessionTokens, error) {
+	refreshToken, err := si.tokenGenerator.Generate()
 	if err != nil {
-		return domain.User{}, "", domain.WrapError(err)
+		return SessionTokens{}, domain.WrapError(err)
+	}
+
+	token := domain.RefreshToken{
+		SessionID: session.ID,
+		Hash:      domain.HashToken(refreshToken),
+	}
+	if err := si.refreshTokenRepository.Store(ctx, &token); err != nil {
+		return SessionTokens{}, domain.WrapError(err)
+	}
+
+	claims := SessionClaims{User: &user, SessionID: session.ID}
+	accessToken, err := si.jwtGenerator.NewToken(si.config.AccessTokenLifetime, claims)
+	if err != nil {
+		return SessionTokens{}, domain.WrapError(err)
+	}
+
+	return SessionTokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+func (si *sessionInteractor) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := si.userRepository.FindByEmail(ctx, email)
+	if err != nil && err != domain.ErrNotFound {
+		return domain.WrapError(err)
 	}
 
 	if user.ID == 0 {
-		return domain.User{}, "", domain.WrapError(ErrUserDoesNotExist, fail.WithIgnorable())
+		return ErrUserDoesNotExist
 	}
 
-	if !si.passwordHasher.Compare(user.Password, password) {
-		return domain.User{}, "", domain.WrapError(ErrPasswordIncorrect, fail.WithIgnorable())
+	token, err := si.tokenGenerator.Generate()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	resetToken := domain.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposePasswordReset,
+		Hash:      domain.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(si.config.PasswordResetLifetime),
+	}
+	if err := si.oneTimeTokenRepository.Store(ctx, &resetToken); err != nil {
+		return domain.WrapError(err)
+	}
+
+	link := tokenLink(si.config.FrontendURL, "/password_reset", token)
+	err = si.mailer.Send(newPasswordResetMail(user, link, si.config.PasswordResetLifetime))
+
+	return domain.WrapError(err)
+}
+
+func (si *sessionInteractor) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	if valid, violations := si.validator.Validate(domain.Password(newPassword)); !valid {
+		return newValidationError(ErrInvalidNewPassword, violations)
+	}
+
+	resetToken, err := si.oneTimeTokenRepository.FindByHash(ctx, domain.TokenPurposePasswordReset, domain.HashToken(token))
+	if err == domain.ErrNotFound {
+		return ErrPasswordResetTokenInvalid
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if !resetToken.IsUsable(time.Now()) {
+		return ErrPasswordResetTokenInvalid
 	}
 
-	claims := SessionClaims{User: &user}
-	token, err := si.jwtGenerator.NewToken(si.sessionLength, claims)
+	hash, err := si.passwordHasher.Hash(domain.Password(newPassword))
 	if err != nil {
-		return domain.User{}, "", domain.WrapError(err)
+		return domain.WrapError(err)
 	}
 
-	return user, token, nil
+	// The token only gets used up when the new password has been stored as well
+	return si.transactions.Run(ctx, func(ctx context.Context) error {
+		// Claim the token before touching the password so concurrent requests can't use it twice
+		err := si.oneTimeTokenRepository.MarkAsUsed(ctx, resetToken.ID)
+		if err == domain.ErrNotFound {
+			return ErrPasswordResetTokenInvalid
+		}
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		user, err := si.userRepository.FindByID(ctx, resetToken.UserID)
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		user.Password = hash
+		if err := si.userRepository.UpdatePassword(ctx, &user); err != nil {
+			return domain.WrapError(err)
+		}
+
+		if err := si.oneTimeTokenRepository.InvalidateAllForUser(ctx, domain.TokenPurposePasswordReset, user.ID); err != nil {
+			return domain.WrapError(err)
+		}
+
+		// Whoever knew the old password shouldn't be able to stay logged in
+		err = si.sessionRepository.RevokeAllForUser(ctx, user.ID)
+		return domain.WrapError(err)
+	})
 }
 
-func (si *sessionInteractor) RefreshSession(user domain.User) (domain.User, string, error) {
-	user, err := si.userRepository.FindByEmail(user.Email)
+func (si *sessionInteractor) VerifyEmail(ctx context.Context, token string) error {
+	verificationToken, err := si.oneTimeTokenRepository.FindByHash(ctx, domain.TokenPurposeEmailVerification, domain.HashToken(token))
+	if err == domain.ErrNotFound {
+		return ErrEmailVerificationTokenInvalid
+	}
 	if err != nil {
-		return domain.User{}, "", domain.WrapError(err)
+		return domain.WrapError(err)
+	}
+
+	if !verificationToken.IsUsable(time.Now()) {
+		return ErrEmailVerificationTokenInvalid
+	}
+
+	// The token only gets used up when the email address has been marked as verified as well
+	return si.transactions.Run(ctx, func(ctx context.Context) error {
+		err := si.oneTimeTokenRepository.MarkAsUsed(ctx, verificationToken.ID)
+		if err == domain.ErrNotFound {
+			return ErrEmailVerificationTokenInvalid
+		}
+		if err != nil {
+			return domain.WrapError(err)
+		}
+
+		err = si.userRepository.MarkEmailAsVerified(ctx, verificationToken.UserID)
+		return domain.WrapError(err)
+	})
+}
+
+func (si *sessionInteractor) ResendEmailVerification(ctx context.Context, email string) error {
+	user, err := si.userRepository.FindByEmail(ctx, email)
+	if err != nil && err != domain.ErrNotFound {
+		return domain.WrapError(err)
 	}
 
 	if user.ID == 0 {
-		return domain.User{}, "", domain.WrapError(ErrUserDoesNotExist, fail.WithIgnorable())
+		return ErrUserDoesNotExist
+	}
+
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
 	}
 
-	claims := SessionClaims{User: &user}
-	token, err := si.jwtGenerator.NewToken(si.sessionLength, claims)
+	// Only the most recent link should work so older mails can't be dug up later on
+	if err := si.oneTimeTokenRepository.InvalidateAllForUser(ctx, domain.TokenPurposeEmailVerification, user.ID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	mail, err := si.newEmailVerification(ctx, user)
 	if err != nil {
-		return domain.User{}, "", domain.WrapError(err)
+		return err
+	}
+
+	err = si.mailer.Send(mail)
+	return domain.WrapError(err)
+}
+
+// newEmailVerification stores a token to verify the email address of the user with, and returns the mail with its link
+func (si *sessionInteractor) newEmailVerification(ctx context.Context, user domain.User) (Mail, error) {
+	token, err := si.tokenGenerator.Generate()
+	if err != nil {
+		return Mail{}, domain.WrapError(err)
+	}
+
+	verificationToken := domain.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposeEmailVerification,
+		Hash:      domain.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(si.config.EmailVerificationLifetime),
+	}
+	if err := si.oneTimeTokenRepository.Store(ctx, &verificationToken); err != nil {
+		return Mail{}, domain.WrapError(err)
 	}
 
-	return user, token, nil
+	link := tokenLink(si.config.FrontendURL, "/verify_email", token)
+	return newEmailVerificationMail(user, link, si.config.EmailVerificationLifetime), nil
 }
@@ -5,9 +5,11 @@
 package usecases
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/tadoku/api/domain"
 	reflect "reflect"
+	time "time"
 )
 
 // MockSessionInteractor is a mock of SessionInteractor interface
@@ -34,47 +36,192 @@ func (m *MockSessionInteractor) EXPECT() *MockSessionInteractorMockRecorder {
 }
 
 // CreateUser mocks base method
-func (m *MockSessionInteractor) CreateUser(user domain.User) error {
+func (m *MockSessionInteractor) CreateUser(ctx context.Context, user domain.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", user)
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateUser indicates an expected call of CreateUser
-func (mr *MockSessionInteractorMockRecorder) CreateUser(user interface{}) *gomock.Call {
+func (mr *MockSessionInteractorMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockSessionInteractor)(nil).CreateUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockSessionInteractor)(nil).CreateUser), ctx, user)
 }
 
 // CreateSession mocks base method
-func (m *MockSessionInteractor) CreateSession(email, password string) (domain.User, string, error) {
+func (m *MockSessionInteractor) CreateSession(ctx context.Context, email, password string, client SessionClient) (domain.User, SessionTokens, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", email, password)
+	ret := m.ctrl.Call(m, "CreateSession", ctx, email, password, client)
 	ret0, _ := ret[0].(domain.User)
-	ret1, _ := ret[1].(string)
+	ret1, _ := ret[1].(SessionTokens)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
 // CreateSession indicates an expected call of CreateSession
-func (mr *MockSessionInteractorMockRecorder) CreateSession(email, password interface{}) *gomock.Call {
+func (mr *MockSessionInteractorMockRecorder) CreateSession(ctx, email, password, client interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionInteractor)(nil).CreateSession), email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockSessionInteractor)(nil).CreateSession), ctx, email, password, client)
+}
+
+// CompleteTwoFactorChallenge mocks base method
+func (m *MockSessionInteractor) CompleteTwoFactorChallenge(ctx context.Context, challengeToken, code string, client SessionClient) (domain.User, SessionTokens, time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTwoFactorChallenge", ctx, challengeToken, code, client)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(SessionTokens)
+	ret2, _ := ret[2].(time.Duration)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// CompleteTwoFactorChallenge indicates an expected call of CompleteTwoFactorChallenge
+func (mr *MockSessionInteractorMockRecorder) CompleteTwoFactorChallenge(ctx, challengeToken, code, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTwoFactorChallenge", reflect.TypeOf((*MockSessionInteractor)(nil).CompleteTwoFactorChallenge), ctx, challengeToken, code, client)
 }
 
 // RefreshSession mocks base method
-func (m *MockSessionInteractor) RefreshSession(user domain.User) (domain.User, string, error) {
+func (m *MockSessionInteractor) RefreshSession(ctx context.Context, refreshToken string, client SessionClient) (domain.User, SessionTokens, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RefreshSession", user)
+	ret := m.ctrl.Call(m, "RefreshSession", ctx, refreshToken, client)
 	ret0, _ := ret[0].(domain.User)
-	ret1, _ := ret[1].(string)
+	ret1, _ := ret[1].(SessionTokens)
 	ret2, _ := ret[2].(error)
 	return ret0, ret1, ret2
 }
 
 // RefreshSession indicates an expected call of RefreshSession
-func (mr *MockSessionInteractorMockRecorder) RefreshSession(user interface{}) *gomock.Call {
+func (mr *MockSessionInteractorMockRecorder) RefreshSession(ctx, refreshToken, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockSessionInteractor)(nil).RefreshSession), ctx, refreshToken, client)
+}
+
+// VerifySession mocks base method
+func (m *MockSessionInteractor) VerifySession(ctx context.Context, sessionID uint64, minRole domain.Role) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifySession", ctx, sessionID, minRole)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifySession indicates an expected call of VerifySession
+func (mr *MockSessionInteractorMockRecorder) VerifySession(ctx, sessionID, minRole interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySession", reflect.TypeOf((*MockSessionInteractor)(nil).VerifySession), ctx, sessionID, minRole)
+}
+
+// ActiveSessions mocks base method
+func (m *MockSessionInteractor) ActiveSessions(ctx context.Context, userID uint64) (domain.Sessions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveSessions", ctx, userID)
+	ret0, _ := ret[0].(domain.Sessions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActiveSessions indicates an expected call of ActiveSessions
+func (mr *MockSessionInteractorMockRecorder) ActiveSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveSessions", reflect.TypeOf((*MockSessionInteractor)(nil).ActiveSessions), ctx, userID)
+}
+
+// RevokeSession mocks base method
+func (m *MockSessionInteractor) RevokeSession(ctx context.Context, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession
+func (mr *MockSessionInteractorMockRecorder) RevokeSession(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockSessionInteractor)(nil).RevokeSession), ctx, refreshToken)
+}
+
+// RevokeUserSession mocks base method
+func (m *MockSessionInteractor) RevokeUserSession(ctx context.Context, userID, sessionID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserSession", ctx, userID, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserSession indicates an expected call of RevokeUserSession
+func (mr *MockSessionInteractorMockRecorder) RevokeUserSession(ctx, userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserSession", reflect.TypeOf((*MockSessionInteractor)(nil).RevokeUserSession), ctx, userID, sessionID)
+}
+
+// RevokeAllSessions mocks base method
+func (m *MockSessionInteractor) RevokeAllSessions(ctx context.Context, userID uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllSessions", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllSessions indicates an expected call of RevokeAllSessions
+func (mr *MockSessionInteractorMockRecorder) RevokeAllSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllSessions", reflect.TypeOf((*MockSessionInteractor)(nil).RevokeAllSessions), ctx, userID)
+}
+
+// RequestPasswordReset mocks base method
+func (m *MockSessionInteractor) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset
+func (mr *MockSessionInteractorMockRecorder) RequestPasswordReset(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockSessionInteractor)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResetPassword mocks base method
+func (m *MockSessionInteractor) ResetPassword(ctx context.Context, token, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword
+func (mr *MockSessionInteractorMockRecorder) ResetPassword(ctx, token, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockSessionInteractor)(nil).ResetPassword), ctx, token, newPassword)
+}
+
+// VerifyEmail mocks base method
+func (m *MockSessionInteractor) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail
+func (mr *MockSessionInteractorMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockSessionInteractor)(nil).VerifyEmail), ctx, token)
+}
+
+// ResendEmailVerification mocks base method
+func (m *MockSessionInteractor) ResendEmailVerification(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResendEmailVerification", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResendEmailVerification indicates an expected call of ResendEmailVerification
+func (mr *MockSessionInteractorMockRecorder) ResendEmailVerification(ctx, email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSession", reflect.TypeOf((*MockSessionInteractor)(nil).RefreshSession), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResendEmailVerification", reflect.TypeOf((*MockSessionInteractor)(nil).ResendEmailVerification), ctx, email)
 }
@@ -1,6 +1,8 @@
 package usecases_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -11,30 +13,77 @@ import (
 	gomock "github.com/golang/mock/gomock"
 )
 
-var sessionLength = time.Hour * 1
+var accessTokenLifetime = time.Minute * 15
 
-func setupSessionTest(t *testing.T) (
+var sessionClient = usecases.SessionClient{UserAgent: "Mozilla/5.0", IPAddress: "127.0.0.1"}
+
+var sessionConfig = usecases.SessionConfig{
+	SessionLength:             time.Hour * 1,
+	AccessTokenLifetime:       accessTokenLifetime,
+	PasswordResetLifetime:     time.Hour * 1,
+	EmailVerificationLifetime: time.Hour * 72,
+	FrontendURL:               "https://tadoku.app",
+}
+
+type sessionTestMocks struct {
+	userRepo      *usecases.MockUserRepository
+	tokenRepo     *usecases.MockOneTimeTokenRepository
+	sessionRepo   *usecases.MockSessionRepository
+	refreshRepo   *usecases.MockRefreshTokenRepository
+	challengeRepo *usecases.MockTwoFactorChallengeRepository
+	twoFactor     *usecases.MockTwoFactorInteractor
+	loginThrottle *usecases.MockLoginThrottleInteractor
+	pwHasher      *usecases.MockPasswordHasher
+	jwtGen        *usecases.MockJWTGenerator
+	tokenGen      *usecases.MockTokenGenerator
+	mailer        *usecases.MockMailer
+	validator     *usecases.MockValidator
+}
+
+func setupSessionTest(t *testing.T, config usecases.SessionConfig) (
 	*gomock.Controller,
-	*usecases.MockUserRepository,
-	*usecases.MockPasswordHasher,
-	*usecases.MockJWTGenerator,
+	*sessionTestMocks,
 	usecases.SessionInteractor,
 ) {
 	ctrl := gomock.NewController(t)
 
-	repo := usecases.NewMockUserRepository(ctrl)
-	pwHasher := usecases.NewMockPasswordHasher(ctrl)
-	jwtGen := usecases.NewMockJWTGenerator(ctrl)
+	m := &sessionTestMocks{
+		userRepo:      usecases.NewMockUserRepository(ctrl),
+		tokenRepo:     usecases.NewMockOneTimeTokenRepository(ctrl),
+		sessionRepo:   usecases.NewMockSessionRepository(ctrl),
+		refreshRepo:   usecases.NewMockRefreshTokenRepository(ctrl),
+		challengeRepo: usecases.NewMockTwoFactorChallengeRepository(ctrl),
+		twoFactor:     usecases.NewMockTwoFactorInteractor(ctrl),
+		loginThrottle: usecases.NewMockLoginThrottleInteractor(ctrl),
+		pwHasher:      usecases.NewMockPasswordHasher(ctrl),
+		jwtGen:        usecases.NewMockJWTGenerator(ctrl),
+		tokenGen:      usecases.NewMockTokenGenerator(ctrl),
+		mailer:        usecases.NewMockMailer(ctrl),
+		validator:     usecases.NewMockValidator(ctrl),
+	}
 
 	interactor := usecases.NewSessionInteractor(
-		repo, pwHasher, jwtGen, sessionLength,
+		m.userRepo,
+		m.tokenRepo,
+		m.sessionRepo,
+		m.refreshRepo,
+		m.challengeRepo,
+		newTransactionManager(ctrl),
+		m.twoFactor,
+		m.loginThrottle,
+		m.pwHasher,
+		m.jwtGen,
+		m.tokenGen,
+		m.mailer,
+		m.validator,
+		config,
 	)
 
-	return ctrl, repo, pwHasher, jwtGen, interactor
+	return ctrl, m, interactor
 }
 
 func TestSessionInteractor_CreateUser(t *testing.T) {
-	ctrl, repo, pwHasher, _, interactor := setupSessionTest(t)
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
 	defer ctrl.Finish()
 
 	user := domain.User{
@@ -45,62 +94,732 @@ func TestSessionInteractor_CreateUser(t *testing.T) {
 	hashedUser := user
 	hashedUser.Password = "barbar"
 
-	pwHasher.EXPECT().Hash(user.Password).Return(hashedUser.Password, nil)
-	repo.EXPECT().Store(&hashedUser)
+	m.validator.EXPECT().Validate(user).Return(true, nil)
+	m.pwHasher.EXPECT().Hash(user.Password).Return(hashedUser.Password, nil)
+	m.userRepo.EXPECT().Store(gomock.Any(), &hashedUser).Do(func(_ context.Context, user *domain.User) { user.ID = 1 })
+	m.tokenGen.EXPECT().Generate().Return("token", nil)
+	m.tokenRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token *domain.OneTimeToken) error {
+		assert.Equal(t, uint64(1), token.UserID)
+		assert.Equal(t, domain.HashToken("token"), token.Hash)
+		return nil
+	})
+	m.mailer.EXPECT().Send(gomock.Any()).DoAndReturn(func(mail usecases.Mail) error {
+		assert.Equal(t, user.Email, mail.To)
+		assert.Contains(t, mail.Body, "https://tadoku.app/verify_email?token=token")
+		return nil
+	})
 
-	err := interactor.CreateUser(user)
+	err := interactor.CreateUser(context.Background(), user)
 
 	assert.NoError(t, err)
+
+	{
+		// Sad path: the account stays when its verification mail can't be sent
+		user := domain.User{Email: "bar@bar.com", DisplayName: "Jane Doe", Password: "barbar"}
+		m.validator.EXPECT().Validate(user).Return(true, nil)
+		m.pwHasher.EXPECT().Hash(user.Password).Return(domain.Password("hashed"), nil)
+		m.userRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		m.tokenGen.EXPECT().Generate().Return("token", nil)
+		m.tokenRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		m.mailer.EXPECT().Send(gomock.Any()).Return(errors.New("connection refused"))
+
+		err := interactor.CreateUser(context.Background(), user)
+		assert.EqualError(t, err, "connection refused: "+usecases.ErrEmailVerificationNotSent.Error())
+	}
+
+	// Sad path: the password is too short
+	{
+		user := domain.User{Email: "foo@bar.com", DisplayName: "John Doe", Password: "foo"}
+		m.validator.EXPECT().Validate(user).Return(false, domain.FieldErrors{domain.NewFieldError("password", "min_length", domain.ErrPasswordTooShort)})
+
+		err := interactor.CreateUser(context.Background(), user)
+		assert.EqualError(t, err, usecases.ErrInvalidUser.Error())
+	}
 }
 
 func TestSessionInteractor_CreateSession(t *testing.T) {
-	ctrl, repo, pwHasher, jwtGen, interactor := setupSessionTest(t)
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
 	defer ctrl.Finish()
 
 	{
 		// Happy path: valid user
 		dbUser := domain.User{ID: 1, Email: "foo@bar.com", Password: "foobar"}
-		repo.EXPECT().FindByEmail("foo@bar.com").Return(dbUser, nil)
-		pwHasher.EXPECT().Compare(dbUser.Password, "foobar").Return(true)
-		jwtGen.EXPECT().NewToken(sessionLength, usecases.SessionClaims{User: &dbUser}).Return("token", nil)
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(dbUser, nil)
+		m.pwHasher.EXPECT().Compare(dbUser.Password, "foobar").Return(true)
+		m.loginThrottle.EXPECT().RecordSuccess(gomock.Any(), "foo@bar.com").Return(nil)
+		m.pwHasher.EXPECT().NeedsRehash(dbUser.Password).Return(false)
+		m.twoFactor.EXPECT().IsEnabled(gomock.Any(), dbUser.ID).Return(false, nil)
+		m.sessionRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, session *domain.Session) error {
+			assert.Equal(t, dbUser.ID, session.UserID)
+			assert.Equal(t, sessionClient.UserAgent, session.UserAgent)
+			assert.Equal(t, sessionClient.IPAddress, session.IPAddress)
+			assert.True(t, session.ExpiresAt.After(time.Now()))
+			session.ID = 2
+			return nil
+		})
+		m.tokenGen.EXPECT().Generate().Return("refresh", nil)
+		m.refreshRepo.EXPECT().Store(gomock.Any(), &domain.RefreshToken{SessionID: 2, Hash: domain.HashToken("refresh")}).Return(nil)
+		m.jwtGen.EXPECT().NewToken(accessTokenLifetime, usecases.SessionClaims{User: &dbUser, SessionID: 2}).Return("token", nil)
 
-		sessionUser, token, err := interactor.CreateSession("foo@bar.com", "foobar")
+		sessionUser, tokens, err := interactor.CreateSession(context.Background(), "foo@bar.com", "foobar", sessionClient)
 		assert.NoError(t, err)
 		assert.Equal(t, sessionUser, dbUser)
-		assert.Equal(t, token, "token")
+		assert.Equal(t, usecases.SessionTokens{AccessToken: "token", RefreshToken: "refresh"}, tokens)
+	}
+
+	{
+		// Happy path: outdated password hash gets upgraded
+		dbUser := domain.User{ID: 1, Email: "foo@bar.com", Password: "oldhash"}
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(dbUser, nil)
+		m.pwHasher.EXPECT().Compare(dbUser.Password, "foobar").Return(true)
+		m.loginThrottle.EXPECT().RecordSuccess(gomock.Any(), "foo@bar.com").Return(nil)
+		m.pwHasher.EXPECT().NeedsRehash(dbUser.Password).Return(true)
+		m.pwHasher.EXPECT().Hash(domain.Password("foobar")).Return(domain.Password("newhash"), nil)
+		m.userRepo.EXPECT().UpdatePassword(gomock.Any(), &domain.User{ID: 1, Email: "foo@bar.com", Password: "newhash"}).Return(nil)
+		m.twoFactor.EXPECT().IsEnabled(gomock.Any(), dbUser.ID).Return(false, nil)
+		m.sessionRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		m.tokenGen.EXPECT().Generate().Return("refresh", nil)
+		m.refreshRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		m.jwtGen.EXPECT().NewToken(accessTokenLifetime, gomock.Any()).Return("token", nil)
+
+		_, tokens, err := interactor.CreateSession(context.Background(), "foo@bar.com", "foobar", sessionClient)
+		assert.NoError(t, err)
+		assert.Equal(t, "token", tokens.AccessToken)
 	}
 
 	{
 		// Sad path: user does not exist
-		repo.EXPECT().FindByEmail("bar@bar.com").Return(domain.User{}, nil)
-		_, _, err := interactor.CreateSession("bar@bar.com", "foobar")
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "bar@bar.com").Return(domain.User{}, domain.ErrNotFound)
+		m.loginThrottle.EXPECT().RecordFailure(gomock.Any(), "bar@bar.com", sessionClient.IPAddress).Return(time.Duration(0), nil)
+		_, _, err := interactor.CreateSession(context.Background(), "bar@bar.com", "foobar", sessionClient)
 		assert.EqualError(t, err, usecases.ErrUserDoesNotExist.Error())
 	}
 
+	{
+		// Sad path: user has been disabled
+		disabledUser := domain.User{ID: 1, Email: "foo@bar.com", Password: "foobar", Role: domain.RoleDisabled}
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(disabledUser, nil)
+		m.pwHasher.EXPECT().Compare(disabledUser.Password, "foobar").Return(true)
+
+		_, _, err := interactor.CreateSession(context.Background(), "foo@bar.com", "foobar", sessionClient)
+		assert.EqualError(t, err, usecases.ErrUserDisabled.Error())
+	}
+
 	{
 		// Sad path: password is incorrect
 		user := domain.User{ID: 1, Email: "foo@bar.com", Password: "barbar"}
-		repo.EXPECT().FindByEmail("foo@bar.com").Return(user, nil)
-		pwHasher.EXPECT().Compare(user.Password, "foobar").Return(false)
-		_, _, err := interactor.CreateSession("foo@bar.com", "foobar")
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(user, nil)
+		m.pwHasher.EXPECT().Compare(user.Password, "foobar").Return(false)
+		m.loginThrottle.EXPECT().RecordFailure(gomock.Any(), "foo@bar.com", sessionClient.IPAddress).Return(time.Duration(0), nil)
+		_, _, err := interactor.CreateSession(context.Background(), "foo@bar.com", "foobar", sessionClient)
 		assert.EqualError(t, err, usecases.ErrPasswordIncorrect.Error())
 	}
+
+	{
+		// Sad path: the incorrect password locks the account
+		user := domain.User{ID: 1, Email: "foo@bar.com", Password: "barbar"}
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(user, nil)
+		m.pwHasher.EXPECT().Compare(user.Password, "foobar").Return(false)
+		m.loginThrottle.EXPECT().RecordFailure(gomock.Any(), "foo@bar.com", sessionClient.IPAddress).Return(time.Minute, usecases.ErrLoginThrottled)
+		_, _, err := interactor.CreateSession(context.Background(), "foo@bar.com", "foobar", sessionClient)
+		assert.EqualError(t, err, usecases.ErrLoginThrottled.Error())
+	}
 }
 
 func TestSessionInteractor_RefreshSession(t *testing.T) {
-	ctrl, repo, _, jwtGen, interactor := setupSessionTest(t)
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
 	defer ctrl.Finish()
 
+	activeSession := domain.Session{ID: 2, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+
 	{
-		user := domain.User{ID: 1, DisplayName: "foo", Email: "foo@bar.com", Password: "foobar"}
-		dbUser := domain.User{ID: 1, DisplayName: "bar", Email: "foo@bar.com", Password: "foobar"}
+		// Happy path: token gets rotated and session extended
+		dbUser := domain.User{ID: 1, DisplayName: "bar", Email: "foo@bar.com"}
+		token := domain.RefreshToken{ID: 3, SessionID: activeSession.ID, Hash: domain.HashToken("refresh")}
 
-		repo.EXPECT().FindByEmail("foo@bar.com").Return(dbUser, nil)
-		jwtGen.EXPECT().NewToken(sessionLength, usecases.SessionClaims{User: &dbUser}).Return("token", nil)
+		m.refreshRepo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("refresh")).Return(token, nil)
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), activeSession.ID).Return(activeSession, nil)
+		m.refreshRepo.EXPECT().MarkAsUsed(gomock.Any(), token.ID).Return(nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), dbUser.ID).Return(dbUser, nil)
+		m.sessionRepo.EXPECT().Extend(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, session domain.Session) error {
+			assert.Equal(t, activeSession.ID, session.ID)
+			assert.Equal(t, sessionClient.IPAddress, session.IPAddress)
+			assert.True(t, session.ExpiresAt.After(activeSession.ExpiresAt))
+			return nil
+		})
+		m.tokenGen.EXPECT().Generate().Return("refresh2", nil)
+		m.refreshRepo.EXPECT().Store(gomock.Any(), &domain.RefreshToken{SessionID: activeSession.ID, Hash: domain.HashToken("refresh2")}).Return(nil)
+		m.jwtGen.EXPECT().NewToken(accessTokenLifetime, usecases.SessionClaims{User: &dbUser, SessionID: activeSession.ID}).Return("token", nil)
 
-		sessionUser, token, err := interactor.RefreshSession(user)
+		sessionUser, tokens, err := interactor.RefreshSession(context.Background(), "refresh", sessionClient)
 		assert.NoError(t, err)
 		assert.Equal(t, sessionUser, dbUser)
-		assert.Equal(t, token, "token")
+		assert.Equal(t, usecases.SessionTokens{AccessToken: "token", RefreshToken: "refresh2"}, tokens)
+	}
+
+	{
+		// Sad path: user has been disabled, session gets revoked
+		disabledUser := domain.User{ID: 1, Role: domain.RoleDisabled}
+		token := domain.RefreshToken{ID: 3, SessionID: activeSession.ID, Hash: domain.HashToken("refresh")}
+
+		m.refreshRepo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("refresh")).Return(token, nil)
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), activeSession.ID).Return(activeSession, nil)
+		m.refreshRepo.EXPECT().MarkAsUsed(gomock.Any(), token.ID).Return(nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), disabledUser.ID).Return(disabledUser, nil)
+		m.sessionRepo.EXPECT().Revoke(gomock.Any(), activeSession.ID).Return(nil)
+
+		_, _, err := interactor.RefreshSession(context.Background(), "refresh", sessionClient)
+		assert.EqualError(t, err, usecases.ErrUserDisabled.Error())
+	}
+
+	{
+		// Sad path: token has been used before, whole session gets revoked
+		usedAt := time.Now().Add(-1 * time.Minute)
+		token := domain.RefreshToken{ID: 3, SessionID: activeSession.ID, UsedAt: &usedAt}
+
+		m.refreshRepo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("refresh")).Return(token, nil)
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), activeSession.ID).Return(activeSession, nil)
+		m.sessionRepo.EXPECT().Revoke(gomock.Any(), activeSession.ID).Return(nil)
+
+		_, _, err := interactor.RefreshSession(context.Background(), "refresh", sessionClient)
+		assert.EqualError(t, err, usecases.ErrRefreshTokenReused.Error())
+	}
+
+	{
+		// Sad path: token got used concurrently, whole session gets revoked
+		token := domain.RefreshToken{ID: 3, SessionID: activeSession.ID}
+
+		m.refreshRepo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("refresh")).Return(token, nil)
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), activeSession.ID).Return(activeSession, nil)
+		m.refreshRepo.EXPECT().MarkAsUsed(gomock.Any(), token.ID).Return(domain.ErrNotFound)
+		m.sessionRepo.EXPECT().Revoke(gomock.Any(), activeSession.ID).Return(nil)
+
+		_, _, err := interactor.RefreshSession(context.Background(), "refresh", sessionClient)
+		assert.EqualError(t, err, usecases.ErrRefreshTokenReused.Error())
+	}
+
+	{
+		// Sad path: new tokens can't be stored, the session stays so the refresh can be retried
+		dbUser := domain.User{ID: 1, DisplayName: "bar", Email: "foo@bar.com"}
+		token := domain.RefreshToken{ID: 3, SessionID: activeSession.ID}
+		errFailed := errors.New("connection reset")
+
+		m.refreshRepo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("refresh")).Return(token, nil)
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), activeSession.ID).Return(activeSession, nil)
+		m.refreshRepo.EXPECT().MarkAsUsed(gomock.Any(), token.ID).Return(nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), dbUser.ID).Return(dbUser, nil)
+		m.sessionRepo.EXPECT().Extend(gomock.Any(), gomock.Any()).Return(nil)
+		m.tokenGen.EXPECT().Generate().Return("refresh2", nil)
+		m.refreshRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(errFailed)
+
+		_, _, err := interactor.RefreshSession(context.Background(), "refresh", sessionClient)
+		assert.EqualError(t, err, errFailed.Error())
+	}
+
+	{
+		// Sad path: session has been revoked
+		revokedAt := time.Now().Add(-1 * time.Minute)
+		revokedSession := domain.Session{ID: 2, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+		token := domain.RefreshToken{ID: 3, SessionID: revokedSession.ID}
+
+		m.refreshRepo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("refresh")).Return(token, nil)
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), revokedSession.ID).Return(revokedSession, nil)
+
+		_, _, err := interactor.RefreshSession(context.Background(), "refresh", sessionClient)
+		assert.EqualError(t, err, usecases.ErrRefreshTokenInvalid.Error())
+	}
+
+	{
+		// Sad path: token does not exist
+		m.refreshRepo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("unknown")).Return(domain.RefreshToken{}, domain.ErrNotFound)
+
+		_, _, err := interactor.RefreshSession(context.Background(), "unknown", sessionClient)
+		assert.EqualError(t, err, usecases.ErrRefreshTokenInvalid.Error())
+	}
+}
+
+func TestSessionInteractor_RevokeSession(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	session := domain.Session{ID: 2, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+	token := domain.RefreshToken{ID: 3, SessionID: session.ID}
+
+	m.refreshRepo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("refresh")).Return(token, nil)
+	m.sessionRepo.EXPECT().FindByID(gomock.Any(), session.ID).Return(session, nil)
+	m.sessionRepo.EXPECT().Revoke(gomock.Any(), session.ID).Return(nil)
+
+	err := interactor.RevokeSession(context.Background(), "refresh")
+	assert.NoError(t, err)
+}
+
+func TestSessionInteractor_VerifySession(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	revokedAt := time.Now().Add(-1 * time.Minute)
+	user := domain.User{ID: 2, Role: domain.RoleUser}
+
+	{
+		// Happy path: session is still active and gets marked as seen
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), uint64(1)).Return(domain.Session{ID: 1, UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		m.sessionRepo.EXPECT().Touch(gomock.Any(), uint64(1)).Return(nil)
+
+		currentUser, err := interactor.VerifySession(context.Background(), 1, domain.RoleUser)
+		assert.NoError(t, err)
+		assert.Equal(t, user, currentUser)
+	}
+
+	{
+		// Happy path: sessions that were seen a moment ago aren't written to again
+		session := domain.Session{ID: 1, UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now().Add(-1 * time.Minute)}
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), uint64(1)).Return(session, nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+
+		_, err := interactor.VerifySession(context.Background(), 1, domain.RoleUser)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: user has been disabled since the token was issued
+		disabled := user
+		disabled.Role = domain.RoleDisabled
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), uint64(1)).Return(domain.Session{ID: 1, UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(disabled, nil)
+
+		_, err := interactor.VerifySession(context.Background(), 1, domain.RoleUser)
+		assert.EqualError(t, err, usecases.ErrUserDisabled.Error())
+	}
+
+	{
+		// Sad path: session has been revoked
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), uint64(1)).Return(domain.Session{ID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}, nil)
+
+		_, err := interactor.VerifySession(context.Background(), 1, domain.RoleUser)
+		assert.EqualError(t, err, usecases.ErrSessionRevoked.Error())
+	}
+
+	{
+		// Sad path: token was issued without a session
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), uint64(0)).Return(domain.Session{}, domain.ErrNotFound)
+
+		_, err := interactor.VerifySession(context.Background(), 0, domain.RoleUser)
+		assert.EqualError(t, err, usecases.ErrSessionRevoked.Error())
+	}
+}
+
+func TestSessionInteractor_VerifySessionWithRequiredTwoFactor(t *testing.T) {
+	config := sessionConfig
+	config.RequireTwoFactorForAdmins = true
+
+	ctrl, m, interactor := setupSessionTest(t, config)
+	defer ctrl.Finish()
+
+	admin := domain.User{ID: 2, Role: domain.RoleAdmin}
+	session := domain.Session{ID: 1, UserID: admin.ID, ExpiresAt: time.Now().Add(time.Hour), LastSeenAt: time.Now()}
+	m.userRepo.EXPECT().FindByID(gomock.Any(), admin.ID).Return(admin, nil).Times(3)
+
+	{
+		// Happy path: regular routes don't need two factor authentication
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), session.ID).Return(session, nil)
+
+		_, err := interactor.VerifySession(context.Background(), session.ID, domain.RoleUser)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Happy path: admin routes with a verified session
+		verified := session
+		verified.TwoFactorVerified = true
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), session.ID).Return(verified, nil)
+
+		_, err := interactor.VerifySession(context.Background(), session.ID, domain.RoleAdmin)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: admin routes without two factor authentication
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), session.ID).Return(session, nil)
+
+		_, err := interactor.VerifySession(context.Background(), session.ID, domain.RoleAdmin)
+		assert.EqualError(t, err, usecases.ErrTwoFactorRequired.Error())
+	}
+}
+
+func TestSessionInteractor_ActiveSessions(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	expected := domain.Sessions{{ID: 1, UserID: 1}, {ID: 2, UserID: 1}}
+	m.sessionRepo.EXPECT().FindActiveByUserID(gomock.Any(), uint64(1)).Return(expected, nil)
+
+	sessions, err := interactor.ActiveSessions(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, sessions)
+}
+
+func TestSessionInteractor_RevokeUserSession(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	session := domain.Session{ID: 2, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}
+
+	{
+		// Happy path: own session gets revoked
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), session.ID).Return(session, nil)
+		m.sessionRepo.EXPECT().Revoke(gomock.Any(), session.ID).Return(nil)
+
+		err := interactor.RevokeUserSession(context.Background(), 1, session.ID)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: session belongs to someone else
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), session.ID).Return(session, nil)
+
+		err := interactor.RevokeUserSession(context.Background(), 3, session.ID)
+		assert.EqualError(t, err, usecases.ErrSessionNotFound.Error())
+	}
+
+	{
+		// Sad path: session does not exist
+		m.sessionRepo.EXPECT().FindByID(gomock.Any(), uint64(4)).Return(domain.Session{}, domain.ErrNotFound)
+
+		err := interactor.RevokeUserSession(context.Background(), 1, 4)
+		assert.EqualError(t, err, usecases.ErrSessionNotFound.Error())
+	}
+}
+
+func TestSessionInteractor_RevokeAllSessions(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	m.sessionRepo.EXPECT().RevokeAllForUser(gomock.Any(), uint64(1)).Return(nil)
+
+	err := interactor.RevokeAllSessions(context.Background(), 1)
+	assert.NoError(t, err)
+}
+
+func TestSessionInteractor_RequestPasswordReset(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: token gets stored and mailed
+		dbUser := domain.User{ID: 1, Email: "foo@bar.com", DisplayName: "foo"}
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(dbUser, nil)
+		m.tokenGen.EXPECT().Generate().Return("token", nil)
+		m.tokenRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, token *domain.OneTimeToken) error {
+			assert.Equal(t, dbUser.ID, token.UserID)
+			assert.Equal(t, domain.HashToken("token"), token.Hash)
+			assert.True(t, token.ExpiresAt.After(time.Now()))
+			return nil
+		})
+		m.mailer.EXPECT().Send(gomock.Any()).DoAndReturn(func(mail usecases.Mail) error {
+			assert.Equal(t, dbUser.Email, mail.To)
+			assert.Contains(t, mail.Body, "https://tadoku.app/password_reset?token=token")
+			return nil
+		})
+
+		err := interactor.RequestPasswordReset(context.Background(), "foo@bar.com")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: user does not exist
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "bar@bar.com").Return(domain.User{}, domain.ErrNotFound)
+		err := interactor.RequestPasswordReset(context.Background(), "bar@bar.com")
+		assert.EqualError(t, err, usecases.ErrUserDoesNotExist.Error())
+	}
+}
+
+func TestSessionInteractor_ResetPassword(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: password gets replaced
+		resetToken := domain.OneTimeToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, Hash: domain.HashToken("token"), ExpiresAt: time.Now().Add(time.Hour)}
+		dbUser := domain.User{ID: 1, Email: "foo@bar.com"}
+		hashedUser := dbUser
+		hashedUser.Password = "hashed"
+
+		m.validator.EXPECT().Validate(domain.Password("foobar")).Return(true, nil)
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposePasswordReset, domain.HashToken("token")).Return(resetToken, nil)
+		m.pwHasher.EXPECT().Hash(domain.Password("foobar")).Return(hashedUser.Password, nil)
+		m.tokenRepo.EXPECT().MarkAsUsed(gomock.Any(), resetToken.ID).Return(nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), resetToken.UserID).Return(dbUser, nil)
+		m.userRepo.EXPECT().UpdatePassword(gomock.Any(), &hashedUser).Return(nil)
+		m.tokenRepo.EXPECT().InvalidateAllForUser(gomock.Any(), domain.TokenPurposePasswordReset, dbUser.ID).Return(nil)
+		m.sessionRepo.EXPECT().RevokeAllForUser(gomock.Any(), dbUser.ID).Return(nil)
+
+		err := interactor.ResetPassword(context.Background(), "token", "foobar")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: the new password is too short, the token stays usable
+		m.validator.EXPECT().Validate(domain.Password("foo")).Return(false, domain.FieldErrors{domain.NewFieldError("password", "min_length", domain.ErrPasswordTooShort)})
+		err := interactor.ResetPassword(context.Background(), "token", "foo")
+		assert.EqualError(t, err, usecases.ErrInvalidNewPassword.Error())
+	}
+
+	{
+		// Sad path: the password can't be stored, so the token isn't used up either
+		resetToken := domain.OneTimeToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, ExpiresAt: time.Now().Add(time.Hour)}
+		transactions := usecases.NewMockTransactionManager(ctrl)
+		failing := usecases.NewSessionInteractor(m.userRepo, m.tokenRepo, m.sessionRepo, m.refreshRepo, m.challengeRepo, transactions, m.twoFactor, m.loginThrottle, m.pwHasher, m.jwtGen, m.tokenGen, m.mailer, m.validator, sessionConfig)
+
+		m.validator.EXPECT().Validate(domain.Password("foobar")).Return(true, nil)
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposePasswordReset, domain.HashToken("token")).Return(resetToken, nil)
+		m.pwHasher.EXPECT().Hash(domain.Password("foobar")).Return(domain.Password("hashed"), nil)
+		transactions.EXPECT().Run(gomock.Any(), gomock.Any()).Return(errors.New("connection reset"))
+
+		err := failing.ResetPassword(context.Background(), "token", "foobar")
+		assert.Error(t, err)
+	}
+
+	{
+		// Sad path: token does not exist
+		m.validator.EXPECT().Validate(domain.Password("foobar")).Return(true, nil)
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposePasswordReset, domain.HashToken("unknown")).Return(domain.OneTimeToken{}, domain.ErrNotFound)
+		err := interactor.ResetPassword(context.Background(), "unknown", "foobar")
+		assert.EqualError(t, err, usecases.ErrPasswordResetTokenInvalid.Error())
+	}
+
+	{
+		// Sad path: token has expired
+		resetToken := domain.OneTimeToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, ExpiresAt: time.Now().Add(-1 * time.Hour)}
+		m.validator.EXPECT().Validate(domain.Password("foobar")).Return(true, nil)
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposePasswordReset, domain.HashToken("expired")).Return(resetToken, nil)
+		err := interactor.ResetPassword(context.Background(), "expired", "foobar")
+		assert.EqualError(t, err, usecases.ErrPasswordResetTokenInvalid.Error())
+	}
+
+	{
+		// Sad path: token got used in the meantime
+		resetToken := domain.OneTimeToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposePasswordReset, ExpiresAt: time.Now().Add(time.Hour)}
+		m.validator.EXPECT().Validate(domain.Password("foobar")).Return(true, nil)
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposePasswordReset, domain.HashToken("token")).Return(resetToken, nil)
+		m.pwHasher.EXPECT().Hash(domain.Password("foobar")).Return(domain.Password("hashed"), nil)
+		m.tokenRepo.EXPECT().MarkAsUsed(gomock.Any(), resetToken.ID).Return(domain.ErrNotFound)
+		err := interactor.ResetPassword(context.Background(), "token", "foobar")
+		assert.EqualError(t, err, usecases.ErrPasswordResetTokenInvalid.Error())
+	}
+}
+
+func TestSessionInteractor_CreateSessionWithRequiredVerification(t *testing.T) {
+	config := sessionConfig
+	config.RequireEmailVerification = true
+
+	ctrl, m, interactor := setupSessionTest(t, config)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: verified user
+		verifiedAt := time.Now()
+		dbUser := domain.User{ID: 1, Email: "foo@bar.com", Password: "foobar", EmailVerifiedAt: &verifiedAt}
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(dbUser, nil)
+		m.pwHasher.EXPECT().Compare(dbUser.Password, "foobar").Return(true)
+		m.loginThrottle.EXPECT().RecordSuccess(gomock.Any(), "foo@bar.com").Return(nil)
+		m.pwHasher.EXPECT().NeedsRehash(dbUser.Password).Return(false)
+		m.twoFactor.EXPECT().IsEnabled(gomock.Any(), dbUser.ID).Return(false, nil)
+		m.sessionRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		m.tokenGen.EXPECT().Generate().Return("refresh", nil)
+		m.refreshRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		m.jwtGen.EXPECT().NewToken(accessTokenLifetime, gomock.Any()).Return("token", nil)
+
+		_, tokens, err := interactor.CreateSession(context.Background(), "foo@bar.com", "foobar", sessionClient)
+		assert.NoError(t, err)
+		assert.Equal(t, "token", tokens.AccessToken)
+	}
+
+	{
+		// Sad path: email is not verified yet
+		dbUser := domain.User{ID: 1, Email: "foo@bar.com", Password: "foobar"}
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(dbUser, nil)
+		m.pwHasher.EXPECT().Compare(dbUser.Password, "foobar").Return(true)
+
+		_, _, err := interactor.CreateSession(context.Background(), "foo@bar.com", "foobar", sessionClient)
+		assert.EqualError(t, err, usecases.ErrEmailNotVerified.Error())
+	}
+}
+
+func TestSessionInteractor_VerifyEmail(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: email gets verified
+		token := domain.OneTimeToken{ID: 1, UserID: 2, Purpose: domain.TokenPurposeEmailVerification, ExpiresAt: time.Now().Add(time.Hour)}
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposeEmailVerification, domain.HashToken("token")).Return(token, nil)
+		m.tokenRepo.EXPECT().MarkAsUsed(gomock.Any(), token.ID).Return(nil)
+		m.userRepo.EXPECT().MarkEmailAsVerified(gomock.Any(), token.UserID).Return(nil)
+
+		err := interactor.VerifyEmail(context.Background(), "token")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: token does not exist
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposeEmailVerification, domain.HashToken("unknown")).Return(domain.OneTimeToken{}, domain.ErrNotFound)
+		err := interactor.VerifyEmail(context.Background(), "unknown")
+		assert.EqualError(t, err, usecases.ErrEmailVerificationTokenInvalid.Error())
+	}
+
+	{
+		// Sad path: token has expired
+		token := domain.OneTimeToken{ID: 1, UserID: 2, Purpose: domain.TokenPurposeEmailVerification, ExpiresAt: time.Now().Add(-1 * time.Hour)}
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposeEmailVerification, domain.HashToken("expired")).Return(token, nil)
+		err := interactor.VerifyEmail(context.Background(), "expired")
+		assert.EqualError(t, err, usecases.ErrEmailVerificationTokenInvalid.Error())
+	}
+}
+
+func TestSessionInteractor_ResendEmailVerification(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: old links get invalidated and a new one is sent
+		dbUser := domain.User{ID: 1, Email: "foo@bar.com"}
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(dbUser, nil)
+		m.tokenRepo.EXPECT().InvalidateAllForUser(gomock.Any(), domain.TokenPurposeEmailVerification, dbUser.ID).Return(nil)
+		m.tokenGen.EXPECT().Generate().Return("token", nil)
+		m.tokenRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(nil)
+		m.mailer.EXPECT().Send(gomock.Any()).Return(nil)
+
+		err := interactor.ResendEmailVerification(context.Background(), "foo@bar.com")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: already verified
+		verifiedAt := time.Now()
+		dbUser := domain.User{ID: 1, Email: "foo@bar.com", EmailVerifiedAt: &verifiedAt}
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(dbUser, nil)
+
+		err := interactor.ResendEmailVerification(context.Background(), "foo@bar.com")
+		assert.EqualError(t, err, usecases.ErrEmailAlreadyVerified.Error())
+	}
+
+	{
+		// Sad path: user does not exist
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "bar@bar.com").Return(domain.User{}, domain.ErrNotFound)
+
+		err := interactor.ResendEmailVerification(context.Background(), "bar@bar.com")
+		assert.EqualError(t, err, usecases.ErrUserDoesNotExist.Error())
+	}
+}
+
+func TestSessionInteractor_CreateSessionWithTwoFactor(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	dbUser := domain.User{ID: 1, Email: "foo@bar.com", Password: "foobar"}
+	m.userRepo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(dbUser, nil)
+	m.pwHasher.EXPECT().Compare(dbUser.Password, "foobar").Return(true)
+	m.pwHasher.EXPECT().NeedsRehash(dbUser.Password).Return(false)
+	m.twoFactor.EXPECT().IsEnabled(gomock.Any(), dbUser.ID).Return(true, nil)
+	m.tokenGen.EXPECT().Generate().Return("challenge", nil)
+	m.challengeRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, challenge *domain.TwoFactorChallenge) error {
+		assert.Equal(t, dbUser.ID, challenge.UserID)
+		assert.Equal(t, domain.HashToken("challenge"), challenge.Hash)
+		return nil
+	})
+
+	sessionUser, tokens, err := interactor.CreateSession(context.Background(), "foo@bar.com", "foobar", sessionClient)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.User{}, sessionUser)
+	assert.Equal(t, usecases.SessionTokens{ChallengeToken: "challenge"}, tokens)
+	assert.True(t, tokens.RequiresTwoFactor())
+}
+
+func TestSessionInteractor_CompleteTwoFactorChallenge(t *testing.T) {
+	ctrl, m, interactor := setupSessionTest(t, sessionConfig)
+	defer ctrl.Finish()
+
+	user := domain.User{ID: 1, Email: "foo@bar.com"}
+	challenge := domain.TwoFactorChallenge{ID: 2, UserID: user.ID, ExpiresAt: time.Now().Add(time.Minute)}
+	hash := domain.HashToken("challenge")
+
+	{
+		// Happy path: correct code
+		m.challengeRepo.EXPECT().FindByHash(gomock.Any(), hash).Return(challenge, nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		m.loginThrottle.EXPECT().Check(gomock.Any(), user.Email, sessionClient.IPAddress).Return(time.Duration(0), nil)
+		m.twoFactor.EXPECT().VerifyCode(gomock.Any(), user.ID, "123456").Return(nil)
+		m.challengeRepo.EXPECT().MarkAsUsed(gomock.Any(), challenge.ID).Return(nil)
+		m.loginThrottle.EXPECT().RecordSuccess(gomock.Any(), user.Email).Return(nil)
+		m.sessionRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, session *domain.Session) error {
+			assert.True(t, session.TwoFactorVerified)
+			session.ID = 3
+			return nil
+		})
+		m.tokenGen.EXPECT().Generate().Return("refresh", nil)
+		m.refreshRepo.EXPECT().Store(gomock.Any(), &domain.RefreshToken{SessionID: 3, Hash: domain.HashToken("refresh")}).Return(nil)
+		m.jwtGen.EXPECT().NewToken(accessTokenLifetime, usecases.SessionClaims{User: &user, SessionID: 3}).Return("token", nil)
+
+		sessionUser, tokens, _, err := interactor.CompleteTwoFactorChallenge(context.Background(), "challenge", "123456", sessionClient)
+		assert.NoError(t, err)
+		assert.Equal(t, user, sessionUser)
+		assert.Equal(t, usecases.SessionTokens{AccessToken: "token", RefreshToken: "refresh"}, tokens)
+	}
+
+	{
+		// Sad path: wrong code counts as a failed attempt
+		m.challengeRepo.EXPECT().FindByHash(gomock.Any(), hash).Return(challenge, nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		m.loginThrottle.EXPECT().Check(gomock.Any(), user.Email, sessionClient.IPAddress).Return(time.Duration(0), nil)
+		m.twoFactor.EXPECT().VerifyCode(gomock.Any(), user.ID, "654321").Return(usecases.ErrTwoFactorCodeInvalid)
+		m.challengeRepo.EXPECT().RecordFailedAttempt(gomock.Any(), challenge.ID).Return(nil)
+		m.loginThrottle.EXPECT().RecordFailure(gomock.Any(), user.Email, sessionClient.IPAddress).Return(time.Duration(0), nil)
+
+		_, _, _, err := interactor.CompleteTwoFactorChallenge(context.Background(), "challenge", "654321", sessionClient)
+		assert.EqualError(t, err, usecases.ErrTwoFactorCodeInvalid.Error())
+	}
+
+	{
+		// Sad path: failures from earlier challenges lock the account
+		m.challengeRepo.EXPECT().FindByHash(gomock.Any(), hash).Return(challenge, nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		m.loginThrottle.EXPECT().Check(gomock.Any(), user.Email, sessionClient.IPAddress).Return(time.Minute, usecases.ErrLoginThrottled)
+
+		_, _, retryAfter, err := interactor.CompleteTwoFactorChallenge(context.Background(), "challenge", "123456", sessionClient)
+		assert.EqualError(t, err, usecases.ErrLoginThrottled.Error())
+		assert.Equal(t, time.Minute, retryAfter)
+	}
+
+	{
+		// Sad path: the wrong code that locks the account
+		m.challengeRepo.EXPECT().FindByHash(gomock.Any(), hash).Return(challenge, nil)
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		m.loginThrottle.EXPECT().Check(gomock.Any(), user.Email, sessionClient.IPAddress).Return(time.Duration(0), nil)
+		m.twoFactor.EXPECT().VerifyCode(gomock.Any(), user.ID, "654321").Return(usecases.ErrTwoFactorCodeInvalid)
+		m.challengeRepo.EXPECT().RecordFailedAttempt(gomock.Any(), challenge.ID).Return(nil)
+		m.loginThrottle.EXPECT().RecordFailure(gomock.Any(), user.Email, sessionClient.IPAddress).Return(2*time.Second, usecases.ErrLoginThrottled)
+
+		_, _, retryAfter, err := interactor.CompleteTwoFactorChallenge(context.Background(), "challenge", "654321", sessionClient)
+		assert.EqualError(t, err, usecases.ErrLoginThrottled.Error())
+		assert.Equal(t, 2*time.Second, retryAfter)
+	}
+
+	{
+		// Sad path: too many failed attempts
+		exhausted := challenge
+		exhausted.FailedAttempts = domain.MaxTwoFactorAttempts
+		m.challengeRepo.EXPECT().FindByHash(gomock.Any(), hash).Return(exhausted, nil)
+
+		_, _, _, err := interactor.CompleteTwoFactorChallenge(context.Background(), "challenge", "123456", sessionClient)
+		assert.EqualError(t, err, usecases.ErrTwoFactorChallengeInvalid.Error())
+	}
+
+	{
+		// Sad path: unknown challenge
+		m.challengeRepo.EXPECT().FindByHash(gomock.Any(), domain.HashToken("unknown")).Return(domain.TwoFactorChallenge{}, domain.ErrNotFound)
+
+		_, _, _, err := interactor.CompleteTwoFactorChallenge(context.Background(), "unknown", "123456", sessionClient)
+		assert.EqualError(t, err, usecases.ErrTwoFactorChallengeInvalid.Error())
 	}
 }
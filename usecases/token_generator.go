@@ -0,0 +1,8 @@
+//go:generate gex mockgen -source=token_generator.go -package usecases -destination=token_generator_mock.go
+
+package usecases
+
+// TokenGenerator generates random tokens that are hard to guess, eg. for links that are sent by email
+type TokenGenerator interface {
+	Generate() (token string, err error)
+}
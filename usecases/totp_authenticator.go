@@ -0,0 +1,16 @@
+//go:generate gex mockgen -source=totp_authenticator.go -package usecases -destination=totp_authenticator_mock.go
+
+package usecases
+
+import (
+	"time"
+)
+
+// TOTPAuthenticator generates secrets for authenticator apps and checks the time-based one-time passwords they produce
+type TOTPAuthenticator interface {
+	NewSecret() (secret string, err error)
+	URI(secret string, accountName string) string
+
+	// Verify returns the time step the code was valid for, so it can be prevented from being used twice
+	Verify(secret string, code string, now time.Time) (step int64, valid bool)
+}
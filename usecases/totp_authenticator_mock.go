@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: totp_authenticator.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+	time "time"
+)
+
+// MockTOTPAuthenticator is a mock of TOTPAuthenticator interface
+type MockTOTPAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockTOTPAuthenticatorMockRecorder
+}
+
+// MockTOTPAuthenticatorMockRecorder is the mock recorder for MockTOTPAuthenticator
+type MockTOTPAuthenticatorMockRecorder struct {
+	mock *MockTOTPAuthenticator
+}
+
+// NewMockTOTPAuthenticator creates a new mock instance
+func NewMockTOTPAuthenticator(ctrl *gomock.Controller) *MockTOTPAuthenticator {
+	mock := &MockTOTPAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockTOTPAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTOTPAuthenticator) EXPECT() *MockTOTPAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// NewSecret mocks base method
+func (m *MockTOTPAuthenticator) NewSecret() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewSecret")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewSecret indicates an expected call of NewSecret
+func (mr *MockTOTPAuthenticatorMockRecorder) NewSecret() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewSecret", reflect.TypeOf((*MockTOTPAuthenticator)(nil).NewSecret))
+}
+
+// URI mocks base method
+func (m *MockTOTPAuthenticator) URI(secret, accountName string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "URI", secret, accountName)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// URI indicates an expected call of URI
+func (mr *MockTOTPAuthenticatorMockRecorder) URI(secret, accountName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URI", reflect.TypeOf((*MockTOTPAuthenticator)(nil).URI), secret, accountName)
+}
+
+// Verify mocks base method
+func (m *MockTOTPAuthenticator) Verify(secret, code string, now time.Time) (int64, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", secret, code, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify
+func (mr *MockTOTPAuthenticatorMockRecorder) Verify(secret, code, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockTOTPAuthenticator)(nil).Verify), secret, code, now)
+}
@@ -0,0 +1,12 @@
+//go:generate gex mockgen -source=transaction_manager.go -package usecases -destination=transaction_manager_mock.go
+
+package usecases
+
+import "context"
+
+// TransactionManager groups changes to several repositories into a single unit of work
+type TransactionManager interface {
+	// Run commits everything fn stored through repositories with the given context, or nothing at all when it returns an error.
+	// Calls inside fn join the transaction that's already running.
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
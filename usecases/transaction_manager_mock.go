@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: transaction_manager.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockTransactionManager is a mock of TransactionManager interface
+type MockTransactionManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionManagerMockRecorder
+}
+
+// MockTransactionManagerMockRecorder is the mock recorder for MockTransactionManager
+type MockTransactionManagerMockRecorder struct {
+	mock *MockTransactionManager
+}
+
+// NewMockTransactionManager creates a new mock instance
+func NewMockTransactionManager(ctrl *gomock.Controller) *MockTransactionManager {
+	mock := &MockTransactionManager{ctrl: ctrl}
+	mock.recorder = &MockTransactionManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTransactionManager) EXPECT() *MockTransactionManagerMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method
+func (m *MockTransactionManager) Run(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run
+func (mr *MockTransactionManagerMockRecorder) Run(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockTransactionManager)(nil).Run), ctx, fn)
+}
@@ -0,0 +1,264 @@
+//go:generate gex mockgen -source=two_factor_interactor.go -package usecases -destination=two_factor_interactor_mock.go
+
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/srvc/fail"
+	"github.com/tadoku/api/domain"
+)
+
+// RecoveryCodeCount is how many recovery codes a user gets when enabling two factor authentication
+const RecoveryCodeCount = 10
+
+// ErrTwoFactorAlreadyEnabled for when a user tries to enroll while two factor authentication is already enabled
+var ErrTwoFactorAlreadyEnabled = fail.New("two factor authentication is already enabled")
+
+// ErrTwoFactorNotEnabled for when a user tries to confirm or disable two factor authentication without having it set up
+var ErrTwoFactorNotEnabled = fail.New("two factor authentication is not enabled")
+
+// ErrTwoFactorCodeInvalid for when a code is wrong, has expired or has already been used
+var ErrTwoFactorCodeInvalid = fail.New("two factor authentication code is invalid")
+
+// TwoFactorEnrollment contains everything an authenticator app needs to start generating codes
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TwoFactorInteractor contains all business logic for two factor authentication
+type TwoFactorInteractor interface {
+	IsEnabled(ctx context.Context, userID uint64) (bool, error)
+	// BeginEnrollment and Disable need the current password, so a stolen session can't change how the account is protected
+	BeginEnrollment(ctx context.Context, user domain.User, password string) (TwoFactorEnrollment, error)
+	ConfirmEnrollment(ctx context.Context, userID uint64, code string) (recoveryCodes []string, err error)
+	Disable(ctx context.Context, userID uint64, password string, code string) error
+
+	// VerifyCode accepts either a code from the authenticator app or an unused recovery code
+	VerifyCode(ctx context.Context, userID uint64, code string) error
+}
+
+// NewTwoFactorInteractor instantiates TwoFactorInteractor with all dependencies
+func NewTwoFactorInteractor(
+	twoFactorRepository TwoFactorAuthenticationRepository,
+	recoveryCodeRepository RecoveryCodeRepository,
+	userRepository UserRepository,
+	totpAuthenticator TOTPAuthenticator,
+	secretBox SecretBox,
+	recoveryCodeGenerator TokenGenerator,
+	passwordHasher PasswordHasher,
+) TwoFactorInteractor {
+	return &twoFactorInteractor{
+		twoFactorRepository:    twoFactorRepository,
+		recoveryCodeRepository: recoveryCodeRepository,
+		userRepository:         userRepository,
+		totpAuthenticator:      totpAuthenticator,
+		secretBox:              secretBox,
+		recoveryCodeGenerator:  recoveryCodeGenerator,
+		passwordHasher:         passwordHasher,
+	}
+}
+
+type twoFactorInteractor struct {
+	twoFactorRepository    TwoFactorAuthenticationRepository
+	recoveryCodeRepository RecoveryCodeRepository
+	userRepository         UserRepository
+	totpAuthenticator      TOTPAuthenticator
+	secretBox              SecretBox
+	recoveryCodeGenerator  TokenGenerator
+	passwordHasher         PasswordHasher
+}
+
+func (i *twoFactorInteractor) IsEnabled(ctx context.Context, userID uint64) (bool, error) {
+	twoFactor, err := i.twoFactorRepository.FindByUserID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, domain.WrapError(err)
+	}
+
+	return twoFactor.IsEnabled(), nil
+}
+
+func (i *twoFactorInteractor) BeginEnrollment(ctx context.Context, user domain.User, password string) (TwoFactorEnrollment, error) {
+	if err := i.checkPassword(ctx, user.ID, password); err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+
+	enabled, err := i.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return TwoFactorEnrollment{}, err
+	}
+	if enabled {
+		return TwoFactorEnrollment{}, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := i.totpAuthenticator.NewSecret()
+	if err != nil {
+		return TwoFactorEnrollment{}, domain.WrapError(err)
+	}
+
+	sealed, err := i.secretBox.Seal(secret)
+	if err != nil {
+		return TwoFactorEnrollment{}, domain.WrapError(err)
+	}
+
+	twoFactor := domain.TwoFactorAuthentication{UserID: user.ID, Secret: sealed}
+	if err := i.twoFactorRepository.Store(ctx, &twoFactor); err != nil {
+		return TwoFactorEnrollment{}, domain.WrapError(err)
+	}
+
+	return TwoFactorEnrollment{
+		Secret: secret,
+		URI:    i.totpAuthenticator.URI(secret, user.Email),
+	}, nil
+}
+
+func (i *twoFactorInteractor) ConfirmEnrollment(ctx context.Context, userID uint64, code string) ([]string, error) {
+	twoFactor, err := i.twoFactorRepository.FindByUserID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return nil, domain.WrapError(err)
+	}
+	if twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if err := i.verifyTOTP(ctx, twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	if err := i.twoFactorRepository.Confirm(ctx, userID); err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return i.replaceRecoveryCodes(ctx, userID)
+}
+
+func (i *twoFactorInteractor) Disable(ctx context.Context, userID uint64, password string, code string) error {
+	if err := i.checkPassword(ctx, userID, password); err != nil {
+		return err
+	}
+
+	if err := i.VerifyCode(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := i.twoFactorRepository.Delete(ctx, userID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	err := i.recoveryCodeRepository.DeleteAllForUser(ctx, userID)
+	return domain.WrapError(err)
+}
+
+func (i *twoFactorInteractor) VerifyCode(ctx context.Context, userID uint64, code string) error {
+	twoFactor, err := i.twoFactorRepository.FindByUserID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+	if !twoFactor.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if err := i.verifyTOTP(ctx, twoFactor, code); err != ErrTwoFactorCodeInvalid {
+		return err
+	}
+
+	err = i.recoveryCodeRepository.MarkAsUsed(ctx, userID, domain.HashToken(code))
+	if err == domain.ErrNotFound {
+		return ErrTwoFactorCodeInvalid
+	}
+
+	return domain.WrapError(err)
+}
+
+func (i *twoFactorInteractor) checkPassword(ctx context.Context, userID uint64, password string) error {
+	user, err := i.userRepository.FindByID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return ErrUserDoesNotExist
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	// Only looking up users by their email address gives us their password hash
+	user, err = i.userRepository.FindByEmail(ctx, user.Email)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if !i.passwordHasher.Compare(user.Password, password) {
+		return ErrPasswordIncorrect
+	}
+
+	return nil
+}
+
+// verifyTOTP checks a code from the authenticator app, every code can only be used once
+func (i *twoFactorInteractor) verifyTOTP(ctx context.Context, twoFactor domain.TwoFactorAuthentication, code string) error {
+	secret, err := i.openSecret(ctx, twoFactor)
+	if err != nil {
+		return err
+	}
+
+	step, valid := i.totpAuthenticator.Verify(secret, code, time.Now())
+	if !valid || step <= twoFactor.LastUsedStep {
+		return ErrTwoFactorCodeInvalid
+	}
+
+	err = i.twoFactorRepository.UpdateLastUsedStep(ctx, twoFactor.UserID, step)
+	if err == domain.ErrNotFound {
+		return ErrTwoFactorCodeInvalid
+	}
+
+	return domain.WrapError(err)
+}
+
+// openSecret decrypts the secret of the authenticator app, secrets from before encryption existed get encrypted on the way
+func (i *twoFactorInteractor) openSecret(ctx context.Context, twoFactor domain.TwoFactorAuthentication) (string, error) {
+	if i.secretBox.IsSealed(twoFactor.Secret) {
+		secret, err := i.secretBox.Open(twoFactor.Secret)
+		return secret, domain.WrapError(err)
+	}
+
+	sealed, err := i.secretBox.Seal(twoFactor.Secret)
+	if err != nil {
+		return "", domain.WrapError(err)
+	}
+	if err := i.twoFactorRepository.UpdateSecret(ctx, twoFactor.UserID, sealed); err != nil {
+		return "", domain.WrapError(err)
+	}
+
+	return twoFactor.Secret, nil
+}
+
+func (i *twoFactorInteractor) replaceRecoveryCodes(ctx context.Context, userID uint64) ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	for n := range codes {
+		code, err := i.recoveryCodeGenerator.Generate()
+		if err != nil {
+			return nil, domain.WrapError(err)
+		}
+
+		codes[n] = code
+		hashes[n] = domain.HashToken(code)
+	}
+
+	if err := i.recoveryCodeRepository.ReplaceAllForUser(ctx, userID, hashes); err != nil {
+		return nil, domain.WrapError(err)
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: two_factor_interactor.go
+
+// Package usecases is a generated GoMock package.
+package usecases
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	domain "github.com/tadoku/api/domain"
+	reflect "reflect"
+)
+
+// MockTwoFactorInteractor is a mock of TwoFactorInteractor interface
+type MockTwoFactorInteractor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorInteractorMockRecorder
+}
+
+// MockTwoFactorInteractorMockRecorder is the mock recorder for MockTwoFactorInteractor
+type MockTwoFactorInteractorMockRecorder struct {
+	mock *MockTwoFactorInteractor
+}
+
+// NewMockTwoFactorInteractor creates a new mock instance
+func NewMockTwoFactorInteractor(ctrl *gomock.Controller) *MockTwoFactorInteractor {
+	mock := &MockTwoFactorInteractor{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorInteractorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockTwoFactorInteractor) EXPECT() *MockTwoFactorInteractorMockRecorder {
+	return m.recorder
+}
+
+// IsEnabled mocks base method
+func (m *MockTwoFactorInteractor) IsEnabled(ctx context.Context, userID uint64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEnabled", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEnabled indicates an expected call of IsEnabled
+func (mr *MockTwoFactorInteractorMockRecorder) IsEnabled(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEnabled", reflect.TypeOf((*MockTwoFactorInteractor)(nil).IsEnabled), ctx, userID)
+}
+
+// BeginEnrollment mocks base method
+func (m *MockTwoFactorInteractor) BeginEnrollment(ctx context.Context, user domain.User, password string) (TwoFactorEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginEnrollment", ctx, user, password)
+	ret0, _ := ret[0].(TwoFactorEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginEnrollment indicates an expected call of BeginEnrollment
+func (mr *MockTwoFactorInteractorMockRecorder) BeginEnrollment(ctx, user, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginEnrollment", reflect.TypeOf((*MockTwoFactorInteractor)(nil).BeginEnrollment), ctx, user, password)
+}
+
+// ConfirmEnrollment mocks base method
+func (m *MockTwoFactorInteractor) ConfirmEnrollment(ctx context.Context, userID uint64, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEnrollment", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEnrollment indicates an expected call of ConfirmEnrollment
+func (mr *MockTwoFactorInteractorMockRecorder) ConfirmEnrollment(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEnrollment", reflect.TypeOf((*MockTwoFactorInteractor)(nil).ConfirmEnrollment), ctx, userID, code)
+}
+
+// Disable mocks base method
+func (m *MockTwoFactorInteractor) Disable(ctx context.Context, userID uint64, password, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, userID, password, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable
+func (mr *MockTwoFactorInteractorMockRecorder) Disable(ctx, userID, password, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockTwoFactorInteractor)(nil).Disable), ctx, userID, password, code)
+}
+
+// VerifyCode mocks base method
+func (m *MockTwoFactorInteractor) VerifyCode(ctx context.Context, userID uint64, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyCode", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyCode indicates an expected call of VerifyCode
+func (mr *MockTwoFactorInteractorMockRecorder) VerifyCode(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyCode", reflect.TypeOf((*MockTwoFactorInteractor)(nil).VerifyCode), ctx, userID, code)
+}
@@ -0,0 +1,232 @@
+package usecases_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tadoku/api/domain"
+	"github.com/tadoku/api/usecases"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+type twoFactorTestMocks struct {
+	repo         *usecases.MockTwoFactorAuthenticationRepository
+	recoveryRepo *usecases.MockRecoveryCodeRepository
+	userRepo     *usecases.MockUserRepository
+	totp         *usecases.MockTOTPAuthenticator
+	secrets      *usecases.MockSecretBox
+	codeGen      *usecases.MockTokenGenerator
+	pwHasher     *usecases.MockPasswordHasher
+}
+
+func setupTwoFactorTest(t *testing.T) (
+	*gomock.Controller,
+	*twoFactorTestMocks,
+	usecases.TwoFactorInteractor,
+) {
+	ctrl := gomock.NewController(t)
+
+	m := &twoFactorTestMocks{
+		repo:         usecases.NewMockTwoFactorAuthenticationRepository(ctrl),
+		recoveryRepo: usecases.NewMockRecoveryCodeRepository(ctrl),
+		userRepo:     usecases.NewMockUserRepository(ctrl),
+		totp:         usecases.NewMockTOTPAuthenticator(ctrl),
+		secrets:      usecases.NewMockSecretBox(ctrl),
+		codeGen:      usecases.NewMockTokenGenerator(ctrl),
+		pwHasher:     usecases.NewMockPasswordHasher(ctrl),
+	}
+	interactor := usecases.NewTwoFactorInteractor(m.repo, m.recoveryRepo, m.userRepo, m.totp, m.secrets, m.codeGen, m.pwHasher)
+
+	// Sealing only marks secrets, so the tests can tell whether they were stored sealed
+	m.secrets.EXPECT().Seal(gomock.Any()).DoAndReturn(func(secret string) (string, error) {
+		return "sealed:" + secret, nil
+	}).AnyTimes()
+	m.secrets.EXPECT().Open(gomock.Any()).DoAndReturn(func(sealed string) (string, error) {
+		return strings.TrimPrefix(sealed, "sealed:"), nil
+	}).AnyTimes()
+	m.secrets.EXPECT().IsSealed(gomock.Any()).DoAndReturn(func(value string) bool {
+		return strings.HasPrefix(value, "sealed:")
+	}).AnyTimes()
+
+	return ctrl, m, interactor
+}
+
+// expectPassword lets the password check pass, or fail when the password doesn't match
+func expectPassword(m *twoFactorTestMocks, user domain.User, password string, matches bool) {
+	m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(domain.User{ID: user.ID, Email: user.Email}, nil)
+	m.userRepo.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+	m.pwHasher.EXPECT().Compare(user.Password, password).Return(matches)
+}
+
+func TestTwoFactorInteractor_BeginEnrollment(t *testing.T) {
+	ctrl, m, interactor := setupTwoFactorTest(t)
+	defer ctrl.Finish()
+
+	user := domain.User{ID: 1, Email: "foo@bar.com", Password: "hash"}
+	confirmedAt := time.Now()
+
+	{
+		// Happy path: new secret gets stored unconfirmed and sealed
+		expectPassword(m, user, "foobar", true)
+		m.repo.EXPECT().FindByUserID(gomock.Any(), user.ID).Return(domain.TwoFactorAuthentication{}, domain.ErrNotFound)
+		m.totp.EXPECT().NewSecret().Return("SECRET", nil)
+		m.repo.EXPECT().Store(gomock.Any(), &domain.TwoFactorAuthentication{UserID: user.ID, Secret: "sealed:SECRET"}).Return(nil)
+		m.totp.EXPECT().URI("SECRET", user.Email).Return("otpauth://totp/foo")
+
+		enrollment, err := interactor.BeginEnrollment(context.Background(), user, "foobar")
+		assert.NoError(t, err)
+		assert.Equal(t, usecases.TwoFactorEnrollment{Secret: "SECRET", URI: "otpauth://totp/foo"}, enrollment)
+	}
+
+	{
+		// Sad path: already enabled
+		expectPassword(m, user, "foobar", true)
+		m.repo.EXPECT().FindByUserID(gomock.Any(), user.ID).Return(domain.TwoFactorAuthentication{UserID: user.ID, ConfirmedAt: &confirmedAt}, nil)
+
+		_, err := interactor.BeginEnrollment(context.Background(), user, "foobar")
+		assert.EqualError(t, err, usecases.ErrTwoFactorAlreadyEnabled.Error())
+	}
+
+	{
+		// Sad path: a session alone isn't enough
+		expectPassword(m, user, "barbar", false)
+
+		_, err := interactor.BeginEnrollment(context.Background(), user, "barbar")
+		assert.EqualError(t, err, usecases.ErrPasswordIncorrect.Error())
+	}
+}
+
+func TestTwoFactorInteractor_ConfirmEnrollment(t *testing.T) {
+	ctrl, m, interactor := setupTwoFactorTest(t)
+	defer ctrl.Finish()
+
+	pending := domain.TwoFactorAuthentication{UserID: 1, Secret: "sealed:SECRET"}
+
+	{
+		// Happy path: correct code enables two factor authentication and hands out recovery codes
+		m.repo.EXPECT().FindByUserID(gomock.Any(), pending.UserID).Return(pending, nil)
+		m.totp.EXPECT().Verify("SECRET", "123456", gomock.Any()).Return(int64(10), true)
+		m.repo.EXPECT().UpdateLastUsedStep(gomock.Any(), pending.UserID, int64(10)).Return(nil)
+		m.repo.EXPECT().Confirm(gomock.Any(), pending.UserID).Return(nil)
+		m.codeGen.EXPECT().Generate().Return("recovery", nil).Times(usecases.RecoveryCodeCount)
+		m.recoveryRepo.EXPECT().ReplaceAllForUser(gomock.Any(), pending.UserID, gomock.Any()).DoAndReturn(func(_ context.Context, userID uint64, hashes []string) error {
+			assert.Len(t, hashes, usecases.RecoveryCodeCount)
+			assert.Equal(t, domain.HashToken("recovery"), hashes[0])
+			return nil
+		})
+
+		codes, err := interactor.ConfirmEnrollment(context.Background(), pending.UserID, "123456")
+		assert.NoError(t, err)
+		assert.Len(t, codes, usecases.RecoveryCodeCount)
+	}
+
+	{
+		// Sad path: wrong code
+		m.repo.EXPECT().FindByUserID(gomock.Any(), pending.UserID).Return(pending, nil)
+		m.totp.EXPECT().Verify("SECRET", "654321", gomock.Any()).Return(int64(0), false)
+
+		_, err := interactor.ConfirmEnrollment(context.Background(), pending.UserID, "654321")
+		assert.EqualError(t, err, usecases.ErrTwoFactorCodeInvalid.Error())
+	}
+
+	{
+		// Sad path: enrollment was never started
+		m.repo.EXPECT().FindByUserID(gomock.Any(), uint64(2)).Return(domain.TwoFactorAuthentication{}, domain.ErrNotFound)
+
+		_, err := interactor.ConfirmEnrollment(context.Background(), 2, "123456")
+		assert.EqualError(t, err, usecases.ErrTwoFactorNotEnabled.Error())
+	}
+}
+
+func TestTwoFactorInteractor_VerifyCode(t *testing.T) {
+	ctrl, m, interactor := setupTwoFactorTest(t)
+	defer ctrl.Finish()
+
+	confirmedAt := time.Now()
+	enabled := domain.TwoFactorAuthentication{UserID: 1, Secret: "sealed:SECRET", LastUsedStep: 10, ConfirmedAt: &confirmedAt}
+
+	{
+		// Happy path: code from the authenticator app
+		m.repo.EXPECT().FindByUserID(gomock.Any(), enabled.UserID).Return(enabled, nil)
+		m.totp.EXPECT().Verify("SECRET", "123456", gomock.Any()).Return(int64(11), true)
+		m.repo.EXPECT().UpdateLastUsedStep(gomock.Any(), enabled.UserID, int64(11)).Return(nil)
+
+		err := interactor.VerifyCode(context.Background(), enabled.UserID, "123456")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Happy path: unused recovery code
+		m.repo.EXPECT().FindByUserID(gomock.Any(), enabled.UserID).Return(enabled, nil)
+		m.totp.EXPECT().Verify("SECRET", "recovery", gomock.Any()).Return(int64(0), false)
+		m.recoveryRepo.EXPECT().MarkAsUsed(gomock.Any(), enabled.UserID, domain.HashToken("recovery")).Return(nil)
+
+		err := interactor.VerifyCode(context.Background(), enabled.UserID, " recovery ")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Happy path: secrets from before they were sealed get sealed when they're used
+		plain := enabled
+		plain.Secret = "SECRET"
+		m.repo.EXPECT().FindByUserID(gomock.Any(), enabled.UserID).Return(plain, nil)
+		m.repo.EXPECT().UpdateSecret(gomock.Any(), enabled.UserID, "sealed:SECRET").Return(nil)
+		m.totp.EXPECT().Verify("SECRET", "123456", gomock.Any()).Return(int64(11), true)
+		m.repo.EXPECT().UpdateLastUsedStep(gomock.Any(), enabled.UserID, int64(11)).Return(nil)
+
+		err := interactor.VerifyCode(context.Background(), enabled.UserID, "123456")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: code from the authenticator app was used before
+		m.repo.EXPECT().FindByUserID(gomock.Any(), enabled.UserID).Return(enabled, nil)
+		m.totp.EXPECT().Verify("SECRET", "123456", gomock.Any()).Return(int64(10), true)
+		m.recoveryRepo.EXPECT().MarkAsUsed(gomock.Any(), enabled.UserID, domain.HashToken("123456")).Return(domain.ErrNotFound)
+
+		err := interactor.VerifyCode(context.Background(), enabled.UserID, "123456")
+		assert.EqualError(t, err, usecases.ErrTwoFactorCodeInvalid.Error())
+	}
+
+	{
+		// Sad path: two factor authentication is not enabled
+		m.repo.EXPECT().FindByUserID(gomock.Any(), uint64(2)).Return(domain.TwoFactorAuthentication{UserID: 2}, nil)
+
+		err := interactor.VerifyCode(context.Background(), 2, "123456")
+		assert.EqualError(t, err, usecases.ErrTwoFactorNotEnabled.Error())
+	}
+}
+
+func TestTwoFactorInteractor_Disable(t *testing.T) {
+	ctrl, m, interactor := setupTwoFactorTest(t)
+	defer ctrl.Finish()
+
+	user := domain.User{ID: 1, Email: "foo@bar.com", Password: "hash"}
+	confirmedAt := time.Now()
+	enabled := domain.TwoFactorAuthentication{UserID: user.ID, Secret: "sealed:SECRET", ConfirmedAt: &confirmedAt}
+
+	{
+		// Happy path: secret and recovery codes are removed
+		expectPassword(m, user, "foobar", true)
+		m.repo.EXPECT().FindByUserID(gomock.Any(), enabled.UserID).Return(enabled, nil)
+		m.totp.EXPECT().Verify("SECRET", "123456", gomock.Any()).Return(int64(1), true)
+		m.repo.EXPECT().UpdateLastUsedStep(gomock.Any(), enabled.UserID, int64(1)).Return(nil)
+		m.repo.EXPECT().Delete(gomock.Any(), enabled.UserID).Return(nil)
+		m.recoveryRepo.EXPECT().DeleteAllForUser(gomock.Any(), enabled.UserID).Return(nil)
+
+		err := interactor.Disable(context.Background(), enabled.UserID, "foobar", "123456")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: a code alone isn't enough
+		expectPassword(m, user, "barbar", false)
+
+		err := interactor.Disable(context.Background(), enabled.UserID, "barbar", "123456")
+		assert.EqualError(t, err, usecases.ErrPasswordIncorrect.Error())
+	}
+}
@@ -3,34 +3,113 @@
 package usecases
 
 import (
+	"context"
+	"time"
+
 	"github.com/srvc/fail"
 	"github.com/tadoku/api/domain"
 )
 
+// ErrEmailAlreadyInUse for when another account already uses the email address
+var ErrEmailAlreadyInUse = fail.New("email address is already in use")
+
+// ErrInvalidPreferences for when preferences can't be saved because one of the settings is invalid
+var ErrInvalidPreferences = fail.New("supplied preferences are invalid")
+
+// ErrEmailChangeRequestInvalid for when an email change confirmation token is unknown, expired or already used
+var ErrEmailChangeRequestInvalid = fail.New("email change request is invalid or has expired")
+
 // UserInteractor contains all business logic for users
 type UserInteractor interface {
-	UpdatePassword(email string, currentPassword, newPassword string) error
-	UpdateProfile(user domain.User) error
+	UpdatePassword(ctx context.Context, email string, currentPassword, newPassword string) error
+	UpdateProfile(ctx context.Context, user domain.User) error
+
+	// RequestEmailChange sends a confirmation link to the new address, the email is only changed once it's confirmed
+	RequestEmailChange(ctx context.Context, userID uint64, password string, newEmail string) error
+	ConfirmEmailChange(ctx context.Context, token string) error
+
+	// Profile is public, users that hide themselves from rankings only have one for themselves.
+	// Amounts are shown in the units of the viewer.
+	Profile(ctx context.Context, userID uint64, viewerID uint64, units domain.Units) (domain.UserProfile, error)
+
+	Preferences(ctx context.Context, userID uint64) (domain.Preferences, error)
+	UpdatePreferences(ctx context.Context, userID uint64, preferences domain.Preferences) error
+
+	// Export gathers all data we have stored about a user
+	Export(ctx context.Context, userID uint64) (UserExport, error)
+	// DeleteAccount removes all personal data of a user after they've confirmed it with their password
+	DeleteAccount(ctx context.Context, userID uint64, password string) error
+}
+
+// UserExport contains all data we have stored about a user
+type UserExport struct {
+	Profile     domain.User         `json:"profile"`
+	Preferences *domain.Preferences `json:"preferences"`
+	Rankings    domain.Rankings     `json:"rankings"`
+	ContestLogs domain.ContestLogs  `json:"contest_logs"`
 }
 
 // NewUserInteractor instantiates UserInteractor with all dependencies
 func NewUserInteractor(
 	userRepository UserRepository,
+	oneTimeTokenRepository OneTimeTokenRepository,
+	sessionRepository SessionRepository,
+	personalAccessTokenRepository PersonalAccessTokenRepository,
+	twoFactorRepository TwoFactorAuthenticationRepository,
+	recoveryCodeRepository RecoveryCodeRepository,
+	loginAttemptRepository LoginAttemptRepository,
+	rankingRepository RankingRepository,
+	contestLogRepository ContestLogRepository,
+	transactions TransactionManager,
 	passwordHasher PasswordHasher,
+	tokenGenerator TokenGenerator,
+	mailer Mailer,
+	validator Validator,
+	config SessionConfig,
 ) UserInteractor {
 	return &userInteractor{
-		userRepository: userRepository,
-		passwordHasher: passwordHasher,
+		userRepository:                userRepository,
+		oneTimeTokenRepository:        oneTimeTokenRepository,
+		sessionRepository:             sessionRepository,
+		personalAccessTokenRepository: personalAccessTokenRepository,
+		twoFactorRepository:           twoFactorRepository,
+		recoveryCodeRepository:        recoveryCodeRepository,
+		loginAttemptRepository:        loginAttemptRepository,
+		rankingRepository:             rankingRepository,
+		contestLogRepository:          contestLogRepository,
+		transactions:                  transactions,
+		passwordHasher:                passwordHasher,
+		tokenGenerator:                tokenGenerator,
+		mailer:                        mailer,
+		validator:                     validator,
+		config:                        config,
 	}
 }
 
 type userInteractor struct {
-	userRepository UserRepository
-	passwordHasher PasswordHasher
+	userRepository                UserRepository
+	oneTimeTokenRepository        OneTimeTokenRepository
+	sessionRepository             SessionRepository
+	personalAccessTokenRepository PersonalAccessTokenRepository
+	twoFactorRepository           TwoFactorAuthenticationRepository
+	recoveryCodeRepository        RecoveryCodeRepository
+	loginAttemptRepository        LoginAttemptRepository
+	rankingRepository             RankingRepository
+	contestLogRepository          ContestLogRepository
+	transactions                  TransactionManager
+	passwordHasher                PasswordHasher
+	tokenGenerator                TokenGenerator
+	mailer                        Mailer
+	validator                     Validator
+	config                        SessionConfig
 }
 
-func (i *userInteractor) UpdatePassword(email string, currentPassword, newPassword string) error {
-	user, err := i.userRepository.FindByEmail(email)
+func (i *userInteractor) UpdatePassword(ctx context.Context, email string, currentPassword, newPassword string) error {
+	if valid, violations := i.validator.Validate(domain.Password(newPassword)); !valid {
+		return newValidationError(ErrInvalidNewPassword, violations)
+	}
+
+	user, err := i.userRepository.FindByEmail(ctx, email)
 	if err != nil {
 		return domain.WrapError(err)
 	}
@@ -48,11 +127,274 @@ func (i *userInteractor) UpdatePassword(email string, currentPassword, newPasswo
 		return domain.WrapError(err)
 	}
 
-	err = i.userRepository.UpdatePassword(&user)
+	err = i.userRepository.UpdatePassword(ctx, &user)
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	// Outstanding reset links were requested for the old password, they shouldn't outlive it
+	err = i.oneTimeTokenRepository.InvalidateAllForUser(ctx, domain.TokenPurposePasswordReset, user.ID)
 
 	return domain.WrapError(err)
 }
 
-func (i *userInteractor) UpdateProfile(user domain.User) error {
-	return i.userRepository.Store(&user)
+func (i *userInteractor) UpdateProfile(ctx context.Context, user domain.User) error {
+	return i.userRepository.Store(ctx, &user)
+}
+
+func (i *userInteractor) RequestEmailChange(ctx context.Context, userID uint64, password string, newEmail string) error {
+	if err := domain.ValidateEmail(newEmail); err != nil {
+		return err
+	}
+
+	user, err := i.findWithPassword(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !i.passwordHasher.Compare(user.Password, password) {
+		return ErrPasswordIncorrect
+	}
+
+	existing, err := i.userRepository.FindByEmail(ctx, newEmail)
+	if err != nil && err != domain.ErrNotFound {
+		return domain.WrapError(err)
+	}
+	if existing.ID != 0 {
+		return ErrEmailAlreadyInUse
+	}
+
+	// Only the most recent link should work so older mails can't be dug up later on
+	if err := i.oneTimeTokenRepository.InvalidateAllForUser(ctx, domain.TokenPurposeEmailChange, user.ID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	token, err := i.tokenGenerator.Generate()
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	request := domain.OneTimeToken{
+		UserID:    user.ID,
+		Purpose:   domain.TokenPurposeEmailChange,
+		Payload:   newEmail,
+		Hash:      domain.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(i.config.EmailVerificationLifetime),
+	}
+	if err := i.oneTimeTokenRepository.Store(ctx, &request); err != nil {
+		return domain.WrapError(err)
+	}
+
+	link := tokenLink(i.config.FrontendURL, "/confirm_email_change", token)
+	if err := i.mailer.Send(newEmailChangeConfirmationMail(user, newEmail, link, i.config.EmailVerificationLifetime)); err != nil {
+		return domain.WrapError(err)
+	}
+
+	err = i.mailer.Send(newEmailChangeNoticeMail(user, newEmail))
+	return domain.WrapError(err)
+}
+
+func (i *userInteractor) ConfirmEmailChange(ctx context.Context, token string) error {
+	return i.transactions.Run(ctx, func(ctx context.Context) error {
+		return i.confirmEmailChange(ctx, token)
+	})
+}
+
+// confirmEmailChange uses up the token and changes the address, it has to run in a transaction so a failed change leaves the token usable
+func (i *userInteractor) confirmEmailChange(ctx context.Context, token string) error {
+	request, err := i.oneTimeTokenRepository.FindByHash(ctx, domain.TokenPurposeEmailChange, domain.HashToken(token))
+	if err == domain.ErrNotFound {
+		return ErrEmailChangeRequestInvalid
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	if !request.IsUsable(time.Now()) {
+		return ErrEmailChangeRequestInvalid
+	}
+
+	err = i.oneTimeTokenRepository.MarkAsUsed(ctx, request.ID)
+	if err == domain.ErrNotFound {
+		return ErrEmailChangeRequestInvalid
+	}
+	if err != nil {
+		return domain.WrapError(err)
+	}
+
+	// Someone could have signed up with the address while the request was pending
+	err = i.userRepository.UpdateEmail(ctx, request.UserID, request.Payload)
+	if err == domain.ErrAlreadyExists {
+		return ErrEmailAlreadyInUse
+	}
+	// The account got disabled or deleted after requesting the change
+	if err == domain.ErrNotFound {
+		return ErrEmailChangeRequestInvalid
+	}
+
+	return domain.WrapError(err)
+}
+
+func (i *userInteractor) Profile(
+	ctx context.Context,
+	userID uint64,
+	viewerID uint64,
+	units domain.Units,
+) (domain.UserProfile, error) {
+	user, err := i.userRepository.FindByID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return domain.UserProfile{}, ErrUserDoesNotExist
+	}
+	if err != nil {
+		return domain.UserProfile{}, domain.WrapError(err)
+	}
+
+	// Hidden users shouldn't be distinguishable from users that don't exist
+	if user.IsDisabled() || user.IsHiddenFrom(viewerID) {
+		return domain.UserProfile{}, ErrUserDoesNotExist
+	}
+
+	rankings, err := i.rankingRepository.FindProfileRankings(ctx, userID)
+	if err != nil {
+		return domain.UserProfile{}, domain.WrapError(err)
+	}
+
+	totals, err := i.contestLogRepository.TotalsForUser(ctx, userID)
+	if err != nil {
+		return domain.UserProfile{}, domain.WrapError(err)
+	}
+
+	return domain.NewUserProfile(user, rankings, totals, units), nil
+}
+
+func (i *userInteractor) Preferences(ctx context.Context, userID uint64) (domain.Preferences, error) {
+	user, err := i.userRepository.FindByID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return domain.Preferences{}, ErrUserDoesNotExist
+	}
+	if err != nil {
+		return domain.Preferences{}, domain.WrapError(err)
+	}
+
+	if user.Preferences == nil {
+		return domain.Preferences{}, nil
+	}
+
+	return *user.Preferences, nil
+}
+
+func (i *userInteractor) UpdatePreferences(ctx context.Context, userID uint64, preferences domain.Preferences) error {
+	if valid, violations := i.validator.Validate(preferences); !valid {
+		return newValidationError(ErrInvalidPreferences, violations)
+	}
+
+	err := i.userRepository.UpdatePreferences(ctx, userID, preferences)
+	if err == domain.ErrNotFound {
+		return ErrUserDoesNotExist
+	}
+
+	return domain.WrapError(err)
+}
+
+func (i *userInteractor) Export(ctx context.Context, userID uint64) (UserExport, error) {
+	user, err := i.userRepository.FindByID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return UserExport{}, ErrUserDoesNotExist
+	}
+	if err != nil {
+		return UserExport{}, domain.WrapError(err)
+	}
+
+	rankings, err := i.rankingRepository.FindAllForUser(ctx, userID)
+	if err != nil {
+		return UserExport{}, domain.WrapError(err)
+	}
+
+	logs, err := i.contestLogRepository.FindAllForUser(ctx, userID)
+	if err != nil {
+		return UserExport{}, domain.WrapError(err)
+	}
+
+	return UserExport{
+		Profile:     user,
+		Preferences: user.Preferences,
+		Rankings:    rankings,
+		ContestLogs: logs,
+	}, nil
+}
+
+func (i *userInteractor) DeleteAccount(ctx context.Context, userID uint64, password string) error {
+	user, err := i.findWithPassword(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !i.passwordHasher.Compare(user.Password, password) {
+		return ErrPasswordIncorrect
+	}
+
+	err = i.transactions.Run(ctx, func(ctx context.Context) error {
+		return i.deleteAccount(ctx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Failed logins are kept by email address, which shouldn't outlive the account
+	err = i.loginAttemptRepository.Delete(ctx, domain.LoginAttemptAccountKey(user.Email))
+	return domain.WrapError(err)
+}
+
+// deleteAccount removes everything that can be traced back to the person behind an account, it has to run in a transaction
+func (i *userInteractor) deleteAccount(ctx context.Context, userID uint64) error {
+	if err := i.contestLogRepository.Purge(ctx, userID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	// Rankings of deleted users would only show up as empty entries on every leaderboard they were part of
+	if err := i.rankingRepository.DeleteAllForUser(ctx, userID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	// Sessions hold IP addresses and user agents, so they're deleted instead of revoked
+	if err := i.sessionRepository.DeleteAllForUser(ctx, userID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	if err := i.personalAccessTokenRepository.DeleteAllForUser(ctx, userID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	if err := i.twoFactorRepository.Delete(ctx, userID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	if err := i.recoveryCodeRepository.DeleteAllForUser(ctx, userID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	if err := i.oneTimeTokenRepository.DeleteAllForUser(ctx, userID); err != nil {
+		return domain.WrapError(err)
+	}
+
+	err := i.userRepository.Anonymize(ctx, userID)
+	return domain.WrapError(err)
+}
+
+// findWithPassword loads a user including their password hash, which FindByID leaves out on purpose
+func (i *userInteractor) findWithPassword(ctx context.Context, userID uint64) (domain.User, error) {
+	user, err := i.userRepository.FindByID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return domain.User{}, ErrUserDoesNotExist
+	}
+	if err != nil {
+		return domain.User{}, domain.WrapError(err)
+	}
+
+	user, err = i.userRepository.FindByEmail(ctx, user.Email)
+	if err != nil {
+		return domain.User{}, domain.WrapError(err)
+	}
+
+	return user, nil
 }
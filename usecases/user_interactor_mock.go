@@ -5,6 +5,7 @@
 package usecases
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/tadoku/api/domain"
 	reflect "reflect"
@@ -34,29 +35,130 @@ func (m *MockUserInteractor) EXPECT() *MockUserInteractorMockRecorder {
 }
 
 // UpdatePassword mocks base method
-func (m *MockUserInteractor) UpdatePassword(email, currentPassword, newPassword string) error {
+func (m *MockUserInteractor) UpdatePassword(ctx context.Context, email, currentPassword, newPassword string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePassword", email, currentPassword, newPassword)
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, email, currentPassword, newPassword)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdatePassword indicates an expected call of UpdatePassword
-func (mr *MockUserInteractorMockRecorder) UpdatePassword(email, currentPassword, newPassword interface{}) *gomock.Call {
+func (mr *MockUserInteractorMockRecorder) UpdatePassword(ctx, email, currentPassword, newPassword interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserInteractor)(nil).UpdatePassword), email, currentPassword, newPassword)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserInteractor)(nil).UpdatePassword), ctx, email, currentPassword, newPassword)
 }
 
 // UpdateProfile mocks base method
-func (m *MockUserInteractor) UpdateProfile(user domain.User) error {
+func (m *MockUserInteractor) UpdateProfile(ctx context.Context, user domain.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProfile", user)
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateProfile indicates an expected call of UpdateProfile
-func (mr *MockUserInteractorMockRecorder) UpdateProfile(user interface{}) *gomock.Call {
+func (mr *MockUserInteractorMockRecorder) UpdateProfile(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserInteractor)(nil).UpdateProfile), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserInteractor)(nil).UpdateProfile), ctx, user)
+}
+
+// RequestEmailChange mocks base method
+func (m *MockUserInteractor) RequestEmailChange(ctx context.Context, userID uint64, password, newEmail string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmailChange", ctx, userID, password, newEmail)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestEmailChange indicates an expected call of RequestEmailChange
+func (mr *MockUserInteractorMockRecorder) RequestEmailChange(ctx, userID, password, newEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmailChange", reflect.TypeOf((*MockUserInteractor)(nil).RequestEmailChange), ctx, userID, password, newEmail)
+}
+
+// ConfirmEmailChange mocks base method
+func (m *MockUserInteractor) ConfirmEmailChange(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange
+func (mr *MockUserInteractorMockRecorder) ConfirmEmailChange(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockUserInteractor)(nil).ConfirmEmailChange), ctx, token)
+}
+
+// Profile mocks base method
+func (m *MockUserInteractor) Profile(ctx context.Context, userID, viewerID uint64, units domain.Units) (domain.UserProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Profile", ctx, userID, viewerID, units)
+	ret0, _ := ret[0].(domain.UserProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Profile indicates an expected call of Profile
+func (mr *MockUserInteractorMockRecorder) Profile(ctx, userID, viewerID, units interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Profile", reflect.TypeOf((*MockUserInteractor)(nil).Profile), ctx, userID, viewerID, units)
+}
+
+// Preferences mocks base method
+func (m *MockUserInteractor) Preferences(ctx context.Context, userID uint64) (domain.Preferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preferences", ctx, userID)
+	ret0, _ := ret[0].(domain.Preferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preferences indicates an expected call of Preferences
+func (mr *MockUserInteractorMockRecorder) Preferences(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preferences", reflect.TypeOf((*MockUserInteractor)(nil).Preferences), ctx, userID)
+}
+
+// UpdatePreferences mocks base method
+func (m *MockUserInteractor) UpdatePreferences(ctx context.Context, userID uint64, preferences domain.Preferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", ctx, userID, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences
+func (mr *MockUserInteractorMockRecorder) UpdatePreferences(ctx, userID, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockUserInteractor)(nil).UpdatePreferences), ctx, userID, preferences)
+}
+
+// Export mocks base method
+func (m *MockUserInteractor) Export(ctx context.Context, userID uint64) (UserExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, userID)
+	ret0, _ := ret[0].(UserExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export
+func (mr *MockUserInteractorMockRecorder) Export(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockUserInteractor)(nil).Export), ctx, userID)
+}
+
+// DeleteAccount mocks base method
+func (m *MockUserInteractor) DeleteAccount(ctx context.Context, userID uint64, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount
+func (mr *MockUserInteractorMockRecorder) DeleteAccount(ctx, userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockUserInteractor)(nil).DeleteAccount), ctx, userID, password)
 }
@@ -1,7 +1,9 @@
 package usecases_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tadoku/api/domain"
@@ -10,25 +12,70 @@ import (
 	gomock "github.com/golang/mock/gomock"
 )
 
+type userTestMocks struct {
+	userRepo      *usecases.MockUserRepository
+	tokenRepo     *usecases.MockOneTimeTokenRepository
+	sessionRepo   *usecases.MockSessionRepository
+	patRepo       *usecases.MockPersonalAccessTokenRepository
+	twoFactorRepo *usecases.MockTwoFactorAuthenticationRepository
+	recoveryRepo  *usecases.MockRecoveryCodeRepository
+	attemptRepo   *usecases.MockLoginAttemptRepository
+	rankingRepo   *usecases.MockRankingRepository
+	logRepo       *usecases.MockContestLogRepository
+	pwHasher      *usecases.MockPasswordHasher
+	tokenGen      *usecases.MockTokenGenerator
+	mailer        *usecases.MockMailer
+	validator     *usecases.MockValidator
+}
+
 func setupUserTest(t *testing.T) (
 	*gomock.Controller,
-	*usecases.MockUserRepository,
-	*usecases.MockPasswordHasher,
+	*userTestMocks,
 	usecases.UserInteractor,
 ) {
 	ctrl := gomock.NewController(t)
 
-	repo := usecases.NewMockUserRepository(ctrl)
-	pwHasher := usecases.NewMockPasswordHasher(ctrl)
+	m := &userTestMocks{
+		userRepo:      usecases.NewMockUserRepository(ctrl),
+		tokenRepo:     usecases.NewMockOneTimeTokenRepository(ctrl),
+		sessionRepo:   usecases.NewMockSessionRepository(ctrl),
+		patRepo:       usecases.NewMockPersonalAccessTokenRepository(ctrl),
+		twoFactorRepo: usecases.NewMockTwoFactorAuthenticationRepository(ctrl),
+		recoveryRepo:  usecases.NewMockRecoveryCodeRepository(ctrl),
+		attemptRepo:   usecases.NewMockLoginAttemptRepository(ctrl),
+		rankingRepo:   usecases.NewMockRankingRepository(ctrl),
+		logRepo:       usecases.NewMockContestLogRepository(ctrl),
+		pwHasher:      usecases.NewMockPasswordHasher(ctrl),
+		tokenGen:      usecases.NewMockTokenGenerator(ctrl),
+		mailer:        usecases.NewMockMailer(ctrl),
+		validator:     usecases.NewMockValidator(ctrl),
+	}
 
-	interactor := usecases.NewUserInteractor(repo, pwHasher)
+	interactor := usecases.NewUserInteractor(
+		m.userRepo,
+		m.tokenRepo,
+		m.sessionRepo,
+		m.patRepo,
+		m.twoFactorRepo,
+		m.recoveryRepo,
+		m.attemptRepo,
+		m.rankingRepo,
+		m.logRepo,
+		newTransactionManager(ctrl),
+		m.pwHasher,
+		m.tokenGen,
+		m.mailer,
+		m.validator,
+		usecases.SessionConfig{EmailVerificationLifetime: time.Hour, FrontendURL: "https://tadoku.app"},
+	)
 
-	return ctrl, repo, pwHasher, interactor
+	return ctrl, m, interactor
 }
 
 func TestUserInteractor_UpdatePassword(t *testing.T) {
-	ctrl, repo, pwHasher, interactor := setupUserTest(t)
+	ctrl, m, interactor := setupUserTest(t)
 	defer ctrl.Finish()
+	repo, tokenRepo, pwHasher := m.userRepo, m.tokenRepo, m.pwHasher
 
 	{
 		// Happy path: valid user/password combination
@@ -36,42 +83,339 @@ func TestUserInteractor_UpdatePassword(t *testing.T) {
 		hashedUser := dbUser
 		hashedUser.Password = "foofoo"
 
-		repo.EXPECT().FindByEmail("foo@bar.com").Return(dbUser, nil)
-		repo.EXPECT().UpdatePassword(&hashedUser)
+		m.validator.EXPECT().Validate(domain.Password("barbar")).Return(true, nil)
+		repo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(dbUser, nil)
+		repo.EXPECT().UpdatePassword(gomock.Any(), &hashedUser)
+		tokenRepo.EXPECT().InvalidateAllForUser(gomock.Any(), domain.TokenPurposePasswordReset, dbUser.ID)
 		pwHasher.EXPECT().Compare(dbUser.Password, "foobar").Return(true)
 		pwHasher.EXPECT().Hash(domain.Password("barbar")).Return(hashedUser.Password, nil)
 
-		err := interactor.UpdatePassword("foo@bar.com", "foobar", "barbar")
+		err := interactor.UpdatePassword(context.Background(), "foo@bar.com", "foobar", "barbar")
 		assert.NoError(t, err)
 	}
 
+	{
+		// Sad path: the new password is too short
+		m.validator.EXPECT().Validate(domain.Password("foo")).Return(false, domain.FieldErrors{domain.NewFieldError("password", "min_length", domain.ErrPasswordTooShort)})
+		err := interactor.UpdatePassword(context.Background(), "foo@bar.com", "foobar", "foo")
+		assert.EqualError(t, err, usecases.ErrInvalidNewPassword.Error())
+	}
+
 	{
 		// Sad path: user does not exist
-		repo.EXPECT().FindByEmail("bar@bar.com").Return(domain.User{}, nil)
-		err := interactor.UpdatePassword("bar@bar.com", "foobar", "barbar")
+		m.validator.EXPECT().Validate(domain.Password("barbar")).Return(true, nil)
+		repo.EXPECT().FindByEmail(gomock.Any(), "bar@bar.com").Return(domain.User{}, nil)
+		err := interactor.UpdatePassword(context.Background(), "bar@bar.com", "foobar", "barbar")
 		assert.EqualError(t, err, usecases.ErrUserDoesNotExist.Error())
 	}
 
 	{
 		// Sad path: password is incorrect
 		user := domain.User{ID: 1, Email: "foo@bar.com", Password: "barbar"}
-		repo.EXPECT().FindByEmail("foo@bar.com").Return(user, nil)
+		m.validator.EXPECT().Validate(domain.Password("foofoo")).Return(true, nil)
+		repo.EXPECT().FindByEmail(gomock.Any(), "foo@bar.com").Return(user, nil)
 		pwHasher.EXPECT().Compare(user.Password, "foobar").Return(false)
-		err := interactor.UpdatePassword("foo@bar.com", "foobar", "foofoo")
+		err := interactor.UpdatePassword(context.Background(), "foo@bar.com", "foobar", "foofoo")
 		assert.EqualError(t, err, usecases.ErrPasswordIncorrect.Error())
 	}
 }
 
 func TestUserInteractor_UpdateProfile(t *testing.T) {
-	ctrl, repo, _, interactor := setupUserTest(t)
+	ctrl, m, interactor := setupUserTest(t)
 	defer ctrl.Finish()
+	repo := m.userRepo
 
 	{
 		user := domain.User{ID: 1, DisplayName: "test", Email: "foo@bar.com", Password: ""}
 
-		repo.EXPECT().Store(&user)
+		repo.EXPECT().Store(gomock.Any(), &user)
+
+		err := interactor.UpdateProfile(context.Background(), user)
+		assert.NoError(t, err)
+	}
+}
+
+func TestUserInteractor_Export(t *testing.T) {
+	ctrl, m, interactor := setupUserTest(t)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: everything including deleted logs is exported
+		deletedAt := time.Now()
+		user := domain.User{ID: 1, Email: "foo@bar.com", Preferences: &domain.Preferences{}}
+		rankings := domain.Rankings{{ID: 1, ContestID: 1, UserID: 1}}
+		logs := domain.ContestLogs{{ID: 1, UserID: 1}, {ID: 2, UserID: 1, DeletedAt: &deletedAt}}
+
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		m.rankingRepo.EXPECT().FindAllForUser(gomock.Any(), user.ID).Return(rankings, nil)
+		m.logRepo.EXPECT().FindAllForUser(gomock.Any(), user.ID).Return(logs, nil)
+
+		export, err := interactor.Export(context.Background(), user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, user, export.Profile)
+		assert.Equal(t, user.Preferences, export.Preferences)
+		assert.Equal(t, rankings, export.Rankings)
+		assert.Equal(t, logs, export.ContestLogs)
+	}
+
+	{
+		// Sad path: user does not exist
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(2)).Return(domain.User{}, domain.ErrNotFound)
+
+		_, err := interactor.Export(context.Background(), 2)
+		assert.EqualError(t, err, usecases.ErrUserDoesNotExist.Error())
+	}
+}
+
+func TestUserInteractor_DeleteAccount(t *testing.T) {
+	ctrl, m, interactor := setupUserTest(t)
+	defer ctrl.Finish()
+
+	user := domain.User{ID: 1, Email: "foo@bar.com", Password: "hashed"}
+
+	{
+		// Happy path: logs, rankings and personal data are purged
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(domain.User{ID: 1, Email: user.Email}, nil)
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+		m.pwHasher.EXPECT().Compare(user.Password, "foobar").Return(true)
+		gomock.InOrder(
+			m.logRepo.EXPECT().Purge(gomock.Any(), user.ID),
+			m.rankingRepo.EXPECT().DeleteAllForUser(gomock.Any(), user.ID),
+			m.sessionRepo.EXPECT().DeleteAllForUser(gomock.Any(), user.ID),
+			m.patRepo.EXPECT().DeleteAllForUser(gomock.Any(), user.ID),
+			m.twoFactorRepo.EXPECT().Delete(gomock.Any(), user.ID),
+			m.recoveryRepo.EXPECT().DeleteAllForUser(gomock.Any(), user.ID),
+			m.tokenRepo.EXPECT().DeleteAllForUser(gomock.Any(), user.ID),
+			m.userRepo.EXPECT().Anonymize(gomock.Any(), user.ID),
+			m.attemptRepo.EXPECT().Delete(gomock.Any(), domain.LoginAttemptAccountKey(user.Email)),
+		)
+
+		err := interactor.DeleteAccount(context.Background(), user.ID, "foobar")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: password is incorrect
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(domain.User{ID: 1, Email: user.Email}, nil)
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+		m.pwHasher.EXPECT().Compare(user.Password, "barbar").Return(false)
+
+		err := interactor.DeleteAccount(context.Background(), user.ID, "barbar")
+		assert.EqualError(t, err, usecases.ErrPasswordIncorrect.Error())
+	}
+}
+
+func TestUserInteractor_RequestEmailChange(t *testing.T) {
+	ctrl, m, interactor := setupUserTest(t)
+	defer ctrl.Finish()
+
+	user := domain.User{ID: 1, Email: "foo@bar.com", Password: "hashed"}
+
+	{
+		// Happy path: confirmation goes to the new address, a notice to the old one
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(domain.User{ID: 1, Email: user.Email}, nil)
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+		m.pwHasher.EXPECT().Compare(user.Password, "foobar").Return(true)
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "new@bar.com").Return(domain.User{}, domain.ErrNotFound)
+		m.tokenRepo.EXPECT().InvalidateAllForUser(gomock.Any(), domain.TokenPurposeEmailChange, user.ID)
+		m.tokenGen.EXPECT().Generate().Return("token", nil)
+		m.tokenRepo.EXPECT().Store(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, request *domain.OneTimeToken) error {
+			assert.Equal(t, user.ID, request.UserID)
+			assert.Equal(t, "new@bar.com", request.Payload)
+			assert.Equal(t, domain.HashToken("token"), request.Hash)
+			return nil
+		})
+		m.mailer.EXPECT().Send(gomock.Any()).DoAndReturn(func(mail usecases.Mail) error {
+			assert.Equal(t, "new@bar.com", mail.To)
+			assert.Contains(t, mail.Body, "https://tadoku.app/confirm_email_change?token=token")
+			return nil
+		})
+		m.mailer.EXPECT().Send(gomock.Any()).DoAndReturn(func(mail usecases.Mail) error {
+			assert.Equal(t, user.Email, mail.To)
+			assert.NotContains(t, mail.Body, "token")
+			return nil
+		})
+
+		err := interactor.RequestEmailChange(context.Background(), user.ID, "foobar", "new@bar.com")
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: invalid email address
+		err := interactor.RequestEmailChange(context.Background(), user.ID, "foobar", "new")
+		assert.EqualError(t, err, domain.ErrEmailInvalid.Error())
+	}
+
+	{
+		// Sad path: password is incorrect
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(domain.User{ID: 1, Email: user.Email}, nil)
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+		m.pwHasher.EXPECT().Compare(user.Password, "barbar").Return(false)
+
+		err := interactor.RequestEmailChange(context.Background(), user.ID, "barbar", "new@bar.com")
+		assert.EqualError(t, err, usecases.ErrPasswordIncorrect.Error())
+	}
+
+	{
+		// Sad path: address is already taken
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(domain.User{ID: 1, Email: user.Email}, nil)
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), user.Email).Return(user, nil)
+		m.pwHasher.EXPECT().Compare(user.Password, "foobar").Return(true)
+		m.userRepo.EXPECT().FindByEmail(gomock.Any(), "taken@bar.com").Return(domain.User{ID: 2}, nil)
+
+		err := interactor.RequestEmailChange(context.Background(), user.ID, "foobar", "taken@bar.com")
+		assert.EqualError(t, err, usecases.ErrEmailAlreadyInUse.Error())
+	}
+}
+
+func TestUserInteractor_ConfirmEmailChange(t *testing.T) {
+	ctrl, m, interactor := setupUserTest(t)
+	defer ctrl.Finish()
+
+	request := domain.OneTimeToken{ID: 1, UserID: 1, Purpose: domain.TokenPurposeEmailChange, Payload: "new@bar.com", ExpiresAt: time.Now().Add(time.Hour)}
+
+	{
+		// Happy path: email gets updated
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposeEmailChange, domain.HashToken("token")).Return(request, nil)
+		m.tokenRepo.EXPECT().MarkAsUsed(gomock.Any(), request.ID)
+		m.userRepo.EXPECT().UpdateEmail(gomock.Any(), request.UserID, request.Payload)
 
-		err := interactor.UpdateProfile(user)
+		err := interactor.ConfirmEmailChange(context.Background(), "token")
 		assert.NoError(t, err)
 	}
+
+	{
+		// Sad path: expired request
+		expired := request
+		expired.ExpiresAt = time.Now().Add(-time.Hour)
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposeEmailChange, domain.HashToken("expired")).Return(expired, nil)
+
+		err := interactor.ConfirmEmailChange(context.Background(), "expired")
+		assert.EqualError(t, err, usecases.ErrEmailChangeRequestInvalid.Error())
+	}
+
+	{
+		// Sad path: unknown token
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposeEmailChange, domain.HashToken("unknown")).Return(domain.OneTimeToken{}, domain.ErrNotFound)
+
+		err := interactor.ConfirmEmailChange(context.Background(), "unknown")
+		assert.EqualError(t, err, usecases.ErrEmailChangeRequestInvalid.Error())
+	}
+
+	{
+		// Sad path: someone else took the address in the meantime
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposeEmailChange, domain.HashToken("token")).Return(request, nil)
+		m.tokenRepo.EXPECT().MarkAsUsed(gomock.Any(), request.ID)
+		m.userRepo.EXPECT().UpdateEmail(gomock.Any(), request.UserID, request.Payload).Return(domain.ErrAlreadyExists)
+
+		err := interactor.ConfirmEmailChange(context.Background(), "token")
+		assert.EqualError(t, err, usecases.ErrEmailAlreadyInUse.Error())
+	}
+
+	{
+		// Sad path: account got disabled or deleted after requesting the change
+		m.tokenRepo.EXPECT().FindByHash(gomock.Any(), domain.TokenPurposeEmailChange, domain.HashToken("token")).Return(request, nil)
+		m.tokenRepo.EXPECT().MarkAsUsed(gomock.Any(), request.ID)
+		m.userRepo.EXPECT().UpdateEmail(gomock.Any(), request.UserID, request.Payload).Return(domain.ErrNotFound)
+
+		err := interactor.ConfirmEmailChange(context.Background(), "token")
+		assert.EqualError(t, err, usecases.ErrEmailChangeRequestInvalid.Error())
+	}
+}
+
+func TestUserInteractor_Profile(t *testing.T) {
+	ctrl, m, interactor := setupUserTest(t)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: profile is aggregated from rankings and logs
+		user := domain.User{ID: 1, DisplayName: "foo", Role: domain.RoleUser, Preferences: &domain.Preferences{}}
+		rankings := []domain.ProfileRanking{{ContestID: 1, Language: domain.Global, Amount: 10, Rank: 1}}
+		totals := []domain.ContestLogTotal{{Language: domain.Japanese, MediumID: domain.MediumBook, Amount: 10}}
+
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		m.rankingRepo.EXPECT().FindProfileRankings(gomock.Any(), user.ID).Return(rankings, nil)
+		m.logRepo.EXPECT().TotalsForUser(gomock.Any(), user.ID).Return(totals, nil)
+
+		profile, err := interactor.Profile(context.Background(), user.ID, 0, domain.UnitsPoints)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.NewUserProfile(user, rankings, totals, domain.UnitsPoints), profile)
+	}
+
+	{
+		// Sad path: user hides themselves
+		user := domain.User{ID: 2, Role: domain.RoleUser, Preferences: &domain.Preferences{HideFromRankings: true}}
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+
+		_, err := interactor.Profile(context.Background(), user.ID, 1, domain.UnitsPoints)
+		assert.EqualError(t, err, usecases.ErrUserDoesNotExist.Error())
+	}
+
+	{
+		// Happy path: users that hide themselves can still see their own profile
+		user := domain.User{ID: 2, Role: domain.RoleUser, Preferences: &domain.Preferences{HideFromRankings: true}}
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+		m.rankingRepo.EXPECT().FindProfileRankings(gomock.Any(), user.ID).Return(nil, nil)
+		m.logRepo.EXPECT().TotalsForUser(gomock.Any(), user.ID).Return(nil, nil)
+
+		profile, err := interactor.Profile(context.Background(), user.ID, user.ID, domain.UnitsPoints)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, profile.UserID)
+	}
+
+	{
+		// Sad path: user has been disabled
+		user := domain.User{ID: 3, Role: domain.RoleDisabled}
+		m.userRepo.EXPECT().FindByID(gomock.Any(), user.ID).Return(user, nil)
+
+		_, err := interactor.Profile(context.Background(), user.ID, user.ID, domain.UnitsPoints)
+		assert.EqualError(t, err, usecases.ErrUserDoesNotExist.Error())
+	}
+}
+
+func TestUserInteractor_Preferences(t *testing.T) {
+	ctrl, m, interactor := setupUserTest(t)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: stored preferences
+		preferences := domain.Preferences{Timezone: "Asia/Tokyo"}
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(1)).Return(domain.User{ID: 1, Preferences: &preferences}, nil)
+
+		result, err := interactor.Preferences(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, preferences, result)
+	}
+
+	{
+		// Sad path: user does not exist
+		m.userRepo.EXPECT().FindByID(gomock.Any(), uint64(2)).Return(domain.User{}, domain.ErrNotFound)
+
+		_, err := interactor.Preferences(context.Background(), 2)
+		assert.EqualError(t, err, usecases.ErrUserDoesNotExist.Error())
+	}
+}
+
+func TestUserInteractor_UpdatePreferences(t *testing.T) {
+	ctrl, m, interactor := setupUserTest(t)
+	defer ctrl.Finish()
+
+	{
+		// Happy path: valid preferences
+		preferences := domain.Preferences{Timezone: "Asia/Tokyo", HideFromRankings: true}
+		m.validator.EXPECT().Validate(preferences).Return(true, nil)
+		m.userRepo.EXPECT().UpdatePreferences(gomock.Any(), uint64(1), preferences)
+
+		err := interactor.UpdatePreferences(context.Background(), 1, preferences)
+		assert.NoError(t, err)
+	}
+
+	{
+		// Sad path: invalid preferences
+		preferences := domain.Preferences{Timezone: "foo"}
+		m.validator.EXPECT().Validate(preferences).Return(false, domain.FieldErrors{domain.NewFieldError("timezone", "timezone", domain.ErrTimezoneInvalid)})
+
+		err := interactor.UpdatePreferences(context.Background(), 1, preferences)
+		assert.EqualError(t, err, usecases.ErrInvalidPreferences.Error())
+	}
 }
@@ -2,8 +2,32 @@
 
 package usecases
 
-// Validator validates entities
+import (
+	"github.com/tadoku/api/domain"
+)
+
+// Validator validates entities, and lists every field that's invalid when they aren't
 type Validator interface {
-	ValidateStruct(s interface{}) (bool, error)
-	Validate(target Validatable) (bool, error)
+	ValidateStruct(s interface{}) (valid bool, violations domain.FieldErrors)
+	Validate(target Validatable) (valid bool, violations domain.FieldErrors)
+}
+
+// ValidationError is returned when an entity is invalid, it unwraps into the error interactors returned before
+// so it can still be compared with errors.Is
+type ValidationError struct {
+	Err        error
+	Violations domain.FieldErrors
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap gives back the error the violations are attached to
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func newValidationError(err error, violations domain.FieldErrors) error {
+	return &ValidationError{Err: err, Violations: violations}
 }
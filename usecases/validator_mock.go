@@ -6,6 +6,7 @@ package usecases
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	domain "github.com/tadoku/api/domain"
 	reflect "reflect"
 )
 
@@ -33,11 +34,11 @@ func (m *MockValidator) EXPECT() *MockValidatorMockRecorder {
 }
 
 // ValidateStruct mocks base method
-func (m *MockValidator) ValidateStruct(s interface{}) (bool, error) {
+func (m *MockValidator) ValidateStruct(s interface{}) (bool, domain.FieldErrors) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ValidateStruct", s)
 	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
+	ret1, _ := ret[1].(domain.FieldErrors)
 	return ret0, ret1
 }
 
@@ -48,11 +49,11 @@ func (mr *MockValidatorMockRecorder) ValidateStruct(s interface{}) *gomock.Call
 }
 
 // Validate mocks base method
-func (m *MockValidator) Validate(target Validatable) (bool, error) {
+func (m *MockValidator) Validate(target Validatable) (bool, domain.FieldErrors) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Validate", target)
 	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
+	ret1, _ := ret[1].(domain.FieldErrors)
 	return ret0, ret1
 }
 